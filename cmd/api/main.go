@@ -20,14 +20,18 @@ import (
 	"github.com/umar5678/go-backend/internal/middleware"
 	"github.com/umar5678/go-backend/internal/modules/admin"
 	"github.com/umar5678/go-backend/internal/modules/auth"
+	"github.com/umar5678/go-backend/internal/modules/commission"
 	"github.com/umar5678/go-backend/internal/modules/drivers"
+	"github.com/umar5678/go-backend/internal/modules/geofences"
 	"github.com/umar5678/go-backend/internal/modules/homeservices"
 	homeservicesAdmin "github.com/umar5678/go-backend/internal/modules/homeservices/admin"
 	homeservicesCustomer "github.com/umar5678/go-backend/internal/modules/homeservices/customer"
 	_ "github.com/umar5678/go-backend/internal/modules/homeservices/dto" // Alias for clarity
 	homeservicesProvider "github.com/umar5678/go-backend/internal/modules/homeservices/provider"
+	"github.com/umar5678/go-backend/internal/modules/jobs"
 	"github.com/umar5678/go-backend/internal/modules/laundry"
 	"github.com/umar5678/go-backend/internal/modules/pricing"
+	"github.com/umar5678/go-backend/internal/modules/ratings"
 	_ "github.com/umar5678/go-backend/internal/modules/ratings/dto"
 	"github.com/umar5678/go-backend/internal/modules/riders"
 	"github.com/umar5678/go-backend/internal/modules/rides"
@@ -37,7 +41,13 @@ import (
 	"github.com/umar5678/go-backend/internal/modules/vehicles"
 	_ "github.com/umar5678/go-backend/internal/modules/vehicles/dto"
 	"github.com/umar5678/go-backend/internal/modules/wallet"
+	"github.com/umar5678/go-backend/internal/services/authstate"
 	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/services/mail"
+	"github.com/umar5678/go-backend/internal/services/otp"
+	"github.com/umar5678/go-backend/internal/services/permissions"
+	"github.com/umar5678/go-backend/internal/services/routing"
+	"github.com/umar5678/go-backend/internal/services/sms"
 	"github.com/umar5678/go-backend/internal/utils/logger"
 	"github.com/umar5678/go-backend/internal/websocket"
 
@@ -167,6 +177,11 @@ func main() {
 	router.GET("/health", healthCheck)
 	router.GET("/ready", readyCheck(db))
 
+	// Background workers started by the modules below
+	var rideDispatcher *rides.Dispatcher
+	var surgeEngine *pricing.SurgeEngine
+	var scheduler *jobs.Scheduler
+
 	// API routes
 	v1 := router.Group("/api/v1")
 	{
@@ -183,20 +198,29 @@ func main() {
 		spRepo := serviceproviders.NewRepository(db)
 		spService := serviceproviders.NewService(spRepo)
 
+		// One-time codes for phone auth, delivered by SMS
+		otpService := otp.NewService(cfg.OTP, sms.NewSender(cfg.SMS))
+
 		// Auth module
 		authRepo := auth.NewRepository(db)
-		authService := auth.NewService(authRepo, cfg, ridersService, spService)
+		authService := auth.NewService(authRepo, cfg, ridersService, spService, otpService, mail.NewMailer(cfg.Mail))
 		authHandler := auth.NewHandler(authService)
-		authMiddleware := middleware.Auth(cfg)
+		accountChecker := authstate.NewChecker(db, cfg.JWT.AccountCacheTTL)
+		authMiddleware := middleware.Auth(cfg, accountChecker)
+		permissionChecker := permissions.NewChecker(db, cfg.JWT.AccountCacheTTL)
 		auth.RegisterRoutes(v1, authHandler, authMiddleware)
 
 		// Register riders routes
 		riders.RegisterRoutes(v1, ridersHandler, authMiddleware)
 		// Wallet module
 		walletRepo := wallet.NewRepository(db)
-		walletService := wallet.NewService(walletRepo, db)
+		paymentProvider, err := wallet.NewPaymentProvider(&cfg.Payments, cfg.App.Environment)
+		if err != nil {
+			logger.Fatal("failed to create payment provider", "error", err)
+		}
+		walletService := wallet.NewService(walletRepo, db, paymentProvider)
 		walletHandler := wallet.NewHandler(walletService)
-		wallet.RegisterRoutes(v1, walletHandler, authMiddleware)
+		wallet.RegisterRoutes(v1, walletHandler, authMiddleware, permissionChecker)
 
 		// Vehicle Types module
 		vehiclesRepo := vehicles.NewRepository(db)
@@ -210,17 +234,36 @@ func main() {
 		driversHandler := drivers.NewHandler(driversService)
 		drivers.RegisterRoutes(v1, driversHandler, authMiddleware)
 
+		// Road routing (distance / ETA) shared by tracking and pricing
+		routingProvider := routing.NewProvider(cfg.Routing)
+
 		// tracking service
 		trackingRepo := tracking.NewRepository(db)
-		trackingService := tracking.NewService(trackingRepo)
+		trackingService := tracking.NewService(trackingRepo, routingProvider)
 		trackingHandler := tracking.NewHandler(trackingService)
 		tracking.RegisterRoutes(v1, trackingHandler, authMiddleware)
 
+		// Geofences (operating cities, no-service zones, airports)
+		geofencesRepo := geofences.NewRepository(db)
+		geofencesService := geofences.NewService(geofencesRepo)
+		geofencesHandler := geofences.NewHandler(geofencesService)
+		geofences.RegisterRoutes(v1, geofencesHandler, authMiddleware, permissionChecker)
+
 		// Pricing module
 		pricingRepo := pricing.NewRepository(db)
-		pricingService := pricing.NewService(pricingRepo, vehiclesRepo)
+		pricingService := pricing.NewService(pricingRepo, vehiclesRepo, routingProvider, geofencesService, cfg.Pricing)
 		pricingHandler := pricing.NewHandler(pricingService)
-		pricing.RegisterRoutes(v1, pricingHandler)
+		pricing.RegisterRoutes(v1, pricingHandler, authMiddleware, permissionChecker)
+
+		// Surge engine (demand/supply multipliers per geohash cell)
+		surgeEngine = pricing.NewSurgeEngine(pricingRepo, cfg.Surge)
+		surgeEngine.Start()
+
+		// Commission rules (used by every payout)
+		commissionRepo := commission.NewRepository(db)
+		commissionService := commission.NewService(commissionRepo)
+		commissionHandler := commission.NewHandler(commissionService)
+		commission.RegisterRoutes(v1, commissionHandler, authMiddleware, permissionChecker)
 
 		// rides service
 		ridesRepo := rides.NewRepository(db)
@@ -230,25 +273,42 @@ func main() {
 			ridersRepo,
 			pricingService,
 			trackingService,
+			trackingRepo,
 			walletService,
+			commissionService,
+			geofencesService,
+			cfg,
 		)
 		ridesHandler := rides.NewHandler(ridesService)
-		rides.RegisterRoutes(v1, ridesHandler, authMiddleware)
+		rides.RegisterRoutes(v1, ridesHandler, authMiddleware, permissionChecker)
+		rides.RegisterWebSocketHandlers(wsManager, ridesService)
+
+		// Ride dispatch workers (resume in-flight searches on boot)
+		rideDispatcher = rides.NewDispatcher(ridesRepo, ridesService, cfg.Dispatch)
+		rideDispatcher.Start()
 
 		// WebSocket routes
-		websocket.RegisterRoutes(router, cfg, wsServer)
+		websocket.RegisterRoutes(router, cfg, wsServer, accountChecker, permissionChecker)
 
 		// Admin module
 		adminRepo := admin.NewRepository(db)
-		adminService := admin.NewService(adminRepo, spRepo)
+		adminService := admin.NewService(adminRepo, spRepo, authService, wsManager.Hub())
 		adminHandler := admin.NewHandler(adminService)
-		admin.RegisterRoutes(v1, adminHandler, authMiddleware)
+		adminRoleService := admin.NewRoleService(admin.NewRoleRepository(db), adminRepo, permissionChecker)
+		adminRoleHandler := admin.NewRoleHandler(adminRoleService)
+		admin.RegisterRoutes(v1, adminHandler, adminRoleHandler, authMiddleware, permissionChecker)
 
 		// Home Services module
 		homeServicesRepo := homeservices.NewRepository(db)
-		homeServicesService := homeservices.NewService(homeServicesRepo, walletService, cfg)
+		homeServicesService := homeservices.NewService(homeServicesRepo, walletService, commissionService, geofencesService, cfg)
 		homeServicesHandler := homeservices.NewHandler(homeServicesService)
-		homeservices.RegisterRoutes(v1, homeServicesHandler, authMiddleware)
+		homeservices.RegisterRoutes(v1, homeServicesHandler, authMiddleware, permissionChecker)
+
+		// Ratings (home service orders and rides)
+		ratingsRepo := ratings.NewRepository(db)
+		ratingsService := ratings.NewService(ratingsRepo, homeServicesRepo, ridesRepo, driversRepo, ridersService, cfg.Ratings)
+		ratingsHandler := ratings.NewHandler(ratingsService)
+		ratings.RegisterRoutes(v1, ratingsHandler, authMiddleware, permissionChecker)
 
 		// Admin Home Services
 		homeservicesAdminRepo := homeservicesAdmin.NewRepository(db)
@@ -268,6 +328,7 @@ func main() {
 			homeservicesAdminHandler,
 			homeservicesAdminOrderHandler,
 			authMiddleware,
+			permissionChecker,
 		)
 
 		// Customer Home Services
@@ -277,7 +338,7 @@ func main() {
 
 		// Customer Order Management
 		homeservicesOrderRepo := homeservicesCustomer.NewOrderRepository(db)
-		homeservicesOrderService := homeservicesCustomer.NewOrderService(homeservicesOrderRepo, homeservicesCustomerRepo, mockWalletService)
+		homeservicesOrderService := homeservicesCustomer.NewOrderService(homeservicesOrderRepo, homeservicesCustomerRepo, mockWalletService, commissionService, geofencesService)
 		homeservicesOrderHandler := homeservicesCustomer.NewOrderHandler(homeservicesOrderService)
 
 		homeservicesCustomer.RegisterRoutes(v1, homeservicesCustomerHandler, homeservicesOrderHandler, authMiddleware)
@@ -297,7 +358,65 @@ func main() {
 		)
 
 		// Laundry Service module
-		laundry.RegisterRoutes(router, db, cfg)
+		laundryService := laundry.NewService(laundry.NewRepository(db), db, geofencesService, commissionService, walletService)
+		laundry.RegisterRoutes(router, laundryService, cfg, authMiddleware)
+
+		// Background jobs (one instance runs them, elected through Redis)
+		jobsRepo := jobs.NewRepository(db)
+		scheduler = jobs.NewScheduler(jobsRepo, cfg.Scheduler)
+		scheduler.Register(jobs.Job{
+			Name:     "wallet.release_expired_holds",
+			Interval: cfg.Scheduler.SweepInterval,
+			Run:      walletService.ReleaseExpiredHolds,
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "rides.expire_requests",
+			Interval: cfg.Scheduler.SweepInterval,
+			Run:      ridesService.ExpireRideRequests,
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "rides.cancel_abandoned_searches",
+			Interval: cfg.Scheduler.SweepInterval,
+			Run: func(ctx context.Context) (int64, error) {
+				return ridesService.CancelAbandonedSearches(ctx, cfg.Scheduler.SearchingRideTimeout)
+			},
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "rides.start_scheduled_rides",
+			Interval: cfg.Scheduler.SweepInterval,
+			Run:      ridesService.StartScheduledRides,
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "homeservices.expire_unaccepted_orders",
+			Interval: cfg.Scheduler.SweepInterval,
+			Run:      homeservicesOrderService.ExpireUnacceptedOrders,
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "laundry.cancel_unassigned_orders",
+			Interval: cfg.Scheduler.SweepInterval,
+			Run: func(ctx context.Context) (int64, error) {
+				return laundryService.CancelUnassignedOrders(ctx, cfg.Scheduler.LaundryOrderTimeout)
+			},
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "websocket.delete_old_messages",
+			Interval: cfg.Scheduler.CleanupInterval,
+			Run: func(ctx context.Context) (int64, error) {
+				return 0, websocket.NewRedisMessageStore().DeleteOld(ctx, cfg.Scheduler.MessageRetention)
+			},
+		})
+		scheduler.Register(jobs.Job{
+			Name:     "jobs.delete_old_runs",
+			Interval: cfg.Scheduler.CleanupInterval,
+			Run: func(ctx context.Context) (int64, error) {
+				return jobsRepo.DeleteRunsBefore(ctx, time.Now().Add(-cfg.Scheduler.HistoryRetention))
+			},
+		})
+		scheduler.Start()
+
+		jobsService := jobs.NewService(jobsRepo, scheduler)
+		jobsHandler := jobs.NewHandler(jobsService)
+		jobs.RegisterRoutes(v1, jobsHandler, authMiddleware, permissionChecker)
 
 		// Add other modules here...
 	}
@@ -342,6 +461,11 @@ func main() {
 		logger.Error("server forced to shutdown", "error", err)
 	}
 
+	// Hand in-flight driver searches back to the queue for the next instance
+	rideDispatcher.Stop()
+	surgeEngine.Stop()
+	scheduler.Stop()
+
 	logger.Info("server stopped gracefully")
 }
 
@@ -3,7 +3,10 @@ package config
 // base config, app-root level ,. envs, etc. /internal.config/config.go
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -61,7 +64,7 @@ func LoadConfig() (*Config, error) {
 	if headersStr != "" {
 		cfg.Server.CORS.AllowedHeaders = strings.Split(headersStr, ",")
 	} else {
-		cfg.Server.CORS.AllowedHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+		cfg.Server.CORS.AllowedHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key"}
 	}
 
 	cfg.Server.CORS.AllowCredentials = v.GetBool("CORS_ALLOW_CREDENTIALS")
@@ -120,6 +123,11 @@ func LoadConfig() (*Config, error) {
 	cfg.JWT.AccessExpiry = v.GetDuration("JWT_ACCESS_EXPIRY") * time.Hour
 	cfg.JWT.RefreshExpiry = v.GetDuration("JWT_REFRESH_EXPIRY") * time.Hour
 	cfg.JWT.Issuer = v.GetString("JWT_ISSUER")
+	cfg.JWT.AccountCacheTTL = v.GetDuration("JWT_ACCOUNT_CACHE_TTL") * time.Second
+
+	if cfg.JWT.AccountCacheTTL == 0 {
+		cfg.JWT.AccountCacheTTL = 5 * time.Minute
+	}
 
 	// Logger Config
 	cfg.Logger.Level = v.GetString("LOG_LEVEL")
@@ -127,9 +135,342 @@ func LoadConfig() (*Config, error) {
 	cfg.Logger.Output = v.GetString("LOG_OUTPUT")
 	cfg.Logger.FilePath = v.GetString("LOG_FILE_PATH")
 
+	// Dispatch Config
+	cfg.Dispatch.Workers = v.GetInt("DISPATCH_WORKERS")
+	cfg.Dispatch.PollInterval = v.GetDuration("DISPATCH_POLL_INTERVAL") * time.Second
+	cfg.Dispatch.LeaseDuration = v.GetDuration("DISPATCH_LEASE_DURATION") * time.Second
+	cfg.Dispatch.MaxAttempts = v.GetInt("DISPATCH_MAX_ATTEMPTS")
+
+	if cfg.Dispatch.Workers == 0 {
+		cfg.Dispatch.Workers = 4
+	}
+	if cfg.Dispatch.PollInterval == 0 {
+		cfg.Dispatch.PollInterval = 2 * time.Second
+	}
+	if cfg.Dispatch.LeaseDuration == 0 {
+		cfg.Dispatch.LeaseDuration = 60 * time.Second
+	}
+	if cfg.Dispatch.MaxAttempts == 0 {
+		cfg.Dispatch.MaxAttempts = 3
+	}
+
+	// Dispatch policy
+	cfg.Dispatch.WaveSize = v.GetInt("DISPATCH_WAVE_SIZE")
+	cfg.Dispatch.WaveTimeout = v.GetDuration("DISPATCH_WAVE_TIMEOUT") * time.Second
+	cfg.Dispatch.CandidateLimit = v.GetInt("DISPATCH_CANDIDATE_LIMIT")
+	cfg.Dispatch.MaxSearchTime = v.GetDuration("DISPATCH_MAX_SEARCH_TIME") * time.Second
+
+	radiiStr := v.GetString("DISPATCH_RADIUS_SCHEDULE_KM")
+	if radiiStr != "" {
+		for _, r := range strings.Split(radiiStr, ",") {
+			radius, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+			if err != nil || radius <= 0 {
+				return nil, fmt.Errorf("invalid DISPATCH_RADIUS_SCHEDULE_KM entry %q", r)
+			}
+			cfg.Dispatch.RadiusScheduleKm = append(cfg.Dispatch.RadiusScheduleKm, radius)
+		}
+	} else {
+		cfg.Dispatch.RadiusScheduleKm = []float64{3, 5, 8}
+	}
+
+	if cfg.Dispatch.WaveSize == 0 {
+		cfg.Dispatch.WaveSize = 3
+	}
+	if cfg.Dispatch.WaveTimeout == 0 {
+		cfg.Dispatch.WaveTimeout = 15 * time.Second
+	}
+	if cfg.Dispatch.CandidateLimit == 0 {
+		cfg.Dispatch.CandidateLimit = 15
+	}
+	if cfg.Dispatch.MaxSearchTime == 0 {
+		cfg.Dispatch.MaxSearchTime = 90 * time.Second
+	}
+	// Unset means the default above; anything left is negative
+	if cfg.Dispatch.WaveSize <= 0 {
+		return nil, fmt.Errorf("invalid DISPATCH_WAVE_SIZE %d, must be positive", cfg.Dispatch.WaveSize)
+	}
+	if cfg.Dispatch.WaveTimeout <= 0 {
+		return nil, fmt.Errorf("invalid DISPATCH_WAVE_TIMEOUT %v, must be positive", cfg.Dispatch.WaveTimeout)
+	}
+	if cfg.Dispatch.CandidateLimit <= 0 {
+		return nil, fmt.Errorf("invalid DISPATCH_CANDIDATE_LIMIT %d, must be positive", cfg.Dispatch.CandidateLimit)
+	}
+	if cfg.Dispatch.MaxSearchTime <= 0 {
+		return nil, fmt.Errorf("invalid DISPATCH_MAX_SEARCH_TIME %v, must be positive", cfg.Dispatch.MaxSearchTime)
+	}
+
+	// Scheduled rides
+	cfg.Dispatch.ScheduledDispatchLead = v.GetDuration("DISPATCH_SCHEDULED_DISPATCH_LEAD") * time.Second
+	cfg.Dispatch.ScheduledHoldLead = v.GetDuration("DISPATCH_SCHEDULED_HOLD_LEAD") * time.Second
+	cfg.Dispatch.ScheduledMinAdvance = v.GetDuration("DISPATCH_SCHEDULED_MIN_ADVANCE") * time.Second
+	cfg.Dispatch.ScheduledMaxAdvance = v.GetDuration("DISPATCH_SCHEDULED_MAX_ADVANCE") * time.Second
+
+	if cfg.Dispatch.ScheduledDispatchLead == 0 {
+		cfg.Dispatch.ScheduledDispatchLead = 15 * time.Minute
+	}
+	if cfg.Dispatch.ScheduledHoldLead == 0 {
+		cfg.Dispatch.ScheduledHoldLead = time.Hour
+	}
+	if cfg.Dispatch.ScheduledHoldLead < cfg.Dispatch.ScheduledDispatchLead {
+		cfg.Dispatch.ScheduledHoldLead = cfg.Dispatch.ScheduledDispatchLead
+	}
+	if cfg.Dispatch.ScheduledMinAdvance == 0 {
+		cfg.Dispatch.ScheduledMinAdvance = 30 * time.Minute
+	}
+	if cfg.Dispatch.ScheduledMaxAdvance == 0 {
+		cfg.Dispatch.ScheduledMaxAdvance = 7 * 24 * time.Hour
+	}
+
+	// Surge engine
+	cfg.Surge.Disabled = v.GetBool("SURGE_DISABLED")
+	cfg.Surge.Interval = v.GetDuration("SURGE_INTERVAL") * time.Second
+	cfg.Surge.ZoneTTL = v.GetDuration("SURGE_ZONE_TTL") * time.Second
+	cfg.Surge.GeohashPrecision = v.GetInt("SURGE_GEOHASH_PRECISION")
+	cfg.Surge.MinDemand = v.GetInt("SURGE_MIN_DEMAND")
+	cfg.Surge.RatioThreshold = v.GetFloat64("SURGE_RATIO_THRESHOLD")
+	cfg.Surge.Sensitivity = v.GetFloat64("SURGE_SENSITIVITY")
+	cfg.Surge.Smoothing = v.GetFloat64("SURGE_SMOOTHING")
+	cfg.Surge.MaxMultiplier = v.GetFloat64("SURGE_MAX_MULTIPLIER")
+
+	if cfg.Surge.Interval == 0 {
+		cfg.Surge.Interval = 60 * time.Second
+	}
+	if cfg.Surge.ZoneTTL == 0 {
+		cfg.Surge.ZoneTTL = 3 * cfg.Surge.Interval
+	}
+	if cfg.Surge.GeohashPrecision == 0 {
+		cfg.Surge.GeohashPrecision = 6
+	}
+	if cfg.Surge.MinDemand == 0 {
+		cfg.Surge.MinDemand = 3
+	}
+	if cfg.Surge.RatioThreshold == 0 {
+		cfg.Surge.RatioThreshold = 1.0
+	}
+	if cfg.Surge.Sensitivity == 0 {
+		cfg.Surge.Sensitivity = 0.5
+	}
+	if cfg.Surge.Smoothing == 0 {
+		cfg.Surge.Smoothing = 0.5
+	}
+	if cfg.Surge.MaxMultiplier == 0 {
+		cfg.Surge.MaxMultiplier = 2.5
+	}
+
+	// Upfront fare quotes
+	cfg.Pricing.QuoteTTL = v.GetDuration("PRICING_QUOTE_TTL") * time.Second
+	cfg.Pricing.QuoteSecret = v.GetString("PRICING_QUOTE_SECRET")
+	cfg.Pricing.FareCapTolerance = v.GetFloat64("PRICING_FARE_CAP_TOLERANCE")
+	cfg.Pricing.RouteDeviationRatio = v.GetFloat64("PRICING_ROUTE_DEVIATION_RATIO")
+
+	if cfg.Pricing.QuoteTTL == 0 {
+		cfg.Pricing.QuoteTTL = 5 * time.Minute
+	}
+	pricingSecret, err := signingSecret(&cfg, "PRICING_QUOTE_SECRET", cfg.Pricing.QuoteSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Pricing.QuoteSecret = pricingSecret
+	if cfg.Pricing.FareCapTolerance == 0 {
+		cfg.Pricing.FareCapTolerance = 0.10
+	}
+	if cfg.Pricing.RouteDeviationRatio == 0 {
+		cfg.Pricing.RouteDeviationRatio = 0.25
+	}
+
+	// Routing
+	cfg.Routing.OSMFile = v.GetString("ROUTING_OSM_FILE")
+	cfg.Routing.MaxSnapKm = v.GetFloat64("ROUTING_MAX_SNAP_KM")
+	cfg.Routing.FallbackSpeedKmh = v.GetFloat64("ROUTING_FALLBACK_SPEED_KMH")
+	cfg.Routing.DetourFactor = v.GetFloat64("ROUTING_DETOUR_FACTOR")
+
+	if cfg.Routing.MaxSnapKm == 0 {
+		cfg.Routing.MaxSnapKm = 0.5
+	}
+	if cfg.Routing.FallbackSpeedKmh == 0 {
+		cfg.Routing.FallbackSpeedKmh = 40
+	}
+	if cfg.Routing.DetourFactor == 0 {
+		cfg.Routing.DetourFactor = 1.2
+	}
+
+	// Payment gateway
+	cfg.Payments.Provider = v.GetString("PAYMENTS_PROVIDER")
+	cfg.Payments.WebhookSecret = v.GetString("PAYMENTS_WEBHOOK_SECRET")
+	cfg.Payments.WebhookTolerance = v.GetDuration("PAYMENTS_WEBHOOK_TOLERANCE") * time.Second
+
+	if cfg.Payments.Provider == "" {
+		cfg.Payments.Provider = "fake"
+	}
+	paymentsSecret, err := signingSecret(&cfg, "PAYMENTS_WEBHOOK_SECRET", cfg.Payments.WebhookSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Payments.WebhookSecret = paymentsSecret
+	if cfg.Payments.WebhookTolerance == 0 {
+		cfg.Payments.WebhookTolerance = 5 * time.Minute
+	}
+
+	// Background job scheduler
+	cfg.Scheduler.Disabled = v.GetBool("SCHEDULER_DISABLED")
+	cfg.Scheduler.LeaderTTL = v.GetDuration("SCHEDULER_LEADER_TTL") * time.Second
+	cfg.Scheduler.SweepInterval = v.GetDuration("SCHEDULER_SWEEP_INTERVAL") * time.Second
+	cfg.Scheduler.CleanupInterval = v.GetDuration("SCHEDULER_CLEANUP_INTERVAL") * time.Second
+	cfg.Scheduler.SearchingRideTimeout = v.GetDuration("SCHEDULER_SEARCHING_RIDE_TIMEOUT") * time.Second
+	cfg.Scheduler.LaundryOrderTimeout = v.GetDuration("SCHEDULER_LAUNDRY_ORDER_TIMEOUT") * time.Second
+	cfg.Scheduler.MessageRetention = v.GetDuration("SCHEDULER_MESSAGE_RETENTION") * time.Second
+	cfg.Scheduler.HistoryRetention = v.GetDuration("SCHEDULER_HISTORY_RETENTION") * time.Second
+
+	if cfg.Scheduler.LeaderTTL == 0 {
+		cfg.Scheduler.LeaderTTL = 30 * time.Second
+	}
+	if cfg.Scheduler.SweepInterval == 0 {
+		cfg.Scheduler.SweepInterval = time.Minute
+	}
+	if cfg.Scheduler.CleanupInterval == 0 {
+		cfg.Scheduler.CleanupInterval = time.Hour
+	}
+	if cfg.Scheduler.SearchingRideTimeout == 0 {
+		// Well past DISPATCH_MAX_SEARCH_TIME, so only searches nobody is running
+		cfg.Scheduler.SearchingRideTimeout = 10 * time.Minute
+	}
+	if cfg.Scheduler.LaundryOrderTimeout == 0 {
+		cfg.Scheduler.LaundryOrderTimeout = 24 * time.Hour
+	}
+	if cfg.Scheduler.MessageRetention == 0 {
+		cfg.Scheduler.MessageRetention = 72 * time.Hour
+	}
+	if cfg.Scheduler.HistoryRetention == 0 {
+		cfg.Scheduler.HistoryRetention = 7 * 24 * time.Hour
+	}
+
+	// Ratings Config
+	cfg.Ratings.RideWindow = v.GetDuration("RATINGS_RIDE_WINDOW") * time.Second
+	cfg.Ratings.LowThreshold = v.GetFloat64("RATINGS_LOW_THRESHOLD")
+	cfg.Ratings.MinCount = v.GetInt("RATINGS_MIN_COUNT")
+
+	if cfg.Ratings.RideWindow == 0 {
+		cfg.Ratings.RideWindow = 7 * 24 * time.Hour
+	}
+	if cfg.Ratings.LowThreshold == 0 {
+		cfg.Ratings.LowThreshold = 4.5
+	}
+	if cfg.Ratings.MinCount == 0 {
+		cfg.Ratings.MinCount = 5
+	}
+
+	// OTP Config
+	cfg.OTP.Length = v.GetInt("OTP_LENGTH")
+	cfg.OTP.TTL = v.GetDuration("OTP_TTL") * time.Second
+	cfg.OTP.MaxAttempts = v.GetInt("OTP_MAX_ATTEMPTS")
+	cfg.OTP.LockoutDuration = v.GetDuration("OTP_LOCKOUT_DURATION") * time.Second
+	cfg.OTP.ResendCooldown = v.GetDuration("OTP_RESEND_COOLDOWN") * time.Second
+	cfg.OTP.MaxSends = v.GetInt("OTP_MAX_SENDS")
+	cfg.OTP.SendWindow = v.GetDuration("OTP_SEND_WINDOW") * time.Second
+	cfg.OTP.Secret = v.GetString("OTP_SECRET")
+
+	if cfg.OTP.Length == 0 {
+		cfg.OTP.Length = 6
+	}
+	if cfg.OTP.TTL == 0 {
+		cfg.OTP.TTL = 5 * time.Minute
+	}
+	if cfg.OTP.MaxAttempts == 0 {
+		cfg.OTP.MaxAttempts = 5
+	}
+	if cfg.OTP.LockoutDuration == 0 {
+		cfg.OTP.LockoutDuration = 15 * time.Minute
+	}
+	if cfg.OTP.ResendCooldown == 0 {
+		cfg.OTP.ResendCooldown = time.Minute
+	}
+	if cfg.OTP.MaxSends == 0 {
+		cfg.OTP.MaxSends = 5
+	}
+	if cfg.OTP.SendWindow == 0 {
+		cfg.OTP.SendWindow = time.Hour
+	}
+	otpSecret, err := signingSecret(&cfg, "OTP_SECRET", cfg.OTP.Secret)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OTP.Secret = otpSecret
+
+	// SMS Config
+	cfg.SMS.Provider = v.GetString("SMS_PROVIDER")
+	cfg.SMS.OutboxFile = v.GetString("SMS_OUTBOX_FILE")
+
+	if cfg.SMS.Provider == "" {
+		cfg.SMS.Provider = "file"
+	}
+	if cfg.SMS.OutboxFile == "" {
+		cfg.SMS.OutboxFile = "logs/sms_outbox.log"
+	}
+
+	// Mail Config
+	cfg.Mail.Provider = v.GetString("MAIL_PROVIDER")
+	cfg.Mail.OutboxFile = v.GetString("MAIL_OUTBOX_FILE")
+	cfg.Mail.From = v.GetString("MAIL_FROM")
+
+	if cfg.Mail.Provider == "" {
+		cfg.Mail.Provider = "file"
+	}
+	if cfg.Mail.OutboxFile == "" {
+		cfg.Mail.OutboxFile = "logs/mail_outbox.log"
+	}
+	if cfg.Mail.From == "" {
+		cfg.Mail.From = "no-reply@localhost"
+	}
+
+	// Account Config
+	cfg.Account.EmailVerificationTTL = v.GetDuration("ACCOUNT_EMAIL_VERIFICATION_TTL") * time.Second
+	cfg.Account.PasswordResetTTL = v.GetDuration("ACCOUNT_PASSWORD_RESET_TTL") * time.Second
+	cfg.Account.EmailCooldown = v.GetDuration("ACCOUNT_EMAIL_COOLDOWN") * time.Second
+	cfg.Account.TokenSecret = v.GetString("ACCOUNT_TOKEN_SECRET")
+	cfg.Account.LinkBaseURL = v.GetString("ACCOUNT_LINK_BASE_URL")
+
+	if cfg.Account.EmailVerificationTTL == 0 {
+		cfg.Account.EmailVerificationTTL = 24 * time.Hour
+	}
+	if cfg.Account.PasswordResetTTL == 0 {
+		cfg.Account.PasswordResetTTL = 30 * time.Minute
+	}
+	if cfg.Account.EmailCooldown == 0 {
+		cfg.Account.EmailCooldown = time.Minute
+	}
+	accountSecret, err := signingSecret(&cfg, "ACCOUNT_TOKEN_SECRET", cfg.Account.TokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Account.TokenSecret = accountSecret
+	if cfg.Account.LinkBaseURL == "" {
+		cfg.Account.LinkBaseURL = "http://localhost:3000"
+	}
+
 	return &cfg, nil
 }
 
+// signingSecret checks an HMAC key that must not be shared with the JWT
+// secret, so holding it never lets anyone sign access tokens. Outside
+// development a missing key fails loading; in development a random key is
+// used for the life of the process.
+func signingSecret(cfg *Config, name, value string) (string, error) {
+	if value == "" {
+		if cfg.App.Environment != "development" {
+			return "", fmt.Errorf("%s is required", name)
+		}
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return "", fmt.Errorf("failed to generate %s: %w", name, err)
+		}
+		return hex.EncodeToString(key), nil
+	}
+	if value == cfg.JWT.Secret {
+		return "", fmt.Errorf("%s must differ from JWT_SECRET", name)
+	}
+	return value, nil
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	if c.App.Name == "" {
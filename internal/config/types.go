@@ -8,13 +8,24 @@ import (
 
 // Config is the top-level configuration struct.
 type Config struct {
-	App      AppConfig
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Upload   UploadConfig
-	Logger   LoggerConfig
+	App       AppConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	Upload    UploadConfig
+	Logger    LoggerConfig
+	Dispatch  DispatchConfig
+	Surge     SurgeConfig
+	Pricing   PricingConfig
+	Routing   RoutingConfig
+	Payments  PaymentsConfig
+	Scheduler SchedulerConfig
+	Ratings   RatingsConfig
+	OTP       OTPConfig
+	SMS       SMSConfig
+	Mail      MailConfig
+	Account   AccountConfig
 }
 
 // AppConfig holds application-level settings.
@@ -81,10 +92,11 @@ type RedisConfig struct {
 
 // JWTConfig holds JWT settings.
 type JWTConfig struct {
-	Secret        string
-	AccessExpiry  time.Duration
-	RefreshExpiry time.Duration
-	Issuer        string
+	Secret          string
+	AccessExpiry    time.Duration
+	RefreshExpiry   time.Duration
+	Issuer          string
+	AccountCacheTTL time.Duration // how long auth checks cache a user's status and token version
 }
 
 // UploadConfig holds file upload settings.
@@ -110,3 +122,114 @@ type LoggerConfig struct {
 	Output   string
 	FilePath string
 }
+
+// PricingConfig holds settings for upfront fare quotes.
+type PricingConfig struct {
+	QuoteTTL            time.Duration // how long a quoted fare can be booked
+	QuoteSecret         string        // HMAC key for quote IDs; required outside development, never the JWT secret
+	FareCapTolerance    float64       // final fare may exceed the quote by at most this share
+	RouteDeviationRatio float64       // trips longer than the quote by this share are not capped
+}
+
+// PaymentsConfig holds settings for the payment gateway behind wallet top-ups
+// and withdrawals.
+type PaymentsConfig struct {
+	Provider         string        // "fake" (in-process, for development and tests)
+	WebhookSecret    string        // HMAC key webhooks are signed with; required outside development, never the JWT secret
+	WebhookTolerance time.Duration // how old a webhook's signature timestamp may be
+}
+
+// RoutingConfig holds settings for road distance and ETA.
+type RoutingConfig struct {
+	OSMFile          string  // OSM XML extract (.osm) to route on; empty uses the straight-line estimate
+	MaxSnapKm        float64 // furthest a point may be from the road graph before falling back
+	FallbackSpeedKmh float64 // average speed assumed by the straight-line estimate
+	DetourFactor     float64 // straight-line distance is multiplied by this to approximate roads
+}
+
+// SurgeConfig holds settings for the demand/supply surge engine.
+type SurgeConfig struct {
+	Disabled         bool          // turns the engine off everywhere; manual zones still apply
+	Interval         time.Duration // how often multipliers are recomputed
+	ZoneTTL          time.Duration // how long a computed zone stays active without being refreshed
+	GeohashPrecision int           // cell size; 6 is roughly 1.2km x 0.6km
+	MinDemand        int           // open requests a cell needs before it can surge
+	RatioThreshold   float64       // demand/supply ratio above which surge starts
+	Sensitivity      float64       // multiplier added per unit of ratio above the threshold
+	Smoothing        float64       // weight of the new value vs the previous one (0..1)
+	MaxMultiplier    float64       // global cap
+}
+
+// SchedulerConfig holds settings for the background job scheduler.
+type SchedulerConfig struct {
+	Disabled             bool          // no instance runs jobs; history is still readable
+	LeaderTTL            time.Duration // how long the leader lock lives without being renewed
+	SweepInterval        time.Duration // how often holds, ride requests and orders are swept
+	CleanupInterval      time.Duration // how often old messages and job history are deleted
+	SearchingRideTimeout time.Duration // a ride still searching after this is cancelled
+	LaundryOrderTimeout  time.Duration // an unassigned laundry order older than this is cancelled
+	MessageRetention     time.Duration // undelivered websocket messages older than this are dropped
+	HistoryRetention     time.Duration // job runs older than this are deleted
+}
+
+// DispatchConfig holds ride dispatch worker settings.
+type DispatchConfig struct {
+	Workers       int           // concurrent dispatch workers per instance
+	PollInterval  time.Duration // how often idle workers look for jobs
+	LeaseDuration time.Duration // how long a claimed job stays locked without a heartbeat
+	MaxAttempts   int           // retries for a job that fails with an infrastructure error
+
+	// Search policy
+	WaveSize         int           // drivers offered the ride at the same time
+	WaveTimeout      time.Duration // how long a wave has to accept before the next wave
+	RadiusScheduleKm []float64     // search radii, widened once all drivers in the current radius were offered
+	CandidateLimit   int           // max drivers fetched per radius
+	MaxSearchTime    time.Duration // total time before the search gives up
+
+	// Scheduled rides
+	ScheduledDispatchLead time.Duration // how long before pickup the driver search starts
+	ScheduledHoldLead     time.Duration // how long before pickup the fare is held in the wallet
+	ScheduledMinAdvance   time.Duration // earliest pickup that can be booked ahead
+	ScheduledMaxAdvance   time.Duration // latest pickup that can be booked ahead
+}
+
+// RatingsConfig holds ride rating settings.
+type RatingsConfig struct {
+	RideWindow   time.Duration // how long after completion a ride can be rated
+	LowThreshold float64       // average below which a user enters the review queue
+	MinCount     int           // ratings needed before an average counts for review
+}
+
+// OTPConfig holds one-time code settings for phone signup and login.
+type OTPConfig struct {
+	Length          int           // digits per code
+	TTL             time.Duration // how long a code can be used
+	MaxAttempts     int           // wrong codes before the phone is locked out
+	LockoutDuration time.Duration // how long a locked-out phone cannot request or verify codes
+	ResendCooldown  time.Duration // minimum time between two codes for the same phone
+	MaxSends        int           // codes a phone can be sent per SendWindow
+	SendWindow      time.Duration
+	Secret          string // HMAC key codes are hashed with; required outside development, never the JWT secret
+}
+
+// SMSConfig holds SMS delivery settings.
+type SMSConfig struct {
+	Provider   string // "file" (append to OutboxFile) or "memory" (in-process outbox), both for offline testing
+	OutboxFile string // where the file provider writes messages
+}
+
+// MailConfig holds email delivery settings.
+type MailConfig struct {
+	Provider   string // "file" (append to OutboxFile) or "memory" (in-process outbox), both for offline testing
+	OutboxFile string // where the file provider writes messages
+	From       string // sender address
+}
+
+// AccountConfig holds email verification and password reset settings.
+type AccountConfig struct {
+	EmailVerificationTTL time.Duration // how long an email verification link works
+	PasswordResetTTL     time.Duration // how long a password reset link works
+	EmailCooldown        time.Duration // minimum time between two emails of the same kind to a user
+	TokenSecret          string        // HMAC key link tokens are signed with; required outside development, never the JWT secret
+	LinkBaseURL          string        // app URL links in emails point to; the token is added as ?token=
+}
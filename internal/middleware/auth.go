@@ -3,16 +3,20 @@ package middleware
 // auth middleware, internal/middleware/auth.go
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/services/authstate"
 	"github.com/umar5678/go-backend/internal/utils/jwt"
 	"github.com/umar5678/go-backend/internal/utils/response"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Auth(cfg *config.Config) gin.HandlerFunc {
+// Auth accepts access tokens only, and checks on every request that the
+// account is still active and the token not revoked
+func Auth(cfg *config.Config, accounts authstate.Checker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract token from header
 		authHeader := c.GetHeader("Authorization")
@@ -33,21 +37,37 @@ func Auth(cfg *config.Config) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Validate token
-		claims, err := jwt.ValidateToken(tokenString, cfg.JWT.Secret)
+		claims, err := jwt.ValidateAccessToken(tokenString, cfg.JWT.Secret)
 		if err != nil {
 			c.Error(response.UnauthorizedError("Invalid or expired token"))
 			c.Abort()
 			return
 		}
 
+		// Reject suspended accounts and revoked tokens
+		if err := accounts.Check(c.Request.Context(), claims); err != nil {
+			c.Error(accountError(err))
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("userID", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
 }
 
+// accountError maps an account check failure to a response error
+func accountError(err error) error {
+	if errors.Is(err, authstate.ErrAccountInactive) {
+		return response.ForbiddenError("Account is not active")
+	}
+	return response.UnauthorizedError("Token has been revoked")
+}
+
 // RequireRole checks if user has required role
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -87,7 +107,7 @@ func OptionalAuth(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		token := parts[1]
-		claims, err := jwt.ValidateToken(token, cfg.JWT.Secret)
+		claims, err := jwt.ValidateAccessToken(token, cfg.JWT.Secret)
 		if err == nil {
 			c.Set("userID", claims.UserID)
 			c.Set("userRole", claims.Role)
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyReplayWindow   = 24 * time.Hour
+	idempotencyInFlightExpiry = 2 * time.Minute // frees the key if the instance dies mid-request
+)
+
+const (
+	idempotencyStatusProcessing = "processing"
+	idempotencyStatusCompleted  = "completed"
+)
+
+// idempotencyRecord is what Redis holds per (user, route, key)
+type idempotencyRecord struct {
+	Status      string `json:"status"`
+	RequestHash string `json:"requestHash"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency makes a money-moving endpoint safe to retry. When the client
+// sends an Idempotency-Key header, the first response for that (user, route,
+// key) is stored for 24 hours and replayed for any retry with the same body.
+// Reusing a key with a different body, or while the first request is still
+// running, is a conflict. Requests without the header are not affected.
+//
+// Only responses the handler wrote with a status below 500 are stored; when
+// the handler fails (c.Error) or returns a server error the key is released
+// so the client can retry.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Error(response.BadRequest(fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(response.BadRequest("Failed to read request body"))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+		ctx := c.Request.Context()
+		cacheKey := idempotencyCacheKey(c, key)
+
+		claimed, err := claimIdempotencyKey(ctx, cacheKey, requestHash)
+		if err != nil {
+			// Without the store a retry could charge twice; refuse instead
+			logger.Error("idempotency store unavailable", "error", err, "path", c.FullPath())
+			c.Error(response.ServiceUnavailable("Unable to process request, please retry"))
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			replayOrReject(c, cacheKey, requestHash)
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+
+		c.Next()
+
+		// Store with a fresh context; the request's may already be cancelled
+		storeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		status := writer.Status()
+		if !writer.Written() || status >= http.StatusInternalServerError {
+			if err := cache.Delete(storeCtx, cacheKey); err != nil {
+				logger.Error("failed to release idempotency key", "error", err, "key", cacheKey)
+			}
+			return
+		}
+
+		record := idempotencyRecord{
+			Status:      idempotencyStatusCompleted,
+			RequestHash: requestHash,
+			StatusCode:  status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := cache.SetJSON(storeCtx, cacheKey, record, idempotencyReplayWindow); err != nil {
+			logger.Error("failed to store idempotent response", "error", err, "key", cacheKey)
+		}
+	}
+}
+
+func idempotencyCacheKey(c *gin.Context, key string) string {
+	owner := c.ClientIP()
+	if userID, exists := c.Get("userID"); exists {
+		owner = fmt.Sprint(userID)
+	}
+	return fmt.Sprintf("idempotency:%s:%s %s:%s", owner, c.Request.Method, c.FullPath(), key)
+}
+
+// claimIdempotencyKey marks the key as in flight; false means it was already taken
+func claimIdempotencyKey(ctx context.Context, cacheKey, requestHash string) (bool, error) {
+	record, err := json.Marshal(idempotencyRecord{
+		Status:      idempotencyStatusProcessing,
+		RequestHash: requestHash,
+	})
+	if err != nil {
+		return false, err
+	}
+	return cache.SetNX(ctx, cacheKey, string(record), idempotencyInFlightExpiry)
+}
+
+func replayOrReject(c *gin.Context, cacheKey, requestHash string) {
+	var record idempotencyRecord
+	if err := cache.GetJSON(c.Request.Context(), cacheKey, &record); err != nil {
+		// Released or expired between the claim and now
+		c.Error(response.ConflictError("A request with this Idempotency-Key is already in progress, please retry"))
+		c.Abort()
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		c.Error(response.ConflictError("Idempotency-Key was already used with a different request"))
+		c.Abort()
+		return
+	}
+
+	if record.Status != idempotencyStatusCompleted {
+		c.Error(response.ConflictError("A request with this Idempotency-Key is already in progress, please retry"))
+		c.Abort()
+		return
+	}
+
+	logger.Info("idempotent request replayed", "key", cacheKey, "status", record.StatusCode)
+
+	c.Header(IdempotentReplayedHeader, "true")
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(record.StatusCode, contentType, record.Body)
+	c.Abort()
+}
+
+// idempotencyWriter keeps a copy of the response body for replay
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 	"github.com/umar5678/go-backend/internal/utils/response"
 )
 
@@ -21,6 +22,32 @@ func RequireAdmin() gin.HandlerFunc {
 	return RequireRole(string(models.RoleAdmin))
 }
 
+// RequirePermission ensures user is an admin whose roles grant the permission
+func RequirePermission(checker permissions.Checker, permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != string(models.RoleAdmin) {
+			c.Error(response.ForbiddenError("Insufficient permissions"))
+			c.Abort()
+			return
+		}
+
+		allowed, err := checker.Has(c.Request.Context(), c.GetString("userID"), permission)
+		if err != nil {
+			c.Error(response.InternalServerError("Failed to check permissions", err))
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			c.Error(response.ForbiddenError("Missing permission: " + string(permission)))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireServiceProvider ensures user is any type of service provider
 func RequireServiceProvider() gin.HandlerFunc {
 	return RequireRole(
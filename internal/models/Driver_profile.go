@@ -18,6 +18,7 @@ type DriverProfile struct {
 	TotalEarnings    float64        `gorm:"type:decimal(10,2);default:0" json:"totalEarnings"`
 	AcceptanceRate   float64        `gorm:"type:decimal(5,2);default:100.0" json:"acceptanceRate"`
 	CancellationRate float64        `gorm:"type:decimal(5,2);default:0.0" json:"cancellationRate"`
+	Tier             string         `gorm:"type:varchar(50);default:'standard'" json:"tier"` // commission tier
 	IsVerified       bool           `gorm:"default:true" json:"isVerified"`
 	WalletBalance    float64        `gorm:"type:decimal(10,2);default:0" json:"walletBalance"`
 	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"createdAt"`
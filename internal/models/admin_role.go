@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Permission is one named admin capability, "<area>.<action>"
+type Permission string
+
+const (
+	PermissionAll Permission = "*" // every permission, present and future
+
+	PermissionUsersView        Permission = "users.view"
+	PermissionUsersManage      Permission = "users.manage" // status changes, suspensions, force logout
+	PermissionProvidersApprove Permission = "providers.approve"
+	PermissionDashboardView    Permission = "dashboard.view"
+
+	PermissionServicesView   Permission = "services.view"
+	PermissionServicesManage Permission = "services.manage" // home service catalogue: services, add-ons, categories
+
+	PermissionOrdersView       Permission = "orders.view"
+	PermissionOrdersUpdate     Permission = "orders.update" // status changes and cancellations
+	PermissionOrdersReassign   Permission = "orders.reassign"
+	PermissionOrdersBulkUpdate Permission = "orders.bulk_update"
+
+	PermissionAnalyticsView Permission = "analytics.view"
+	PermissionRevenueView   Permission = "revenue.view"
+
+	PermissionWalletView   Permission = "wallet.view"
+	PermissionWalletAdjust Permission = "wallet.adjust" // refunds and simulated payments
+
+	PermissionRealtimeView           Permission = "realtime.view" // websocket connection stats
+	PermissionNotificationsBroadcast Permission = "notifications.broadcast"
+
+	PermissionCommissionManage Permission = "commission.manage" // commission rules and quotes
+	PermissionPricingManage    Permission = "pricing.manage"    // surge settings per city
+	PermissionGeofencesManage  Permission = "geofences.manage"  // service areas and pickup points
+	PermissionDispatchView     Permission = "dispatch.view"     // a ride's driver search state
+	PermissionRatingsReview    Permission = "ratings.review"    // low-rating review queues
+	PermissionJobsView         Permission = "jobs.view"         // background job schedule and runs
+
+	PermissionRolesManage Permission = "roles.manage"
+)
+
+// PermissionDescriptions lists every permission that can be granted
+var PermissionDescriptions = map[Permission]string{
+	PermissionUsersView:              "List users",
+	PermissionUsersManage:            "Change user status, suspend users and log them out",
+	PermissionProvidersApprove:       "Approve service provider registrations",
+	PermissionDashboardView:          "View admin dashboards",
+	PermissionServicesView:           "View the home service catalogue",
+	PermissionServicesManage:         "Create and edit home services, add-ons and categories",
+	PermissionOrdersView:             "View home service orders",
+	PermissionOrdersUpdate:           "Change order status and cancel orders",
+	PermissionOrdersReassign:         "Reassign orders to another provider",
+	PermissionOrdersBulkUpdate:       "Change the status of many orders at once",
+	PermissionAnalyticsView:          "View order and provider analytics",
+	PermissionRevenueView:            "View revenue reports",
+	PermissionWalletView:             "View wallet reconciliation",
+	PermissionWalletAdjust:           "Refund top-ups and settle payments",
+	PermissionRealtimeView:           "View websocket connection stats",
+	PermissionNotificationsBroadcast: "Broadcast messages to connected users",
+	PermissionCommissionManage:       "Create and end commission rules and preview quotes",
+	PermissionPricingManage:          "Change surge settings per city",
+	PermissionGeofencesManage:        "Edit service areas and pickup points",
+	PermissionDispatchView:           "View how a ride's driver search is going",
+	PermissionRatingsReview:          "Review low-rated users and rides",
+	PermissionJobsView:               "View background jobs and their runs",
+	PermissionRolesManage:            "Manage admin roles and assign them",
+}
+
+// IsValid reports whether the permission can be granted
+func (p Permission) IsValid() bool {
+	if p == PermissionAll {
+		return true
+	}
+	_, ok := PermissionDescriptions[p]
+	return ok
+}
+
+// Built-in admin roles, seeded by migration
+const (
+	AdminRoleSuperAdmin   = "super_admin"
+	AdminRoleOpsManager   = "ops_manager"
+	AdminRoleFinance      = "finance"
+	AdminRoleSupportAgent = "support_agent"
+)
+
+// AdminRole bundles permissions. System roles come from the migration and
+// cannot be edited or deleted; custom roles can.
+type AdminRole struct {
+	ID          string         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Slug        string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"slug"`
+	Name        string         `gorm:"type:varchar(100);not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	Permissions pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"permissions"`
+	IsSystem    bool           `gorm:"not null;default:false" json:"isSystem"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (AdminRole) TableName() string {
+	return "admin_roles"
+}
+
+// Grants reports whether the role includes a permission
+func (r *AdminRole) Grants(permission Permission) bool {
+	return HasPermission(r.Permissions, permission)
+}
+
+// AdminRoleAssignment gives an admin user a role
+type AdminRoleAssignment struct {
+	UserID     string     `gorm:"type:uuid;primaryKey" json:"userId"`
+	RoleID     string     `gorm:"type:uuid;primaryKey" json:"roleId"`
+	AssignedBy *string    `gorm:"type:uuid" json:"assignedBy,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	Role       *AdminRole `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+}
+
+func (AdminRoleAssignment) TableName() string {
+	return "admin_role_assignments"
+}
+
+// HasPermission reports whether a granted set includes a permission, either
+// by name or through PermissionAll
+func HasPermission(granted []string, permission Permission) bool {
+	for _, p := range granted {
+		if p == string(PermissionAll) || p == string(permission) {
+			return true
+		}
+	}
+	return false
+}
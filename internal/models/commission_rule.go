@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Service lines that pay out through commission rules
+const (
+	ServiceLineRide        = "ride"
+	ServiceLineHomeService = "homeservice"
+	ServiceLineLaundry     = "laundry"
+)
+
+// CommissionRule is one effective-dated version of the platform's cut for a
+// scope. Scope fields left nil match anything; the most specific matching
+// rule wins. Rules with the same scope form a version chain: creating a new
+// version closes the previous one at the new version's EffectiveFrom.
+type CommissionRule struct {
+	ID          string `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	ServiceLine string `gorm:"type:varchar(50);not null;index" json:"serviceLine"` // ride, homeservice, laundry
+
+	// Scope
+	City          *string `gorm:"type:varchar(100)" json:"city,omitempty"`
+	VehicleTypeID *string `gorm:"type:uuid" json:"vehicleTypeId,omitempty"`    // rides
+	Category      *string `gorm:"type:varchar(255)" json:"category,omitempty"` // home service category slug
+	Tier          *string `gorm:"type:varchar(50)" json:"tier,omitempty"`      // driver / provider tier
+
+	// Terms
+	Rate          float64  `gorm:"type:decimal(5,4);not null" json:"rate"` // platform share, 0.20 = 20%
+	FlatFee       float64  `gorm:"type:decimal(10,2);default:0" json:"flatFee"`
+	MinCommission *float64 `gorm:"type:decimal(10,2)" json:"minCommission,omitempty"`
+	MaxCommission *float64 `gorm:"type:decimal(10,2)" json:"maxCommission,omitempty"`
+
+	// Versioning
+	Version       int        `gorm:"not null;default:1" json:"version"`
+	EffectiveFrom time.Time  `gorm:"not null;index" json:"effectiveFrom"`
+	EffectiveTo   *time.Time `json:"effectiveTo,omitempty"`
+	IsActive      bool       `gorm:"default:true" json:"isActive"`
+
+	Notes     string    `gorm:"type:text" json:"notes,omitempty"`
+	CreatedBy *string   `gorm:"type:uuid" json:"createdBy,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (CommissionRule) TableName() string {
+	return "commission_rules"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// DispatchRankingWeights tunes how candidate drivers are ordered for a ride.
+// City and VehicleTypeID are optional; the most specific active row wins
+// (city + vehicle type, then city, then vehicle type, then the global row).
+type DispatchRankingWeights struct {
+	ID                 string    `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	City               *string   `gorm:"type:varchar(100);index" json:"city,omitempty"`
+	VehicleTypeID      *string   `gorm:"type:uuid;index" json:"vehicleTypeId,omitempty"`
+	ETAWeight          float64   `gorm:"type:decimal(5,2);not null;default:0.5" json:"etaWeight"`
+	RatingWeight       float64   `gorm:"type:decimal(5,2);not null;default:0.2" json:"ratingWeight"`
+	AcceptanceWeight   float64   `gorm:"type:decimal(5,2);not null;default:0.2" json:"acceptanceWeight"`
+	IdleWeight         float64   `gorm:"type:decimal(5,2);not null;default:0.1" json:"idleWeight"`
+	CancellationWeight float64   `gorm:"type:decimal(5,2);not null;default:0" json:"cancellationWeight"` // penalty
+	MaxETASeconds      int       `gorm:"not null;default:900" json:"maxEtaSeconds"`                      // ETA at which the ETA score reaches 0
+	MaxIdleMinutes     int       `gorm:"not null;default:60" json:"maxIdleMinutes"`                      // idle time at which the idle score saturates
+	IsActive           bool      `gorm:"default:true" json:"isActive"`
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (DispatchRankingWeights) TableName() string {
+	return "dispatch_ranking_weights"
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+// Geofence types
+const (
+	GeofenceTypeOperatingCity = "operating_city" // area a city is served in
+	GeofenceTypeNoService     = "no_service"     // no pickups or dropoffs inside
+	GeofenceTypeAirport       = "airport"        // designated pickup points and a flat fee
+)
+
+// Geofence is an admin-drawn polygon that decides where the platform operates.
+// Once any operating city exists, locations outside every operating city are
+// rejected; no-service zones are rejected wherever they are.
+type Geofence struct {
+	ID       string         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Name     string         `gorm:"type:varchar(100);not null" json:"name"`
+	Type     string         `gorm:"type:varchar(20);not null;index" json:"type"`       // operating_city, no_service, airport
+	City     string         `gorm:"type:varchar(100)" json:"city"`                     // operating cities: the city's name, used for per-city settings
+	Services pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"services"` // service lines the zone applies to; empty means all
+	Fee      money.Amount   `gorm:"type:decimal(10,2);default:0" json:"fee"`           // airports: flat fee per ride picking up or dropping off inside
+	IsActive bool           `gorm:"default:true;index" json:"isActive"`
+
+	// Boundary is the polygon in EWKB as stored; BoundaryGeoJSON is only
+	// filled when the query selects ST_AsGeoJSON(boundary) into it
+	Boundary        string `gorm:"type:geometry(Polygon,4326);not null" json:"-"`
+	BoundaryGeoJSON string `gorm:"->;column:boundary_geojson" json:"-"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+
+	// Relations
+	PickupPoints []GeofencePickupPoint `gorm:"foreignKey:GeofenceID" json:"pickupPoints,omitempty"`
+}
+
+func (Geofence) TableName() string {
+	return "geofences"
+}
+
+// AppliesTo reports whether the zone covers the service line
+func (g *Geofence) AppliesTo(serviceLine string) bool {
+	if len(g.Services) == 0 {
+		return true
+	}
+	for _, s := range g.Services {
+		if s == serviceLine {
+			return true
+		}
+	}
+	return false
+}
+
+// GeofencePickupPoint is a designated spot riders are picked up at inside an
+// airport zone, e.g. a terminal's rideshare bay
+type GeofencePickupPoint struct {
+	ID         string    `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	GeofenceID string    `gorm:"type:uuid;not null;index" json:"geofenceId"`
+	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
+	Lat        float64   `gorm:"type:decimal(10,8);not null" json:"lat"`
+	Lon        float64   `gorm:"type:decimal(11,8);not null" json:"lon"`
+	IsActive   bool      `gorm:"default:true" json:"isActive"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (GeofencePickupPoint) TableName() string {
+	return "geofence_pickup_points"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// Job run statuses
+const (
+	JobRunStatusRunning   = "running"
+	JobRunStatusSucceeded = "succeeded"
+	JobRunStatusFailed    = "failed"
+)
+
+// JobRun is one execution of a background scheduler job. A run left
+// "running" belonged to an instance that died mid-run.
+type JobRun struct {
+	ID         string     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	JobName    string     `gorm:"type:varchar(100);not null;index" json:"jobName"`
+	InstanceID string     `gorm:"type:varchar(100);not null" json:"instanceId"`
+	Status     string     `gorm:"type:varchar(20);not null" json:"status"` // running, succeeded, failed
+	Affected   int64      `gorm:"not null;default:0" json:"affected"`      // rows the job changed
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time  `gorm:"not null" json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	DurationMs *int64     `json:"durationMs,omitempty"`
+}
+
+func (JobRun) TableName() string {
+	return "job_runs"
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"gorm.io/gorm"
 )
 
@@ -13,21 +14,21 @@ import (
 
 // LaundryServiceCatalog - Service types
 type LaundryServiceCatalog struct {
-	ID              string    `gorm:"type:uuid;primaryKey" json:"id"`
-	Slug            string    `gorm:"type:varchar(100);uniqueIndex" json:"slug"`
-	Title           string    `gorm:"type:varchar(255)" json:"title"`
-	Description     string    `gorm:"type:text" json:"description"`
-	ColorCode       string    `gorm:"type:varchar(20)" json:"colorCode"`
-	BasePrice       float64   `gorm:"type:decimal(10,2)" json:"basePrice"`
-	PricingUnit     string    `gorm:"type:varchar(20)" json:"pricingUnit"`
-	TurnaroundHours int       `gorm:"default:48" json:"turnaroundHours"`
-	ExpressFee      float64   `gorm:"type:decimal(10,2)" json:"expressFee"`
-	ExpressHours    int       `gorm:"default:24" json:"expressHours"`
-	DisplayOrder    int       `gorm:"default:0" json:"displayOrder"`
-	CategorySlug    string    `gorm:"type:varchar(100);default:'laundry';index" json:"categorySlug"`
-	IsActive        bool      `gorm:"default:true" json:"isActive"`
-	CreatedAt       time.Time `json:"createdAt"`
-	UpdatedAt       time.Time `json:"updatedAt"`
+	ID              string       `gorm:"type:uuid;primaryKey" json:"id"`
+	Slug            string       `gorm:"type:varchar(100);uniqueIndex" json:"slug"`
+	Title           string       `gorm:"type:varchar(255)" json:"title"`
+	Description     string       `gorm:"type:text" json:"description"`
+	ColorCode       string       `gorm:"type:varchar(20)" json:"colorCode"`
+	BasePrice       money.Amount `gorm:"type:decimal(10,2)" json:"basePrice"`
+	PricingUnit     string       `gorm:"type:varchar(20)" json:"pricingUnit"`
+	TurnaroundHours int          `gorm:"default:48" json:"turnaroundHours"`
+	ExpressFee      money.Amount `gorm:"type:decimal(10,2)" json:"expressFee"`
+	ExpressHours    int          `gorm:"default:24" json:"expressHours"`
+	DisplayOrder    int          `gorm:"default:0" json:"displayOrder"`
+	CategorySlug    string       `gorm:"type:varchar(100);default:'laundry';index" json:"categorySlug"`
+	IsActive        bool         `gorm:"default:true" json:"isActive"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
 
 	// Relationships
 	Products []LaundryServiceProduct `gorm:"foreignKey:ServiceSlug;references:Slug" json:"products,omitempty"`
@@ -39,22 +40,22 @@ func (LaundryServiceCatalog) TableName() string {
 
 // LaundryServiceProduct - Products for each service (NEW!)
 type LaundryServiceProduct struct {
-	ID                  string    `gorm:"type:uuid;primaryKey" json:"id"`
-	ServiceSlug         string    `gorm:"type:varchar(100);not null" json:"serviceSlug"`
-	Name                string    `gorm:"type:varchar(255);not null" json:"name"`
-	Slug                string    `gorm:"type:varchar(100);not null" json:"slug"`
-	Description         string    `gorm:"type:text" json:"description"`
-	IconURL             *string   `gorm:"type:varchar(500)" json:"iconUrl,omitempty"`
-	Price               *float64  `gorm:"type:decimal(10,2)" json:"price,omitempty"`
-	PricingUnit         *string   `gorm:"type:varchar(20)" json:"pricingUnit,omitempty"`
-	TypicalWeight       *float64  `gorm:"type:decimal(8,3)" json:"typicalWeight,omitempty"`
-	RequiresSpecialCare bool      `gorm:"default:false" json:"requiresSpecialCare"`
-	SpecialCareFee      float64   `gorm:"type:decimal(10,2);default:0" json:"specialCareFee"`
-	DisplayOrder        int       `gorm:"default:0" json:"displayOrder"`
-	CategorySlug        string    `gorm:"type:varchar(100);default:'laundry';index" json:"categorySlug"`
-	IsActive            bool      `gorm:"default:true" json:"isActive"`
-	CreatedAt           time.Time `json:"createdAt"`
-	UpdatedAt           time.Time `json:"updatedAt"`
+	ID                  string        `gorm:"type:uuid;primaryKey" json:"id"`
+	ServiceSlug         string        `gorm:"type:varchar(100);not null" json:"serviceSlug"`
+	Name                string        `gorm:"type:varchar(255);not null" json:"name"`
+	Slug                string        `gorm:"type:varchar(100);not null" json:"slug"`
+	Description         string        `gorm:"type:text" json:"description"`
+	IconURL             *string       `gorm:"type:varchar(500)" json:"iconUrl,omitempty"`
+	Price               *money.Amount `gorm:"type:decimal(10,2)" json:"price,omitempty"`
+	PricingUnit         *string       `gorm:"type:varchar(20)" json:"pricingUnit,omitempty"`
+	TypicalWeight       *float64      `gorm:"type:decimal(8,3)" json:"typicalWeight,omitempty"`
+	RequiresSpecialCare bool          `gorm:"default:false" json:"requiresSpecialCare"`
+	SpecialCareFee      money.Amount  `gorm:"type:decimal(10,2);default:0" json:"specialCareFee"`
+	DisplayOrder        int           `gorm:"default:0" json:"displayOrder"`
+	CategorySlug        string        `gorm:"type:varchar(100);default:'laundry';index" json:"categorySlug"`
+	IsActive            bool          `gorm:"default:true" json:"isActive"`
+	CreatedAt           time.Time     `json:"createdAt"`
+	UpdatedAt           time.Time     `json:"updatedAt"`
 }
 
 func (l *LaundryServiceProduct) BeforeCreate(tx *gorm.DB) error {
@@ -74,23 +75,23 @@ func (LaundryServiceProduct) TableName() string {
 
 // LaundryOrderItem - Updated to include product reference
 type LaundryOrderItem struct {
-	ID               string     `gorm:"type:uuid;primaryKey" json:"id"`
-	OrderID          string     `gorm:"type:uuid;not null" json:"orderId"`
-	ServiceSlug      string     `gorm:"type:varchar(100)" json:"serviceSlug"`
-	ProductSlug      string     `gorm:"type:varchar(100)" json:"productSlug"` // NEW: Link to product
-	ItemType         string     `gorm:"type:varchar(100)" json:"itemType"`
-	Quantity         int        `gorm:"default:1" json:"quantity"`
-	Weight           *float64   `gorm:"type:decimal(8,3)" json:"weight,omitempty"`
-	QRCode           string     `gorm:"type:varchar(255);uniqueIndex" json:"qrCode"`
-	Status           string     `gorm:"type:varchar(50);default:'pending'" json:"status"`
-	HasIssue         bool       `gorm:"default:false" json:"hasIssue"`
-	IssueDescription *string    `gorm:"type:text" json:"issueDescription,omitempty"`
-	Price            float64    `gorm:"type:decimal(10,2)" json:"price"`
-	ReceivedAt       *time.Time `json:"receivedAt,omitempty"`
-	PackedAt         *time.Time `json:"packedAt,omitempty"`
-	DeliveredAt      *time.Time `json:"deliveredAt,omitempty"`
-	CreatedAt        time.Time  `json:"createdAt"`
-	UpdatedAt        time.Time  `json:"updatedAt"`
+	ID               string       `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID          string       `gorm:"type:uuid;not null" json:"orderId"`
+	ServiceSlug      string       `gorm:"type:varchar(100)" json:"serviceSlug"`
+	ProductSlug      string       `gorm:"type:varchar(100)" json:"productSlug"` // NEW: Link to product
+	ItemType         string       `gorm:"type:varchar(100)" json:"itemType"`
+	Quantity         int          `gorm:"default:1" json:"quantity"`
+	Weight           *float64     `gorm:"type:decimal(8,3)" json:"weight,omitempty"`
+	QRCode           string       `gorm:"type:varchar(255);uniqueIndex" json:"qrCode"`
+	Status           string       `gorm:"type:varchar(50);default:'pending'" json:"status"`
+	HasIssue         bool         `gorm:"default:false" json:"hasIssue"`
+	IssueDescription *string      `gorm:"type:text" json:"issueDescription,omitempty"`
+	Price            money.Amount `gorm:"type:decimal(10,2)" json:"price"`
+	ReceivedAt       *time.Time   `json:"receivedAt,omitempty"`
+	PackedAt         *time.Time   `json:"packedAt,omitempty"`
+	DeliveredAt      *time.Time   `json:"deliveredAt,omitempty"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	UpdatedAt        time.Time    `json:"updatedAt"`
 }
 
 func (l *LaundryOrderItem) BeforeCreate(tx *gorm.DB) error {
@@ -174,20 +175,20 @@ func (LaundryDelivery) TableName() string {
 // =====================================================
 
 type LaundryIssue struct {
-	ID               string     `gorm:"type:uuid;primaryKey" json:"id"`
-	OrderID          string     `gorm:"type:uuid;not null" json:"orderId"`
-	CustomerID       string     `gorm:"type:uuid;not null" json:"customerId"`
-	ProviderID       string     `gorm:"type:uuid;not null" json:"providerId"`
-	IssueType        string     `gorm:"type:varchar(100)" json:"issueType"` // missing_item, damage, poor_cleaning, late_delivery
-	Description      string     `gorm:"type:text" json:"description"`
-	Priority         string     `gorm:"type:varchar(20);default:'medium'" json:"priority"` // low, medium, high, urgent
-	Status           string     `gorm:"type:varchar(50);default:'open'" json:"status"`     // open → investigating → resolved → rejected
-	Resolution       *string    `gorm:"type:text" json:"resolution,omitempty"`
-	RefundAmount     *float64   `gorm:"type:decimal(10,2)" json:"refundAmount,omitempty"`
-	CompensationType *string    `gorm:"type:varchar(100)" json:"compensationType,omitempty"` // refund, replacement, credit
-	ResolvedAt       *time.Time `json:"resolvedAt,omitempty"`
-	CreatedAt        time.Time  `json:"createdAt"`
-	UpdatedAt        time.Time  `json:"updatedAt"`
+	ID               string        `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID          string        `gorm:"type:uuid;not null" json:"orderId"`
+	CustomerID       string        `gorm:"type:uuid;not null" json:"customerId"`
+	ProviderID       string        `gorm:"type:uuid;not null" json:"providerId"`
+	IssueType        string        `gorm:"type:varchar(100)" json:"issueType"` // missing_item, damage, poor_cleaning, late_delivery
+	Description      string        `gorm:"type:text" json:"description"`
+	Priority         string        `gorm:"type:varchar(20);default:'medium'" json:"priority"` // low, medium, high, urgent
+	Status           string        `gorm:"type:varchar(50);default:'open'" json:"status"`     // open → investigating → resolved → rejected
+	Resolution       *string       `gorm:"type:text" json:"resolution,omitempty"`
+	RefundAmount     *money.Amount `gorm:"type:decimal(10,2)" json:"refundAmount,omitempty"`
+	CompensationType *string       `gorm:"type:varchar(100)" json:"compensationType,omitempty"` // refund, replacement, credit
+	ResolvedAt       *time.Time    `json:"resolvedAt,omitempty"`
+	CreatedAt        time.Time     `json:"createdAt"`
+	UpdatedAt        time.Time     `json:"updatedAt"`
 }
 
 func (i *LaundryIssue) BeforeCreate(tx *gorm.DB) error {
@@ -218,10 +219,10 @@ type LaundryOrder struct {
 	Longitude float64 `gorm:"type:decimal(11,8)" json:"lng"`
 
 	// Dates & pricing
-	ServiceDate *time.Time `json:"serviceDate,omitempty"`
-	Total       float64    `gorm:"type:decimal(10,2);not null" json:"total"`
-	Tip         *float64   `gorm:"type:decimal(10,2)" json:"tip,omitempty"`     // Optional tip for delivery person
-	IsExpress   bool       `gorm:"type:boolean;default:false" json:"isExpress"` // Express delivery flag
+	ServiceDate *time.Time    `json:"serviceDate,omitempty"`
+	Total       money.Amount  `gorm:"type:decimal(10,2);not null" json:"total"`
+	Tip         *money.Amount `gorm:"type:decimal(10,2)" json:"tip,omitempty"`     // Optional tip for delivery person
+	IsExpress   bool          `gorm:"type:boolean;default:false" json:"isExpress"` // Express delivery flag
 
 	// Provider (optional)
 	ProviderID *string `gorm:"type:uuid;index" json:"providerId,omitempty"`
@@ -234,3 +235,27 @@ type LaundryOrder struct {
 func (LaundryOrder) TableName() string {
 	return "laundry_orders"
 }
+
+// LaundryOrderStatusHistory is the audit trail of a laundry order's status
+type LaundryOrderStatusHistory struct {
+	ID            string                `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID       string                `gorm:"type:uuid;not null;index" json:"orderId"`
+	FromStatus    string                `gorm:"type:varchar(50)" json:"fromStatus"`
+	ToStatus      string                `gorm:"type:varchar(50);not null" json:"toStatus"`
+	ChangedBy     *string               `gorm:"type:uuid" json:"changedBy,omitempty"`
+	ChangedByRole string                `gorm:"type:varchar(50)" json:"changedByRole"` // customer, provider, admin, system
+	Notes         string                `gorm:"type:text" json:"notes"`
+	Metadata      StatusHistoryMetadata `gorm:"type:jsonb" json:"metadata,omitempty"`
+	CreatedAt     time.Time             `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (h *LaundryOrderStatusHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (LaundryOrderStatusHistory) TableName() string {
+	return "laundry_order_status_history"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+// Platform ledger accounts. Every wallet also has its own account.
+const (
+	// Money entering or leaving the system (top-ups, withdrawals, opening balances)
+	LedgerAccountExternal = "platform:external"
+	// Payments collected from customers and not yet paid out
+	LedgerAccountClearing = "platform:clearing"
+	// Commission the platform has earned
+	LedgerAccountRevenue = "platform:revenue"
+	// Withdrawals and refunds sent to the payment gateway and not yet settled
+	LedgerAccountPayoutsPending = "platform:payouts_pending"
+)
+
+// LedgerAccount is one side of a posting: a user wallet or a platform account
+type LedgerAccount struct {
+	ID          string     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Code        string     `gorm:"type:varchar(100);not null;uniqueIndex" json:"code"` // wallet:{walletID} or platform:*
+	AccountType WalletType `gorm:"type:wallet_type;not null" json:"accountType"`
+	WalletID    *string    `gorm:"type:uuid;uniqueIndex" json:"walletId,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (LedgerAccount) TableName() string {
+	return "ledger_accounts"
+}
+
+// LedgerJournal groups the postings of one money movement. Its postings
+// always sum to zero.
+type LedgerJournal struct {
+	ID            string    `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	ReferenceType string    `gorm:"type:varchar(50);not null" json:"referenceType"`
+	ReferenceID   string    `gorm:"type:varchar(50);not null" json:"referenceId"`
+	Description   string    `gorm:"type:text" json:"description,omitempty"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	// Relations
+	Postings []LedgerPosting `gorm:"foreignKey:JournalID" json:"postings,omitempty"`
+}
+
+func (LedgerJournal) TableName() string {
+	return "ledger_journals"
+}
+
+// LedgerPosting moves Amount into (positive) or out of (negative) an account
+type LedgerPosting struct {
+	ID        string       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	JournalID string       `gorm:"type:uuid;not null;index" json:"journalId"`
+	AccountID string       `gorm:"type:uuid;not null;index" json:"accountId"`
+	Amount    money.Amount `gorm:"type:decimal(12,2);not null" json:"amount"`
+	CreatedAt time.Time    `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (LedgerPosting) TableName() string {
+	return "ledger_postings"
+}
@@ -11,7 +11,7 @@ type Ride struct {
 	RiderID       string  `gorm:"type:uuid;not null;index" json:"riderId"`
 	DriverID      *string `gorm:"type:uuid;index" json:"driverId"`
 	VehicleTypeID string  `gorm:"type:uuid;not null" json:"vehicleTypeId"`
-	Status        string  `gorm:"type:varchar(50);not null;index" json:"status"` // searching, accepted, arrived, started, completed, cancelled
+	Status        string  `gorm:"type:varchar(50);not null;index" json:"status"` // scheduled, searching, accepted, arrived, started, completed, cancelled
 
 	// Locations
 	PickupLocation string  `gorm:"type:geometry(Point,4326);not null" json:"pickupLocation"`
@@ -34,8 +34,20 @@ type Ride struct {
 	ActualDuration *int     `json:"actualDuration"`                           // seconds
 	ActualFare     *float64 `gorm:"type:decimal(10,2)" json:"actualFare"`
 
+	// Driver-reported figures, kept for audit; fares use the GPS-derived actuals
+	ReportedDistance *float64 `gorm:"type:decimal(10,2)" json:"reportedDistance"` // km
+	ReportedDuration *int     `json:"reportedDuration"`                           // seconds
+
+	// Route
+	RoutePolyline   string `gorm:"type:text" json:"routePolyline"`    // encoded GPS trace of the trip
+	RouteFlagged    bool   `gorm:"default:false" json:"routeFlagged"` // reported figures disagree with GPS
+	RouteFlagReason string `gorm:"type:text" json:"routeFlagReason"`
+
 	// Pricing
 	SurgeMultiplier float64 `gorm:"type:decimal(3,2);default:1.0" json:"surgeMultiplier"`
+	QuoteID         *string `gorm:"type:varchar(36)" json:"quoteId,omitempty"`      // upfront quote the ride was booked with
+	FareCapped      bool    `gorm:"default:false" json:"fareCapped"`                // final fare was limited by the quote
+	AirportFee      float64 `gorm:"type:decimal(10,2);default:0" json:"airportFee"` // flat airport zone fees included in the fare
 
 	// Wallet
 	WalletHoldID *string `gorm:"type:uuid" json:"walletHoldId"`
@@ -46,7 +58,8 @@ type Ride struct {
 	CancelledBy        *string `gorm:"type:varchar(50)" json:"cancelledBy"` // rider, driver, system
 
 	// Timestamps
-	RequestedAt time.Time  `gorm:"not null" json:"requestedAt"`
+	RequestedAt time.Time  `gorm:"not null" json:"requestedAt"` // when the driver search started (booking time until then)
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`       // pickup time of a ride booked ahead
 	AcceptedAt  *time.Time `json:"acceptedAt"`
 	ArrivedAt   *time.Time `json:"arrivedAt"`
 	StartedAt   *time.Time `json:"startedAt"`
@@ -61,6 +74,7 @@ type Ride struct {
 	Rider       User        `gorm:"foreignKey:RiderID" json:"rider,omitempty"`
 	Driver      *User       `gorm:"foreignKey:DriverID" json:"driver,omitempty"`
 	VehicleType VehicleType `gorm:"foreignKey:VehicleTypeID" json:"vehicleType,omitempty"`
+	Stops       []RideStop  `gorm:"foreignKey:RideID" json:"stops,omitempty"`
 }
 
 func (Ride) TableName() string {
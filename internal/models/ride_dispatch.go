@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// Dispatch job statuses
+const (
+	DispatchStatusPending   = "pending"   // waiting for a worker
+	DispatchStatusRunning   = "running"   // claimed by a worker, search in progress
+	DispatchStatusCompleted = "completed" // driver assigned (or ride left searching)
+	DispatchStatusFailed    = "failed"    // search exhausted, ride cancelled
+	DispatchStatusCancelled = "cancelled" // ride cancelled by rider/system while searching
+)
+
+// RideDispatchJob is the durable record of a driver search for a ride.
+// Workers claim jobs with a lease (LockedBy/LockedUntil) so that a search
+// survives restarts and is resumed by another worker if the lease expires.
+type RideDispatchJob struct {
+	ID               string     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	RideID           string     `gorm:"type:uuid;not null;uniqueIndex" json:"rideId"`
+	Status           string     `gorm:"type:varchar(20);not null;index" json:"status"` // pending, running, completed, failed, cancelled
+	Attempts         int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts      int        `gorm:"not null;default:3" json:"maxAttempts"`
+	CurrentRadiusKm  float64    `gorm:"type:decimal(6,2);default:0" json:"currentRadiusKm"`
+	DriversContacted int        `gorm:"not null;default:0" json:"driversContacted"`
+	AssignedDriverID *string    `gorm:"type:uuid" json:"assignedDriverId,omitempty"`
+	LastError        string     `gorm:"type:text" json:"lastError,omitempty"`
+	LockedBy         *string    `gorm:"type:varchar(100)" json:"lockedBy,omitempty"`
+	LockedUntil      *time.Time `json:"lockedUntil,omitempty"`
+	NextRunAt        time.Time  `gorm:"not null;index" json:"nextRunAt"`
+	StartedAt        *time.Time `json:"startedAt,omitempty"`
+	FinishedAt       *time.Time `json:"finishedAt,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (RideDispatchJob) TableName() string {
+	return "ride_dispatch_jobs"
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Who gave a ride rating
+const (
+	RideRatingByRider  = "rider"  // rider rating the driver
+	RideRatingByDriver = "driver" // driver rating the rider
+)
+
+// RideRatingTags are the tags each side can attach to a rating, keyed by
+// rater role
+var RideRatingTags = map[string][]string{
+	RideRatingByRider: {
+		"clean_car", "safe_driving", "friendly", "good_navigation", "on_time",
+		"dirty_car", "unsafe_driving", "rude", "wrong_route", "late",
+	},
+	RideRatingByDriver: {
+		"polite", "on_time", "respectful",
+		"rude", "late", "messy", "wrong_pickup",
+	},
+}
+
+// RideRating is one side's rating of the other after a completed ride. Each
+// ride gets at most one rating from the rider and one from the driver.
+type RideRating struct {
+	ID        string         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	RideID    string         `gorm:"type:uuid;not null;uniqueIndex:uq_ride_ratings_ride_rater" json:"rideId"`
+	RaterRole string         `gorm:"type:varchar(20);not null;uniqueIndex:uq_ride_ratings_ride_rater" json:"raterRole"` // rider, driver
+	RaterID   string         `gorm:"type:uuid;not null" json:"raterId"`                                                 // user ID
+	RateeID   string         `gorm:"type:uuid;not null;index" json:"rateeId"`                                           // user ID
+	Score     int            `gorm:"not null;check:score >= 1 AND score <= 5" json:"score"`
+	Tags      pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"tags"`
+	Comment   *string        `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (RideRating) TableName() string {
+	return "ride_ratings"
+}
+
+// IsRideRatingTag reports whether tag is one the rater role can use
+func IsRideRatingTag(raterRole, tag string) bool {
+	for _, t := range RideRatingTags[raterRole] {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// RideRatingSummary is the ride ratings one user has received, as read for
+// the admin review queue. It is not a table.
+type RideRatingSummary struct {
+	UserID      string    `json:"userId"`
+	Name        string    `json:"name"`
+	Average     float64   `json:"average"`
+	RatingCount int       `json:"ratingCount"`
+	LowCount    int       `json:"lowCount"` // ratings of 1 or 2
+	LastRatedAt time.Time `json:"lastRatedAt"`
+}
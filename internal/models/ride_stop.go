@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Ride stop statuses
+const (
+	RideStopStatusPending  = "pending"  // not reached yet
+	RideStopStatusArrived  = "arrived"  // driver waiting at the stop
+	RideStopStatusDeparted = "departed" // driver left the stop
+	RideStopStatusSkipped  = "skipped"  // trip ended without reaching the stop
+)
+
+// RideStop is an intermediate waypoint of a multi-stop ride, visited in
+// Sequence order between pickup and dropoff
+type RideStop struct {
+	ID         string     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	RideID     string     `gorm:"type:uuid;not null;index" json:"rideId"`
+	Sequence   int        `gorm:"not null" json:"sequence"` // 1-based visiting order
+	Lat        float64    `gorm:"type:decimal(10,8);not null" json:"lat"`
+	Lon        float64    `gorm:"type:decimal(11,8);not null" json:"lon"`
+	Address    string     `gorm:"type:text" json:"address"`
+	Status     string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"` // pending, arrived, departed, skipped
+	ArrivedAt  *time.Time `json:"arrivedAt,omitempty"`
+	DepartedAt *time.Time `json:"departedAt,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (RideStop) TableName() string {
+	return "ride_stops"
+}
+
+// Reached reports whether the driver has got to the stop
+func (s *RideStop) Reached() bool {
+	return s.Status == RideStopStatusArrived || s.Status == RideStopStatusDeparted
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"gorm.io/gorm"
 )
 
@@ -65,10 +66,10 @@ func (b *BookingInfo) Scan(value interface{}) error {
 
 // SelectedServiceItem represents a service in the order
 type SelectedServiceItem struct {
-	ServiceSlug string  `json:"serviceSlug"`
-	Title       string  `json:"title"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
+	ServiceSlug string       `json:"serviceSlug"`
+	Title       string       `json:"title"`
+	Price       money.Amount `json:"price"`
+	Quantity    int          `json:"quantity"`
 }
 
 // SelectedServices is a slice of selected service items
@@ -94,10 +95,10 @@ func (s *SelectedServices) Scan(value interface{}) error {
 
 // SelectedAddonItem represents an addon in the order
 type SelectedAddonItem struct {
-	AddonSlug string  `json:"addonSlug"`
-	Title     string  `json:"title"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
+	AddonSlug string       `json:"addonSlug"`
+	Title     string       `json:"title"`
+	Price     money.Amount `json:"price"`
+	Quantity  int          `json:"quantity"`
 }
 
 // SelectedAddons is a slice of selected addon items
@@ -126,12 +127,12 @@ func (s *SelectedAddons) Scan(value interface{}) error {
 
 // PaymentInfo stores payment details
 type PaymentInfo struct {
-	Method        string  `json:"method"` // wallet, cash, card
-	Status        string  `json:"status"` // pending, completed, failed, refunded
-	Total         float64 `json:"total"`
-	AmountPaid    float64 `json:"amountPaid"`
-	Voucher       string  `json:"voucher,omitempty"`
-	TransactionID string  `json:"transactionId,omitempty"`
+	Method        string       `json:"method"` // wallet, cash, card
+	Status        string       `json:"status"` // pending, completed, failed, refunded
+	Total         money.Amount `json:"total"`
+	AmountPaid    money.Amount `json:"amountPaid"`
+	Voucher       string       `json:"voucher,omitempty"`
+	TransactionID string       `json:"transactionId,omitempty"`
 }
 
 // Value implements driver.Valuer for database storage
@@ -153,11 +154,11 @@ func (p *PaymentInfo) Scan(value interface{}) error {
 
 // CancellationInfo stores cancellation details
 type CancellationInfo struct {
-	CancelledBy     string    `json:"cancelledBy"` // customer, provider, admin, system
-	CancelledAt     time.Time `json:"cancelledAt"`
-	Reason          string    `json:"reason"`
-	CancellationFee float64   `json:"cancellationFee"`
-	RefundAmount    float64   `json:"refundAmount"`
+	CancelledBy     string       `json:"cancelledBy"` // customer, provider, admin, system
+	CancelledAt     time.Time    `json:"cancelledAt"`
+	Reason          string       `json:"reason"`
+	CancellationFee money.Amount `json:"cancellationFee"`
+	RefundAmount    money.Amount `json:"refundAmount"`
 }
 
 // Value implements driver.Valuer for database storage
@@ -197,11 +198,15 @@ type ServiceOrderNew struct {
 	SpecialNotes     string           `gorm:"type:text" json:"specialNotes"`
 
 	// Pricing
-	ServicesTotal      float64 `gorm:"type:decimal(10,2);not null" json:"servicesTotal"`
-	AddonsTotal        float64 `gorm:"type:decimal(10,2);default:0" json:"addonsTotal"`
-	Subtotal           float64 `gorm:"type:decimal(10,2);not null" json:"subtotal"`
-	PlatformCommission float64 `gorm:"type:decimal(10,2);not null" json:"platformCommission"`
-	TotalPrice         float64 `gorm:"type:decimal(10,2);not null" json:"totalPrice"`
+	ServicesTotal      money.Amount `gorm:"type:decimal(10,2);not null" json:"servicesTotal"`
+	AddonsTotal        money.Amount `gorm:"type:decimal(10,2);default:0" json:"addonsTotal"`
+	Subtotal           money.Amount `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+	PlatformCommission money.Amount `gorm:"type:decimal(10,2);not null" json:"platformCommission"`
+	TotalPrice         money.Amount `gorm:"type:decimal(10,2);not null" json:"totalPrice"`
+
+	// Commission rule that priced PlatformCommission
+	CommissionRuleID      *string `gorm:"type:uuid" json:"commissionRuleId,omitempty"`
+	CommissionRuleVersion *int    `json:"commissionRuleVersion,omitempty"`
 
 	// Payment
 	PaymentInfo  *PaymentInfo `gorm:"type:jsonb" json:"paymentInfo"`
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +18,9 @@ type SurgePricingZone struct {
 	ActiveFrom  time.Time      `gorm:"not null" json:"activeFrom"`
 	ActiveUntil time.Time      `gorm:"not null" json:"activeUntil"`
 	IsActive    bool           `gorm:"default:true" json:"isActive"`
+	Source      string         `gorm:"type:varchar(20);not null;default:'manual'" json:"source"` // manual, computed
+	DemandCount int            `gorm:"default:0" json:"demandCount"`                             // open requests when computed
+	SupplyCount int            `gorm:"default:0" json:"supplyCount"`                             // available drivers when computed
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -26,16 +30,52 @@ func (SurgePricingZone) TableName() string {
 	return "surge_pricing_zones"
 }
 
+// Surge zone sources
+const (
+	SurgeSourceManual   = "manual"   // created by an admin
+	SurgeSourceComputed = "computed" // written by the surge engine
+)
+
+// SurgeCitySetting controls the surge engine for one city. A city is the
+// circle CenterLat/CenterLon/RadiusKm; cells outside every city use the
+// global settings.
+type SurgeCitySetting struct {
+	ID            string    `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	City          string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"city"`
+	CenterLat     float64   `gorm:"type:decimal(10,8);not null" json:"centerLat"`
+	CenterLon     float64   `gorm:"type:decimal(11,8);not null" json:"centerLon"`
+	RadiusKm      float64   `gorm:"type:decimal(6,2);not null" json:"radiusKm"`
+	Enabled       bool      `gorm:"not null" json:"enabled"`                          // no gorm default so false is written on insert
+	MaxMultiplier *float64  `gorm:"type:decimal(3,2)" json:"maxMultiplier,omitempty"` // overrides the global cap
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (SurgeCitySetting) TableName() string {
+	return "surge_city_settings"
+}
+
 type FareEstimate struct {
-	BaseFare          float64 `json:"baseFare"`
-	DistanceFare      float64 `json:"distanceFare"`
-	DurationFare      float64 `json:"durationFare"`
-	BookingFee        float64 `json:"bookingFee"`
-	SurgeMultiplier   float64 `json:"surgeMultiplier"`
-	SubTotal          float64 `json:"subTotal"`
-	SurgeAmount       float64 `json:"surgeAmount"`
-	TotalFare         float64 `json:"totalFare"`
-	EstimatedDistance float64 `json:"estimatedDistance"` // km
-	EstimatedDuration int     `json:"estimatedDuration"` // seconds
-	VehicleTypeName   string  `json:"vehicleTypeName"`
+	BaseFare          money.Amount `json:"baseFare"`
+	DistanceFare      money.Amount `json:"distanceFare"`
+	DurationFare      money.Amount `json:"durationFare"`
+	BookingFee        money.Amount `json:"bookingFee"`
+	SurgeMultiplier   float64      `json:"surgeMultiplier"`
+	SubTotal          money.Amount `json:"subTotal"`
+	SurgeAmount       money.Amount `json:"surgeAmount"`
+	TotalFare         money.Amount `json:"totalFare"`
+	EstimatedDistance float64      `json:"estimatedDistance"` // km
+	EstimatedDuration int          `json:"estimatedDuration"` // seconds
+	VehicleTypeName   string       `json:"vehicleTypeName"`
+	Legs              []FareLeg    `json:"legs,omitempty"` // multi-stop trips only
+}
+
+// FareLeg is one leg of a multi-stop trip: pickup to the first stop, stop to
+// stop, or the last stop to dropoff. Base fare, booking fee and surge belong
+// to the trip, so a leg only carries its distance and duration fares.
+type FareLeg struct {
+	DistanceKm   float64      `json:"distanceKm"`
+	DurationSec  int          `json:"durationSec"`
+	DistanceFare money.Amount `json:"distanceFare"`
+	DurationFare money.Amount `json:"durationFare"`
 }
@@ -36,10 +36,12 @@ type User struct {
 	Email           *string        `gorm:"type:varchar(255);uniqueIndex" json:"email,omitempty"`
 	Phone           *string        `gorm:"type:varchar(20);uniqueIndex" json:"phone,omitempty"`
 	Password        *string        `gorm:"type:varchar(255)" json:"-"`
+	EmailVerifiedAt *time.Time     `json:"emailVerifiedAt,omitempty"`
 	Role            UserRole       `gorm:"type:user_role;not null;default:'rider'" json:"role"`
 	Status          UserStatus     `gorm:"type:user_status;not null;default:'active'" json:"status"`
 	ProfilePhotoURL *string        `gorm:"type:varchar(500)" json:"profilePhotoUrl,omitempty"`
 	LastLoginAt     *time.Time     `json:"lastLoginAt,omitempty"`
+	TokenVersion    int            `gorm:"not null;default:0" json:"-"` // bumped to revoke every token issued so far
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -49,6 +51,13 @@ func (User) TableName() string {
 	return "users"
 }
 
+// CanAuthenticate reports whether tokens of a user with this status are
+// accepted. Accounts awaiting verification or approval keep access so they
+// can finish onboarding.
+func (s UserStatus) CanAuthenticate() bool {
+	return s != StatusSuspended && s != StatusBanned
+}
+
 // IsPhoneUser checks if user uses phone authentication
 func (u *User) IsPhoneUser() bool {
 	return u.Phone != nil &&
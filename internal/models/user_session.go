@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Why a session stopped being usable
+const (
+	SessionRevokedLogout         = "logout"          // the user logged out on this device
+	SessionRevokedByUser         = "user_revoked"    // revoked from another device
+	SessionRevokedReplaced       = "replaced"        // the same device logged in again
+	SessionRevokedTokenReuse     = "token_reuse"     // an already rotated refresh token was presented
+	SessionRevokedForceLogout    = "force_logout"    // an admin logged the user out
+	SessionRevokedPasswordChange = "password_change" // the password was changed or reset
+)
+
+// UserSession is one logged-in device. The session is the refresh token
+// family: each refresh rotates RefreshTokenID, and presenting any earlier
+// refresh token of the family revokes the whole session.
+type UserSession struct {
+	ID             string     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	UserID         string     `gorm:"type:uuid;not null;index" json:"userId"`
+	RefreshTokenID string     `gorm:"type:varchar(64);not null" json:"-"` // jti of the only refresh token still accepted
+	DeviceID       string     `gorm:"type:varchar(255)" json:"deviceId,omitempty"`
+	DeviceName     string     `gorm:"type:varchar(255)" json:"deviceName,omitempty"`
+	AppVersion     string     `gorm:"type:varchar(50)" json:"appVersion,omitempty"`
+	UserAgent      string     `gorm:"type:varchar(500)" json:"userAgent,omitempty"`
+	IPAddress      string     `gorm:"type:varchar(45)" json:"ipAddress,omitempty"`
+	LastUsedAt     time.Time  `gorm:"not null" json:"lastUsedAt"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expiresAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+	RevokedReason  *string    `gorm:"type:varchar(50)" json:"revokedReason,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive reports whether the session can still be refreshed
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RevokedSessionKey is the Redis key marking a session as revoked, so access
+// tokens issued to it stop working before they expire
+func RevokedSessionKey(sessionID string) string {
+	return "session:revoked:" + sessionID
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"gorm.io/gorm"
 )
 
@@ -10,10 +11,10 @@ type VehicleType struct {
 	ID            string         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	Name          string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"` // economy, comfort, premium, xl, bike
 	DisplayName   string         `gorm:"type:varchar(100);not null" json:"displayName"`
-	BaseFare      float64        `gorm:"type:decimal(10,2);not null" json:"baseFare"`
-	PerKmRate     float64        `gorm:"type:decimal(10,2);not null" json:"perKmRate"`
-	PerMinuteRate float64        `gorm:"type:decimal(10,2);not null" json:"perMinuteRate"`
-	BookingFee    float64        `gorm:"type:decimal(10,2);not null;default:0.50" json:"bookingFee"`
+	BaseFare      money.Amount   `gorm:"type:decimal(10,2);not null" json:"baseFare"`
+	PerKmRate     money.Amount   `gorm:"type:decimal(10,2);not null" json:"perKmRate"`
+	PerMinuteRate money.Amount   `gorm:"type:decimal(10,2);not null" json:"perMinuteRate"`
+	BookingFee    money.Amount   `gorm:"type:decimal(10,2);not null;default:0.50" json:"bookingFee"`
 	Capacity      int            `gorm:"not null" json:"capacity"`
 	Description   string         `gorm:"type:text" json:"description"`
 	IsActive      bool           `gorm:"default:true" json:"isActive"`
@@ -2,6 +2,9 @@ package models
 
 import (
 	"time"
+
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"gorm.io/gorm"
 )
 
 // WalletType represents the type of wallet
@@ -40,15 +43,15 @@ const (
 
 // Wallet model
 type Wallet struct {
-	ID          string     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
-	UserID      string     `gorm:"type:uuid;not null;index" json:"userId"`
-	WalletType  WalletType `gorm:"type:wallet_type;not null" json:"walletType"`
-	Balance     float64    `gorm:"type:decimal(12,2);not null;default:0.00" json:"balance"`
-	HeldBalance float64    `gorm:"type:decimal(12,2);not null;default:0.00" json:"heldBalance"`
-	Currency    string     `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
-	IsActive    bool       `gorm:"not null;default:true" json:"isActive"`
-	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+	ID          string       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	UserID      string       `gorm:"type:uuid;not null;index" json:"userId"`
+	WalletType  WalletType   `gorm:"type:wallet_type;not null" json:"walletType"`
+	Balance     money.Amount `gorm:"type:decimal(12,2);not null;default:0.00" json:"balance"`
+	HeldBalance money.Amount `gorm:"type:decimal(12,2);not null;default:0.00" json:"heldBalance"`
+	Currency    string       `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	IsActive    bool         `gorm:"not null;default:true" json:"isActive"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time    `gorm:"autoUpdateTime" json:"updatedAt"`
 
 	// Relations
 	User         User                `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -60,9 +63,25 @@ func (Wallet) TableName() string {
 	return "wallets"
 }
 
+// AfterFind tags the balances with the wallet's currency; the DECIMAL
+// columns alone do not say which currency they are in
+func (w *Wallet) AfterFind(tx *gorm.DB) error {
+	currency := money.Currency(w.Currency)
+	if currency == "" {
+		return nil
+	}
+
+	var err error
+	if w.Balance, err = w.Balance.In(currency); err != nil {
+		return err
+	}
+	w.HeldBalance, err = w.HeldBalance.In(currency)
+	return err
+}
+
 // GetAvailableBalance returns balance minus held balance
-func (w *Wallet) GetAvailableBalance() float64 {
-	return w.Balance - w.HeldBalance
+func (w *Wallet) GetAvailableBalance() money.Amount {
+	return w.Balance.Sub(w.HeldBalance)
 }
 
 // WalletTransaction model
@@ -70,14 +89,15 @@ type WalletTransaction struct {
 	ID            string                 `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	WalletID      string                 `gorm:"type:uuid;not null;index" json:"walletId"`
 	Type          TransactionType        `gorm:"type:transaction_type;not null" json:"type"`
-	Amount        float64                `gorm:"type:decimal(12,2);not null" json:"amount"`
-	BalanceBefore float64                `gorm:"type:decimal(12,2);not null" json:"balanceBefore"`
-	BalanceAfter  float64                `gorm:"type:decimal(12,2);not null" json:"balanceAfter"`
+	Amount        money.Amount           `gorm:"type:decimal(12,2);not null" json:"amount"`
+	BalanceBefore money.Amount           `gorm:"type:decimal(12,2);not null" json:"balanceBefore"`
+	BalanceAfter  money.Amount           `gorm:"type:decimal(12,2);not null" json:"balanceAfter"`
 	Status        TransactionStatus      `gorm:"type:transaction_status;not null;default:'pending'" json:"status"`
 	ReferenceType *string                `gorm:"type:varchar(50)" json:"referenceType,omitempty"`
 	ReferenceID   *string                `gorm:"type:varchar(50);not null" json:"referenceId"`
 	Description   *string                `gorm:"type:text" json:"description,omitempty"`
 	Metadata      map[string]interface{} `gorm:"type:jsonb" json:"metadata,omitempty"`
+	JournalID     *string                `gorm:"type:uuid;index" json:"journalId,omitempty"` // ledger journal that moved the money
 	ProcessedAt   *time.Time             `json:"processedAt,omitempty"`
 	CreatedAt     time.Time              `gorm:"autoCreateTime" json:"createdAt"`
 
@@ -93,7 +113,7 @@ func (WalletTransaction) TableName() string {
 type WalletHold struct {
 	ID            string            `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	WalletID      string            `gorm:"type:uuid;not null;index" json:"walletId"`
-	Amount        float64           `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Amount        money.Amount      `gorm:"type:decimal(12,2);not null" json:"amount"`
 	ReferenceType string            `gorm:"type:varchar(50);not null" json:"referenceType"`
 	ReferenceID   string            `gorm:"type:uuid;not null" json:"referenceId"`
 	Status        TransactionStatus `gorm:"type:transaction_status;not null;default:'held'" json:"status"`
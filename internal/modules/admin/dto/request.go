@@ -30,3 +30,24 @@ type ApproveServiceProviderParams struct {
 type UserIDParams struct {
 	ID string `uri:"id" binding:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
 }
+
+// CreateRoleRequest represents the request to create a custom admin role
+type CreateRoleRequest struct {
+	Slug        string   `json:"slug" binding:"required,min=2,max=50" example:"dispatch_lead"`
+	Name        string   `json:"name" binding:"required,max=100" example:"Dispatch Lead"`
+	Description string   `json:"description" example:"Reassigns and reschedules orders"`
+	Permissions []string `json:"permissions" binding:"required,min=1" example:"orders.view,orders.reassign"`
+}
+
+// UpdateRoleRequest represents the request to update a custom admin role;
+// omitted fields are left unchanged
+type UpdateRoleRequest struct {
+	Name        *string  `json:"name" binding:"omitempty,min=1,max=100" example:"Dispatch Lead"`
+	Description *string  `json:"description" example:"Reassigns and reschedules orders"`
+	Permissions []string `json:"permissions" binding:"omitempty,min=1" example:"orders.view,orders.reassign"`
+}
+
+// AssignRolesRequest replaces an admin user's roles; an empty list removes them all
+type AssignRolesRequest struct {
+	RoleIDs []string `json:"roleIds" binding:"required,dive,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
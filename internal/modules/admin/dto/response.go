@@ -68,3 +68,50 @@ type UpdateUserStatusResponse struct {
 	UserID    string            `json:"userId" example:"550e8400-e29b-41d4-a716-446655440000"`
 	NewStatus models.UserStatus `json:"newStatus" example:"active"`
 }
+
+// ForceLogoutResponse reports how many sessions a force logout revoked
+type ForceLogoutResponse struct {
+	RevokedSessions int `json:"revokedSessions" example:"2"`
+}
+
+// PermissionResponse describes a grantable permission
+type PermissionResponse struct {
+	Name        models.Permission `json:"name" example:"orders.reassign"`
+	Description string            `json:"description" example:"Reassign orders to another provider"`
+}
+
+// RoleResponse represents an admin role
+type RoleResponse struct {
+	ID          string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Slug        string    `json:"slug" example:"finance"`
+	Name        string    `json:"name" example:"Finance"`
+	Description string    `json:"description,omitempty" example:"Revenue, reconciliation and wallet adjustments"`
+	Permissions []string  `json:"permissions" example:"revenue.view,wallet.adjust"`
+	IsSystem    bool      `json:"isSystem" example:"true"`
+	CreatedAt   time.Time `json:"createdAt" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updatedAt" example:"2024-01-15T12:00:00Z"`
+}
+
+// UserRolesResponse lists an admin user's roles and the permissions they add up to
+type UserRolesResponse struct {
+	UserID      string          `json:"userId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Roles       []*RoleResponse `json:"roles"`
+	Permissions []string        `json:"permissions" example:"orders.view,orders.update"`
+}
+
+func ToRoleResponse(role *models.AdminRole) *RoleResponse {
+	permissions := []string(role.Permissions)
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &RoleResponse{
+		ID:          role.ID,
+		Slug:        role.Slug,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		IsSystem:    role.IsSystem,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
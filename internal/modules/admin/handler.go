@@ -74,7 +74,7 @@ func (h *Handler) ApproveServiceProvider(c *gin.Context) {
 
 // SuspendUser godoc
 // @Summary Suspend a user (Admin)
-// @Description Suspend a user account with a reason
+// @Description Suspend a user account with a reason. Their open websocket connections are closed.
 // @Tags Admin routes
 // @Accept json
 // @Produce json
@@ -104,6 +104,30 @@ func (h *Handler) SuspendUser(c *gin.Context) {
 	response.Success(c, nil, "User suspended")
 }
 
+// ForceLogout godoc
+// @Summary Force logout a user (Admin)
+// @Description Revoke every session of a user; their tokens stop working immediately and open websocket connections are closed
+// @Tags Admin routes
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=dto.ForceLogoutResponse} "User logged out"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/users/{id}/logout [post]
+// @Security BearerAuth
+func (h *Handler) ForceLogout(c *gin.Context) {
+	userID := c.Param("id")
+
+	result, err := h.service.ForceLogout(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result, "User logged out")
+}
+
 // UpdateUserStatus godoc
 // @Summary Update user status (Admin)
 // @Description Change the status of a user account
@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	dto "github.com/umar5678/go-backend/internal/modules/admin/dto"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+// RoleHandler handles HTTP requests for admin roles and permissions
+type RoleHandler struct {
+	service RoleService
+}
+
+func NewRoleHandler(service RoleService) *RoleHandler {
+	return &RoleHandler{service: service}
+}
+
+// ListPermissions godoc
+// @Summary List permissions (Admin)
+// @Description Every permission that can be bundled into a role
+// @Tags Admin roles
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dto.PermissionResponse} "Permissions retrieved"
+// @Failure 403 {object} response.Response "Missing roles.manage permission"
+// @Router /admin/permissions [get]
+// @Security BearerAuth
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	response.Success(c, h.service.ListPermissions(), "Permissions retrieved")
+}
+
+// ListRoles godoc
+// @Summary List admin roles (Admin)
+// @Description Built-in and custom roles with their permissions
+// @Tags Admin roles
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dto.RoleResponse} "Roles retrieved"
+// @Failure 403 {object} response.Response "Missing roles.manage permission"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /admin/roles [get]
+// @Security BearerAuth
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.service.ListRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, roles, "Roles retrieved")
+}
+
+// GetRole godoc
+// @Summary Get an admin role (Admin)
+// @Tags Admin roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} response.Response{data=dto.RoleResponse} "Role retrieved"
+// @Failure 403 {object} response.Response "Missing roles.manage permission"
+// @Failure 404 {object} response.Response "Role not found"
+// @Router /admin/roles/{id} [get]
+// @Security BearerAuth
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.service.GetRole(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, role, "Role retrieved")
+}
+
+// CreateRole godoc
+// @Summary Create a custom admin role (Admin)
+// @Description Bundles permissions into a new role. Only permissions the caller holds can be granted.
+// @Tags Admin roles
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRoleRequest true "Role"
+// @Success 200 {object} response.Response{data=dto.RoleResponse} "Role created"
+// @Failure 400 {object} response.Response "Invalid slug or unknown permission"
+// @Failure 403 {object} response.Response "Caller lacks a permission being granted"
+// @Failure 409 {object} response.Response "Slug already in use"
+// @Router /admin/roles [post]
+// @Security BearerAuth
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request"))
+		return
+	}
+
+	role, err := h.service.CreateRole(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, role, "Role created")
+}
+
+// UpdateRole godoc
+// @Summary Update a custom admin role (Admin)
+// @Description Changes take effect on the next request of every admin holding the role. Built-in roles are read-only.
+// @Tags Admin roles
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body dto.UpdateRoleRequest true "Fields to change"
+// @Success 200 {object} response.Response{data=dto.RoleResponse} "Role updated"
+// @Failure 400 {object} response.Response "Unknown permission"
+// @Failure 403 {object} response.Response "Built-in role, or caller lacks a permission being changed"
+// @Failure 404 {object} response.Response "Role not found"
+// @Router /admin/roles/{id} [put]
+// @Security BearerAuth
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request"))
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), userID.(string), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, role, "Role updated")
+}
+
+// DeleteRole godoc
+// @Summary Delete a custom admin role (Admin)
+// @Description Removes the role from every admin holding it. Built-in roles cannot be deleted.
+// @Tags Admin roles
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} response.Response "Role deleted"
+// @Failure 403 {object} response.Response "Built-in role, or caller lacks one of its permissions"
+// @Failure 404 {object} response.Response "Role not found"
+// @Router /admin/roles/{id} [delete]
+// @Security BearerAuth
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.service.DeleteRole(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Role deleted")
+}
+
+// GetUserRoles godoc
+// @Summary Get an admin user's roles (Admin)
+// @Tags Admin roles
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=dto.UserRolesResponse} "Roles retrieved"
+// @Failure 403 {object} response.Response "Missing roles.manage permission"
+// @Failure 404 {object} response.Response "User not found"
+// @Router /admin/users/{id}/roles [get]
+// @Security BearerAuth
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	result, err := h.service.GetUserRoles(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result, "Roles retrieved")
+}
+
+// SetUserRoles godoc
+// @Summary Set an admin user's roles (Admin)
+// @Description Replaces the user's roles. The caller must hold every permission of the roles added or removed, and the last super admin cannot lose the role.
+// @Tags Admin roles
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.AssignRolesRequest true "Role IDs"
+// @Success 200 {object} response.Response{data=dto.UserRolesResponse} "Roles assigned"
+// @Failure 400 {object} response.Response "User is not an admin"
+// @Failure 403 {object} response.Response "Caller lacks a permission being changed"
+// @Failure 404 {object} response.Response "User or role not found"
+// @Failure 409 {object} response.Response "Would remove the last super admin"
+// @Router /admin/users/{id}/roles [put]
+// @Security BearerAuth
+func (h *RoleHandler) SetUserRoles(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.AssignRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request"))
+		return
+	}
+
+	result, err := h.service.SetUserRoles(c.Request.Context(), userID.(string), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result, "Roles assigned")
+}
+
+// GetMyRoles godoc
+// @Summary Get own roles and permissions (Admin)
+// @Description Lets admin UIs show only what the caller can do
+// @Tags Admin roles
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.UserRolesResponse} "Roles retrieved"
+// @Failure 403 {object} response.Response "Not an admin"
+// @Router /admin/me/roles [get]
+// @Security BearerAuth
+func (h *RoleHandler) GetMyRoles(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	result, err := h.service.GetUserRoles(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result, "Roles retrieved")
+}
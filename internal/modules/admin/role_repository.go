@@ -0,0 +1,121 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository stores admin roles and their assignments
+type RoleRepository interface {
+	ListRoles(ctx context.Context) ([]*models.AdminRole, error)
+	FindRoleByID(ctx context.Context, id string) (*models.AdminRole, error)
+	FindRoleBySlug(ctx context.Context, slug string) (*models.AdminRole, error)
+	FindRolesByIDs(ctx context.Context, ids []string) ([]*models.AdminRole, error)
+	CreateRole(ctx context.Context, role *models.AdminRole) error
+	UpdateRole(ctx context.Context, role *models.AdminRole) error
+	DeleteRole(ctx context.Context, id string) error
+
+	// Assignments
+	ListUserRoles(ctx context.Context, userID string) ([]*models.AdminRole, error)
+	ListRoleUserIDs(ctx context.Context, roleID string) ([]string, error)
+	ReplaceUserRoles(ctx context.Context, userID string, roleIDs []string, assignedBy string) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) ListRoles(ctx context.Context) ([]*models.AdminRole, error) {
+	var roles []*models.AdminRole
+	err := r.db.WithContext(ctx).Order("is_system DESC, name ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindRoleByID(ctx context.Context, id string) (*models.AdminRole, error) {
+	var role models.AdminRole
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&role).Error
+	return &role, err
+}
+
+func (r *roleRepository) FindRoleBySlug(ctx context.Context, slug string) (*models.AdminRole, error) {
+	var role models.AdminRole
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&role).Error
+	return &role, err
+}
+
+func (r *roleRepository) FindRolesByIDs(ctx context.Context, ids []string) ([]*models.AdminRole, error) {
+	var roles []*models.AdminRole
+	if len(ids) == 0 {
+		return roles, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.AdminRole) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, role *models.AdminRole) error {
+	return r.db.WithContext(ctx).
+		Model(role).
+		Select("name", "description", "permissions").
+		Updates(role).Error
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&models.AdminRole{}, "id = ?", id).Error
+}
+
+func (r *roleRepository) ListUserRoles(ctx context.Context, userID string) ([]*models.AdminRole, error) {
+	var roles []*models.AdminRole
+	err := r.db.WithContext(ctx).
+		Joins("JOIN admin_role_assignments ara ON ara.role_id = admin_roles.id").
+		Where("ara.user_id = ?", userID).
+		Order("admin_roles.name ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) ListRoleUserIDs(ctx context.Context, roleID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.WithContext(ctx).
+		Model(&models.AdminRoleAssignment{}).
+		Where("role_id = ?", roleID).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// ReplaceUserRoles makes roleIDs the user's exact set of roles
+func (r *roleRepository) ReplaceUserRoles(ctx context.Context, userID string, roleIDs []string, assignedBy string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		remove := tx.Where("user_id = ?", userID)
+		if len(roleIDs) > 0 {
+			remove = remove.Where("role_id NOT IN ?", roleIDs)
+		}
+		if err := remove.Delete(&models.AdminRoleAssignment{}).Error; err != nil {
+			return err
+		}
+
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]models.AdminRoleAssignment, len(roleIDs))
+		for i, roleID := range roleIDs {
+			assignments[i] = models.AdminRoleAssignment{
+				UserID:     userID,
+				RoleID:     roleID,
+				AssignedBy: &assignedBy,
+			}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments).Error
+	})
+}
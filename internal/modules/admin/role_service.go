@@ -0,0 +1,341 @@
+package admin
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"github.com/umar5678/go-backend/internal/models"
+	dto "github.com/umar5678/go-backend/internal/modules/admin/dto"
+	"github.com/umar5678/go-backend/internal/services/permissions"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+var roleSlugPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// RoleService manages admin roles and who has them. An admin can only grant,
+// or take away, permissions they hold themselves.
+type RoleService interface {
+	ListPermissions() []*dto.PermissionResponse
+	ListRoles(ctx context.Context) ([]*dto.RoleResponse, error)
+	GetRole(ctx context.Context, roleID string) (*dto.RoleResponse, error)
+	CreateRole(ctx context.Context, actorID string, req dto.CreateRoleRequest) (*dto.RoleResponse, error)
+	UpdateRole(ctx context.Context, actorID, roleID string, req dto.UpdateRoleRequest) (*dto.RoleResponse, error)
+	DeleteRole(ctx context.Context, actorID, roleID string) error
+	GetUserRoles(ctx context.Context, userID string) (*dto.UserRolesResponse, error)
+	SetUserRoles(ctx context.Context, actorID, userID string, req dto.AssignRolesRequest) (*dto.UserRolesResponse, error)
+}
+
+type roleService struct {
+	repo    RoleRepository
+	users   Repository
+	checker permissions.Checker
+}
+
+func NewRoleService(repo RoleRepository, users Repository, checker permissions.Checker) RoleService {
+	return &roleService{
+		repo:    repo,
+		users:   users,
+		checker: checker,
+	}
+}
+
+func (s *roleService) ListPermissions() []*dto.PermissionResponse {
+	result := make([]*dto.PermissionResponse, 0, len(models.PermissionDescriptions))
+	for name, description := range models.PermissionDescriptions {
+		result = append(result, &dto.PermissionResponse{Name: name, Description: description})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]*dto.RoleResponse, error) {
+	roles, err := s.repo.ListRoles(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch roles", err)
+	}
+
+	result := make([]*dto.RoleResponse, len(roles))
+	for i, role := range roles {
+		result[i] = dto.ToRoleResponse(role)
+	}
+	return result, nil
+}
+
+func (s *roleService) GetRole(ctx context.Context, roleID string) (*dto.RoleResponse, error) {
+	role, err := s.repo.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return nil, response.NotFoundError("Role")
+	}
+	return dto.ToRoleResponse(role), nil
+}
+
+func (s *roleService) CreateRole(ctx context.Context, actorID string, req dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	if !roleSlugPattern.MatchString(req.Slug) {
+		return nil, response.BadRequest("Slug may only contain lowercase letters, digits and underscores")
+	}
+
+	granted, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireHeld(ctx, actorID, granted); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.FindRoleBySlug(ctx, req.Slug); err == nil {
+		return nil, response.ConflictError("A role with this slug already exists")
+	}
+
+	role := &models.AdminRole{
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: granted,
+	}
+	if err := s.repo.CreateRole(ctx, role); err != nil {
+		return nil, response.InternalServerError("Failed to create role", err)
+	}
+
+	logger.Info("admin role created", "roleId", role.ID, "slug", role.Slug, "by", actorID)
+	return dto.ToRoleResponse(role), nil
+}
+
+func (s *roleService) UpdateRole(ctx context.Context, actorID, roleID string, req dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	role, err := s.repo.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return nil, response.NotFoundError("Role")
+	}
+	if role.IsSystem {
+		return nil, response.ForbiddenError("Built-in roles cannot be changed")
+	}
+
+	if req.Name != nil {
+		role.Name = *req.Name
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		granted, err := normalizePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		// Both the permissions added and the ones taken away must be held
+		if err := s.requireHeld(ctx, actorID, append(granted, role.Permissions...)); err != nil {
+			return nil, err
+		}
+		role.Permissions = granted
+	}
+
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		return nil, response.InternalServerError("Failed to update role", err)
+	}
+
+	s.invalidateRoleUsers(ctx, role.ID)
+
+	logger.Info("admin role updated", "roleId", role.ID, "by", actorID)
+	return dto.ToRoleResponse(role), nil
+}
+
+func (s *roleService) DeleteRole(ctx context.Context, actorID, roleID string) error {
+	role, err := s.repo.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return response.NotFoundError("Role")
+	}
+	if role.IsSystem {
+		return response.ForbiddenError("Built-in roles cannot be deleted")
+	}
+	if err := s.requireHeld(ctx, actorID, role.Permissions); err != nil {
+		return err
+	}
+
+	userIDs, err := s.repo.ListRoleUserIDs(ctx, role.ID)
+	if err != nil {
+		return response.InternalServerError("Failed to delete role", err)
+	}
+
+	if err := s.repo.DeleteRole(ctx, role.ID); err != nil {
+		return response.InternalServerError("Failed to delete role", err)
+	}
+
+	permissions.Invalidate(ctx, userIDs...)
+
+	logger.Info("admin role deleted", "roleId", role.ID, "slug", role.Slug, "by", actorID, "users", len(userIDs))
+	return nil
+}
+
+func (s *roleService) GetUserRoles(ctx context.Context, userID string) (*dto.UserRolesResponse, error) {
+	if _, err := s.users.FindUserByID(ctx, userID); err != nil {
+		return nil, response.NotFoundError("User")
+	}
+
+	roles, err := s.repo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch roles", err)
+	}
+
+	granted, err := s.checker.Permissions(ctx, userID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch permissions", err)
+	}
+
+	result := &dto.UserRolesResponse{
+		UserID:      userID,
+		Roles:       make([]*dto.RoleResponse, len(roles)),
+		Permissions: granted,
+	}
+	for i, role := range roles {
+		result.Roles[i] = dto.ToRoleResponse(role)
+	}
+	return result, nil
+}
+
+func (s *roleService) SetUserRoles(ctx context.Context, actorID, userID string, req dto.AssignRolesRequest) (*dto.UserRolesResponse, error) {
+	user, err := s.users.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, response.NotFoundError("User")
+	}
+	if user.Role != models.RoleAdmin {
+		return nil, response.BadRequest("Roles can only be assigned to admin users")
+	}
+
+	roleIDs := uniqueStrings(req.RoleIDs)
+	wanted, err := s.repo.FindRolesByIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch roles", err)
+	}
+	if len(wanted) != len(roleIDs) {
+		return nil, response.NotFoundError("Role")
+	}
+
+	current, err := s.repo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch roles", err)
+	}
+
+	// The actor must hold everything they hand out or take away
+	changed := diffRoles(current, wanted)
+	var touched []string
+	for _, role := range changed {
+		touched = append(touched, role.Permissions...)
+	}
+	if err := s.requireHeld(ctx, actorID, touched); err != nil {
+		return nil, err
+	}
+
+	if err := s.keepSuperAdmin(ctx, userID, current, wanted); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceUserRoles(ctx, userID, roleIDs, actorID); err != nil {
+		return nil, response.InternalServerError("Failed to assign roles", err)
+	}
+
+	permissions.Invalidate(ctx, userID)
+
+	logger.Info("admin roles assigned", "userId", userID, "roles", roleIDs, "by", actorID)
+	return s.GetUserRoles(ctx, userID)
+}
+
+// keepSuperAdmin refuses to take the super admin role from its last holder,
+// which would leave nobody able to manage roles
+func (s *roleService) keepSuperAdmin(ctx context.Context, userID string, current, wanted []*models.AdminRole) error {
+	var superAdmin *models.AdminRole
+	for _, role := range current {
+		if role.Slug == models.AdminRoleSuperAdmin {
+			superAdmin = role
+		}
+	}
+	if superAdmin == nil {
+		return nil
+	}
+	for _, role := range wanted {
+		if role.ID == superAdmin.ID {
+			return nil
+		}
+	}
+
+	holders, err := s.repo.ListRoleUserIDs(ctx, superAdmin.ID)
+	if err != nil {
+		return response.InternalServerError("Failed to assign roles", err)
+	}
+	if len(holders) <= 1 {
+		return response.ConflictError("At least one super admin is required")
+	}
+	return nil
+}
+
+// requireHeld checks that the actor holds every one of the permissions
+func (s *roleService) requireHeld(ctx context.Context, actorID string, needed []string) error {
+	held, err := s.checker.Permissions(ctx, actorID)
+	if err != nil {
+		return response.InternalServerError("Failed to check permissions", err)
+	}
+
+	for _, p := range needed {
+		if !models.HasPermission(held, models.Permission(p)) {
+			return response.ForbiddenError("You cannot grant or revoke a permission you do not have: " + p)
+		}
+	}
+	return nil
+}
+
+func (s *roleService) invalidateRoleUsers(ctx context.Context, roleID string) {
+	userIDs, err := s.repo.ListRoleUserIDs(ctx, roleID)
+	if err != nil {
+		logger.Error("failed to list role users", "error", err, "roleId", roleID)
+		return
+	}
+	permissions.Invalidate(ctx, userIDs...)
+}
+
+// normalizePermissions validates, dedupes and sorts requested permissions
+func normalizePermissions(requested []string) ([]string, error) {
+	result := uniqueStrings(requested)
+	for _, p := range result {
+		if !models.Permission(p).IsValid() {
+			return nil, response.BadRequest("Unknown permission: " + p)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// diffRoles returns the roles in exactly one of the two sets
+func diffRoles(current, wanted []*models.AdminRole) []*models.AdminRole {
+	inCurrent := make(map[string]bool, len(current))
+	for _, role := range current {
+		inCurrent[role.ID] = true
+	}
+	inWanted := make(map[string]bool, len(wanted))
+	for _, role := range wanted {
+		inWanted[role.ID] = true
+	}
+
+	var changed []*models.AdminRole
+	for _, role := range current {
+		if !inWanted[role.ID] {
+			changed = append(changed, role)
+		}
+	}
+	for _, role := range wanted {
+		if !inCurrent[role.ID] {
+			changed = append(changed, role)
+		}
+	}
+	return changed
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -5,17 +5,34 @@ package admin
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, roleHandler *RoleHandler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
+	can := func(permission models.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(checker, permission)
+	}
+
 	admin := router.Group("/admin")
-	admin.Use(authMiddleware)            // All admin routes require auth
-	admin.Use(middleware.RequireAdmin()) // All routes require admin role
+	admin.Use(authMiddleware) // All admin routes require auth; each route names its permission
 	{
-		admin.GET("/users", handler.ListUsers)
-		admin.PUT("/users/:id/status", handler.UpdateUserStatus)
-		admin.POST("/service-providers/:id/approve", handler.ApproveServiceProvider)
-		admin.POST("/users/:id/suspend", handler.SuspendUser)
-		admin.GET("/dashboard/stats", handler.GetDashboardStats)
+		admin.GET("/users", can(models.PermissionUsersView), handler.ListUsers)
+		admin.PUT("/users/:id/status", can(models.PermissionUsersManage), handler.UpdateUserStatus)
+		admin.POST("/service-providers/:id/approve", can(models.PermissionProvidersApprove), handler.ApproveServiceProvider)
+		admin.POST("/users/:id/suspend", can(models.PermissionUsersManage), handler.SuspendUser)
+		admin.POST("/users/:id/logout", can(models.PermissionUsersManage), handler.ForceLogout)
+		admin.GET("/dashboard/stats", can(models.PermissionDashboardView), handler.GetDashboardStats)
+
+		// Roles and permissions
+		admin.GET("/me/roles", middleware.RequireAdmin(), roleHandler.GetMyRoles)
+		admin.GET("/permissions", can(models.PermissionRolesManage), roleHandler.ListPermissions)
+		admin.GET("/roles", can(models.PermissionRolesManage), roleHandler.ListRoles)
+		admin.POST("/roles", can(models.PermissionRolesManage), roleHandler.CreateRole)
+		admin.GET("/roles/:id", can(models.PermissionRolesManage), roleHandler.GetRole)
+		admin.PUT("/roles/:id", can(models.PermissionRolesManage), roleHandler.UpdateRole)
+		admin.DELETE("/roles/:id", can(models.PermissionRolesManage), roleHandler.DeleteRole)
+		admin.GET("/users/:id/roles", can(models.PermissionRolesManage), roleHandler.GetUserRoles)
+		admin.PUT("/users/:id/roles", can(models.PermissionRolesManage), roleHandler.SetUserRoles)
 	}
 }
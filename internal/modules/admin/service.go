@@ -7,7 +7,9 @@ import (
 	"strconv"
 
 	"github.com/umar5678/go-backend/internal/models"
+	dto "github.com/umar5678/go-backend/internal/modules/admin/dto"
 	"github.com/umar5678/go-backend/internal/modules/serviceproviders"
+	"github.com/umar5678/go-backend/internal/services/authstate"
 	"github.com/umar5678/go-backend/internal/utils/logger"
 	"github.com/umar5678/go-backend/internal/utils/response"
 )
@@ -16,19 +18,36 @@ type Service interface {
 	ListUsers(ctx context.Context, role, status, page, limit string) (map[string]interface{}, error)
 	ApproveServiceProvider(ctx context.Context, providerID string) error
 	SuspendUser(ctx context.Context, userID, reason string) error
+	ForceLogout(ctx context.Context, userID string) (*dto.ForceLogoutResponse, error)
 	UpdateUserStatus(ctx context.Context, userID string, status models.UserStatus) error
 	GetDashboardStats(ctx context.Context) (map[string]interface{}, error)
 }
 
+// SessionRevoker logs a user out of every device. The auth service
+// implements it.
+type SessionRevoker interface {
+	ForceLogout(ctx context.Context, userID string) (int, error)
+}
+
+// ConnectionCloser drops a user's open websocket connections. The websocket
+// hub implements it.
+type ConnectionCloser interface {
+	DisconnectUser(userID, reason string)
+}
+
 type service struct {
-	repo   Repository
-	spRepo serviceproviders.Repository
+	repo        Repository
+	spRepo      serviceproviders.Repository
+	sessions    SessionRevoker
+	connections ConnectionCloser
 }
 
-func NewService(repo Repository, spRepo serviceproviders.Repository) Service {
+func NewService(repo Repository, spRepo serviceproviders.Repository, sessions SessionRevoker, connections ConnectionCloser) Service {
 	return &service{
-		repo:   repo,
-		spRepo: spRepo,
+		repo:        repo,
+		spRepo:      spRepo,
+		sessions:    sessions,
+		connections: connections,
 	}
 }
 
@@ -76,6 +95,8 @@ func (s *service) ApproveServiceProvider(ctx context.Context, providerID string)
 		return response.InternalServerError("Failed to update user status", err)
 	}
 
+	authstate.Invalidate(ctx, profile.UserID)
+
 	// Update service provider status to active
 	if err := s.spRepo.UpdateStatus(ctx, providerID, models.SPStatusActive); err != nil {
 		return response.InternalServerError("Failed to update provider status", err)
@@ -95,15 +116,37 @@ func (s *service) SuspendUser(ctx context.Context, userID, reason string) error
 		return response.InternalServerError("Failed to suspend user", err)
 	}
 
+	// HTTP requests are refused from the next one on; open websocket
+	// connections are closed and cannot be reopened
+	authstate.Invalidate(ctx, userID)
+	s.connections.DisconnectUser(userID, "account suspended")
+
 	logger.Info("user suspended", "userID", userID, "reason", reason, user)
 	return nil
 }
 
+func (s *service) ForceLogout(ctx context.Context, userID string) (*dto.ForceLogoutResponse, error) {
+	revoked, err := s.sessions.ForceLogout(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.connections.DisconnectUser(userID, "logged out")
+
+	logger.Info("admin force logout", "userID", userID, "sessions", revoked)
+	return &dto.ForceLogoutResponse{RevokedSessions: revoked}, nil
+}
+
 func (s *service) UpdateUserStatus(ctx context.Context, userID string, status models.UserStatus) error {
 	if err := s.repo.UpdateUserStatus(ctx, userID, status); err != nil {
 		return response.InternalServerError("Failed to update user status", err)
 	}
 
+	authstate.Invalidate(ctx, userID)
+	if !status.CanAuthenticate() {
+		s.connections.DisconnectUser(userID, "account "+string(status))
+	}
+
 	logger.Info("user status updated", "userID", userID, "status", status)
 	return nil
 }
@@ -0,0 +1,377 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/models"
+	authdto "github.com/umar5678/go-backend/internal/modules/auth/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/services/mail"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/password"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/gorm"
+)
+
+// Link token purposes
+const (
+	linkVerifyEmail   = "verify_email"
+	linkResetPassword = "reset_password"
+)
+
+// linkToken is the signed payload of an emailed link. Only the newest token
+// of each purpose per user is accepted, and only once: its nonce is kept in
+// Redis until it is used or expires.
+type linkToken struct {
+	Purpose   string `json:"p"`
+	UserID    string `json:"u"`
+	Email     string `json:"m"`
+	Nonce     string `json:"n"`
+	ExpiresAt int64  `json:"e"`
+}
+
+func linkNonceKey(purpose, userID string) string {
+	return fmt.Sprintf("auth:link:%s:%s", purpose, userID)
+}
+
+func linkCooldownKey(purpose, userID string) string {
+	return fmt.Sprintf("auth:link_cooldown:%s:%s", purpose, userID)
+}
+
+// SendEmailVerification emails the user a link to verify their address
+func (s *service) SendEmailVerification(ctx context.Context, userID string) error {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return response.NotFoundError("User")
+	}
+
+	if user.Email == nil {
+		return response.BadRequest("Account has no email address")
+	}
+	if user.EmailVerifiedAt != nil {
+		return response.ConflictError("Email is already verified")
+	}
+
+	sent, err := s.sendVerificationEmail(ctx, user)
+	if err != nil {
+		return err
+	}
+	if !sent {
+		return response.TooManyRequests("A verification email was sent recently, please check your inbox")
+	}
+	return nil
+}
+
+// VerifyEmail marks the address a verification link was sent to as verified
+func (s *service) VerifyEmail(ctx context.Context, req authdto.VerifyEmailRequest) error {
+	claims, err := s.redeemLinkToken(ctx, linkVerifyEmail, req.Token)
+	if err != nil {
+		return err
+	}
+
+	verified, err := s.repo.MarkEmailVerified(ctx, claims.UserID, claims.Email)
+	if err != nil {
+		return response.InternalServerError("Failed to verify email", err)
+	}
+	// The address was changed after the link was sent
+	if !verified {
+		return response.BadRequest("Link is invalid or has expired")
+	}
+
+	cache.Delete(ctx, "user:profile:"+claims.UserID)
+
+	logger.Info("email verified", "userId", claims.UserID)
+
+	return nil
+}
+
+// ForgotPassword emails a reset link to an email account. It succeeds
+// whether or not the address is registered, so it cannot be used to find
+// out which addresses have accounts.
+func (s *service) ForgotPassword(ctx context.Context, req authdto.ForgotPasswordRequest) error {
+	user, err := s.repo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.Error("failed to find user for password reset", "error", err)
+		}
+		return nil
+	}
+
+	// Phone accounts have no password to reset
+	if user.Password == nil {
+		return nil
+	}
+
+	sent, err := s.sendLink(ctx, user, linkResetPassword, s.cfg.Account.PasswordResetTTL, "/reset-password",
+		"Reset your password",
+		"Someone asked to reset the password of your account. Use the link below to choose a new one:\n\n%s\n\n"+
+			"The link works once and expires in %s. If you did not ask for this, you can ignore this email.")
+	if err != nil {
+		logger.Error("failed to send password reset email", "error", err, "userId", user.ID)
+		return nil
+	}
+
+	if sent {
+		logger.Info("password reset requested", "userId", user.ID)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password from a reset link and logs the user out
+// everywhere
+func (s *service) ResetPassword(ctx context.Context, req authdto.ResetPasswordRequest) error {
+	claims, err := s.redeemLinkToken(ctx, linkResetPassword, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil || user.Email == nil || *user.Email != claims.Email {
+		return response.BadRequest("Link is invalid or has expired")
+	}
+
+	if err := s.setPassword(ctx, user.ID, req.NewPassword); err != nil {
+		return err
+	}
+
+	ids, err := s.repo.RevokeUserSessions(ctx, user.ID, "", models.SessionRevokedPasswordChange)
+	if err != nil {
+		logger.Error("failed to revoke sessions after password reset", "error", err, "userId", user.ID)
+	}
+	s.markRevoked(ctx, ids...)
+
+	logger.Info("password reset", "userId", user.ID, "revokedSessions", len(ids))
+
+	return nil
+}
+
+// ChangePassword replaces the password of a logged-in user. Every other
+// session is logged out; the current one gets a fresh token pair, since
+// tokens issued before the change stop working.
+func (s *service) ChangePassword(ctx context.Context, userID, sessionID string, req authdto.ChangePasswordRequest) (*authdto.AuthResponse, error) {
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, response.NotFoundError("User")
+	}
+
+	if user.Password == nil {
+		return nil, response.BadRequest("This account uses phone authentication")
+	}
+	if !password.Verify(req.CurrentPassword, *user.Password) {
+		return nil, response.UnauthorizedError("Current password is incorrect")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return nil, response.BadRequest("New password must be different from the current one")
+	}
+
+	session, err := s.repo.FindSessionByID(ctx, sessionID)
+	if err != nil || session.UserID != userID || !session.IsActive(time.Now()) {
+		return nil, response.UnauthorizedError("Session has been revoked, please log in again")
+	}
+
+	if err := s.setPassword(ctx, user.ID, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.repo.RevokeUserSessions(ctx, user.ID, session.ID, models.SessionRevokedPasswordChange)
+	if err != nil {
+		logger.Error("failed to revoke sessions after password change", "error", err, "userId", user.ID)
+	}
+	s.markRevoked(ctx, ids...)
+
+	// Reissue the current session's tokens at the new token version
+	user, err = s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to find user", err)
+	}
+
+	newTokenID := uuid.NewString()
+	expiresAt := time.Now().Add(s.refreshExpiry())
+	rotated, err := s.repo.RotateSession(ctx, session.ID, session.RefreshTokenID, newTokenID,
+		expiresAt, session.IPAddress, session.UserAgent)
+	if err != nil || !rotated {
+		return nil, response.UnauthorizedError("Password changed, please log in again")
+	}
+	session.RefreshTokenID = newTokenID
+	session.ExpiresAt = expiresAt
+
+	logger.Info("password changed", "userId", user.ID, "revokedSessions", len(ids))
+
+	return s.issueTokens(user, session)
+}
+
+// setPassword stores a new password and bumps the token version, so every
+// token issued with the old password stops working
+func (s *service) setPassword(ctx context.Context, userID, newPassword string) error {
+	hashed, err := password.Hash(newPassword)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+
+	if err := s.repo.UpdatePassword(ctx, userID, hashed); err != nil {
+		return response.InternalServerError("Failed to update password", err)
+	}
+
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	cache.Delete(ctx, "user:profile:"+userID)
+	return nil
+}
+
+// upgradePasswordHash rehashes a password made with an older cost while the
+// plain password is at hand. Failures only mean the upgrade waits for the
+// next login.
+func (s *service) upgradePasswordHash(ctx context.Context, user *models.User, plain string) {
+	if !password.NeedsRehash(*user.Password) {
+		return
+	}
+
+	hashed, err := password.Hash(plain)
+	if err != nil {
+		logger.Warn("failed to rehash password", "error", err, "userId", user.ID)
+		return
+	}
+
+	if err := s.repo.UpdatePassword(ctx, user.ID, hashed); err != nil {
+		logger.Warn("failed to store rehashed password", "error", err, "userId", user.ID)
+		return
+	}
+
+	logger.Info("password hash upgraded", "userId", user.ID)
+}
+
+// sendVerificationEmail sends a verification link unless one went out within
+// the cooldown. Returns false when it did not send.
+func (s *service) sendVerificationEmail(ctx context.Context, user *models.User) (bool, error) {
+	return s.sendLink(ctx, user, linkVerifyEmail, s.cfg.Account.EmailVerificationTTL, "/verify-email",
+		"Verify your email address",
+		"Welcome! Please confirm this is your email address by opening the link below:\n\n%s\n\n"+
+			"The link works once and expires in %s.")
+}
+
+// sendLink issues a link token and emails it, at most once per cooldown
+func (s *service) sendLink(ctx context.Context, user *models.User, purpose string, ttl time.Duration, path, subject, bodyFormat string) (bool, error) {
+	ok, err := cache.SetNX(ctx, linkCooldownKey(purpose, user.ID), "1", s.cfg.Account.EmailCooldown)
+	if err != nil {
+		return false, response.InternalServerError("Failed to send email", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	token, err := s.issueLinkToken(ctx, purpose, user.ID, *user.Email, ttl)
+	if err != nil {
+		cache.Delete(ctx, linkCooldownKey(purpose, user.ID))
+		return false, err
+	}
+
+	link := strings.TrimRight(s.cfg.Account.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+	msg := mail.Message{
+		To:      *user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf(bodyFormat, link, humanDuration(ttl)),
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		cache.Delete(ctx, linkNonceKey(purpose, user.ID))
+		cache.Delete(ctx, linkCooldownKey(purpose, user.ID))
+		return false, response.InternalServerError("Failed to send email", err)
+	}
+
+	return true, nil
+}
+
+// issueLinkToken signs a token for an emailed link. Issuing a new token
+// invalidates the previous one of the same purpose.
+func (s *service) issueLinkToken(ctx context.Context, purpose, userID, email string, ttl time.Duration) (string, error) {
+	claims := linkToken{
+		Purpose:   purpose,
+		UserID:    userID,
+		Email:     email,
+		Nonce:     randomToken(),
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	}
+
+	if err := cache.Set(ctx, linkNonceKey(purpose, userID), claims.Nonce, ttl); err != nil {
+		return "", response.InternalServerError("Failed to create link", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", response.InternalServerError("Failed to create link", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.signLink(encoded), nil
+}
+
+// redeemLinkToken checks a link token's signature and expiry and consumes it
+func (s *service) redeemLinkToken(ctx context.Context, purpose, token string) (*linkToken, error) {
+	invalid := response.BadRequest("Link is invalid or has expired")
+
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.signLink(encoded))) {
+		return nil, invalid
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var claims linkToken
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, invalid
+	}
+	if claims.Purpose != purpose || time.Now().Unix() > claims.ExpiresAt {
+		return nil, invalid
+	}
+
+	// Single use: only the caller that deletes the nonce gets through
+	consumed, err := cache.CompareAndDelete(ctx, linkNonceKey(purpose, claims.UserID), claims.Nonce)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to check link", err)
+	}
+	if !consumed {
+		return nil, invalid
+	}
+
+	return &claims, nil
+}
+
+func (s *service) signLink(encoded string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Account.TokenSecret))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// humanDuration formats a link lifetime for emails
+func humanDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", d/time.Hour)
+	}
+	return fmt.Sprintf("%d minutes", d/time.Minute)
+}
+
+// randomToken returns 128 random bits as hex
+func randomToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
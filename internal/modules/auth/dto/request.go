@@ -10,6 +10,9 @@ import (
 // Phone regex pattern (international format)
 var phoneRegex = regexp.MustCompile(`^\+?[1-9]\d{1,14}$`)
 
+// Verification codes are short digit strings
+var codeRegex = regexp.MustCompile(`^\d{4,10}$`)
+
 // PhoneSignupRequest for rider/driver signup
 type PhoneSignupRequest struct {
 	Name  string          `json:"name" binding:"required,min=2,max=255"`
@@ -55,6 +58,26 @@ func (r *PhoneLoginRequest) Validate() error {
 	return nil
 }
 
+// VerifyPhoneRequest completes a phone signup or login with the code that
+// was sent by SMS
+type VerifyPhoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+func (r *VerifyPhoneRequest) Validate() error {
+	if r.Phone == "" {
+		return errors.New("phone is required")
+	}
+	if !phoneRegex.MatchString(r.Phone) {
+		return errors.New("invalid phone number format")
+	}
+	if !codeRegex.MatchString(r.Code) {
+		return errors.New("code must be digits only")
+	}
+	return nil
+}
+
 // EmailSignupRequest for other roles
 type EmailSignupRequest struct {
 	Name     string          `json:"name" binding:"required,min=2,max=255"`
@@ -117,6 +140,44 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+// DeviceInfo describes the device a session is created on. Handlers fill it
+// from the X-Device-ID, X-Device-Name and X-App-Version headers, the
+// User-Agent and the client IP.
+type DeviceInfo struct {
+	DeviceID   string
+	DeviceName string
+	AppVersion string
+	UserAgent  string
+	IPAddress  string
+}
+
+// RevokeSessionsRequest for revoking all sessions
+type RevokeSessionsRequest struct {
+	KeepCurrent bool `form:"keepCurrent"` // keep the session making the request
+}
+
+// VerifyEmailRequest redeems an email verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest asks for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password from a reset link
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8,max=128"`
+}
+
+// ChangePasswordRequest for a logged-in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=8,max=128"`
+}
+
 // UpdateProfileRequest
 type UpdateProfileRequest struct {
 	Name            *string `json:"name" binding:"omitempty,min=2,max=255"`
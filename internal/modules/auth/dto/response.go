@@ -1,6 +1,7 @@
 package authdto
 
 import (
+	"strings"
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
@@ -9,13 +10,66 @@ import (
 type AuthResponse struct {
 	AccessToken  string        `json:"accessToken"`
 	RefreshToken string        `json:"refreshToken"`
+	SessionID    string        `json:"sessionId"`
 	User         *UserResponse `json:"user"`
 }
 
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceID   string    `json:"deviceId,omitempty"`
+	DeviceName string    `json:"deviceName,omitempty"`
+	AppVersion string    `json:"appVersion,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	IPAddress  string    `json:"ipAddress,omitempty"`
+	Current    bool      `json:"current"` // the session making the request
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func ToSessionResponse(session *models.UserSession, currentSessionID string) *SessionResponse {
+	return &SessionResponse{
+		ID:         session.ID,
+		DeviceID:   session.DeviceID,
+		DeviceName: session.DeviceName,
+		AppVersion: session.AppVersion,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.ID == currentSessionID,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
+
+// RevokedSessionsResponse reports how many sessions were logged out
+type RevokedSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// OTPChallengeResponse is returned when a verification code has been sent.
+// Purpose says which verify endpoint completes the flow: a signup for a
+// phone that is already registered turns into a login.
+type OTPChallengeResponse struct {
+	Purpose     string    `json:"purpose"`
+	Phone       string    `json:"phone"` // masked
+	ExpiresAt   time.Time `json:"expiresAt"`
+	ResendAfter time.Time `json:"resendAfter"`
+}
+
+// MaskPhone hides all but the last four digits of a phone number
+func MaskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
 type UserResponse struct {
 	ID              string            `json:"id"`
 	Name            string            `json:"name"`
 	Email           *string           `json:"email,omitempty"`
+	EmailVerified   bool              `json:"emailVerified"`
 	Phone           *string           `json:"phone,omitempty"`
 	Role            models.UserRole   `json:"role"`
 	Status          models.UserStatus `json:"status"`
@@ -29,6 +83,7 @@ func ToUserResponse(user *models.User) *UserResponse {
 		ID:              user.ID,
 		Name:            user.Name,
 		Email:           user.Email,
+		EmailVerified:   user.EmailVerifiedAt != nil,
 		Phone:           user.Phone,
 		Role:            user.Role,
 		Status:          user.Status,
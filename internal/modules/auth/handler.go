@@ -17,12 +17,14 @@ func NewHandler(service Service) *Handler {
 }
 
 // PhoneSignup godoc
-// @Summary Signup with phone (riders / drivers / service providers)
+// @Summary Start phone signup (riders / drivers / service providers)
+// @Description Texts a verification code to the phone. Calling again resends the code after the cooldown. If the phone is already registered a login code is sent instead (purpose "login").
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body authdto.PhoneSignupRequest true "Signup data"
-// @Success 201 {object} response.Response{data=authdto.AuthResponse}
+// @Success 200 {object} response.Response{data=authdto.OTPChallengeResponse}
+// @Failure 429 {object} response.Response
 // @Router /auth/phone/signup [post]
 func (h *Handler) PhoneSignup(c *gin.Context) {
 	var req authdto.PhoneSignupRequest
@@ -31,7 +33,36 @@ func (h *Handler) PhoneSignup(c *gin.Context) {
 		return
 	}
 
-	authResp, err := h.service.PhoneSignup(c.Request.Context(), req)
+	challenge, err := h.service.PhoneSignup(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, challenge, "Verification code sent")
+}
+
+// VerifyPhoneSignup godoc
+// @Summary Verify phone signup code
+// @Description Creates the account started by /auth/phone/signup and logs it in
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body authdto.VerifyPhoneRequest true "Phone and code"
+// @Success 201 {object} response.Response{data=authdto.AuthResponse}
+// @Failure 429 {object} response.Response
+// @Param X-Device-ID header string false "Stable device identifier; one session is kept per device"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-App-Version header string false "App version"
+// @Router /auth/phone/signup/verify [post]
+func (h *Handler) VerifyPhoneSignup(c *gin.Context) {
+	var req authdto.VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	authResp, err := h.service.VerifyPhoneSignup(c.Request.Context(), req, deviceInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -41,12 +72,14 @@ func (h *Handler) PhoneSignup(c *gin.Context) {
 }
 
 // PhoneLogin godoc
-// @Summary Login with phone (riders / drivers / service providers)
+// @Summary Start phone login (riders / drivers / service providers)
+// @Description Texts a verification code to a registered phone. Calling again resends the code after the cooldown.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body authdto.PhoneLoginRequest true "Login data"
-// @Success 200 {object} response.Response{data=authdto.AuthResponse}
+// @Success 200 {object} response.Response{data=authdto.OTPChallengeResponse}
+// @Failure 429 {object} response.Response
 // @Router /auth/phone/login [post]
 func (h *Handler) PhoneLogin(c *gin.Context) {
 	var req authdto.PhoneLoginRequest
@@ -55,7 +88,35 @@ func (h *Handler) PhoneLogin(c *gin.Context) {
 		return
 	}
 
-	authResp, err := h.service.PhoneLogin(c.Request.Context(), req)
+	challenge, err := h.service.PhoneLogin(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, challenge, "Verification code sent")
+}
+
+// VerifyPhoneLogin godoc
+// @Summary Verify phone login code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body authdto.VerifyPhoneRequest true "Phone and code"
+// @Success 200 {object} response.Response{data=authdto.AuthResponse}
+// @Failure 429 {object} response.Response
+// @Param X-Device-ID header string false "Stable device identifier; one session is kept per device"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-App-Version header string false "App version"
+// @Router /auth/phone/login/verify [post]
+func (h *Handler) VerifyPhoneLogin(c *gin.Context) {
+	var req authdto.VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	authResp, err := h.service.VerifyPhoneLogin(c.Request.Context(), req, deviceInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -71,6 +132,9 @@ func (h *Handler) PhoneLogin(c *gin.Context) {
 // @Produce json
 // @Param request body authdto.EmailSignupRequest true "Signup data"
 // @Success 201 {object} response.Response{data=authdto.AuthResponse}
+// @Param X-Device-ID header string false "Stable device identifier; one session is kept per device"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-App-Version header string false "App version"
 // @Router /auth/email/signup [post]
 func (h *Handler) EmailSignup(c *gin.Context) {
 	var req authdto.EmailSignupRequest
@@ -79,7 +143,7 @@ func (h *Handler) EmailSignup(c *gin.Context) {
 		return
 	}
 
-	authResp, err := h.service.EmailSignup(c.Request.Context(), req)
+	authResp, err := h.service.EmailSignup(c.Request.Context(), req, deviceInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -95,6 +159,9 @@ func (h *Handler) EmailSignup(c *gin.Context) {
 // @Produce json
 // @Param request body authdto.EmailLoginRequest true "Login data"
 // @Success 200 {object} response.Response{data=authdto.AuthResponse}
+// @Param X-Device-ID header string false "Stable device identifier; one session is kept per device"
+// @Param X-Device-Name header string false "Device name shown in the session list"
+// @Param X-App-Version header string false "App version"
 // @Router /auth/email/login [post]
 func (h *Handler) EmailLogin(c *gin.Context) {
 
@@ -105,7 +172,7 @@ func (h *Handler) EmailLogin(c *gin.Context) {
 		return
 	}
 
-	authResp, err := h.service.EmailLogin(c.Request.Context(), req)
+	authResp, err := h.service.EmailLogin(c.Request.Context(), req, deviceInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -116,6 +183,7 @@ func (h *Handler) EmailLogin(c *gin.Context) {
 
 // RefreshToken godoc
 // @Summary Refresh access token
+// @Description Rotates the refresh token: the one sent is no longer accepted. Sending an already rotated refresh token revokes the session.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -129,7 +197,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	authResp, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
+	authResp, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken, deviceInfo(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -156,7 +224,7 @@ func (h *Handler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Logout(c.Request.Context(), userID.(string), req.RefreshToken); err != nil {
+	if err := h.service.Logout(c.Request.Context(), userID.(string), c.GetString("sessionID"), req.RefreshToken); err != nil {
 		c.Error(err)
 		return
 	}
@@ -164,6 +232,198 @@ func (h *Handler) Logout(c *gin.Context) {
 	response.Success(c, nil, "Logged out successfully")
 }
 
+// ListSessions godoc
+// @Summary List active sessions
+// @Description One session per logged-in device; the one making the request is marked current
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response{data=[]authdto.SessionResponse}
+// @Router /auth/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), userID.(string), c.GetString("sessionID"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, sessions, "Sessions retrieved successfully")
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Logs one device out; its tokens stop working immediately
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Response
+// @Router /auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.service.RevokeSession(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Session revoked successfully")
+}
+
+// RevokeAllSessions godoc
+// @Summary Revoke all sessions
+// @Description Logs every device out, or every other device with keepCurrent=true
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param keepCurrent query bool false "Keep the session making the request"
+// @Success 200 {object} response.Response{data=authdto.RevokedSessionsResponse}
+// @Router /auth/sessions [delete]
+func (h *Handler) RevokeAllSessions(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req authdto.RevokeSessionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	keepSessionID := ""
+	if req.KeepCurrent {
+		keepSessionID = c.GetString("sessionID")
+	}
+
+	result, err := h.service.RevokeAllSessions(c.Request.Context(), userID.(string), keepSessionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result, "Sessions revoked successfully")
+}
+
+// SendEmailVerification godoc
+// @Summary Send email verification link
+// @Description Emails a link to verify the account's address. Limited to one email per cooldown.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /auth/email/verify/send [post]
+func (h *Handler) SendEmailVerification(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	if err := h.service.SendEmailVerification(c.Request.Context(), userID.(string)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Verification email sent")
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Redeems the token from a verification link. Each link works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body authdto.VerifyEmailRequest true "Token from the link"
+// @Success 200 {object} response.Response
+// @Router /auth/email/verify [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req authdto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Email verified successfully")
+}
+
+// ForgotPassword godoc
+// @Summary Request password reset
+// @Description Emails a reset link to the address if it belongs to an email account. Always succeeds, so it does not reveal which addresses are registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body authdto.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} response.Response
+// @Router /auth/password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req authdto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "If the address has an account, a reset link has been sent")
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Sets a new password from a reset link and logs the account out on every device
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body authdto.ResetPasswordRequest true "Token from the link and new password"
+// @Success 200 {object} response.Response
+// @Router /auth/password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req authdto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Password reset successfully")
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Logs every other device out and returns new tokens for this one; earlier tokens stop working
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body authdto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.Response{data=authdto.AuthResponse}
+// @Router /auth/password [put]
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req authdto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	authResp, err := h.service.ChangePassword(c.Request.Context(), userID.(string), c.GetString("sessionID"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, authResp, "Password changed successfully")
+}
+
 // GetProfile godoc
 // @Summary Get user profile
 // @Tags auth
@@ -209,3 +469,14 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 
 	response.Success(c, profile, "Profile updated successfully")
 }
+
+// deviceInfo reads the device a login or refresh comes from
+func deviceInfo(c *gin.Context) authdto.DeviceInfo {
+	return authdto.DeviceInfo{
+		DeviceID:   c.GetHeader("X-Device-ID"),
+		DeviceName: c.GetHeader("X-Device-Name"),
+		AppVersion: c.GetHeader("X-App-Version"),
+		UserAgent:  c.Request.UserAgent(),
+		IPAddress:  c.ClientIP(),
+	}
+}
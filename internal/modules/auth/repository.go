@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
 	"gorm.io/gorm"
@@ -15,9 +16,21 @@ type Repository interface {
 	FindByPhone(ctx context.Context, phone string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	UpdateLastLogin(ctx context.Context, userID string) error
+	IncrementTokenVersion(ctx context.Context, userID string) error
+	UpdatePassword(ctx context.Context, userID, hash string) error
+	MarkEmailVerified(ctx context.Context, userID, email string) (bool, error)
 
 	// Wallet operations (will be used by auth service)
 	CreateWallet(ctx context.Context, wallet *models.Wallet) error
+
+	// Session operations
+	CreateSession(ctx context.Context, session *models.UserSession) error
+	FindSessionByID(ctx context.Context, id string) (*models.UserSession, error)
+	ListActiveSessions(ctx context.Context, userID string) ([]*models.UserSession, error)
+	RotateSession(ctx context.Context, id, oldTokenID, newTokenID string, expiresAt time.Time, ipAddress, userAgent string) (bool, error)
+	RevokeSession(ctx context.Context, id, reason string) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID, exceptSessionID, reason string) ([]string, error)
+	RevokeDeviceSessions(ctx context.Context, userID, deviceID, reason string) ([]string, error)
 }
 
 type repository struct {
@@ -61,6 +74,115 @@ func (r *repository) UpdateLastLogin(ctx context.Context, userID string) error {
 		Update("last_login_at", gorm.Expr("NOW()")).Error
 }
 
+func (r *repository) IncrementTokenVersion(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+func (r *repository) UpdatePassword(ctx context.Context, userID, hash string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("password", hash).Error
+}
+
+// MarkEmailVerified verifies the user's email only while it is still the
+// address the link was sent to
+func (r *repository) MarkEmailVerified(ctx context.Context, userID, email string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND email = ?", userID, email).
+		Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, NOW())"))
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *repository) CreateWallet(ctx context.Context, wallet *models.Wallet) error {
 	return r.db.WithContext(ctx).Create(wallet).Error
 }
+
+func (r *repository) CreateSession(ctx context.Context, session *models.UserSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *repository) FindSessionByID(ctx context.Context, id string) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	return &session, err
+}
+
+func (r *repository) ListActiveSessions(ctx context.Context, userID string) ([]*models.UserSession, error) {
+	var sessions []*models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > NOW()", userID).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RotateSession swaps the session's refresh token only while oldTokenID is
+// still current, so two refreshes with the same token cannot both succeed
+func (r *repository) RotateSession(ctx context.Context, id, oldTokenID, newTokenID string, expiresAt time.Time, ipAddress, userAgent string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_id = ? AND revoked_at IS NULL", id, oldTokenID).
+		Updates(map[string]interface{}{
+			"refresh_token_id": newTokenID,
+			"expires_at":       expiresAt,
+			"ip_address":       ipAddress,
+			"user_agent":       userAgent,
+			"last_used_at":     gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) RevokeSession(ctx context.Context, id, reason string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":     gorm.Expr("NOW()"),
+			"revoked_reason": reason,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// RevokeUserSessions revokes every live session of a user except
+// exceptSessionID (when set) and returns the revoked IDs
+func (r *repository) RevokeUserSessions(ctx context.Context, userID, exceptSessionID, reason string) ([]string, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptSessionID != "" {
+		query = query.Where("id <> ?", exceptSessionID)
+	}
+	return r.revokeSessions(ctx, query, reason)
+}
+
+// RevokeDeviceSessions revokes the live sessions a user has on one device
+func (r *repository) RevokeDeviceSessions(ctx context.Context, userID, deviceID, reason string) ([]string, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", userID, deviceID)
+	return r.revokeSessions(ctx, query, reason)
+}
+
+func (r *repository) revokeSessions(ctx context.Context, query *gorm.DB, reason string) ([]string, error) {
+	var ids []string
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&models.UserSession{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Updates(map[string]interface{}{
+			"revoked_at":     gorm.Expr("NOW()"),
+			"revoked_reason": reason,
+		}).Error
+	return ids, err
+}
@@ -11,7 +11,9 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 		phone := auth.Group("/phone")
 		{
 			phone.POST("/signup", handler.PhoneSignup)
+			phone.POST("/signup/verify", handler.VerifyPhoneSignup)
 			phone.POST("/login", handler.PhoneLogin)
+			phone.POST("/login/verify", handler.VerifyPhoneLogin)
 		}
 
 		// Email-based authentication (other roles)
@@ -19,6 +21,14 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 		{
 			email.POST("/signup", handler.EmailSignup)
 			email.POST("/login", handler.EmailLogin)
+			email.POST("/verify", handler.VerifyEmail)
+		}
+
+		// Password reset by emailed link
+		passwordReset := auth.Group("/password")
+		{
+			passwordReset.POST("/forgot", handler.ForgotPassword)
+			passwordReset.POST("/reset", handler.ResetPassword)
 		}
 
 		// Common endpoints
@@ -31,6 +41,14 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 			protected.POST("/logout", handler.Logout)
 			protected.GET("/profile", handler.GetProfile)
 			protected.PUT("/profile", handler.UpdateProfile)
+
+			protected.PUT("/password", handler.ChangePassword)
+			protected.POST("/email/verify/send", handler.SendEmailVerification)
+
+			// Sessions (logged-in devices)
+			protected.GET("/sessions", handler.ListSessions)
+			protected.DELETE("/sessions", handler.RevokeAllSessions)
+			protected.DELETE("/sessions/:id", handler.RevokeSession)
 		}
 	}
 }
@@ -11,26 +11,42 @@ import (
 	"github.com/umar5678/go-backend/internal/modules/riders"
 	"github.com/umar5678/go-backend/internal/modules/serviceproviders"
 	"github.com/umar5678/go-backend/internal/services/cache"
-	"github.com/umar5678/go-backend/internal/utils/jwt"
+	"github.com/umar5678/go-backend/internal/services/mail"
+	"github.com/umar5678/go-backend/internal/services/otp"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"github.com/umar5678/go-backend/internal/utils/password"
 	"github.com/umar5678/go-backend/internal/utils/response"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type Service interface {
-	// Phone-based auth (riders/drivers)
-	PhoneSignup(ctx context.Context, req authdto.PhoneSignupRequest) (*authdto.AuthResponse, error)
-	PhoneLogin(ctx context.Context, req authdto.PhoneLoginRequest) (*authdto.AuthResponse, error)
+	// Phone-based auth (riders/drivers): start sends a code, verify redeems it
+	PhoneSignup(ctx context.Context, req authdto.PhoneSignupRequest) (*authdto.OTPChallengeResponse, error)
+	VerifyPhoneSignup(ctx context.Context, req authdto.VerifyPhoneRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error)
+	PhoneLogin(ctx context.Context, req authdto.PhoneLoginRequest) (*authdto.OTPChallengeResponse, error)
+	VerifyPhoneLogin(ctx context.Context, req authdto.VerifyPhoneRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error)
 
 	// Email-based auth (other roles)
-	EmailSignup(ctx context.Context, req authdto.EmailSignupRequest) (*authdto.AuthResponse, error)
-	EmailLogin(ctx context.Context, req authdto.EmailLoginRequest) (*authdto.AuthResponse, error)
+	EmailSignup(ctx context.Context, req authdto.EmailSignupRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error)
+	EmailLogin(ctx context.Context, req authdto.EmailLoginRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error)
 
 	// Common
-	RefreshToken(ctx context.Context, refreshToken string) (*authdto.AuthResponse, error)
-	Logout(ctx context.Context, userID, refreshToken string) error
+	RefreshToken(ctx context.Context, refreshToken string, device authdto.DeviceInfo) (*authdto.AuthResponse, error)
+	Logout(ctx context.Context, userID, sessionID, refreshToken string) error
+
+	// Sessions (one per logged-in device)
+	ListSessions(ctx context.Context, userID, currentSessionID string) ([]*authdto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID, keepSessionID string) (*authdto.RevokedSessionsResponse, error)
+	ForceLogout(ctx context.Context, userID string) (int, error)
+
+	// Email verification and passwords (email accounts)
+	SendEmailVerification(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, req authdto.VerifyEmailRequest) error
+	ForgotPassword(ctx context.Context, req authdto.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req authdto.ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID, sessionID string, req authdto.ChangePasswordRequest) (*authdto.AuthResponse, error)
 	GetProfile(ctx context.Context, userID string) (*authdto.UserResponse, error)
 	UpdateProfile(ctx context.Context, userID string, req authdto.UpdateProfileRequest) (*authdto.UserResponse, error)
 }
@@ -40,6 +56,8 @@ type service struct {
 	cfg                    *config.Config
 	riderService           riders.Service
 	serviceProviderService serviceproviders.Service // ✅ ADDED
+	otpService             otp.Service
+	mailer                 mail.Mailer
 }
 
 func NewService(
@@ -47,17 +65,33 @@ func NewService(
 	cfg *config.Config,
 	riderService riders.Service,
 	serviceProviderService serviceproviders.Service, // ✅ ADDED
+	otpService otp.Service,
+	mailer mail.Mailer,
 ) Service {
 	return &service{
 		repo:                   repo,
 		cfg:                    cfg,
 		riderService:           riderService,
 		serviceProviderService: serviceProviderService, // ✅ ADDED
+		otpService:             otpService,
+		mailer:                 mailer,
 	}
 }
 
-// PhoneSignup handles rider/driver signup
-func (s *service) PhoneSignup(ctx context.Context, req authdto.PhoneSignupRequest) (*authdto.AuthResponse, error) {
+// pendingPhoneSignup holds signup details between sending the code and
+// verifying it
+type pendingPhoneSignup struct {
+	Name string          `json:"name"`
+	Role models.UserRole `json:"role"`
+}
+
+func pendingSignupKey(phone string) string {
+	return "auth:phone_signup:" + phone
+}
+
+// PhoneSignup starts a rider/driver signup by texting a code to the phone.
+// Calling it again resends the code once the cooldown has passed.
+func (s *service) PhoneSignup(ctx context.Context, req authdto.PhoneSignupRequest) (*authdto.OTPChallengeResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, response.BadRequest(err.Error())
 	}
@@ -68,15 +102,50 @@ func (s *service) PhoneSignup(ctx context.Context, req authdto.PhoneSignupReques
 		// Phone exists - this is a login
 		return s.PhoneLogin(ctx, authdto.PhoneLoginRequest{
 			Phone: req.Phone,
-			// Role:  req.Role,
+			Role:  req.Role,
 		})
 	}
 
+	pending := pendingPhoneSignup{Name: req.Name, Role: req.Role}
+	if err := cache.SetJSON(ctx, pendingSignupKey(req.Phone), pending, s.cfg.OTP.TTL); err != nil {
+		return nil, response.InternalServerError("Failed to start signup", err)
+	}
+
+	challenge, err := s.otpService.Send(ctx, otp.PurposeSignup, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("phone signup started", "phone", req.Phone, "role", req.Role)
+
+	return toChallengeResponse(otp.PurposeSignup, req.Phone, challenge), nil
+}
+
+// VerifyPhoneSignup checks the signup code and creates the account
+func (s *service) VerifyPhoneSignup(ctx context.Context, req authdto.VerifyPhoneRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+
+	if err := s.otpService.Verify(ctx, otp.PurposeSignup, req.Phone, req.Code); err != nil {
+		return nil, err
+	}
+
+	var pending pendingPhoneSignup
+	if err := cache.TakeJSON(ctx, pendingSignupKey(req.Phone), &pending); err != nil {
+		return nil, response.BadRequest("Signup has expired, please start again")
+	}
+
+	// The phone may have been registered since the code was sent
+	if existingUser, err := s.repo.FindByPhone(ctx, req.Phone); err == nil && existingUser != nil {
+		return nil, response.ConflictError("Phone number already registered, please log in")
+	}
+
 	// Create new user
 	user := &models.User{
-		Name:   req.Name,
+		Name:   pending.Name,
 		Phone:  &req.Phone,
-		Role:   req.Role,
+		Role:   pending.Role,
 		Status: models.StatusActive,
 	}
 
@@ -115,23 +184,71 @@ func (s *service) PhoneSignup(ctx context.Context, req authdto.PhoneSignupReques
 	s.repo.UpdateLastLogin(ctx, user.ID)
 
 	// Generate tokens
-	authResp, err := s.generateAuthResponse(user)
+	authResp, err := s.startSession(ctx, user, device)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Info("phone signup successful", "userId", user.ID, "phone", req.Phone, "role", req.Role)
+	logger.Info("phone signup successful", "userId", user.ID, "phone", req.Phone, "role", user.Role)
 
 	return authResp, nil
 }
 
-// PhoneLogin handles rider/driver login
-func (s *service) PhoneLogin(ctx context.Context, req authdto.PhoneLoginRequest) (*authdto.AuthResponse, error) {
+// PhoneLogin starts a rider/driver login by texting a code to the phone.
+// Calling it again resends the code once the cooldown has passed.
+func (s *service) PhoneLogin(ctx context.Context, req authdto.PhoneLoginRequest) (*authdto.OTPChallengeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+
+	if _, err := s.findActiveByPhone(ctx, req.Phone); err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.otpService.Send(ctx, otp.PurposeLogin, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("phone login started", "phone", req.Phone)
+
+	return toChallengeResponse(otp.PurposeLogin, req.Phone, challenge), nil
+}
+
+// VerifyPhoneLogin checks the login code and issues tokens
+func (s *service) VerifyPhoneLogin(ctx context.Context, req authdto.VerifyPhoneRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, response.BadRequest(err.Error())
 	}
 
-	user, err := s.repo.FindByPhone(ctx, req.Phone)
+	if err := s.otpService.Verify(ctx, otp.PurposeLogin, req.Phone, req.Code); err != nil {
+		return nil, err
+	}
+
+	// Status is checked again: the account may have been suspended since
+	// the code was sent
+	user, err := s.findActiveByPhone(ctx, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update last login
+	s.repo.UpdateLastLogin(ctx, user.ID)
+
+	// Generate tokens
+	authResp, err := s.startSession(ctx, user, device)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("phone login successful", "userId", user.ID, "phone", req.Phone)
+
+	return authResp, nil
+}
+
+// Helper: Find a phone account that is allowed to log in
+func (s *service) findActiveByPhone(ctx context.Context, phone string) (*models.User, error) {
+	user, err := s.repo.FindByPhone(ctx, phone)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// Phone not found - this is a signup
@@ -150,22 +267,20 @@ func (s *service) PhoneLogin(ctx context.Context, req authdto.PhoneLoginRequest)
 		return nil, response.ForbiddenError("Account is not active")
 	}
 
-	// Update last login
-	s.repo.UpdateLastLogin(ctx, user.ID)
+	return user, nil
+}
 
-	// Generate tokens
-	authResp, err := s.generateAuthResponse(user)
-	if err != nil {
-		return nil, err
+func toChallengeResponse(purpose, phone string, challenge *otp.Challenge) *authdto.OTPChallengeResponse {
+	return &authdto.OTPChallengeResponse{
+		Purpose:     purpose,
+		Phone:       authdto.MaskPhone(phone),
+		ExpiresAt:   challenge.ExpiresAt,
+		ResendAfter: challenge.ResendAfter,
 	}
-
-	logger.Info("phone login successful", "userId", user.ID, "phone", req.Phone)
-
-	return authResp, nil
 }
 
 // EmailSignup handles email-based signup for other roles
-func (s *service) EmailSignup(ctx context.Context, req authdto.EmailSignupRequest) (*authdto.AuthResponse, error) {
+func (s *service) EmailSignup(ctx context.Context, req authdto.EmailSignupRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, response.BadRequest(err.Error())
 	}
@@ -218,11 +333,17 @@ func (s *service) EmailSignup(ctx context.Context, req authdto.EmailSignupReques
 		logger.Error("failed to create wallet", "error", err, "userId", user.ID)
 	}
 
+	// Don't fail signup if the verification email cannot be sent; it can be
+	// requested again
+	if _, err := s.sendVerificationEmail(ctx, user); err != nil {
+		logger.Error("failed to send verification email", "error", err, "userId", user.ID)
+	}
+
 	// Update last login
 	s.repo.UpdateLastLogin(ctx, user.ID)
 
 	// Generate tokens
-	authResp, err := s.generateAuthResponse(user)
+	authResp, err := s.startSession(ctx, user, device)
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +405,7 @@ func (s *service) EmailSignup(ctx context.Context, req authdto.EmailSignupReques
 // }
 
 // EmailLogin handles email-based login
-func (s *service) EmailLogin(ctx context.Context, req authdto.EmailLoginRequest) (*authdto.AuthResponse, error) {
+func (s *service) EmailLogin(ctx context.Context, req authdto.EmailLoginRequest, device authdto.DeviceInfo) (*authdto.AuthResponse, error) {
 	fmt.Println("=========================================================service  called")
 	logger.Debug("service fun for emial login, ============")
 	if err := req.Validate(); err != nil {
@@ -310,17 +431,6 @@ func (s *service) EmailLogin(ctx context.Context, req authdto.EmailLoginRequest)
 	// }
 
 	if !password.Verify(req.Password, *user.Password) {
-		fmt.Println("RAW stored hash: ", *user.Password)
-		logger.Debug("RAW stored hash: ", *user.Password)
-		fmt.Println("Hash length: ", len(*user.Password))
-		logger.Debug("Hash length: ", len(*user.Password))
-		fmt.Println("Hash starts with: ", (*user.Password)[:7]) // should be "$2a$" or "$2b$"
-
-		// Test manually with bcrypt
-		err := bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(req.Password))
-		fmt.Println("bcrypt compare error: ", err)
-		logger.Debug("bcrypt error: ", err)
-
 		return nil, response.UnauthorizedError("Invalid credentials")
 	}
 
@@ -329,11 +439,13 @@ func (s *service) EmailLogin(ctx context.Context, req authdto.EmailLoginRequest)
 		return nil, response.ForbiddenError("Account is not active")
 	}
 
+	s.upgradePasswordHash(ctx, user, req.Password)
+
 	// Update last login
 	s.repo.UpdateLastLogin(ctx, user.ID)
 
 	// Generate tokens
-	authResp, err := s.generateAuthResponse(user)
+	authResp, err := s.startSession(ctx, user, device)
 	if err != nil {
 		return nil, err
 	}
@@ -357,60 +469,6 @@ func (s *service) mapRoleToCategory(role models.UserRole) string {
 	}
 }
 
-// RefreshToken generates new tokens
-func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*authdto.AuthResponse, error) {
-	// Validate refresh token
-	claims, err := jwt.ValidateToken(refreshToken, s.cfg.JWT.Secret)
-	if err != nil {
-		return nil, response.UnauthorizedError("Invalid refresh token")
-	}
-
-	// Check if token is blacklisted
-	isBlacklisted, _ := cache.Get(ctx, "blacklist:"+refreshToken)
-	if isBlacklisted != "" {
-		return nil, response.UnauthorizedError("Token has been revoked")
-	}
-
-	// Get user
-	user, err := s.repo.FindByID(ctx, claims.UserID)
-	if err != nil {
-		return nil, response.NotFoundError("User")
-	}
-
-	// Check account status
-	if user.Status != models.StatusActive {
-		return nil, response.ForbiddenError("Account is not active")
-	}
-
-	// Generate new tokens
-	authResp, err := s.generateAuthResponse(user)
-	if err != nil {
-		return nil, err
-	}
-
-	// Blacklist old refresh token
-	cache.Set(ctx, "blacklist:"+refreshToken, "1", time.Duration(s.cfg.JWT.RefreshExpiry)*20)
-
-	logger.Info("token refreshed", "userId", user.ID)
-
-	return authResp, nil
-}
-
-// Logout invalidates tokens
-func (s *service) Logout(ctx context.Context, userID, refreshToken string) error {
-	// Blacklist refresh token
-	if refreshToken != "" {
-		cache.Set(ctx, "blacklist:"+refreshToken, "1", time.Duration(s.cfg.JWT.RefreshExpiry)*20)
-	}
-
-	// Clear user cache
-	cache.Delete(ctx, "user:profile:"+userID)
-
-	logger.Info("user logged out", "userId", userID)
-
-	return nil
-}
-
 // GetProfile retrieves user profile
 func (s *service) GetProfile(ctx context.Context, userID string) (*authdto.UserResponse, error) {
 	// Try cache first
@@ -440,6 +498,8 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, req authdto.
 		return nil, response.NotFoundError("User")
 	}
 
+	emailChanged := false
+
 	// Update fields
 	if req.Name != nil {
 		user.Name = *req.Name
@@ -450,6 +510,11 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, req authdto.
 		if err == nil && existingUser.ID != userID {
 			return nil, response.ConflictError("Email already in use")
 		}
+		// A new address has to be verified again
+		if user.Email == nil || *user.Email != *req.Email {
+			user.EmailVerifiedAt = nil
+			emailChanged = true
+		}
 		user.Email = req.Email
 	}
 	if req.ProfilePhotoURL != nil {
@@ -463,57 +528,30 @@ func (s *service) UpdateProfile(ctx context.Context, userID string, req authdto.
 	// Invalidate cache
 	cache.Delete(ctx, "user:profile:"+userID)
 
-	logger.Info("profile updated", "userId", userID)
-
-	return authdto.ToUserResponse(user), nil
-}
-
-// Helper: Generate auth response with tokens
-func (s *service) generateAuthResponse(user *models.User) (*authdto.AuthResponse, error) {
-	// Generate access token
-	accessToken, err := jwt.GenerateToken(
-		user.ID,
-		string(user.Role),
-		s.cfg.JWT.Secret,
-		time.Duration(s.cfg.JWT.AccessExpiry)*7,
-	)
-	if err != nil {
-		return nil, response.InternalServerError("Failed to generate access token", err)
+	if emailChanged {
+		if _, err := s.sendVerificationEmail(ctx, user); err != nil {
+			logger.Error("failed to send verification email", "error", err, "userId", userID)
+		}
 	}
 
-	// Generate refresh token
-	refreshToken, err := jwt.GenerateToken(
-		user.ID,
-		string(user.Role),
-		s.cfg.JWT.Secret,
-		time.Duration(s.cfg.JWT.RefreshExpiry)*20,
-	)
-	if err != nil {
-		return nil, response.InternalServerError("Failed to generate refresh token", err)
-	}
+	logger.Info("profile updated", "userId", userID)
 
-	return &authdto.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User:         authdto.ToUserResponse(user),
-	}, nil
+	return authdto.ToUserResponse(user), nil
 }
 
 // ✅ Helper: Update wallet creation logic
 func (s *service) createUserWallet(ctx context.Context, user *models.User) error {
 	var walletType models.WalletType
-	var initialBalance float64
+	initialBalance := money.Zero(money.USD)
 
 	switch user.Role {
 	case models.RoleRider:
 		walletType = models.WalletTypeRider
-		initialBalance = 1000.00 // Give riders $1000 fake money
+		initialBalance = money.New(1000_00, money.USD) // Give riders $1000 fake money
 	case models.RoleDriver:
 		walletType = models.WalletTypeDriver
-		initialBalance = 0.00
 	case models.RoleServiceProvider, models.RoleHandyman, models.RoleDeliveryPerson:
 		walletType = models.WalletTypeServiceProvider
-		initialBalance = 0.00
 	default:
 		// Admins don't need wallets
 		return nil
@@ -523,8 +561,8 @@ func (s *service) createUserWallet(ctx context.Context, user *models.User) error
 		UserID:      user.ID,
 		WalletType:  walletType,
 		Balance:     initialBalance,
-		HeldBalance: 0.00,
-		Currency:    "USD",
+		HeldBalance: money.Zero(money.USD),
+		Currency:    string(money.USD),
 		IsActive:    true,
 	}
 
@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/models"
+	authdto "github.com/umar5678/go-backend/internal/modules/auth/dto"
+	"github.com/umar5678/go-backend/internal/services/authstate"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/jwt"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/gorm"
+)
+
+// startSession records a new device session for the user and issues its
+// first token pair
+func (s *service) startSession(ctx context.Context, user *models.User, device authdto.DeviceInfo) (*authdto.AuthResponse, error) {
+	// One session per device: logging in again on the same device replaces it
+	if device.DeviceID != "" {
+		ids, err := s.repo.RevokeDeviceSessions(ctx, user.ID, device.DeviceID, models.SessionRevokedReplaced)
+		if err != nil {
+			logger.Error("failed to revoke previous device session", "error", err, "userId", user.ID, "deviceId", device.DeviceID)
+		}
+		s.markRevoked(ctx, ids...)
+	}
+
+	now := time.Now()
+	session := &models.UserSession{
+		UserID:         user.ID,
+		RefreshTokenID: uuid.NewString(),
+		DeviceID:       truncate(device.DeviceID, 255),
+		DeviceName:     truncate(device.DeviceName, 255),
+		AppVersion:     truncate(device.AppVersion, 50),
+		UserAgent:      truncate(device.UserAgent, 500),
+		IPAddress:      truncate(device.IPAddress, 45),
+		LastUsedAt:     now,
+		ExpiresAt:      now.Add(s.refreshExpiry()),
+	}
+
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		logger.Error("failed to create session", "error", err, "userId", user.ID)
+		return nil, response.InternalServerError("Failed to create session", err)
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// RefreshToken rotates a session's refresh token. A refresh token that was
+// already rotated means it leaked or was replayed: the whole session is
+// revoked and the device has to log in again.
+func (s *service) RefreshToken(ctx context.Context, refreshToken string, device authdto.DeviceInfo) (*authdto.AuthResponse, error) {
+	// Validate refresh token
+	claims, err := jwt.ValidateRefreshToken(refreshToken, s.cfg.JWT.Secret)
+	if err != nil {
+		return nil, response.UnauthorizedError("Invalid refresh token")
+	}
+
+	// Tokens issued before sessions existed cannot be rotated
+	if claims.SessionID == "" || claims.ID == "" {
+		return nil, response.UnauthorizedError("Session expired, please log in again")
+	}
+
+	session, err := s.repo.FindSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, response.UnauthorizedError("Invalid refresh token")
+		}
+		return nil, response.InternalServerError("Failed to find session", err)
+	}
+
+	if session.UserID != claims.UserID {
+		return nil, response.UnauthorizedError("Invalid refresh token")
+	}
+
+	if !session.IsActive(time.Now()) {
+		return nil, response.UnauthorizedError("Session has been revoked, please log in again")
+	}
+
+	if claims.ID != session.RefreshTokenID {
+		return nil, s.revokeOnReuse(ctx, session)
+	}
+
+	// Get user
+	user, err := s.repo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, response.NotFoundError("User")
+	}
+
+	// Check account status
+	if user.Status != models.StatusActive {
+		return nil, response.ForbiddenError("Account is not active")
+	}
+
+	// Every token issued before the last version bump is revoked
+	if claims.Version != user.TokenVersion {
+		return nil, response.UnauthorizedError("Session has been revoked, please log in again")
+	}
+
+	newTokenID := uuid.NewString()
+	expiresAt := time.Now().Add(s.refreshExpiry())
+
+	// Only one refresh per token can win; the loser presented a token that
+	// is no longer current, which is reuse
+	rotated, err := s.repo.RotateSession(ctx, session.ID, claims.ID, newTokenID,
+		expiresAt, truncate(device.IPAddress, 45), truncate(device.UserAgent, 500))
+	if err != nil {
+		return nil, response.InternalServerError("Failed to refresh session", err)
+	}
+	if !rotated {
+		return nil, s.revokeOnReuse(ctx, session)
+	}
+
+	session.RefreshTokenID = newTokenID
+	session.ExpiresAt = expiresAt
+
+	// Generate new tokens
+	authResp, err := s.issueTokens(user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("token refreshed", "userId", user.ID, "sessionId", session.ID)
+
+	return authResp, nil
+}
+
+// Logout ends the current session. The session comes from the access token,
+// or from the refresh token when the access token carries none.
+func (s *service) Logout(ctx context.Context, userID, sessionID, refreshToken string) error {
+	if sessionID == "" && refreshToken != "" {
+		if claims, err := jwt.ValidateRefreshToken(refreshToken, s.cfg.JWT.Secret); err == nil && claims.UserID == userID {
+			sessionID = claims.SessionID
+		}
+	}
+
+	if sessionID != "" {
+		session, err := s.repo.FindSessionByID(ctx, sessionID)
+		if err == nil && session.UserID == userID {
+			if _, err := s.repo.RevokeSession(ctx, sessionID, models.SessionRevokedLogout); err != nil {
+				return response.InternalServerError("Failed to log out", err)
+			}
+			s.markRevoked(ctx, sessionID)
+		}
+	}
+
+	// Clear user cache
+	cache.Delete(ctx, "user:profile:"+userID)
+
+	logger.Info("user logged out", "userId", userID, "sessionId", sessionID)
+
+	return nil
+}
+
+// ListSessions returns the user's live sessions, most recently used first
+func (s *service) ListSessions(ctx context.Context, userID, currentSessionID string) ([]*authdto.SessionResponse, error) {
+	sessions, err := s.repo.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch sessions", err)
+	}
+
+	result := make([]*authdto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		result[i] = authdto.ToSessionResponse(session, currentSessionID)
+	}
+	return result, nil
+}
+
+// RevokeSession logs one of the user's devices out
+func (s *service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.repo.FindSessionByID(ctx, sessionID)
+	if err != nil || session.UserID != userID {
+		return response.NotFoundError("Session")
+	}
+
+	if _, err := s.repo.RevokeSession(ctx, sessionID, models.SessionRevokedByUser); err != nil {
+		return response.InternalServerError("Failed to revoke session", err)
+	}
+	s.markRevoked(ctx, sessionID)
+
+	logger.Info("session revoked", "userId", userID, "sessionId", sessionID)
+
+	return nil
+}
+
+// RevokeAllSessions logs all of the user's devices out, except keepSessionID
+// when it is set
+func (s *service) RevokeAllSessions(ctx context.Context, userID, keepSessionID string) (*authdto.RevokedSessionsResponse, error) {
+	ids, err := s.repo.RevokeUserSessions(ctx, userID, keepSessionID, models.SessionRevokedByUser)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to revoke sessions", err)
+	}
+	s.markRevoked(ctx, ids...)
+
+	logger.Info("sessions revoked", "userId", userID, "count", len(ids), "kept", keepSessionID)
+
+	return &authdto.RevokedSessionsResponse{Revoked: len(ids)}, nil
+}
+
+// ForceLogout revokes every session of a user on an admin's behalf. The
+// token version is bumped too, so no token issued so far survives.
+func (s *service) ForceLogout(ctx context.Context, userID string) (int, error) {
+	if _, err := s.repo.FindByID(ctx, userID); err != nil {
+		return 0, response.NotFoundError("User")
+	}
+
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	ids, err := s.repo.RevokeUserSessions(ctx, userID, "", models.SessionRevokedForceLogout)
+	if err != nil {
+		return 0, response.InternalServerError("Failed to revoke sessions", err)
+	}
+	s.markRevoked(ctx, ids...)
+
+	cache.Delete(ctx, "user:profile:"+userID)
+
+	logger.Info("user force logged out", "userId", userID, "count", len(ids))
+
+	return len(ids), nil
+}
+
+// revokeOnReuse kills a session whose rotated refresh token was presented
+func (s *service) revokeOnReuse(ctx context.Context, session *models.UserSession) error {
+	if _, err := s.repo.RevokeSession(ctx, session.ID, models.SessionRevokedTokenReuse); err != nil {
+		logger.Error("failed to revoke session after refresh token reuse", "error", err, "sessionId", session.ID)
+	}
+	s.markRevoked(ctx, session.ID)
+
+	logger.Warn("refresh token reuse detected, session revoked", "userId", session.UserID, "sessionId", session.ID)
+
+	return response.UnauthorizedError("Refresh token has already been used, please log in again")
+}
+
+// revokeAllTokens bumps the user's token version, invalidating every access
+// and refresh token issued so far on all devices
+func (s *service) revokeAllTokens(ctx context.Context, userID string) error {
+	if err := s.repo.IncrementTokenVersion(ctx, userID); err != nil {
+		return response.InternalServerError("Failed to revoke tokens", err)
+	}
+	authstate.Invalidate(ctx, userID)
+	return nil
+}
+
+// markRevoked tells the auth middleware to reject access tokens of revoked
+// sessions. The marker only needs to outlive the longest access token.
+func (s *service) markRevoked(ctx context.Context, sessionIDs ...string) {
+	for _, id := range sessionIDs {
+		if err := cache.Set(ctx, models.RevokedSessionKey(id), "1", s.accessExpiry()); err != nil {
+			logger.Error("failed to mark session revoked", "error", err, "sessionId", id)
+		}
+	}
+}
+
+// issueTokens signs an access and refresh token for a session
+func (s *service) issueTokens(user *models.User, session *models.UserSession) (*authdto.AuthResponse, error) {
+	// Generate access token
+	accessToken, err := jwt.GenerateAccessToken(
+		user.ID,
+		string(user.Role),
+		session.ID,
+		user.TokenVersion,
+		s.cfg.JWT.Secret,
+		s.accessExpiry(),
+	)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to generate access token", err)
+	}
+
+	// Generate refresh token
+	refreshToken, err := jwt.GenerateRefreshToken(
+		user.ID,
+		string(user.Role),
+		session.ID,
+		session.RefreshTokenID,
+		user.TokenVersion,
+		s.cfg.JWT.Secret,
+		time.Until(session.ExpiresAt),
+	)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to generate refresh token", err)
+	}
+
+	return &authdto.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		SessionID:    session.ID,
+		User:         authdto.ToUserResponse(user),
+	}, nil
+}
+
+func (s *service) accessExpiry() time.Duration {
+	return time.Duration(s.cfg.JWT.AccessExpiry) * 7
+}
+
+func (s *service) refreshExpiry() time.Duration {
+	return time.Duration(s.cfg.JWT.RefreshExpiry) * 20
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"errors"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+// CalculateRequest describes a payout to be split between the platform and
+// the driver/provider. Empty scope fields only match rules that leave the
+// same field open.
+type CalculateRequest struct {
+	ServiceLine   string
+	City          string
+	VehicleTypeID string
+	Category      string
+	Tier          string
+	Amount        money.Amount
+	At            time.Time // zero means now
+}
+
+type CreateRuleRequest struct {
+	ServiceLine   string     `json:"serviceLine" binding:"required,oneof=ride homeservice laundry"`
+	City          *string    `json:"city" binding:"omitempty,max=100"`
+	VehicleTypeID *string    `json:"vehicleTypeId" binding:"omitempty,uuid"`
+	Category      *string    `json:"category" binding:"omitempty,max=255"`
+	Tier          *string    `json:"tier" binding:"omitempty,max=50"`
+	Rate          float64    `json:"rate" binding:"min=0,max=1"`
+	FlatFee       float64    `json:"flatFee" binding:"omitempty,min=0"`
+	MinCommission *float64   `json:"minCommission" binding:"omitempty,min=0"`
+	MaxCommission *float64   `json:"maxCommission" binding:"omitempty,min=0"`
+	EffectiveFrom *time.Time `json:"effectiveFrom"` // defaults to now
+	Notes         string     `json:"notes" binding:"omitempty,max=1000"`
+}
+
+func (r *CreateRuleRequest) Validate() error {
+	if r.VehicleTypeID != nil && r.ServiceLine != models.ServiceLineRide {
+		return errors.New("vehicleTypeId only applies to ride rules")
+	}
+	if r.Category != nil && r.ServiceLine == models.ServiceLineRide {
+		return errors.New("category does not apply to ride rules")
+	}
+	if r.MinCommission != nil && r.MaxCommission != nil && *r.MinCommission > *r.MaxCommission {
+		return errors.New("minCommission cannot be greater than maxCommission")
+	}
+	// Past versions have already priced payouts and must stay as they were
+	if r.EffectiveFrom != nil && r.EffectiveFrom.Before(time.Now().Add(-time.Minute)) {
+		return errors.New("effectiveFrom cannot be in the past")
+	}
+	return nil
+}
+
+type ListRulesRequest struct {
+	ServiceLine     string `form:"serviceLine" binding:"omitempty,oneof=ride homeservice laundry"`
+	IncludeInactive bool   `form:"includeInactive"`
+}
+
+type QuoteRequest struct {
+	ServiceLine   string  `form:"serviceLine" binding:"required,oneof=ride homeservice laundry"`
+	City          string  `form:"city" binding:"omitempty,max=100"`
+	VehicleTypeID string  `form:"vehicleTypeId" binding:"omitempty,uuid"`
+	Category      string  `form:"category" binding:"omitempty,max=255"`
+	Tier          string  `form:"tier" binding:"omitempty,max=50"`
+	Amount        float64 `form:"amount" binding:"required,gt=0"`
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+type CommissionRuleResponse struct {
+	ID            string     `json:"id"`
+	ServiceLine   string     `json:"serviceLine"`
+	City          *string    `json:"city,omitempty"`
+	VehicleTypeID *string    `json:"vehicleTypeId,omitempty"`
+	Category      *string    `json:"category,omitempty"`
+	Tier          *string    `json:"tier,omitempty"`
+	Rate          float64    `json:"rate"`
+	FlatFee       float64    `json:"flatFee"`
+	MinCommission *float64   `json:"minCommission,omitempty"`
+	MaxCommission *float64   `json:"maxCommission,omitempty"`
+	Version       int        `json:"version"`
+	EffectiveFrom time.Time  `json:"effectiveFrom"`
+	EffectiveTo   *time.Time `json:"effectiveTo,omitempty"`
+	IsActive      bool       `json:"isActive"`
+	Notes         string     `json:"notes,omitempty"`
+	CreatedBy     *string    `json:"createdBy,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+func ToCommissionRuleResponse(rule *models.CommissionRule) *CommissionRuleResponse {
+	return &CommissionRuleResponse{
+		ID:            rule.ID,
+		ServiceLine:   rule.ServiceLine,
+		City:          rule.City,
+		VehicleTypeID: rule.VehicleTypeID,
+		Category:      rule.Category,
+		Tier:          rule.Tier,
+		Rate:          rule.Rate,
+		FlatFee:       rule.FlatFee,
+		MinCommission: rule.MinCommission,
+		MaxCommission: rule.MaxCommission,
+		Version:       rule.Version,
+		EffectiveFrom: rule.EffectiveFrom,
+		EffectiveTo:   rule.EffectiveTo,
+		IsActive:      rule.IsActive,
+		Notes:         rule.Notes,
+		CreatedBy:     rule.CreatedBy,
+		CreatedAt:     rule.CreatedAt,
+	}
+}
+
+// CommissionQuote is the platform/payee split for one payout. RuleID is empty
+// and RuleVersion 0 when no rule matched and the built-in default applied.
+type CommissionQuote struct {
+	RuleID      string       `json:"ruleId,omitempty"`
+	RuleVersion int          `json:"ruleVersion"`
+	ServiceLine string       `json:"serviceLine"`
+	Rate        float64      `json:"rate"`
+	FlatFee     float64      `json:"flatFee"`
+	Amount      money.Amount `json:"amount"`
+	Commission  money.Amount `json:"commission"`
+	Payout      money.Amount `json:"payout"`
+}
+
+// Metadata is what payouts record in their wallet transaction so every
+// credit can be traced back to the rule version that priced it
+func (q *CommissionQuote) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"commissionRuleId":      q.RuleID,
+		"commissionRuleVersion": q.RuleVersion,
+		"commissionRate":        q.Rate,
+		"commissionFlatFee":     q.FlatFee,
+		"commission":            q.Commission.Float64(),
+		"grossAmount":           q.Amount.Float64(),
+	}
+}
+
+// RuleIDPtr returns the rule ID for storing on orders, nil for the default
+func (q *CommissionQuote) RuleIDPtr() *string {
+	if q.RuleID == "" {
+		return nil
+	}
+	id := q.RuleID
+	return &id
+}
@@ -0,0 +1,141 @@
+package commission
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/modules/commission/dto"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListRules godoc
+// @Summary List commission rules (Admin)
+// @Tags commission
+// @Security BearerAuth
+// @Produce json
+// @Param serviceLine query string false "Service line (ride, homeservice, laundry)"
+// @Param includeInactive query bool false "Include ended and deactivated versions"
+// @Success 200 {object} response.Response{data=[]dto.CommissionRuleResponse}
+// @Router /admin/commission/rules [get]
+func (h *Handler) ListRules(c *gin.Context) {
+	var req dto.ListRulesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	rules, err := h.service.ListRules(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, rules, "Commission rules retrieved successfully")
+}
+
+// GetRule godoc
+// @Summary Get commission rule version (Admin)
+// @Tags commission
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} response.Response{data=dto.CommissionRuleResponse}
+// @Router /admin/commission/rules/{id} [get]
+func (h *Handler) GetRule(c *gin.Context) {
+	rule, err := h.service.GetRule(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, rule, "Commission rule retrieved successfully")
+}
+
+// CreateRule godoc
+// @Summary Create commission rule version (Admin)
+// @Description Adds a new version for the rule's scope. The current version is closed when the new one takes effect.
+// @Tags commission
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRuleRequest true "Rule"
+// @Success 200 {object} response.Response{data=dto.CommissionRuleResponse}
+// @Router /admin/commission/rules [post]
+func (h *Handler) CreateRule(c *gin.Context) {
+	var req dto.CreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	adminID, _ := c.Get("userID")
+
+	rule, err := h.service.CreateRule(c.Request.Context(), adminID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, rule, "Commission rule created successfully")
+}
+
+// EndRule godoc
+// @Summary End commission rule version (Admin)
+// @Description Stops the version from applying; the next most specific rule takes over
+// @Tags commission
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} response.Response
+// @Router /admin/commission/rules/{id} [delete]
+func (h *Handler) EndRule(c *gin.Context) {
+	if err := h.service.EndRule(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Commission rule ended successfully")
+}
+
+// Quote godoc
+// @Summary Preview commission for an amount (Admin)
+// @Tags commission
+// @Security BearerAuth
+// @Produce json
+// @Param serviceLine query string true "Service line (ride, homeservice, laundry)"
+// @Param amount query number true "Gross amount"
+// @Param city query string false "City"
+// @Param vehicleTypeId query string false "Vehicle type ID"
+// @Param category query string false "Home service category slug"
+// @Param tier query string false "Driver / provider tier"
+// @Success 200 {object} response.Response{data=dto.CommissionQuote}
+// @Router /admin/commission/quote [get]
+func (h *Handler) Quote(c *gin.Context) {
+	var req dto.QuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	quote, err := h.service.Calculate(c.Request.Context(), dto.CalculateRequest{
+		ServiceLine:   req.ServiceLine,
+		City:          req.City,
+		VehicleTypeID: req.VehicleTypeID,
+		Category:      req.Category,
+		Tier:          req.Tier,
+		Amount:        money.FromFloat(req.Amount, money.DefaultCurrency, money.HalfUp),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, quote, "Commission calculated successfully")
+}
@@ -0,0 +1,126 @@
+package commission
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLaterVersionScheduled is returned when a new version would start before
+// a version of the same scope that is already scheduled
+var ErrLaterVersionScheduled = errors.New("a later version of this rule is already scheduled")
+
+type Repository interface {
+	FindActiveRules(ctx context.Context, serviceLine string) ([]*models.CommissionRule, error)
+	FindRuleByID(ctx context.Context, id string) (*models.CommissionRule, error)
+	ListRules(ctx context.Context, serviceLine string, includeInactive bool) ([]*models.CommissionRule, error)
+	CreateRuleVersion(ctx context.Context, rule *models.CommissionRule) error
+	EndRule(ctx context.Context, id string, at time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindActiveRules returns every active version for the service line that has
+// not ended yet, including versions scheduled for the future
+func (r *repository) FindActiveRules(ctx context.Context, serviceLine string) ([]*models.CommissionRule, error) {
+	var rules []*models.CommissionRule
+	err := r.db.WithContext(ctx).
+		Where("service_line = ? AND is_active = true", serviceLine).
+		Where("effective_to IS NULL OR effective_to > ?", time.Now()).
+		Order("effective_from ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *repository) FindRuleByID(ctx context.Context, id string) (*models.CommissionRule, error) {
+	var rule models.CommissionRule
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&rule).Error
+	return &rule, err
+}
+
+func (r *repository) ListRules(ctx context.Context, serviceLine string, includeInactive bool) ([]*models.CommissionRule, error) {
+	var rules []*models.CommissionRule
+	query := r.db.WithContext(ctx).Model(&models.CommissionRule{})
+
+	if serviceLine != "" {
+		query = query.Where("service_line = ?", serviceLine)
+	}
+	if !includeInactive {
+		query = query.Where("is_active = true").
+			Where("effective_to IS NULL OR effective_to > ?", time.Now())
+	}
+
+	err := query.
+		Order("service_line ASC, city ASC NULLS FIRST, vehicle_type_id ASC NULLS FIRST, category ASC NULLS FIRST, tier ASC NULLS FIRST, version DESC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// CreateRuleVersion appends rule to the version chain of its scope. The open
+// version (if any) is closed at rule.EffectiveFrom so exactly one version of a
+// scope applies at any point in time.
+func (r *repository) CreateRuleVersion(ctx context.Context, rule *models.CommissionRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest models.CommissionRule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("service_line = ?", rule.ServiceLine).
+			Where("city IS NOT DISTINCT FROM ?", rule.City).
+			Where("vehicle_type_id IS NOT DISTINCT FROM ?", rule.VehicleTypeID).
+			Where("category IS NOT DISTINCT FROM ?", rule.Category).
+			Where("tier IS NOT DISTINCT FROM ?", rule.Tier).
+			Order("version DESC").
+			First(&latest).Error
+
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			rule.Version = 1
+		case err != nil:
+			return err
+		default:
+			rule.Version = latest.Version + 1
+
+			if latest.IsActive && (latest.EffectiveTo == nil || latest.EffectiveTo.After(rule.EffectiveFrom)) {
+				if !latest.EffectiveFrom.Before(rule.EffectiveFrom) {
+					return ErrLaterVersionScheduled
+				}
+				if err := tx.Model(&models.CommissionRule{}).
+					Where("id = ?", latest.ID).
+					Update("effective_to", rule.EffectiveFrom).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Create(rule).Error
+	})
+}
+
+// EndRule stops a version from applying after at, so the next most specific
+// rule takes over. A version that has not started yet is deactivated instead.
+func (r *repository) EndRule(ctx context.Context, id string, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.CommissionRule{}).
+		Where("id = ? AND is_active = true", id).
+		Where("effective_to IS NULL OR effective_to > ?", at).
+		Updates(map[string]interface{}{
+			"effective_to": gorm.Expr("CASE WHEN effective_from < ? THEN ?::timestamptz ELSE effective_to END", at, at),
+			"is_active":    gorm.Expr("effective_from < ?", at),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package commission
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
+	commission := router.Group("/admin/commission")
+	commission.Use(authMiddleware)
+	commission.Use(middleware.RequirePermission(checker, models.PermissionCommissionManage))
+	{
+		commission.GET("/rules", handler.ListRules)
+		commission.POST("/rules", handler.CreateRule)
+		commission.GET("/rules/:id", handler.GetRule)
+		commission.DELETE("/rules/:id", handler.EndRule)
+		commission.GET("/quote", handler.Quote)
+	}
+}
@@ -0,0 +1,283 @@
+package commission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/commission/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/gorm"
+)
+
+// Platform share used when no rule matches. These mirror the rates that were
+// hardcoded before rules existed and the rows seeded by the migration.
+var defaultCommissionRates = map[string]float64{
+	models.ServiceLineRide:        0.20,
+	models.ServiceLineHomeService: 0.10,
+	models.ServiceLineLaundry:     0.10,
+}
+
+const rulesCacheTTL = 5 * time.Minute
+
+type Service interface {
+	// Calculate splits an amount using the rule in force at req.At
+	Calculate(ctx context.Context, req dto.CalculateRequest) (*dto.CommissionQuote, error)
+
+	// Admin
+	ListRules(ctx context.Context, req dto.ListRulesRequest) ([]*dto.CommissionRuleResponse, error)
+	GetRule(ctx context.Context, id string) (*dto.CommissionRuleResponse, error)
+	CreateRule(ctx context.Context, adminID string, req dto.CreateRuleRequest) (*dto.CommissionRuleResponse, error)
+	EndRule(ctx context.Context, id string) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Calculate(ctx context.Context, req dto.CalculateRequest) (*dto.CommissionQuote, error) {
+	defaultRate, ok := defaultCommissionRates[req.ServiceLine]
+	if !ok {
+		return nil, response.BadRequest(fmt.Sprintf("Unknown service line '%s'", req.ServiceLine))
+	}
+
+	at := req.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	quote := &dto.CommissionQuote{
+		ServiceLine: req.ServiceLine,
+		Rate:        defaultRate,
+		Amount:      req.Amount,
+	}
+
+	rules, err := s.loadRules(ctx, req.ServiceLine)
+	if err != nil {
+		// A payout must never be blocked by the rules table; fall back to the
+		// default and leave RuleVersion 0 in the metadata so it can be found
+		logger.Error("failed to load commission rules, using default rate",
+			"error", err,
+			"serviceLine", req.ServiceLine,
+		)
+	}
+
+	var minCommission, maxCommission *float64
+	if rule := selectRule(rules, req, at); rule != nil {
+		quote.RuleID = rule.ID
+		quote.RuleVersion = rule.Version
+		quote.Rate = rule.Rate
+		quote.FlatFee = rule.FlatFee
+		minCommission = rule.MinCommission
+		maxCommission = rule.MaxCommission
+	}
+
+	currency := req.Amount.Currency()
+	commission := req.Amount.Mul(quote.Rate, money.HalfUp).Add(money.FromFloat(quote.FlatFee, currency, money.HalfUp))
+	if minCommission != nil {
+		commission = money.Max(commission, money.FromFloat(*minCommission, currency, money.HalfUp))
+	}
+	if maxCommission != nil {
+		commission = money.Min(commission, money.FromFloat(*maxCommission, currency, money.HalfUp))
+	}
+	// The platform can never take more than the whole amount
+	commission = money.Max(money.Zero(currency), money.Min(commission, req.Amount))
+
+	// Payout is whatever is left, so the two always add up to the amount
+	quote.Commission = commission
+	quote.Payout = req.Amount.Sub(commission)
+
+	return quote, nil
+}
+
+// selectRule picks the most specific rule in force at the given time. Ties go
+// to the version that started most recently.
+func selectRule(rules []*models.CommissionRule, req dto.CalculateRequest, at time.Time) *models.CommissionRule {
+	var best *models.CommissionRule
+	bestScore := -1
+
+	for _, rule := range rules {
+		if rule.EffectiveFrom.After(at) || (rule.EffectiveTo != nil && !rule.EffectiveTo.After(at)) {
+			continue
+		}
+
+		score, ok := ruleSpecificity(rule, req)
+		if !ok {
+			continue
+		}
+
+		if score > bestScore || (score == bestScore && rule.EffectiveFrom.After(best.EffectiveFrom)) {
+			best = rule
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// ruleSpecificity reports whether the rule's scope matches the request and,
+// if so, how specific it is. Vehicle type and category outrank city, which
+// outranks tier, so a city-wide override never beats a product-level one.
+func ruleSpecificity(rule *models.CommissionRule, req dto.CalculateRequest) (int, bool) {
+	score := 0
+
+	scopes := []struct {
+		rule   *string
+		value  string
+		weight int
+	}{
+		{rule.VehicleTypeID, req.VehicleTypeID, 8},
+		{rule.Category, req.Category, 8},
+		{rule.City, req.City, 4},
+		{rule.Tier, req.Tier, 2},
+	}
+
+	for _, scope := range scopes {
+		if scope.rule == nil {
+			continue
+		}
+		if !strings.EqualFold(*scope.rule, scope.value) {
+			return 0, false
+		}
+		score += scope.weight
+	}
+
+	return score, true
+}
+
+func (s *service) loadRules(ctx context.Context, serviceLine string) ([]*models.CommissionRule, error) {
+	cacheKey := fmt.Sprintf("commission:rules:%s", serviceLine)
+
+	var cached []*models.CommissionRule
+	if err := cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return cached, nil
+	}
+
+	rules, err := s.repo.FindActiveRules(ctx, serviceLine)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.SetJSON(ctx, cacheKey, rules, rulesCacheTTL)
+	return rules, nil
+}
+
+func (s *service) invalidateRules(ctx context.Context, serviceLine string) {
+	cache.Delete(ctx, fmt.Sprintf("commission:rules:%s", serviceLine))
+}
+
+func (s *service) ListRules(ctx context.Context, req dto.ListRulesRequest) ([]*dto.CommissionRuleResponse, error) {
+	rules, err := s.repo.ListRules(ctx, req.ServiceLine, req.IncludeInactive)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch commission rules", err)
+	}
+
+	result := make([]*dto.CommissionRuleResponse, len(rules))
+	for i, rule := range rules {
+		result[i] = dto.ToCommissionRuleResponse(rule)
+	}
+	return result, nil
+}
+
+func (s *service) GetRule(ctx context.Context, id string) (*dto.CommissionRuleResponse, error) {
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFoundError("Commission rule")
+		}
+		return nil, response.InternalServerError("Failed to fetch commission rule", err)
+	}
+	return dto.ToCommissionRuleResponse(rule), nil
+}
+
+func (s *service) CreateRule(ctx context.Context, adminID string, req dto.CreateRuleRequest) (*dto.CommissionRuleResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+
+	effectiveFrom := time.Now()
+	if req.EffectiveFrom != nil && req.EffectiveFrom.After(effectiveFrom) {
+		effectiveFrom = *req.EffectiveFrom
+	}
+
+	rule := &models.CommissionRule{
+		ServiceLine:   req.ServiceLine,
+		City:          normalizeScope(req.City),
+		VehicleTypeID: normalizeScope(req.VehicleTypeID),
+		Category:      normalizeScope(req.Category),
+		Tier:          normalizeScope(req.Tier),
+		Rate:          req.Rate,
+		FlatFee:       req.FlatFee,
+		MinCommission: req.MinCommission,
+		MaxCommission: req.MaxCommission,
+		EffectiveFrom: effectiveFrom,
+		IsActive:      true,
+		Notes:         req.Notes,
+		CreatedBy:     &adminID,
+	}
+
+	if err := s.repo.CreateRuleVersion(ctx, rule); err != nil {
+		if errors.Is(err, ErrLaterVersionScheduled) {
+			return nil, response.ConflictError("A later version of this rule is already scheduled")
+		}
+		logger.Error("failed to create commission rule", "error", err, "serviceLine", req.ServiceLine)
+		return nil, response.InternalServerError("Failed to create commission rule", err)
+	}
+
+	s.invalidateRules(ctx, rule.ServiceLine)
+
+	logger.Info("commission rule version created",
+		"ruleID", rule.ID,
+		"serviceLine", rule.ServiceLine,
+		"version", rule.Version,
+		"rate", rule.Rate,
+		"effectiveFrom", rule.EffectiveFrom,
+		"adminID", adminID,
+	)
+
+	return dto.ToCommissionRuleResponse(rule), nil
+}
+
+func (s *service) EndRule(ctx context.Context, id string) error {
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFoundError("Commission rule")
+		}
+		return response.InternalServerError("Failed to end commission rule", err)
+	}
+
+	if err := s.repo.EndRule(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.BadRequest("Commission rule has already ended")
+		}
+		return response.InternalServerError("Failed to end commission rule", err)
+	}
+
+	s.invalidateRules(ctx, rule.ServiceLine)
+
+	logger.Info("commission rule ended", "ruleID", id, "serviceLine", rule.ServiceLine, "version", rule.Version)
+	return nil
+}
+
+// normalizeScope treats blank scope values as "match anything"
+func normalizeScope(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*v)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/umar5678/go-backend/internal/models"
+)
+
+// Coordinate is a vertex of a geofence boundary
+type Coordinate struct {
+	Lat float64 `json:"lat" binding:"min=-90,max=90"`
+	Lon float64 `json:"lon" binding:"min=-180,max=180"`
+}
+
+type PickupPointRequest struct {
+	Name string  `json:"name" binding:"required,max=100"`
+	Lat  float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lon  float64 `json:"lon" binding:"required,min=-180,max=180"`
+}
+
+type CreateGeofenceRequest struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	Type     string   `json:"type" binding:"required,oneof=operating_city no_service airport"`
+	City     string   `json:"city" binding:"omitempty,max=100"`
+	Services []string `json:"services" binding:"omitempty,dive,oneof=ride homeservice laundry"` // empty applies to every service line
+	Fee      float64  `json:"fee" binding:"omitempty,min=0"`                                    // airports only
+
+	// Boundary is the polygon's outer ring; it is closed automatically
+	Boundary     []Coordinate         `json:"boundary" binding:"required,min=3,max=1000,dive"`
+	PickupPoints []PickupPointRequest `json:"pickupPoints" binding:"omitempty,max=50,dive"` // airports only
+}
+
+func (r *CreateGeofenceRequest) Validate() error {
+	if r.Type == models.GeofenceTypeOperatingCity && strings.TrimSpace(r.City) == "" {
+		return errors.New("city is required for an operating city")
+	}
+	if r.Type != models.GeofenceTypeAirport {
+		if r.Fee > 0 {
+			return errors.New("fee only applies to airports")
+		}
+		if len(r.PickupPoints) > 0 {
+			return errors.New("pickup points only apply to airports")
+		}
+	}
+	return validateRing(r.Boundary)
+}
+
+// UpdateGeofenceRequest changes the given fields; the type cannot change
+type UpdateGeofenceRequest struct {
+	Name     *string      `json:"name" binding:"omitempty,max=100"`
+	City     *string      `json:"city" binding:"omitempty,max=100"`
+	Services *[]string    `json:"services" binding:"omitempty,dive,oneof=ride homeservice laundry"`
+	Fee      *float64     `json:"fee" binding:"omitempty,min=0"`
+	IsActive *bool        `json:"isActive"`
+	Boundary []Coordinate `json:"boundary" binding:"omitempty,min=3,max=1000,dive"`
+}
+
+func (r *UpdateGeofenceRequest) Validate() error {
+	if len(r.Boundary) > 0 {
+		return validateRing(r.Boundary)
+	}
+	return nil
+}
+
+type ListGeofencesRequest struct {
+	Type            string `form:"type" binding:"omitempty,oneof=operating_city no_service airport"`
+	IncludeInactive bool   `form:"includeInactive"`
+}
+
+// validateRing rejects rings too small to enclose an area once duplicate
+// consecutive vertices are ignored. Self-intersections are left to PostGIS.
+func validateRing(ring []Coordinate) error {
+	distinct := 0
+	for i, c := range ring {
+		if i == 0 || c != ring[i-1] {
+			distinct++
+		}
+	}
+	if ring[0] == ring[len(ring)-1] {
+		distinct--
+	}
+	if distinct < 3 {
+		return errors.New("boundary needs at least 3 distinct points")
+	}
+	return nil
+}
+
+// RingWKT renders the ring as a WKT polygon, closing it if needed
+func RingWKT(ring []Coordinate) string {
+	if ring[0] != ring[len(ring)-1] {
+		ring = append(ring[:len(ring):len(ring)], ring[0])
+	}
+	points := make([]string, len(ring))
+	for i, c := range ring {
+		points[i] = fmt.Sprintf("%f %f", c.Lon, c.Lat)
+	}
+	return fmt.Sprintf("POLYGON((%s))", strings.Join(points, ", "))
+}
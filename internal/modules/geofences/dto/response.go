@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+type GeofenceResponse struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"`
+	City         string                 `json:"city,omitempty"`
+	Services     []string               `json:"services"`
+	Fee          money.Amount           `json:"fee"`
+	IsActive     bool                   `json:"isActive"`
+	Boundary     []Coordinate           `json:"boundary"`
+	PickupPoints []*PickupPointResponse `json:"pickupPoints,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	UpdatedAt    time.Time              `json:"updatedAt"`
+}
+
+type PickupPointResponse struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	IsActive bool    `json:"isActive"`
+}
+
+// TripZones is what the geofences decided about a ride's pickup and dropoff
+type TripZones struct {
+	// The requested pickup, or the airport pickup point it was moved to
+	PickupLat   float64              `json:"pickupLat"`
+	PickupLon   float64              `json:"pickupLon"`
+	PickupPoint *PickupPointResponse `json:"pickupPoint,omitempty"`
+
+	// Flat fees of the airports the ride picks up or drops off in
+	AirportFee money.Amount `json:"airportFee"`
+	Airports   []string     `json:"airports,omitempty"`
+
+	City string `json:"city,omitempty"` // operating city of the pickup
+}
+
+func ToGeofenceResponse(g *models.Geofence) *GeofenceResponse {
+	services := []string(g.Services)
+	if services == nil {
+		services = []string{}
+	}
+
+	resp := &GeofenceResponse{
+		ID:        g.ID,
+		Name:      g.Name,
+		Type:      g.Type,
+		City:      g.City,
+		Services:  services,
+		Fee:       g.Fee,
+		IsActive:  g.IsActive,
+		Boundary:  boundaryFromGeoJSON(g.BoundaryGeoJSON),
+		CreatedAt: g.CreatedAt,
+		UpdatedAt: g.UpdatedAt,
+	}
+	for i := range g.PickupPoints {
+		resp.PickupPoints = append(resp.PickupPoints, ToPickupPointResponse(&g.PickupPoints[i]))
+	}
+	return resp
+}
+
+func ToPickupPointResponse(p *models.GeofencePickupPoint) *PickupPointResponse {
+	return &PickupPointResponse{
+		ID:       p.ID,
+		Name:     p.Name,
+		Lat:      p.Lat,
+		Lon:      p.Lon,
+		IsActive: p.IsActive,
+	}
+}
+
+// boundaryFromGeoJSON returns the outer ring of a GeoJSON polygon
+func boundaryFromGeoJSON(geoJSON string) []Coordinate {
+	var polygon struct {
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+	if geoJSON == "" || json.Unmarshal([]byte(geoJSON), &polygon) != nil || len(polygon.Coordinates) == 0 {
+		return []Coordinate{}
+	}
+
+	ring := make([]Coordinate, len(polygon.Coordinates[0]))
+	for i, p := range polygon.Coordinates[0] {
+		ring[i] = Coordinate{Lat: p[1], Lon: p[0]}
+	}
+	return ring
+}
@@ -0,0 +1,171 @@
+package geofences
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/modules/geofences/dto"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// ListGeofences godoc
+// @Summary List geofences (Admin)
+// @Tags geofences
+// @Security BearerAuth
+// @Produce json
+// @Param type query string false "Type (operating_city, no_service, airport)"
+// @Param includeInactive query bool false "Include deactivated geofences"
+// @Success 200 {object} response.Response{data=[]dto.GeofenceResponse}
+// @Router /admin/geofences [get]
+func (h *Handler) ListGeofences(c *gin.Context) {
+	var req dto.ListGeofencesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	geofences, err := h.service.ListGeofences(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, geofences, "Geofences retrieved successfully")
+}
+
+// GetGeofence godoc
+// @Summary Get geofence (Admin)
+// @Tags geofences
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Success 200 {object} response.Response{data=dto.GeofenceResponse}
+// @Router /admin/geofences/{id} [get]
+func (h *Handler) GetGeofence(c *gin.Context) {
+	geofence, err := h.service.GetGeofence(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, geofence, "Geofence retrieved successfully")
+}
+
+// CreateGeofence godoc
+// @Summary Create geofence (Admin)
+// @Description Draws an operating city, no-service zone or airport. Airports can carry a flat fee and designated pickup points.
+// @Tags geofences
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateGeofenceRequest true "Geofence"
+// @Success 200 {object} response.Response{data=dto.GeofenceResponse}
+// @Router /admin/geofences [post]
+func (h *Handler) CreateGeofence(c *gin.Context) {
+	var req dto.CreateGeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	geofence, err := h.service.CreateGeofence(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, geofence, "Geofence created successfully")
+}
+
+// UpdateGeofence godoc
+// @Summary Update geofence (Admin)
+// @Tags geofences
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Param request body dto.UpdateGeofenceRequest true "Fields to change"
+// @Success 200 {object} response.Response{data=dto.GeofenceResponse}
+// @Router /admin/geofences/{id} [patch]
+func (h *Handler) UpdateGeofence(c *gin.Context) {
+	var req dto.UpdateGeofenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	geofence, err := h.service.UpdateGeofence(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, geofence, "Geofence updated successfully")
+}
+
+// DeleteGeofence godoc
+// @Summary Delete geofence (Admin)
+// @Tags geofences
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Success 200 {object} response.Response
+// @Router /admin/geofences/{id} [delete]
+func (h *Handler) DeleteGeofence(c *gin.Context) {
+	if err := h.service.DeleteGeofence(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Geofence deleted successfully")
+}
+
+// AddPickupPoint godoc
+// @Summary Add airport pickup point (Admin)
+// @Tags geofences
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Param request body dto.PickupPointRequest true "Pickup point"
+// @Success 200 {object} response.Response{data=dto.PickupPointResponse}
+// @Router /admin/geofences/{id}/pickup-points [post]
+func (h *Handler) AddPickupPoint(c *gin.Context) {
+	var req dto.PickupPointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	point, err := h.service.AddPickupPoint(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, point, "Pickup point added successfully")
+}
+
+// DeletePickupPoint godoc
+// @Summary Delete airport pickup point (Admin)
+// @Tags geofences
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Param pointId path string true "Pickup point ID"
+// @Success 200 {object} response.Response
+// @Router /admin/geofences/{id}/pickup-points/{pointId} [delete]
+func (h *Handler) DeletePickupPoint(c *gin.Context) {
+	if err := h.service.DeletePickupPoint(c.Request.Context(), c.Param("id"), c.Param("pointId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Pickup point deleted successfully")
+}
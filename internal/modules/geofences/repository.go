@@ -0,0 +1,162 @@
+package geofences
+
+import (
+	"context"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// withBoundary selects the boundary as GeoJSON next to the stored columns
+const withBoundary = "geofences.*, ST_AsGeoJSON(boundary) AS boundary_geojson"
+
+type Repository interface {
+	// Lookups
+	FindContaining(ctx context.Context, lat, lon float64) ([]*models.Geofence, error)
+	HasActiveType(ctx context.Context, geofenceType string) (bool, error)
+	ValidatePolygon(ctx context.Context, wkt string) (string, error)
+
+	// Admin
+	List(ctx context.Context, geofenceType string, includeInactive bool) ([]*models.Geofence, error)
+	FindByID(ctx context.Context, id string) (*models.Geofence, error)
+	Create(ctx context.Context, geofence *models.Geofence, boundaryWKT string) error
+	Update(ctx context.Context, id string, updates map[string]interface{}) error
+	Delete(ctx context.Context, id string) error
+	CreatePickupPoint(ctx context.Context, point *models.GeofencePickupPoint) error
+	DeletePickupPoint(ctx context.Context, geofenceID, pointID string) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func activePickupPoints(db *gorm.DB) *gorm.DB {
+	return db.Where("is_active = true").Order("name ASC")
+}
+
+// FindContaining returns the active geofences whose boundary covers the
+// point, with their active pickup points
+func (r *repository) FindContaining(ctx context.Context, lat, lon float64) ([]*models.Geofence, error) {
+	var geofences []*models.Geofence
+	err := r.db.WithContext(ctx).
+		Preload("PickupPoints", activePickupPoints).
+		Where("is_active = true").
+		Where("ST_Covers(boundary, ST_SetSRID(ST_MakePoint(?, ?), 4326))", lon, lat).
+		Order("created_at ASC").
+		Find(&geofences).Error
+	return geofences, err
+}
+
+func (r *repository) HasActiveType(ctx context.Context, geofenceType string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Geofence{}).
+		Where("type = ? AND is_active = true", geofenceType).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ValidatePolygon returns why the WKT polygon is invalid, or "" if it is valid
+func (r *repository) ValidatePolygon(ctx context.Context, wkt string) (string, error) {
+	var result struct {
+		Valid  bool
+		Reason string
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT ST_IsValid(geom) AS valid, ST_IsValidReason(geom) AS reason
+		FROM (SELECT ST_GeomFromText(?, 4326) AS geom) AS g
+	`, wkt).Scan(&result).Error
+	if err != nil || result.Valid {
+		return "", err
+	}
+	return result.Reason, nil
+}
+
+func (r *repository) List(ctx context.Context, geofenceType string, includeInactive bool) ([]*models.Geofence, error) {
+	var geofences []*models.Geofence
+	query := r.db.WithContext(ctx).
+		Select(withBoundary).
+		Preload("PickupPoints", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		})
+
+	if geofenceType != "" {
+		query = query.Where("type = ?", geofenceType)
+	}
+	if !includeInactive {
+		query = query.Where("is_active = true")
+	}
+
+	err := query.Order("type ASC, name ASC").Find(&geofences).Error
+	return geofences, err
+}
+
+func (r *repository) FindByID(ctx context.Context, id string) (*models.Geofence, error) {
+	var geofence models.Geofence
+	err := r.db.WithContext(ctx).
+		Select(withBoundary).
+		Preload("PickupPoints", func(db *gorm.DB) *gorm.DB {
+			return db.Order("name ASC")
+		}).
+		Where("id = ?", id).
+		First(&geofence).Error
+	return &geofence, err
+}
+
+// Create inserts the geofence and its pickup points. geofence.ID must be set.
+func (r *repository) Create(ctx context.Context, geofence *models.Geofence, boundaryWKT string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO geofences (id, name, type, city, services, fee, is_active, boundary)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ST_GeomFromText(?, 4326))
+		`, geofence.ID, geofence.Name, geofence.Type, geofence.City, geofence.Services,
+			geofence.Fee, geofence.IsActive, boundaryWKT,
+		).Error
+		if err != nil || len(geofence.PickupPoints) == 0 {
+			return err
+		}
+		return tx.Create(&geofence.PickupPoints).Error
+	})
+}
+
+func (r *repository) Update(ctx context.Context, id string, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.Geofence{}).
+		Where("id = ?", id).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete removes the geofence; its pickup points go with it (ON DELETE CASCADE)
+func (r *repository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Geofence{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repository) CreatePickupPoint(ctx context.Context, point *models.GeofencePickupPoint) error {
+	return r.db.WithContext(ctx).Create(point).Error
+}
+
+func (r *repository) DeletePickupPoint(ctx context.Context, geofenceID, pointID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND geofence_id = ?", pointID, geofenceID).
+		Delete(&models.GeofencePickupPoint{})
+	return result.RowsAffected > 0, result.Error
+}
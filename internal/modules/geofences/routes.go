@@ -0,0 +1,23 @@
+package geofences
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
+	geofences := router.Group("/admin/geofences")
+	geofences.Use(authMiddleware)
+	geofences.Use(middleware.RequirePermission(checker, models.PermissionGeofencesManage))
+	{
+		geofences.GET("", handler.ListGeofences)
+		geofences.POST("", handler.CreateGeofence)
+		geofences.GET("/:id", handler.GetGeofence)
+		geofences.PATCH("/:id", handler.UpdateGeofence)
+		geofences.DELETE("/:id", handler.DeleteGeofence)
+		geofences.POST("/:id/pickup-points", handler.AddPickupPoint)
+		geofences.DELETE("/:id/pickup-points/:pointId", handler.DeletePickupPoint)
+	}
+}
@@ -0,0 +1,382 @@
+package geofences
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/geofences/dto"
+	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	// CheckLocation rejects a location the service line does not operate at,
+	// e.g. a home service or laundry address
+	CheckLocation(ctx context.Context, serviceLine string, lat, lon float64) error
+
+	// CheckTrip rejects a ride whose pickup or dropoff is not served, moves an
+	// airport pickup to the nearest designated pickup point and totals the
+	// airport fees
+	CheckTrip(ctx context.Context, pickup, dropoff location.Point) (*dto.TripZones, error)
+
+	// ResolveCity returns the ride operating city containing the point, or ""
+	ResolveCity(ctx context.Context, lat, lon float64) string
+
+	// ResolveServiceCity is ResolveCity for another service line, e.g. the
+	// city whose commission rules apply to a home service address
+	ResolveServiceCity(ctx context.Context, serviceLine string, lat, lon float64) string
+
+	// Admin
+	ListGeofences(ctx context.Context, req dto.ListGeofencesRequest) ([]*dto.GeofenceResponse, error)
+	GetGeofence(ctx context.Context, id string) (*dto.GeofenceResponse, error)
+	CreateGeofence(ctx context.Context, req dto.CreateGeofenceRequest) (*dto.GeofenceResponse, error)
+	UpdateGeofence(ctx context.Context, id string, req dto.UpdateGeofenceRequest) (*dto.GeofenceResponse, error)
+	DeleteGeofence(ctx context.Context, id string) error
+	AddPickupPoint(ctx context.Context, geofenceID string, req dto.PickupPointRequest) (*dto.PickupPointResponse, error)
+	DeletePickupPoint(ctx context.Context, geofenceID, pointID string) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// zonesAt returns the active geofences containing the point that apply to
+// the service line. A failed lookup is logged and treated as no zones, so a
+// database hiccup never blocks bookings.
+func (s *service) zonesAt(ctx context.Context, serviceLine string, lat, lon float64) []*models.Geofence {
+	geofences, err := s.repo.FindContaining(ctx, lat, lon)
+	if err != nil {
+		logger.Error("failed to look up geofences", "error", err, "lat", lat, "lon", lon)
+		return nil
+	}
+
+	zones := geofences[:0]
+	for _, g := range geofences {
+		if g.AppliesTo(serviceLine) {
+			zones = append(zones, g)
+		}
+	}
+	return zones
+}
+
+// checkZones rejects a point in a no-service zone, or outside every operating
+// city once any operating city has been drawn. label names the point in the
+// error, e.g. "Pickup location".
+func (s *service) checkZones(ctx context.Context, zones []*models.Geofence, label string) error {
+	inCity := false
+	for _, zone := range zones {
+		switch zone.Type {
+		case models.GeofenceTypeNoService:
+			return response.BadRequest(fmt.Sprintf("%s is in an area we do not serve (%s)", label, zone.Name))
+		case models.GeofenceTypeOperatingCity:
+			inCity = true
+		}
+	}
+	if inCity {
+		return nil
+	}
+
+	// With no operating cities drawn yet the service runs everywhere
+	hasCities, err := s.repo.HasActiveType(ctx, models.GeofenceTypeOperatingCity)
+	if err != nil {
+		logger.Error("failed to check operating cities", "error", err)
+		return nil
+	}
+	if hasCities {
+		return response.BadRequest(fmt.Sprintf("%s is outside our service area", label))
+	}
+	return nil
+}
+
+func (s *service) CheckLocation(ctx context.Context, serviceLine string, lat, lon float64) error {
+	zones := s.zonesAt(ctx, serviceLine, lat, lon)
+	return s.checkZones(ctx, zones, "Service address")
+}
+
+func (s *service) CheckTrip(ctx context.Context, pickup, dropoff location.Point) (*dto.TripZones, error) {
+	pickupZones := s.zonesAt(ctx, models.ServiceLineRide, pickup.Latitude, pickup.Longitude)
+	if err := s.checkZones(ctx, pickupZones, "Pickup location"); err != nil {
+		return nil, err
+	}
+	dropoffZones := s.zonesAt(ctx, models.ServiceLineRide, dropoff.Latitude, dropoff.Longitude)
+	if err := s.checkZones(ctx, dropoffZones, "Dropoff location"); err != nil {
+		return nil, err
+	}
+
+	trip := &dto.TripZones{
+		PickupLat:  pickup.Latitude,
+		PickupLon:  pickup.Longitude,
+		AirportFee: money.Zero(money.DefaultCurrency),
+		City:       cityOf(pickupZones),
+	}
+
+	// Each airport's fee is charged once, even for a ride within one airport
+	charged := make(map[string]bool)
+	for _, zone := range append(pickupZones, dropoffZones...) {
+		if zone.Type != models.GeofenceTypeAirport || charged[zone.ID] {
+			continue
+		}
+		charged[zone.ID] = true
+		if zone.Fee.IsPositive() {
+			trip.AirportFee = trip.AirportFee.Add(zone.Fee)
+			trip.Airports = append(trip.Airports, zone.Name)
+		}
+	}
+
+	// Airport pickups happen at the designated points only
+	if point := nearestPickupPoint(pickupZones, pickup); point != nil {
+		trip.PickupLat = point.Lat
+		trip.PickupLon = point.Lon
+		trip.PickupPoint = dto.ToPickupPointResponse(point)
+	}
+
+	return trip, nil
+}
+
+// nearestPickupPoint returns the designated pickup point closest to the
+// pickup among the airports containing it, or nil if there is none
+func nearestPickupPoint(zones []*models.Geofence, pickup location.Point) *models.GeofencePickupPoint {
+	var nearest *models.GeofencePickupPoint
+	best := math.Inf(1)
+	for _, zone := range zones {
+		if zone.Type != models.GeofenceTypeAirport {
+			continue
+		}
+		for i := range zone.PickupPoints {
+			point := &zone.PickupPoints[i]
+			d := location.HaversineDistance(pickup.Latitude, pickup.Longitude, point.Lat, point.Lon)
+			if d < best {
+				nearest, best = point, d
+			}
+		}
+	}
+	return nearest
+}
+
+func cityOf(zones []*models.Geofence) string {
+	for _, zone := range zones {
+		if zone.Type == models.GeofenceTypeOperatingCity {
+			return zone.City
+		}
+	}
+	return ""
+}
+
+func (s *service) ResolveCity(ctx context.Context, lat, lon float64) string {
+	return s.ResolveServiceCity(ctx, models.ServiceLineRide, lat, lon)
+}
+
+func (s *service) ResolveServiceCity(ctx context.Context, serviceLine string, lat, lon float64) string {
+	return cityOf(s.zonesAt(ctx, serviceLine, lat, lon))
+}
+
+// Admin
+
+func (s *service) ListGeofences(ctx context.Context, req dto.ListGeofencesRequest) ([]*dto.GeofenceResponse, error) {
+	geofences, err := s.repo.List(ctx, req.Type, req.IncludeInactive)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to list geofences", err)
+	}
+
+	result := make([]*dto.GeofenceResponse, len(geofences))
+	for i, g := range geofences {
+		result[i] = dto.ToGeofenceResponse(g)
+	}
+	return result, nil
+}
+
+func (s *service) GetGeofence(ctx context.Context, id string) (*dto.GeofenceResponse, error) {
+	geofence, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFoundError("Geofence")
+		}
+		return nil, response.InternalServerError("Failed to get geofence", err)
+	}
+	return dto.ToGeofenceResponse(geofence), nil
+}
+
+func (s *service) CreateGeofence(ctx context.Context, req dto.CreateGeofenceRequest) (*dto.GeofenceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+
+	wkt := dto.RingWKT(req.Boundary)
+	if err := s.validateBoundary(ctx, wkt); err != nil {
+		return nil, err
+	}
+
+	geofence := &models.Geofence{
+		ID:       uuid.New().String(),
+		Name:     strings.TrimSpace(req.Name),
+		Type:     req.Type,
+		City:     strings.TrimSpace(req.City),
+		Services: pq.StringArray(req.Services),
+		Fee:      money.FromFloat(req.Fee, money.DefaultCurrency, money.HalfUp),
+		IsActive: true,
+	}
+	if geofence.Services == nil {
+		geofence.Services = pq.StringArray{}
+	}
+	for _, p := range req.PickupPoints {
+		geofence.PickupPoints = append(geofence.PickupPoints, models.GeofencePickupPoint{
+			GeofenceID: geofence.ID,
+			Name:       strings.TrimSpace(p.Name),
+			Lat:        p.Lat,
+			Lon:        p.Lon,
+			IsActive:   true,
+		})
+	}
+
+	if err := s.repo.Create(ctx, geofence, wkt); err != nil {
+		return nil, response.InternalServerError("Failed to create geofence", err)
+	}
+
+	logger.Info("geofence created",
+		"geofenceID", geofence.ID,
+		"name", geofence.Name,
+		"type", geofence.Type,
+		"pickupPoints", len(geofence.PickupPoints),
+	)
+
+	return s.GetGeofence(ctx, geofence.ID)
+}
+
+func (s *service) UpdateGeofence(ctx context.Context, id string, req dto.UpdateGeofenceRequest) (*dto.GeofenceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+
+	current, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFoundError("Geofence")
+		}
+		return nil, response.InternalServerError("Failed to get geofence", err)
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.City != nil {
+		city := strings.TrimSpace(*req.City)
+		if city == "" && current.Type == models.GeofenceTypeOperatingCity {
+			return nil, response.BadRequest("city is required for an operating city")
+		}
+		updates["city"] = city
+	}
+	if req.Services != nil {
+		services := pq.StringArray(*req.Services)
+		if services == nil {
+			services = pq.StringArray{}
+		}
+		updates["services"] = services
+	}
+	if req.Fee != nil {
+		if *req.Fee > 0 && current.Type != models.GeofenceTypeAirport {
+			return nil, response.BadRequest("fee only applies to airports")
+		}
+		updates["fee"] = money.FromFloat(*req.Fee, money.DefaultCurrency, money.HalfUp)
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if len(req.Boundary) > 0 {
+		wkt := dto.RingWKT(req.Boundary)
+		if err := s.validateBoundary(ctx, wkt); err != nil {
+			return nil, err
+		}
+		updates["boundary"] = gorm.Expr("ST_GeomFromText(?, 4326)", wkt)
+	}
+	if len(updates) == 0 {
+		return dto.ToGeofenceResponse(current), nil
+	}
+
+	if err := s.repo.Update(ctx, id, updates); err != nil {
+		return nil, response.InternalServerError("Failed to update geofence", err)
+	}
+
+	logger.Info("geofence updated", "geofenceID", id, "fields", len(updates))
+
+	return s.GetGeofence(ctx, id)
+}
+
+func (s *service) DeleteGeofence(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFoundError("Geofence")
+		}
+		return response.InternalServerError("Failed to delete geofence", err)
+	}
+
+	logger.Info("geofence deleted", "geofenceID", id)
+	return nil
+}
+
+func (s *service) AddPickupPoint(ctx context.Context, geofenceID string, req dto.PickupPointRequest) (*dto.PickupPointResponse, error) {
+	geofence, err := s.repo.FindByID(ctx, geofenceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFoundError("Geofence")
+		}
+		return nil, response.InternalServerError("Failed to get geofence", err)
+	}
+	if geofence.Type != models.GeofenceTypeAirport {
+		return nil, response.BadRequest("pickup points only apply to airports")
+	}
+
+	point := &models.GeofencePickupPoint{
+		GeofenceID: geofenceID,
+		Name:       strings.TrimSpace(req.Name),
+		Lat:        req.Lat,
+		Lon:        req.Lon,
+		IsActive:   true,
+	}
+	if err := s.repo.CreatePickupPoint(ctx, point); err != nil {
+		return nil, response.InternalServerError("Failed to add pickup point", err)
+	}
+
+	logger.Info("geofence pickup point added", "geofenceID", geofenceID, "pointID", point.ID, "name", point.Name)
+
+	return dto.ToPickupPointResponse(point), nil
+}
+
+func (s *service) DeletePickupPoint(ctx context.Context, geofenceID, pointID string) error {
+	deleted, err := s.repo.DeletePickupPoint(ctx, geofenceID, pointID)
+	if err != nil {
+		return response.InternalServerError("Failed to delete pickup point", err)
+	}
+	if !deleted {
+		return response.NotFoundError("Pickup point")
+	}
+
+	logger.Info("geofence pickup point deleted", "geofenceID", geofenceID, "pointID", pointID)
+	return nil
+}
+
+// validateBoundary rejects polygons PostGIS considers invalid, e.g. a ring
+// that crosses itself
+func (s *service) validateBoundary(ctx context.Context, wkt string) error {
+	reason, err := s.repo.ValidatePolygon(ctx, wkt)
+	if err != nil {
+		return response.InternalServerError("Failed to validate boundary", err)
+	}
+	if reason != "" {
+		return response.BadRequest(fmt.Sprintf("Invalid boundary: %s", reason))
+	}
+	return nil
+}
@@ -2,34 +2,36 @@ package dto
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
 	"github.com/umar5678/go-backend/internal/modules/homeservices/shared"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // ==================== Order Responses ====================
 
 // AdminOrderListResponse represents an order in admin list view
 type AdminOrderListResponse struct {
-	ID             string     `json:"id"`
-	OrderNumber    string     `json:"orderNumber"`
-	CategorySlug   string     `json:"categorySlug"`
-	CategoryTitle  string     `json:"categoryTitle"`
-	CustomerName   string     `json:"customerName"`
-	CustomerPhone  string     `json:"customerPhone"`
-	ProviderName   string     `json:"providerName,omitempty"`
-	BookingDate    string     `json:"bookingDate"`
-	BookingTime    string     `json:"bookingTime"`
-	TotalPrice     float64    `json:"totalPrice"`
-	ProviderPayout float64    `json:"providerPayout"`
-	Commission     float64    `json:"commission"`
-	Status         string     `json:"status"`
-	DisplayStatus  string     `json:"displayStatus"`
-	PaymentMethod  string     `json:"paymentMethod"`
-	PaymentStatus  string     `json:"paymentStatus"`
-	CreatedAt      time.Time  `json:"createdAt"`
-	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	ID             string       `json:"id"`
+	OrderNumber    string       `json:"orderNumber"`
+	CategorySlug   string       `json:"categorySlug"`
+	CategoryTitle  string       `json:"categoryTitle"`
+	CustomerName   string       `json:"customerName"`
+	CustomerPhone  string       `json:"customerPhone"`
+	ProviderName   string       `json:"providerName,omitempty"`
+	BookingDate    string       `json:"bookingDate"`
+	BookingTime    string       `json:"bookingTime"`
+	TotalPrice     money.Amount `json:"totalPrice"`
+	ProviderPayout money.Amount `json:"providerPayout"`
+	Commission     money.Amount `json:"commission"`
+	Status         string       `json:"status"`
+	DisplayStatus  string       `json:"displayStatus"`
+	PaymentMethod  string       `json:"paymentMethod"`
+	PaymentStatus  string       `json:"paymentStatus"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	CompletedAt    *time.Time   `json:"completedAt,omitempty"`
 }
 
 // AdminOrderDetailResponse represents full order details for admin
@@ -108,43 +110,43 @@ type AdminBookingInfo struct {
 
 // AdminOrderServiceItem represents a service item
 type AdminOrderServiceItem struct {
-	ServiceSlug string  `json:"serviceSlug"`
-	Title       string  `json:"title"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	ServiceSlug string       `json:"serviceSlug"`
+	Title       string       `json:"title"`
+	Price       money.Amount `json:"price"`
+	Quantity    int          `json:"quantity"`
+	Subtotal    money.Amount `json:"subtotal"`
 }
 
 // AdminOrderAddonItem represents an addon item
 type AdminOrderAddonItem struct {
-	AddonSlug string  `json:"addonSlug"`
-	Title     string  `json:"title"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Subtotal  float64 `json:"subtotal"`
+	AddonSlug string       `json:"addonSlug"`
+	Title     string       `json:"title"`
+	Price     money.Amount `json:"price"`
+	Quantity  int          `json:"quantity"`
+	Subtotal  money.Amount `json:"subtotal"`
 }
 
 // AdminOrderPricing represents pricing breakdown
 type AdminOrderPricing struct {
-	ServicesTotal      float64 `json:"servicesTotal"`
-	AddonsTotal        float64 `json:"addonsTotal"`
-	Subtotal           float64 `json:"subtotal"`
-	PlatformCommission float64 `json:"platformCommission"`
-	CommissionRate     float64 `json:"commissionRate"` // e.g., 0.10 for 10%
-	TotalPrice         float64 `json:"totalPrice"`
-	ProviderPayout     float64 `json:"providerPayout"`
-	FormattedTotal     string  `json:"formattedTotal"`
+	ServicesTotal      money.Amount `json:"servicesTotal"`
+	AddonsTotal        money.Amount `json:"addonsTotal"`
+	Subtotal           money.Amount `json:"subtotal"`
+	PlatformCommission money.Amount `json:"platformCommission"`
+	CommissionRate     float64      `json:"commissionRate"` // e.g., 0.10 for 10%
+	TotalPrice         money.Amount `json:"totalPrice"`
+	ProviderPayout     money.Amount `json:"providerPayout"`
+	FormattedTotal     string       `json:"formattedTotal"`
 }
 
 // AdminPaymentInfo represents payment info
 type AdminPaymentInfo struct {
-	Method        string  `json:"method"`
-	Status        string  `json:"status"`
-	Total         float64 `json:"total"`
-	AmountPaid    float64 `json:"amountPaid"`
-	Voucher       string  `json:"voucher,omitempty"`
-	TransactionID string  `json:"transactionId,omitempty"`
-	WalletHoldID  string  `json:"walletHoldId,omitempty"`
+	Method        string       `json:"method"`
+	Status        string       `json:"status"`
+	Total         money.Amount `json:"total"`
+	AmountPaid    money.Amount `json:"amountPaid"`
+	Voucher       string       `json:"voucher,omitempty"`
+	TransactionID string       `json:"transactionId,omitempty"`
+	WalletHoldID  string       `json:"walletHoldId,omitempty"`
 }
 
 // AdminOrderStatus represents order status info
@@ -160,11 +162,11 @@ type AdminOrderStatus struct {
 
 // AdminCancellationInfo represents cancellation info
 type AdminCancellationInfo struct {
-	CancelledBy     string    `json:"cancelledBy"`
-	CancelledAt     time.Time `json:"cancelledAt"`
-	Reason          string    `json:"reason"`
-	CancellationFee float64   `json:"cancellationFee"`
-	RefundAmount    float64   `json:"refundAmount"`
+	CancelledBy     string       `json:"cancelledBy"`
+	CancelledAt     time.Time    `json:"cancelledAt"`
+	Reason          string       `json:"reason"`
+	CancellationFee money.Amount `json:"cancellationFee"`
+	RefundAmount    money.Amount `json:"refundAmount"`
 }
 
 // AdminRatingsInfo represents ratings info
@@ -413,6 +415,11 @@ func FormatPrice(price float64) string {
 	return fmt.Sprintf("$%.2f", price)
 }
 
+// FormatAmount formats an order amount for display
+func FormatAmount(amount money.Amount) string {
+	return "$" + amount.String()
+}
+
 // FormatDate formats date for display
 func FormatDate(dateStr string) string {
 	date, err := time.Parse("2006-01-02", dateStr)
@@ -431,9 +438,19 @@ func FormatTime(timeStr string) string {
 	return t.Format("3:04 PM")
 }
 
-// CalculateProviderPayout calculates provider payout (90%)
-func CalculateProviderPayout(totalPrice float64) float64 {
-	return shared.RoundToTwoDecimals(totalPrice * (1 - shared.PlatformCommissionRate))
+// CalculateProviderPayout calculates provider payout after the commission
+// that was fixed when the order was priced
+func CalculateProviderPayout(totalPrice, platformCommission money.Amount) money.Amount {
+	return totalPrice.Sub(platformCommission)
+}
+
+// commissionRate is the effective platform share recorded on an order
+func commissionRate(order *models.ServiceOrderNew) float64 {
+	if !order.Subtotal.IsPositive() {
+		return 0
+	}
+	rate := float64(order.PlatformCommission.Minor()) / float64(order.Subtotal.Minor())
+	return math.Round(rate*10000) / 10000
 }
 
 // GetAvailableActions returns available admin actions for an order
@@ -460,8 +477,8 @@ func GetAvailableActions(status string) []string {
 
 // ToAdminOrderListResponse converts order to list response
 func ToAdminOrderListResponse(order *models.ServiceOrderNew) AdminOrderListResponse {
-	providerPayout := CalculateProviderPayout(order.TotalPrice)
-	commission := order.TotalPrice - providerPayout
+	providerPayout := CalculateProviderPayout(order.TotalPrice, order.PlatformCommission)
+	commission := order.TotalPrice.Sub(providerPayout)
 
 	response := AdminOrderListResponse{
 		ID:             order.ID,
@@ -505,7 +522,7 @@ func ToAdminOrderListResponses(orders []*models.ServiceOrderNew) []AdminOrderLis
 
 // ToAdminOrderDetailResponse converts order to detail response
 func ToAdminOrderDetailResponse(order *models.ServiceOrderNew, history []models.OrderStatusHistory) *AdminOrderDetailResponse {
-	providerPayout := CalculateProviderPayout(order.TotalPrice)
+	providerPayout := CalculateProviderPayout(order.TotalPrice, order.PlatformCommission)
 
 	// Build services
 	services := make([]AdminOrderServiceItem, len(order.SelectedServices))
@@ -515,7 +532,7 @@ func ToAdminOrderDetailResponse(order *models.ServiceOrderNew, history []models.
 			Title:       s.Title,
 			Price:       s.Price,
 			Quantity:    s.Quantity,
-			Subtotal:    s.Price * float64(s.Quantity),
+			Subtotal:    s.Price.MulInt(int64(s.Quantity)),
 		}
 	}
 
@@ -529,7 +546,7 @@ func ToAdminOrderDetailResponse(order *models.ServiceOrderNew, history []models.
 				Title:     a.Title,
 				Price:     a.Price,
 				Quantity:  a.Quantity,
-				Subtotal:  a.Price * float64(a.Quantity),
+				Subtotal:  a.Price.MulInt(int64(a.Quantity)),
 			}
 		}
 	}
@@ -538,9 +555,9 @@ func ToAdminOrderDetailResponse(order *models.ServiceOrderNew, history []models.
 	bookingDate, _ := time.Parse("2006-01-02", order.BookingInfo.Date)
 	today := time.Now().Truncate(24 * time.Hour)
 	bookingInfo := AdminBookingInfo{
-		Day:           order.BookingInfo.Day,
-		Date:          order.BookingInfo.Date,
-		Time:          order.BookingInfo.Time,
+		Day:  order.BookingInfo.Day,
+		Date: order.BookingInfo.Date,
+		Time: order.BookingInfo.Time,
 		PreferredTime: func() string {
 			if order.BookingInfo.PreferredTime.IsZero() {
 				return ""
@@ -594,10 +611,10 @@ func ToAdminOrderDetailResponse(order *models.ServiceOrderNew, history []models.
 			AddonsTotal:        order.AddonsTotal,
 			Subtotal:           order.Subtotal,
 			PlatformCommission: order.PlatformCommission,
-			CommissionRate:     shared.PlatformCommissionRate,
+			CommissionRate:     commissionRate(order),
 			TotalPrice:         order.TotalPrice,
 			ProviderPayout:     providerPayout,
-			FormattedTotal:     FormatPrice(order.TotalPrice),
+			FormattedTotal:     FormatAmount(order.TotalPrice),
 		},
 		Status: AdminOrderStatus{
 			Current:            order.Status,
@@ -63,6 +63,7 @@ type CategoryStats struct {
 	CategorySlug string
 	OrderCount   int64
 	Revenue      float64
+	Commission   float64
 }
 
 type RevenueStats struct {
@@ -322,7 +323,7 @@ func (r *orderRepository) GetOrdersByCategory(ctx context.Context, fromDate, toD
 		Model(&models.ServiceOrderNew{}).
 		Where("created_at >= ? AND created_at < ?", fromDate, toDateEnd).
 		Where("status = ?", shared.OrderStatusCompleted).
-		Select("category_slug, COUNT(*) as order_count, COALESCE(SUM(total_price), 0) as revenue").
+		Select("category_slug, COUNT(*) as order_count, COALESCE(SUM(total_price), 0) as revenue, COALESCE(SUM(platform_commission), 0) as commission").
 		Group("category_slug").
 		Order("revenue DESC").
 		Find(&stats).Error
@@ -11,12 +11,13 @@ import (
 	"github.com/umar5678/go-backend/internal/modules/homeservices/admin/dto"
 	"github.com/umar5678/go-backend/internal/modules/homeservices/shared"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"github.com/umar5678/go-backend/internal/utils/response"
 )
 
 // WalletService interface for wallet operations
 type WalletService interface {
-	Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string) error
+	Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string, metadata map[string]interface{}) error
 	Debit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string) error
 	ReleaseHold(ctx context.Context, holdID string) error
 }
@@ -312,16 +313,16 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID string, req dto.
 	}
 
 	// Calculate refund amount
-	var refundAmount float64
-	var cancellationFee float64
+	var refundAmount money.Amount
+	var cancellationFee money.Amount
 
 	if req.RefundAmount != nil {
 		// Use custom refund amount
-		refundAmount = *req.RefundAmount
-		if refundAmount > order.TotalPrice {
+		refundAmount = money.FromFloat(*req.RefundAmount, money.DefaultCurrency, money.HalfUp)
+		if refundAmount.GreaterThan(order.TotalPrice) {
 			return nil, response.BadRequest("Refund amount cannot exceed order total")
 		}
-		cancellationFee = order.TotalPrice - refundAmount
+		cancellationFee = order.TotalPrice.Sub(refundAmount)
 	} else {
 		// Use standard cancellation fees
 		cancellationFee, refundAmount = shared.CalculateCancellationFee(order.Status, order.TotalPrice)
@@ -331,7 +332,7 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID string, req dto.
 	previousStatus := order.Status
 
 	// Process refund if wallet payment
-	if order.WalletHoldID != nil && refundAmount > 0 {
+	if order.WalletHoldID != nil && refundAmount.IsPositive() {
 		// Release hold
 		if err := s.walletService.ReleaseHold(ctx, *order.WalletHoldID); err != nil {
 			logger.Error("failed to release wallet hold", "error", err, "holdID", *order.WalletHoldID)
@@ -339,11 +340,11 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID string, req dto.
 		}
 
 		// Debit cancellation fee if applicable
-		if cancellationFee > 0 {
+		if cancellationFee.IsPositive() {
 			if err := s.walletService.Debit(
 				ctx,
 				order.CustomerID,
-				cancellationFee,
+				cancellationFee.Float64(),
 				"admin_cancellation_fee",
 				order.ID,
 				fmt.Sprintf("Cancellation fee for order %s (cancelled by admin)", order.OrderNumber),
@@ -366,7 +367,7 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID string, req dto.
 	}
 
 	if order.PaymentInfo != nil {
-		if refundAmount > 0 {
+		if refundAmount.IsPositive() {
 			order.PaymentInfo.Status = shared.PaymentStatusRefunded
 		} else {
 			order.PaymentInfo.Status = shared.PaymentStatusCompleted
@@ -753,12 +754,11 @@ func (s *orderService) GetRevenueReport(ctx context.Context, query dto.Analytics
 		if stats.TotalRevenue > 0 {
 			percentage = cs.Revenue / stats.TotalRevenue * 100
 		}
-		commission := cs.Revenue * shared.PlatformCommissionRate
 		response.ByCategory = append(response.ByCategory, dto.CategoryRevenue{
 			CategorySlug:  cs.CategorySlug,
 			CategoryTitle: dto.GetCategoryTitle(cs.CategorySlug),
 			Revenue:       cs.Revenue,
-			Commission:    commission,
+			Commission:    cs.Commission,
 			OrderCount:    int(cs.OrderCount),
 			Percentage:    percentage,
 		})
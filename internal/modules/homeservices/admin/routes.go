@@ -2,6 +2,9 @@ package admin
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
 // RegisterRoutes registers all admin home services routes
@@ -10,68 +13,73 @@ func RegisterRoutes(
 	handler *Handler,
 	orderHandler *OrderHandler,
 	adminAuthMiddleware gin.HandlerFunc,
+	checker permissions.Checker,
 ) {
-	// All routes require admin authentication
+	can := func(permission models.Permission) gin.HandlerFunc {
+		return middleware.RequirePermission(checker, permission)
+	}
+
+	// All routes require admin authentication and a permission
 	homeservices := router.Group("/homeservices")
 	homeservices.Use(adminAuthMiddleware)
 	{
 		// ==================== Service Management ====================
 		services := homeservices.Group("/services")
 		{
-			services.POST("", handler.CreateService)
+			services.POST("", can(models.PermissionServicesManage), handler.CreateService)
 			// services.POST("", handler.UpdateHomeCleaningService)
-			services.GET("", handler.ListServices)
-			services.GET("/:slug", handler.GetService)
-			services.PUT("/:slug", handler.UpdateService)
-			services.PATCH("/:slug/status", handler.UpdateServiceStatus)
-			services.DELETE("/:slug", handler.DeleteService)
+			services.GET("", can(models.PermissionServicesView), handler.ListServices)
+			services.GET("/:slug", can(models.PermissionServicesView), handler.GetService)
+			services.PUT("/:slug", can(models.PermissionServicesManage), handler.UpdateService)
+			services.PATCH("/:slug/status", can(models.PermissionServicesManage), handler.UpdateServiceStatus)
+			services.DELETE("/:slug", can(models.PermissionServicesManage), handler.DeleteService)
 		}
 
 		// ==================== Addon Management ====================
 		addons := homeservices.Group("/addons")
 		{
-			addons.POST("", handler.CreateAddon)
-			addons.GET("", handler.ListAddons)
-			addons.GET("/:slug", handler.GetAddon)
-			addons.PUT("/:slug", handler.UpdateAddon)
-			addons.PATCH("/:slug/status", handler.UpdateAddonStatus)
-			addons.DELETE("/:slug", handler.DeleteAddon)
+			addons.POST("", can(models.PermissionServicesManage), handler.CreateAddon)
+			addons.GET("", can(models.PermissionServicesView), handler.ListAddons)
+			addons.GET("/:slug", can(models.PermissionServicesView), handler.GetAddon)
+			addons.PUT("/:slug", can(models.PermissionServicesManage), handler.UpdateAddon)
+			addons.PATCH("/:slug/status", can(models.PermissionServicesManage), handler.UpdateAddonStatus)
+			addons.DELETE("/:slug", can(models.PermissionServicesManage), handler.DeleteAddon)
 		}
 
 		// ==================== Category Management ====================
 		categories := homeservices.Group("/categories")
 		{
-			categories.GET("", handler.GetAllCategories)
-			categories.GET("/:categorySlug", handler.GetCategoryDetails)
+			categories.GET("", can(models.PermissionServicesView), handler.GetAllCategories)
+			categories.GET("/:categorySlug", can(models.PermissionServicesView), handler.GetCategoryDetails)
 		}
 
 		// ==================== Order Management ====================
 		orders := homeservices.Group("/orders")
 		{
 			// List and search
-			orders.GET("", orderHandler.GetOrders)
-			orders.GET("/:id", orderHandler.GetOrderByID)
-			orders.GET("/number/:orderNumber", orderHandler.GetOrderByNumber)
-			orders.GET("/:id/history", orderHandler.GetOrderHistory)
+			orders.GET("", can(models.PermissionOrdersView), orderHandler.GetOrders)
+			orders.GET("/:id", can(models.PermissionOrdersView), orderHandler.GetOrderByID)
+			orders.GET("/number/:orderNumber", can(models.PermissionOrdersView), orderHandler.GetOrderByNumber)
+			orders.GET("/:id/history", can(models.PermissionOrdersView), orderHandler.GetOrderHistory)
 
 			// Order actions
-			orders.PATCH("/:id/status", orderHandler.UpdateOrderStatus)
-			orders.POST("/:id/reassign", orderHandler.ReassignOrder)
-			orders.POST("/:id/cancel", orderHandler.CancelOrder)
+			orders.PATCH("/:id/status", can(models.PermissionOrdersUpdate), orderHandler.UpdateOrderStatus)
+			orders.POST("/:id/reassign", can(models.PermissionOrdersReassign), orderHandler.ReassignOrder)
+			orders.POST("/:id/cancel", can(models.PermissionOrdersUpdate), orderHandler.CancelOrder)
 
 			// Bulk operations
-			orders.POST("/bulk/status", orderHandler.BulkUpdateStatus)
+			orders.POST("/bulk/status", can(models.PermissionOrdersBulkUpdate), orderHandler.BulkUpdateStatus)
 		}
 
 		// ==================== Analytics ====================
 		analytics := homeservices.Group("/analytics")
 		{
-			analytics.GET("/overview", orderHandler.GetOverviewAnalytics)
-			analytics.GET("/providers", orderHandler.GetProviderAnalytics)
-			analytics.GET("/revenue", orderHandler.GetRevenueReport)
+			analytics.GET("/overview", can(models.PermissionAnalyticsView), orderHandler.GetOverviewAnalytics)
+			analytics.GET("/providers", can(models.PermissionAnalyticsView), orderHandler.GetProviderAnalytics)
+			analytics.GET("/revenue", can(models.PermissionRevenueView), orderHandler.GetRevenueReport)
 		}
 
 		// ==================== Dashboard ====================
-		homeservices.GET("/dashboard", orderHandler.GetDashboard)
+		homeservices.GET("/dashboard", can(models.PermissionDashboardView), orderHandler.GetDashboard)
 	}
 }
@@ -5,26 +5,27 @@ import (
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // ==================== Order Responses ====================
 
 // OrderServiceItem represents a service item in order response
 type OrderServiceItem struct {
-	ServiceSlug string  `json:"serviceSlug"`
-	Title       string  `json:"title"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	ServiceSlug string       `json:"serviceSlug"`
+	Title       string       `json:"title"`
+	Price       money.Amount `json:"price"`
+	Quantity    int          `json:"quantity"`
+	Subtotal    money.Amount `json:"subtotal"`
 }
 
 // OrderAddonItem represents an addon item in order response
 type OrderAddonItem struct {
-	AddonSlug string  `json:"addonSlug"`
-	Title     string  `json:"title"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Subtotal  float64 `json:"subtotal"`
+	AddonSlug string       `json:"addonSlug"`
+	Title     string       `json:"title"`
+	Price     money.Amount `json:"price"`
+	Quantity  int          `json:"quantity"`
+	Subtotal  money.Amount `json:"subtotal"`
 }
 
 // OrderCustomerInfo represents customer info in order response
@@ -50,20 +51,20 @@ type OrderBookingInfo struct {
 
 // OrderPricing represents pricing breakdown in order response
 type OrderPricing struct {
-	ServicesTotal      float64 `json:"servicesTotal"`
-	AddonsTotal        float64 `json:"addonsTotal"`
-	Subtotal           float64 `json:"subtotal"`
-	PlatformCommission float64 `json:"platformCommission"`
-	TotalPrice         float64 `json:"totalPrice"`
-	FormattedTotal     string  `json:"formattedTotal"`
+	ServicesTotal      money.Amount `json:"servicesTotal"`
+	AddonsTotal        money.Amount `json:"addonsTotal"`
+	Subtotal           money.Amount `json:"subtotal"`
+	PlatformCommission money.Amount `json:"platformCommission"`
+	TotalPrice         money.Amount `json:"totalPrice"`
+	FormattedTotal     string       `json:"formattedTotal"`
 }
 
 // OrderPaymentInfo represents payment info in order response
 type OrderPaymentInfo struct {
-	Method        string  `json:"method"`
-	Status        string  `json:"status"`
-	AmountPaid    float64 `json:"amountPaid"`
-	TransactionID string  `json:"transactionId,omitempty"`
+	Method        string       `json:"method"`
+	Status        string       `json:"status"`
+	AmountPaid    money.Amount `json:"amountPaid"`
+	TransactionID string       `json:"transactionId,omitempty"`
 }
 
 // OrderProviderInfo represents assigned provider info (minimal for customer)
@@ -78,11 +79,11 @@ type OrderProviderInfo struct {
 
 // OrderCancellationInfo represents cancellation details
 type OrderCancellationInfo struct {
-	CancelledBy     string    `json:"cancelledBy"`
-	CancelledAt     time.Time `json:"cancelledAt"`
-	Reason          string    `json:"reason"`
-	CancellationFee float64   `json:"cancellationFee"`
-	RefundAmount    float64   `json:"refundAmount"`
+	CancelledBy     string       `json:"cancelledBy"`
+	CancelledAt     time.Time    `json:"cancelledAt"`
+	Reason          string       `json:"reason"`
+	CancellationFee money.Amount `json:"cancellationFee"`
+	RefundAmount    money.Amount `json:"refundAmount"`
 }
 
 // OrderRatingInfo represents rating information
@@ -134,7 +135,7 @@ type OrderListResponse struct {
 	CategorySlug   string           `json:"categorySlug"`
 	CategoryTitle  string           `json:"categoryTitle"`
 	BookingInfo    OrderBookingInfo `json:"bookingInfo"`
-	TotalPrice     float64          `json:"totalPrice"`
+	TotalPrice     money.Amount     `json:"totalPrice"`
 	FormattedTotal string           `json:"formattedTotal"`
 	Status         string           `json:"status"`
 	DisplayStatus  string           `json:"displayStatus"`
@@ -151,7 +152,7 @@ type OrderCreatedResponse struct {
 	Status                  string           `json:"status"`
 	DisplayStatus           string           `json:"displayStatus"`
 	BookingInfo             OrderBookingInfo `json:"bookingInfo"`
-	TotalPrice              float64          `json:"totalPrice"`
+	TotalPrice              money.Amount     `json:"totalPrice"`
 	FormattedTotal          string           `json:"formattedTotal"`
 	EstimatedAssignmentTime string           `json:"estimatedAssignmentTime"`
 	Message                 string           `json:"message"`
@@ -159,14 +160,14 @@ type OrderCreatedResponse struct {
 
 // CancellationPreviewResponse represents cancellation fee preview
 type CancellationPreviewResponse struct {
-	OrderID         string  `json:"orderId"`
-	OrderNumber     string  `json:"orderNumber"`
-	CurrentStatus   string  `json:"currentStatus"`
-	TotalPrice      float64 `json:"totalPrice"`
-	CancellationFee float64 `json:"cancellationFee"`
-	RefundAmount    float64 `json:"refundAmount"`
-	FeePercentage   float64 `json:"feePercentage"`
-	Message         string  `json:"message"`
+	OrderID         string       `json:"orderId"`
+	OrderNumber     string       `json:"orderNumber"`
+	CurrentStatus   string       `json:"currentStatus"`
+	TotalPrice      money.Amount `json:"totalPrice"`
+	CancellationFee money.Amount `json:"cancellationFee"`
+	RefundAmount    money.Amount `json:"refundAmount"`
+	FeePercentage   float64      `json:"feePercentage"`
+	Message         string       `json:"message"`
 }
 
 // ==================== Conversion Functions ====================
@@ -246,7 +247,7 @@ func ToOrderServiceItems(services models.SelectedServices) []OrderServiceItem {
 			Title:       s.Title,
 			Price:       s.Price,
 			Quantity:    s.Quantity,
-			Subtotal:    s.Price * float64(s.Quantity),
+			Subtotal:    s.Price.MulInt(int64(s.Quantity)),
 		}
 	}
 	return items
@@ -264,7 +265,7 @@ func ToOrderAddonItems(addons models.SelectedAddons) []OrderAddonItem {
 			Title:     a.Title,
 			Price:     a.Price,
 			Quantity:  a.Quantity,
-			Subtotal:  a.Price * float64(a.Quantity),
+			Subtotal:  a.Price.MulInt(int64(a.Quantity)),
 		}
 	}
 	return items
@@ -278,7 +279,7 @@ func ToOrderPricing(order *models.ServiceOrderNew) OrderPricing {
 		Subtotal:           order.Subtotal,
 		PlatformCommission: order.PlatformCommission,
 		TotalPrice:         order.TotalPrice,
-		FormattedTotal:     FormatPriceValue(order.TotalPrice),
+		FormattedTotal:     FormatAmount(order.TotalPrice),
 	}
 }
 
@@ -394,7 +395,7 @@ func ToOrderListResponse(order *models.ServiceOrderNew) OrderListResponse {
 		CategoryTitle:  GetCategoryTitle(order.CategorySlug),
 		BookingInfo:    ToOrderBookingInfo(order.BookingInfo),
 		TotalPrice:     order.TotalPrice,
-		FormattedTotal: FormatPriceValue(order.TotalPrice),
+		FormattedTotal: FormatAmount(order.TotalPrice),
 		Status:         order.Status,
 		DisplayStatus:  GetDisplayStatus(order.Status),
 		ServiceCount:   len(order.SelectedServices),
@@ -425,7 +426,7 @@ func ToOrderCreatedResponse(order *models.ServiceOrderNew) *OrderCreatedResponse
 		DisplayStatus:           GetDisplayStatus(order.Status),
 		BookingInfo:             ToOrderBookingInfo(order.BookingInfo),
 		TotalPrice:              order.TotalPrice,
-		FormattedTotal:          FormatPriceValue(order.TotalPrice),
+		FormattedTotal:          FormatAmount(order.TotalPrice),
 		EstimatedAssignmentTime: "5-15 minutes",
 		Message:                 "Your booking has been created. We're finding the best provider for you.",
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // ==================== Category Responses ====================
@@ -174,6 +175,11 @@ func FormatPriceValue(price float64) string {
 	return fmt.Sprintf("$%.2f", price)
 }
 
+// FormatAmount formats an amount to string
+func FormatAmount(amount money.Amount) string {
+	return "$" + amount.String()
+}
+
 // ToServiceResponse converts model to full service response
 func ToServiceResponse(service *models.ServiceNew) ServiceResponse {
 	// highlights := make([]string, len(service.Highlights))
@@ -27,6 +27,8 @@ type OrderRepository interface {
 
 	// Status operations
 	UpdateStatus(ctx context.Context, orderID, status string) error
+	FindExpiredUnacceptedOrders(ctx context.Context, now time.Time, limit int) ([]*models.ServiceOrderNew, error)
+	ExpireOrder(ctx context.Context, orderID string, info *models.CancellationInfo) (bool, error)
 
 	// Status history
 	CreateStatusHistory(ctx context.Context, history *models.OrderStatusHistory) error
@@ -156,6 +158,35 @@ func (r *orderRepository) UpdateStatus(ctx context.Context, orderID, status stri
 		Updates(updates).Error
 }
 
+// FindExpiredUnacceptedOrders returns orders no provider accepted before
+// their expiry
+func (r *orderRepository) FindExpiredUnacceptedOrders(ctx context.Context, now time.Time, limit int) ([]*models.ServiceOrderNew, error) {
+	var orders []*models.ServiceOrderNew
+	err := r.db.WithContext(ctx).
+		Where("status IN ? AND expires_at < ?",
+			[]string{shared.OrderStatusPending, shared.OrderStatusSearchingProvider}, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// ExpireOrder moves an order to expired only if it is still waiting for a
+// provider, so a provider accepting at the same moment wins. Returns false
+// when the order had already moved on.
+func (r *orderRepository) ExpireOrder(ctx context.Context, orderID string, info *models.CancellationInfo) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ServiceOrderNew{}).
+		Where("id = ? AND status IN ?", orderID,
+			[]string{shared.OrderStatusPending, shared.OrderStatusSearchingProvider}).
+		Updates(map[string]interface{}{
+			"status":            shared.OrderStatusExpired,
+			"cancellation_info": info,
+			"updated_at":        time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *orderRepository) CreateStatusHistory(ctx context.Context, history *models.OrderStatusHistory) error {
 	return r.db.WithContext(ctx).Create(history).Error
 }
@@ -8,9 +8,13 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/commission"
+	commissiondto "github.com/umar5678/go-backend/internal/modules/commission/dto"
+	"github.com/umar5678/go-backend/internal/modules/geofences"
 	"github.com/umar5678/go-backend/internal/modules/homeservices/customer/dto"
 	"github.com/umar5678/go-backend/internal/modules/homeservices/shared"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"github.com/umar5678/go-backend/internal/utils/response"
 )
 
@@ -27,20 +31,27 @@ type OrderService interface {
 
 	// Rating
 	RateOrder(ctx context.Context, customerID, orderID string, req dto.RateOrderRequest) (*dto.OrderResponse, error)
+
+	// Background jobs
+	ExpireUnacceptedOrders(ctx context.Context) (int64, error)
 }
 
 type orderService struct {
-	orderRepo     OrderRepository
-	serviceRepo   Repository // From Module 3 - for validating services/addons
-	walletService WalletService
+	orderRepo         OrderRepository
+	serviceRepo       Repository // From Module 3 - for validating services/addons
+	walletService     WalletService
+	commissionService commission.Service
+	geofenceService   geofences.Service
 }
 
 // NewOrderService creates a new order service instance
-func NewOrderService(orderRepo OrderRepository, serviceRepo Repository, walletService WalletService) OrderService {
+func NewOrderService(orderRepo OrderRepository, serviceRepo Repository, walletService WalletService, commissionService commission.Service, geofenceService geofences.Service) OrderService {
 	return &orderService{
-		orderRepo:     orderRepo,
-		serviceRepo:   serviceRepo,
-		walletService: walletService,
+		orderRepo:         orderRepo,
+		serviceRepo:       serviceRepo,
+		walletService:     walletService,
+		commissionService: commissionService,
+		geofenceService:   geofenceService,
 	}
 }
 
@@ -52,6 +63,11 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req d
 		return nil, response.BadRequest(err.Error())
 	}
 
+	// The service address must be in an area we serve
+	if err := s.geofenceService.CheckLocation(ctx, models.ServiceLineHomeService, req.CustomerInfo.Lat, req.CustomerInfo.Lng); err != nil {
+		return nil, err
+	}
+
 	// Check for too many active orders
 	activeCount, err := s.orderRepo.CountCustomerActiveOrders(ctx, customerID)
 	if err != nil {
@@ -75,8 +91,17 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req d
 	}
 
 	// Calculate pricing
-	subtotal := servicesTotal + addonsTotal
-	platformCommission := shared.CalculatePlatformCommission(subtotal)
+	subtotal := servicesTotal.Add(addonsTotal)
+	quote, err := s.commissionService.Calculate(ctx, commissiondto.CalculateRequest{
+		ServiceLine: models.ServiceLineHomeService,
+		City:        s.geofenceService.ResolveServiceCity(ctx, models.ServiceLineHomeService, req.CustomerInfo.Lat, req.CustomerInfo.Lng),
+		Category:    req.CategorySlug,
+		Amount:      subtotal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	platformCommission := quote.Commission
 	totalPrice := subtotal // Customer pays subtotal, commission is taken from provider payment
 
 	// Validate wallet balance if paying with wallet
@@ -86,8 +111,8 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req d
 			logger.Error("failed to get wallet balance", "error", err, "customerID", customerID)
 			return nil, response.InternalServerError("Failed to check wallet balance", err)
 		}
-		if balance < totalPrice {
-			return nil, response.BadRequest(fmt.Sprintf("Insufficient wallet balance. Required: $%.2f, Available: $%.2f", totalPrice, balance))
+		if money.FromFloat(balance, totalPrice.Currency(), money.HalfUp).LessThan(totalPrice) {
+			return nil, response.BadRequest(fmt.Sprintf("Insufficient wallet balance. Required: $%s, Available: $%.2f", totalPrice, balance))
 		}
 	}
 
@@ -123,15 +148,17 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req d
 			PreferredTime:  preferredTime,
 			QuantityOfPros: req.BookingInfo.QuantityOfPros,
 		},
-		CategorySlug:       req.CategorySlug,
-		SelectedServices:   selectedServices,
-		SelectedAddons:     selectedAddons,
-		SpecialNotes:       req.SpecialNotes,
-		ServicesTotal:      servicesTotal,
-		AddonsTotal:        addonsTotal,
-		Subtotal:           subtotal,
-		PlatformCommission: platformCommission,
-		TotalPrice:         totalPrice,
+		CategorySlug:          req.CategorySlug,
+		SelectedServices:      selectedServices,
+		SelectedAddons:        selectedAddons,
+		SpecialNotes:          req.SpecialNotes,
+		ServicesTotal:         servicesTotal,
+		AddonsTotal:           addonsTotal,
+		Subtotal:              subtotal,
+		PlatformCommission:    platformCommission,
+		CommissionRuleID:      quote.RuleIDPtr(),
+		CommissionRuleVersion: &quote.RuleVersion,
+		TotalPrice:            totalPrice,
 		PaymentInfo: &models.PaymentInfo{
 			Method: req.PaymentMethod,
 			Status: shared.PaymentStatusPending,
@@ -152,7 +179,7 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req d
 		holdID, err := s.walletService.HoldFunds(
 			ctx,
 			customerID,
-			totalPrice,
+			totalPrice.Float64(),
 			"service_order",
 			order.ID,
 			fmt.Sprintf("Hold for order booking"),
@@ -232,8 +259,8 @@ func (s *orderService) CreateOrder(ctx context.Context, customerID string, req d
 	return dto.ToOrderCreatedResponse(order), nil
 }
 
-func (s *orderService) validateAndCalculateServices(ctx context.Context, categorySlug string, services []dto.SelectedServiceRequest) (float64, models.SelectedServices, error) {
-	var total float64
+func (s *orderService) validateAndCalculateServices(ctx context.Context, categorySlug string, services []dto.SelectedServiceRequest) (money.Amount, models.SelectedServices, error) {
+	var items []shared.ServiceItem
 	var selectedServices models.SelectedServices
 
 	for _, svc := range services {
@@ -241,43 +268,43 @@ func (s *orderService) validateAndCalculateServices(ctx context.Context, categor
 		service, err := s.serviceRepo.GetActiveServiceBySlug(ctx, svc.ServiceSlug)
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
-				return 0, nil, response.BadRequest(fmt.Sprintf("Service '%s' not found or unavailable", svc.ServiceSlug))
+				return money.Amount{}, nil, response.BadRequest(fmt.Sprintf("Service '%s' not found or unavailable", svc.ServiceSlug))
 			}
-			return 0, nil, response.InternalServerError("Failed to validate services", err)
+			return money.Amount{}, nil, response.InternalServerError("Failed to validate services", err)
 		}
 
 		// Validate category matches
 		if service.CategorySlug != categorySlug {
-			return 0, nil, response.BadRequest(fmt.Sprintf("Service '%s' does not belong to category '%s'", svc.ServiceSlug, categorySlug))
+			return money.Amount{}, nil, response.BadRequest(fmt.Sprintf("Service '%s' does not belong to category '%s'", svc.ServiceSlug, categorySlug))
 		}
 
 		// Check if service has a price
 		if service.BasePrice == nil {
-			return 0, nil, response.BadRequest(fmt.Sprintf("Service '%s' does not have a price set", svc.ServiceSlug))
+			return money.Amount{}, nil, response.BadRequest(fmt.Sprintf("Service '%s' does not have a price set", svc.ServiceSlug))
 		}
 
-		// Calculate subtotal
-		subtotal := *service.BasePrice * float64(svc.Quantity)
-		total += subtotal
+		// Catalog prices are stored to the cent
+		price := money.FromFloat(*service.BasePrice, money.DefaultCurrency, money.HalfUp)
+		items = append(items, shared.ServiceItem{Price: price, Quantity: svc.Quantity})
 
 		// Add to selected services
 		selectedServices = append(selectedServices, models.SelectedServiceItem{
 			ServiceSlug: service.ServiceSlug,
 			Title:       service.Title,
-			Price:       *service.BasePrice,
+			Price:       price,
 			Quantity:    svc.Quantity,
 		})
 	}
 
-	return shared.RoundToTwoDecimals(total), selectedServices, nil
+	return shared.CalculateServicesTotal(items), selectedServices, nil
 }
 
-func (s *orderService) validateAndCalculateAddons(ctx context.Context, categorySlug string, addons []dto.SelectedAddonRequest) (float64, models.SelectedAddons, error) {
+func (s *orderService) validateAndCalculateAddons(ctx context.Context, categorySlug string, addons []dto.SelectedAddonRequest) (money.Amount, models.SelectedAddons, error) {
 	if len(addons) == 0 {
-		return 0, nil, nil
+		return money.Zero(money.DefaultCurrency), nil, nil
 	}
 
-	var total float64
+	var items []shared.AddonItem
 	var selectedAddons models.SelectedAddons
 
 	for _, add := range addons {
@@ -285,30 +312,29 @@ func (s *orderService) validateAndCalculateAddons(ctx context.Context, categoryS
 		addon, err := s.serviceRepo.GetActiveAddonBySlug(ctx, add.AddonSlug)
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
-				return 0, nil, response.BadRequest(fmt.Sprintf("Addon '%s' not found or unavailable", add.AddonSlug))
+				return money.Amount{}, nil, response.BadRequest(fmt.Sprintf("Addon '%s' not found or unavailable", add.AddonSlug))
 			}
-			return 0, nil, response.InternalServerError("Failed to validate addons", err)
+			return money.Amount{}, nil, response.InternalServerError("Failed to validate addons", err)
 		}
 
 		// Validate category matches
 		if addon.CategorySlug != categorySlug {
-			return 0, nil, response.BadRequest(fmt.Sprintf("Addon '%s' does not belong to category '%s'", add.AddonSlug, categorySlug))
+			return money.Amount{}, nil, response.BadRequest(fmt.Sprintf("Addon '%s' does not belong to category '%s'", add.AddonSlug, categorySlug))
 		}
 
-		// Calculate subtotal
-		subtotal := addon.Price * float64(add.Quantity)
-		total += subtotal
+		price := money.FromFloat(addon.Price, money.DefaultCurrency, money.HalfUp)
+		items = append(items, shared.AddonItem{Price: price, Quantity: add.Quantity})
 
 		// Add to selected addons
 		selectedAddons = append(selectedAddons, models.SelectedAddonItem{
 			AddonSlug: addon.AddonSlug,
 			Title:     addon.Title,
-			Price:     addon.Price,
+			Price:     price,
 			Quantity:  add.Quantity,
 		})
 	}
 
-	return shared.RoundToTwoDecimals(total), selectedAddons, nil
+	return shared.CalculateAddonsTotal(items), selectedAddons, nil
 }
 
 // ==================== Get Order ====================
@@ -382,8 +408,8 @@ func (s *orderService) GetCancellationPreview(ctx context.Context, customerID, o
 	}
 
 	message := fmt.Sprintf("Cancellation fee of %.0f%% will be applied.", feePercentage)
-	if refundAmount > 0 {
-		message += fmt.Sprintf(" You will receive a refund of $%.2f.", refundAmount)
+	if refundAmount.IsPositive() {
+		message += fmt.Sprintf(" You will receive a refund of $%s.", refundAmount)
 	}
 
 	return &dto.CancellationPreviewResponse{
@@ -423,7 +449,7 @@ func (s *orderService) CancelOrder(ctx context.Context, customerID, orderID stri
 
 	// Process refund/release hold
 	if order.WalletHoldID != nil {
-		if refundAmount > 0 {
+		if refundAmount.IsPositive() {
 			// Release the hold
 			if err := s.walletService.ReleaseHold(ctx, *order.WalletHoldID); err != nil {
 				logger.Error("failed to release wallet hold", "error", err, "holdID", *order.WalletHoldID)
@@ -431,11 +457,11 @@ func (s *orderService) CancelOrder(ctx context.Context, customerID, orderID stri
 			}
 
 			// If there's a cancellation fee, debit it
-			if cancellationFee > 0 {
+			if cancellationFee.IsPositive() {
 				if err := s.walletService.Debit(
 					ctx,
 					customerID,
-					cancellationFee,
+					cancellationFee.Float64(),
 					"cancellation_fee",
 					order.ID,
 					fmt.Sprintf("Cancellation fee for order %s", order.OrderNumber),
@@ -488,6 +514,61 @@ func (s *orderService) CancelOrder(ctx context.Context, customerID, orderID stri
 	return dto.ToOrderResponse(order), nil
 }
 
+// expiredOrderBatch caps how many orders one sweep expires
+const expiredOrderBatch = 100
+
+// ExpireUnacceptedOrders expires orders no provider accepted before their
+// expiry and releases their wallet holds. Run periodically by the job
+// scheduler; returns how many orders were expired.
+func (s *orderService) ExpireUnacceptedOrders(ctx context.Context) (int64, error) {
+	orders, err := s.orderRepo.FindExpiredUnacceptedOrders(ctx, time.Now(), expiredOrderBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for _, order := range orders {
+		info := &models.CancellationInfo{
+			CancelledBy:  shared.CancelledBySystem,
+			CancelledAt:  time.Now(),
+			Reason:       "No provider accepted the order in time",
+			RefundAmount: order.TotalPrice,
+		}
+
+		ok, err := s.orderRepo.ExpireOrder(ctx, order.ID, info)
+		if err != nil {
+			logger.Error("failed to expire order", "error", err, "orderID", order.ID)
+			continue
+		}
+		if !ok {
+			// Accepted or cancelled since the scan
+			continue
+		}
+
+		if order.WalletHoldID != nil {
+			if err := s.walletService.ReleaseHold(ctx, *order.WalletHoldID); err != nil {
+				logger.Error("failed to release wallet hold", "error", err, "holdID", *order.WalletHoldID)
+			}
+		}
+
+		history := models.NewOrderStatusHistory(
+			order.ID,
+			order.Status,
+			shared.OrderStatusExpired,
+			nil,
+			shared.RoleSystem,
+			info.Reason,
+			nil,
+		)
+		s.orderRepo.CreateStatusHistory(ctx, history)
+
+		logger.Info("order expired", "orderID", order.ID, "customerID", order.CustomerID)
+		expired++
+	}
+
+	return expired, nil
+}
+
 // ==================== Rating ====================
 
 func (s *orderService) RateOrder(ctx context.Context, customerID, orderID string, req dto.RateOrderRequest) (*dto.OrderResponse, error) {
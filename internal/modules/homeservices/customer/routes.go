@@ -2,6 +2,7 @@ package customer
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
 )
 
 // RegisterRoutes registers all customer home services routes
@@ -48,7 +49,7 @@ func RegisterRoutes(
 		orders := homeservices.Group("/orders")
 		orders.Use(authMiddleware)
 		{
-			orders.POST("", orderHandler.CreateOrder)
+			orders.POST("", middleware.Idempotency(), orderHandler.CreateOrder)
 			orders.GET("", orderHandler.ListOrders)
 			orders.GET("/:id", orderHandler.GetOrder)
 			orders.GET("/:id/cancel/preview", orderHandler.GetCancellationPreview)
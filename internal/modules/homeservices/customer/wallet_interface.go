@@ -23,8 +23,9 @@ type WalletService interface {
 	// Debit directly debits amount from wallet (for cancellation fees, etc.)
 	Debit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string) error
 
-	// Credit credits amount to wallet (for refunds)
-	Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string) error
+	// Credit credits amount to wallet (for refunds and payouts); metadata is
+	// stored on the wallet transaction
+	Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string, metadata map[string]interface{}) error
 }
 
 // MockWalletService is a placeholder implementation for development
@@ -56,7 +57,7 @@ func (m *MockWalletService) Debit(ctx context.Context, userID string, amount flo
 	return nil
 }
 
-func (m *MockWalletService) Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string) error {
+func (m *MockWalletService) Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string, metadata map[string]interface{}) error {
 	return nil
 }
  
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 
 	"github.com/umar5678/go-backend/internal/models"
 	authdto "github.com/umar5678/go-backend/internal/modules/auth/dto"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 type ProviderProfileResponse struct {
@@ -291,11 +292,11 @@ type OrderResponse struct {
 	Frequency      string               `json:"frequency"`
 	QuantityOfPros int                  `json:"quantityOfPros"`
 	HoursOfService float64              `json:"hoursOfService"`
-	Subtotal       float64              `json:"subtotal"`
-	Discount       float64              `json:"discount"`
-	SurgeFee       float64              `json:"surgeFee"`
-	PlatformFee    float64              `json:"platformFee"`
-	Total          float64              `json:"total"`
+	Subtotal       money.Amount         `json:"subtotal"`
+	Discount       money.Amount         `json:"discount"`
+	SurgeFee       money.Amount         `json:"surgeFee"`
+	PlatformFee    money.Amount         `json:"platformFee"`
+	Total          money.Amount         `json:"total"`
 	CouponCode     *string              `json:"couponCode,omitempty"`
 	CreatedAt      time.Time            `json:"createdAt"`
 	AcceptedAt     *time.Time           `json:"acceptedAt,omitempty"`
@@ -306,15 +307,15 @@ type OrderResponse struct {
 }
 
 type OrderListResponse struct {
-	ID             string    `json:"id"`
-	Code           string    `json:"code"`
-	Status         string    `json:"status"`
-	Address        string    `json:"address"`
-	ServiceDate    time.Time `json:"serviceDate"`
-	QuantityOfPros int       `json:"quantityOfPros"` //  NEW
-	HoursOfService float64   `json:"hoursOfService"` //  NEW
-	Total          float64   `json:"total"`
-	CreatedAt      time.Time `json:"createdAt"`
+	ID             string       `json:"id"`
+	Code           string       `json:"code"`
+	Status         string       `json:"status"`
+	Address        string       `json:"address"`
+	ServiceDate    time.Time    `json:"serviceDate"`
+	QuantityOfPros int          `json:"quantityOfPros"` //  NEW
+	HoursOfService float64      `json:"hoursOfService"` //  NEW
+	Total          money.Amount `json:"total"`
+	CreatedAt      time.Time    `json:"createdAt"`
 }
 
 type OrderItemResponse struct {
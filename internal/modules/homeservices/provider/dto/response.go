@@ -6,6 +6,7 @@ import (
 
 	"github.com/umar5678/go-backend/internal/models"
 	"github.com/umar5678/go-backend/internal/modules/homeservices/shared"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // ==================== Profile Responses ====================
@@ -69,8 +70,8 @@ type AvailableOrderResponse struct {
 	Services        []OrderServiceItem `json:"services"`
 	Addons          []OrderAddonItem   `json:"addons,omitempty"`
 	SpecialNotes    string             `json:"specialNotes,omitempty"`
-	TotalPrice      float64            `json:"totalPrice"`
-	ProviderPayout  float64            `json:"providerPayout"` // 90% of total
+	TotalPrice      money.Amount       `json:"totalPrice"`
+	ProviderPayout  money.Amount       `json:"providerPayout"` // 90% of total
 	FormattedPayout string             `json:"formattedPayout"`
 	Distance        *float64           `json:"distance,omitempty"` // km from provider
 	CreatedAt       time.Time          `json:"createdAt"`
@@ -98,20 +99,20 @@ type OrderBookingInfo struct {
 
 // OrderServiceItem represents a service in the order
 type OrderServiceItem struct {
-	ServiceSlug string  `json:"serviceSlug"`
-	Title       string  `json:"title"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	ServiceSlug string       `json:"serviceSlug"`
+	Title       string       `json:"title"`
+	Price       money.Amount `json:"price"`
+	Quantity    int          `json:"quantity"`
+	Subtotal    money.Amount `json:"subtotal"`
 }
 
 // OrderAddonItem represents an addon in the order
 type OrderAddonItem struct {
-	AddonSlug string  `json:"addonSlug"`
-	Title     string  `json:"title"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Subtotal  float64 `json:"subtotal"`
+	AddonSlug string       `json:"addonSlug"`
+	Title     string       `json:"title"`
+	Price     money.Amount `json:"price"`
+	Quantity  int          `json:"quantity"`
+	Subtotal  money.Amount `json:"subtotal"`
 }
 
 // ProviderOrderResponse represents a provider's order (assigned/completed)
@@ -125,8 +126,8 @@ type ProviderOrderResponse struct {
 	Services        []OrderServiceItem `json:"services"`
 	Addons          []OrderAddonItem   `json:"addons,omitempty"`
 	SpecialNotes    string             `json:"specialNotes,omitempty"`
-	TotalPrice      float64            `json:"totalPrice"`
-	ProviderPayout  float64            `json:"providerPayout"`
+	TotalPrice      money.Amount       `json:"totalPrice"`
+	ProviderPayout  money.Amount       `json:"providerPayout"`
 	FormattedPayout string             `json:"formattedPayout"`
 	Status          OrderStatusInfo    `json:"status"`
 	Rating          *OrderRatingInfo   `json:"rating,omitempty"`
@@ -164,7 +165,7 @@ type ProviderOrderListResponse struct {
 	CategoryTitle   string           `json:"categoryTitle"`
 	CustomerName    string           `json:"customerName"`
 	BookingInfo     OrderBookingInfo `json:"bookingInfo"`
-	ProviderPayout  float64          `json:"providerPayout"`
+	ProviderPayout  money.Amount     `json:"providerPayout"`
 	FormattedPayout string           `json:"formattedPayout"`
 	Status          string           `json:"status"`
 	DisplayStatus   string           `json:"displayStatus"`
@@ -265,9 +266,15 @@ func FormatPrice(price float64) string {
 	return fmt.Sprintf("$%.2f", price)
 }
 
-// CalculateProviderPayout calculates provider's payout (90%)
-func CalculateProviderPayout(totalPrice float64) float64 {
-	return shared.RoundToTwoDecimals(totalPrice * (1 - shared.PlatformCommissionRate))
+// FormatAmount formats an order amount
+func FormatAmount(amount money.Amount) string {
+	return "$" + amount.String()
+}
+
+// CalculateProviderPayout calculates provider's payout after the commission
+// that was fixed when the order was priced
+func CalculateProviderPayout(totalPrice, platformCommission money.Amount) money.Amount {
+	return totalPrice.Sub(platformCommission)
 }
 
 // ToOrderBookingInfo converts model to response
@@ -295,7 +302,7 @@ func ToOrderServiceItems(services models.SelectedServices) []OrderServiceItem {
 			Title:       s.Title,
 			Price:       s.Price,
 			Quantity:    s.Quantity,
-			Subtotal:    s.Price * float64(s.Quantity),
+			Subtotal:    s.Price.MulInt(int64(s.Quantity)),
 		}
 	}
 	return items
@@ -313,7 +320,7 @@ func ToOrderAddonItems(addons models.SelectedAddons) []OrderAddonItem {
 			Title:     a.Title,
 			Price:     a.Price,
 			Quantity:  a.Quantity,
-			Subtotal:  a.Price * float64(a.Quantity),
+			Subtotal:  a.Price.MulInt(int64(a.Quantity)),
 		}
 	}
 	return items
@@ -347,7 +354,7 @@ func ToServiceCategoryResponses(categories []*models.ProviderServiceCategory) []
 
 // ToAvailableOrderResponse converts order model to available order response
 func ToAvailableOrderResponse(order *models.ServiceOrderNew, distance *float64) AvailableOrderResponse {
-	providerPayout := CalculateProviderPayout(order.TotalPrice)
+	providerPayout := CalculateProviderPayout(order.TotalPrice, order.PlatformCommission)
 
 	return AvailableOrderResponse{
 		ID:            order.ID,
@@ -367,7 +374,7 @@ func ToAvailableOrderResponse(order *models.ServiceOrderNew, distance *float64)
 		SpecialNotes:    order.SpecialNotes,
 		TotalPrice:      order.TotalPrice,
 		ProviderPayout:  providerPayout,
-		FormattedPayout: FormatPrice(providerPayout),
+		FormattedPayout: FormatAmount(providerPayout),
 		Distance:        distance,
 		CreatedAt:       order.CreatedAt,
 		ExpiresAt:       order.ExpiresAt,
@@ -376,7 +383,7 @@ func ToAvailableOrderResponse(order *models.ServiceOrderNew, distance *float64)
 
 // ToProviderOrderResponse converts order model to provider order response
 func ToProviderOrderResponse(order *models.ServiceOrderNew) *ProviderOrderResponse {
-	providerPayout := CalculateProviderPayout(order.TotalPrice)
+	providerPayout := CalculateProviderPayout(order.TotalPrice, order.PlatformCommission)
 
 	response := &ProviderOrderResponse{
 		ID:            order.ID,
@@ -396,7 +403,7 @@ func ToProviderOrderResponse(order *models.ServiceOrderNew) *ProviderOrderRespon
 		SpecialNotes:    order.SpecialNotes,
 		TotalPrice:      order.TotalPrice,
 		ProviderPayout:  providerPayout,
-		FormattedPayout: FormatPrice(providerPayout),
+		FormattedPayout: FormatAmount(providerPayout),
 		Status: OrderStatusInfo{
 			Current:       order.Status,
 			DisplayStatus: GetDisplayStatus(order.Status),
@@ -428,7 +435,7 @@ func ToProviderOrderResponse(order *models.ServiceOrderNew) *ProviderOrderRespon
 
 // ToProviderOrderListResponse converts order model to list response
 func ToProviderOrderListResponse(order *models.ServiceOrderNew) ProviderOrderListResponse {
-	providerPayout := CalculateProviderPayout(order.TotalPrice)
+	providerPayout := CalculateProviderPayout(order.TotalPrice, order.PlatformCommission)
 
 	return ProviderOrderListResponse{
 		ID:              order.ID,
@@ -438,7 +445,7 @@ func ToProviderOrderListResponse(order *models.ServiceOrderNew) ProviderOrderLis
 		CustomerName:    order.CustomerInfo.Name,
 		BookingInfo:     ToOrderBookingInfo(order.BookingInfo),
 		ProviderPayout:  providerPayout,
-		FormattedPayout: FormatPrice(providerPayout),
+		FormattedPayout: FormatAmount(providerPayout),
 		Status:          order.Status,
 		DisplayStatus:   GetDisplayStatus(order.Status),
 		CreatedAt:       order.CreatedAt,
@@ -11,6 +11,7 @@ import (
 	"github.com/umar5678/go-backend/internal/modules/homeservices/provider/dto"
 	"github.com/umar5678/go-backend/internal/modules/homeservices/shared"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // Repository defines the interface for provider data access
@@ -293,8 +294,8 @@ func (r *repository) convertLaundryOrderToServiceOrder(ctx context.Context, orde
 	// Calculate pricing fields (for laundry, total is already calculated as total price)
 	// ServicesTotal is the base total, Subtotal = ServicesTotal, TotalPrice includes tip
 	totalPrice := laundryOrder.Total
-	if laundryOrder.Tip != nil && *laundryOrder.Tip > 0 {
-		totalPrice = laundryOrder.Total + *laundryOrder.Tip
+	if laundryOrder.Tip != nil && laundryOrder.Tip.IsPositive() {
+		totalPrice = laundryOrder.Total.Add(*laundryOrder.Tip)
 	}
 
 	order := &models.ServiceOrderNew{
@@ -305,8 +306,8 @@ func (r *repository) convertLaundryOrderToServiceOrder(ctx context.Context, orde
 		ServicesTotal:      laundryOrder.Total, // Base price (without tip)
 		Subtotal:           laundryOrder.Total, // No separate subtotal for laundry
 		TotalPrice:         totalPrice,         // Total including tip
-		PlatformCommission: 0,                  // Laundry doesn't track separately
-		AddonsTotal:        0,                  // No addons for laundry
+		PlatformCommission: money.Amount{},     // Laundry doesn't track separately
+		AddonsTotal:        money.Amount{},     // No addons for laundry
 		Status:             laundryOrder.Status,
 		AssignedProviderID: laundryOrder.ProviderID,
 		CreatedAt:          laundryOrder.CreatedAt,
@@ -419,8 +420,8 @@ func (r *repository) GetAvailableOrders(ctx context.Context, categorySlugs []str
 		// Calculate pricing fields (for laundry, total is already calculated as total price)
 		// ServicesTotal is the base total, Subtotal = ServicesTotal, TotalPrice includes tip
 		totalPrice := laundryOrder.Total
-		if laundryOrder.Tip != nil && *laundryOrder.Tip > 0 {
-			totalPrice = laundryOrder.Total + *laundryOrder.Tip
+		if laundryOrder.Tip != nil && laundryOrder.Tip.IsPositive() {
+			totalPrice = laundryOrder.Total.Add(*laundryOrder.Tip)
 		}
 
 		serviceOrder := &models.ServiceOrderNew{
@@ -431,8 +432,8 @@ func (r *repository) GetAvailableOrders(ctx context.Context, categorySlugs []str
 			ServicesTotal:      laundryOrder.Total, // Base price (without tip)
 			Subtotal:           laundryOrder.Total, // No separate subtotal for laundry
 			TotalPrice:         totalPrice,         // Total including tip
-			PlatformCommission: 0,                  // Laundry doesn't track separately
-			AddonsTotal:        0,                  // No addons for laundry
+			PlatformCommission: money.Amount{},     // Laundry doesn't track separately
+			AddonsTotal:        money.Amount{},     // No addons for laundry
 			Status:             laundryOrder.Status,
 			AssignedProviderID: laundryOrder.ProviderID,
 			CreatedAt:          laundryOrder.CreatedAt,
@@ -726,8 +727,8 @@ func (r *repository) GetProviderOrders(ctx context.Context, providerID string, q
 		// Calculate pricing fields (for laundry, total is already calculated as total price)
 		// ServicesTotal is the base total, Subtotal = ServicesTotal, TotalPrice includes tip
 		totalPrice := laundryOrder.Total
-		if laundryOrder.Tip != nil && *laundryOrder.Tip > 0 {
-			totalPrice = laundryOrder.Total + *laundryOrder.Tip
+		if laundryOrder.Tip != nil && laundryOrder.Tip.IsPositive() {
+			totalPrice = laundryOrder.Total.Add(*laundryOrder.Tip)
 		}
 
 		serviceOrder := &models.ServiceOrderNew{
@@ -738,8 +739,8 @@ func (r *repository) GetProviderOrders(ctx context.Context, providerID string, q
 			ServicesTotal:      laundryOrder.Total, // Base price (without tip)
 			Subtotal:           laundryOrder.Total, // No separate subtotal for laundry
 			TotalPrice:         totalPrice,         // Total including tip
-			PlatformCommission: 0,                  // Laundry doesn't track separately
-			AddonsTotal:        0,                  // No addons for laundry
+			PlatformCommission: money.Amount{},     // Laundry doesn't track separately
+			AddonsTotal:        money.Amount{},     // No addons for laundry
 			Status:             laundryOrder.Status,
 			AssignedProviderID: laundryOrder.ProviderID,
 			CreatedAt:          laundryOrder.CreatedAt,
@@ -17,7 +17,7 @@ import (
 
 // WalletService interface for wallet operations
 type WalletService interface {
-	Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string) error
+	Credit(ctx context.Context, userID string, amount float64, transactionType, referenceID, description string, metadata map[string]interface{}) error
 	CaptureHold(ctx context.Context, holdID string, amount float64, description string) error
 }
 
@@ -455,7 +455,7 @@ func (s *service) AcceptOrder(ctx context.Context, providerID, orderID string) (
 		if err := s.walletService.CaptureHold(
 			ctx,
 			*order.WalletHoldID,
-			order.TotalPrice,
+			order.TotalPrice.Float64(),
 			fmt.Sprintf("Payment for order %s", order.OrderNumber),
 		); err != nil {
 			logger.Error("failed to capture wallet hold", "error", err, "orderID", orderID)
@@ -582,17 +582,24 @@ func (s *service) CompleteOrder(ctx context.Context, providerID, orderID string,
 		return nil, response.BadRequest(fmt.Sprintf("Cannot complete order in '%s' status", order.Status))
 	}
 
-	// Calculate provider payout
-	providerPayout := dto.CalculateProviderPayout(order.TotalPrice)
+	// Provider payout uses the commission fixed when the order was priced
+	providerPayout := dto.CalculateProviderPayout(order.TotalPrice, order.PlatformCommission)
+	commissionMetadata := map[string]interface{}{
+		"commissionRuleId":      order.CommissionRuleID,
+		"commissionRuleVersion": order.CommissionRuleVersion,
+		"commission":            order.PlatformCommission.Float64(),
+		"grossAmount":           order.TotalPrice.Float64(),
+	}
 
 	// Credit provider wallet
 	if err := s.walletService.Credit(
 		ctx,
 		providerID,
-		providerPayout,
+		providerPayout.Float64(),
 		"service_payment",
 		order.ID,
 		fmt.Sprintf("Payment for order %s", order.OrderNumber),
+		commissionMetadata,
 	); err != nil {
 		logger.Error("failed to credit provider wallet", "error", err, "orderID", orderID)
 		return nil, response.InternalServerError("Failed to process payment", err)
@@ -617,13 +624,15 @@ func (s *service) CompleteOrder(ctx context.Context, providerID, orderID string,
 	// Update provider category statistics
 	category, err := s.repo.GetProviderCategory(ctx, providerID, order.CategorySlug)
 	if err == nil && category != nil {
-		category.IncrementCompletedJobs(providerPayout)
+		category.IncrementCompletedJobs(providerPayout.Float64())
 		s.repo.UpdateProviderCategory(ctx, category)
 	}
 
 	// Create status history
 	metadata := models.StatusHistoryMetadata{
-		"providerPayout": providerPayout,
+		"providerPayout":        providerPayout,
+		"commissionRuleId":      order.CommissionRuleID,
+		"commissionRuleVersion": order.CommissionRuleVersion,
 	}
 	if req.Notes != "" {
 		metadata["completionNotes"] = req.Notes
@@ -3,9 +3,11 @@ package homeservices
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
 	services := router.Group("/services")
 	{
 		// --- Public Routes ---
@@ -42,8 +44,8 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 
 		// --- Admin Protected Routes ---
 		admin := services.Group("/admin")
-		// admin.Use(authMiddleware)
-		// admin.Use(middleware.RequireRole("admin"))
+		admin.Use(authMiddleware)
+		admin.Use(middleware.RequirePermission(checker, models.PermissionServicesManage))
 		{
 			admin.POST("/categories", handler.CreateCategory)
 			admin.POST("/tabs", handler.CreateTab)
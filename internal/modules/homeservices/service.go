@@ -12,11 +12,15 @@ import (
 
 	"github.com/umar5678/go-backend/internal/config"
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/commission"
+	commissiondto "github.com/umar5678/go-backend/internal/modules/commission/dto"
+	"github.com/umar5678/go-backend/internal/modules/geofences"
 	homeservicedto "github.com/umar5678/go-backend/internal/modules/homeservices/dto"
 	"github.com/umar5678/go-backend/internal/modules/wallet"
 	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
 	"github.com/umar5678/go-backend/internal/services/cache"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"github.com/umar5678/go-backend/internal/utils/response"
 )
 
@@ -61,16 +65,20 @@ type Service interface {
 }
 
 type service struct {
-	repo          Repository
-	walletService wallet.Service
-	cfg           *config.Config
+	repo              Repository
+	walletService     wallet.Service
+	commissionService commission.Service
+	geofenceService   geofences.Service
+	cfg               *config.Config
 }
 
-func NewService(repo Repository, walletService wallet.Service, cfg *config.Config) Service {
+func NewService(repo Repository, walletService wallet.Service, commissionService commission.Service, geofenceService geofences.Service, cfg *config.Config) Service {
 	return &service{
-		repo:          repo,
-		walletService: walletService,
-		cfg:           cfg,
+		repo:              repo,
+		walletService:     walletService,
+		commissionService: commissionService,
+		geofenceService:   geofenceService,
+		cfg:               cfg,
 	}
 }
 
@@ -265,11 +273,15 @@ func (s *service) CreateOrder(ctx context.Context, userID string, req homeservic
 		return nil, response.BadRequest("Service date must be in the future")
 	}
 
+	if err := s.geofenceService.CheckLocation(ctx, models.ServiceLineHomeService, req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+
 	// 3. Calculate pricing for each item
 	var items []models.OrderItem
 	var addOns []models.OrderAddOn
 	var categorySlug string // Track category from first service
-	var subtotal float64
+	subtotal := money.Zero(money.DefaultCurrency)
 	var totalDuration int
 
 	// Process main service items
@@ -311,7 +323,7 @@ func (s *service) CreateOrder(ctx context.Context, userID string, req homeservic
 			SelectedOptions: nil,
 		})
 
-		subtotal += price
+		subtotal = subtotal.Add(money.FromFloat(price, money.DefaultCurrency, money.HalfUp))
 		totalDuration += duration
 	}
 
@@ -333,19 +345,28 @@ func (s *service) CreateOrder(ctx context.Context, userID string, req homeservic
 				Price:   addon.Price,
 			})
 
-			subtotal += addon.Price
+			subtotal = subtotal.Add(money.FromFloat(addon.Price, money.DefaultCurrency, money.HalfUp))
 			totalDuration += addon.DurationMinutes
 		}
 	}
 
 	// 4. Calculate fees
-	surgeFee := s.calculateSurgeFee(req.Latitude, req.Longitude, serviceDate)
-	platformFee := subtotal * 0.10 // 10% platform fee
-	discount := 0.0
+	surgeFee := money.FromFloat(s.calculateSurgeFee(req.Latitude, req.Longitude, serviceDate), money.DefaultCurrency, money.HalfUp)
+	quote, err := s.commissionService.Calculate(ctx, commissiondto.CalculateRequest{
+		ServiceLine: models.ServiceLineHomeService,
+		City:        s.geofenceService.ResolveServiceCity(ctx, models.ServiceLineHomeService, req.Latitude, req.Longitude),
+		Category:    categorySlug,
+		Amount:      subtotal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	platformFee := quote.Commission
+	discount := money.Zero(money.DefaultCurrency)
 
 	// TODO: Apply coupon if provided
 
-	total := subtotal + surgeFee + platformFee - discount
+	total := subtotal.Add(surgeFee).Add(platformFee).Sub(discount)
 
 	// 5. Create wallet hold using existing wallet service
 	orderCode := s.generateOrderCode()
@@ -365,25 +386,27 @@ func (s *service) CreateOrder(ctx context.Context, userID string, req homeservic
 
 	// 6. Create order
 	order := &models.ServiceOrder{
-		ID:             uuid.New().String(),
-		Code:           orderCode,
-		UserID:         userID,
-		Status:         "searching_provider",
-		Address:        req.Address,
-		Latitude:       req.Latitude,
-		Longitude:      req.Longitude,
-		ServiceDate:    serviceDate,
-		Frequency:      req.Frequency,
-		QuantityOfPros: req.QuantityOfPros, // ✅ NEW
-		HoursOfService: req.HoursOfService, // ✅ NEW
-		CategorySlug:   categorySlug,       // ✅ Set category slug from first service
-		Notes:          req.Notes,
-		Subtotal:       subtotal,
-		Discount:       discount,
-		SurgeFee:       surgeFee,
-		PlatformFee:    platformFee,
-		Total:          total,
-		CouponCode:     req.CouponCode,
+		ID:                    uuid.New().String(),
+		Code:                  orderCode,
+		UserID:                userID,
+		Status:                "searching_provider",
+		Address:               req.Address,
+		Latitude:              req.Latitude,
+		Longitude:             req.Longitude,
+		ServiceDate:           serviceDate,
+		Frequency:             req.Frequency,
+		QuantityOfPros:        req.QuantityOfPros, // ✅ NEW
+		HoursOfService:        req.HoursOfService, // ✅ NEW
+		CategorySlug:          categorySlug,       // ✅ Set category slug from first service
+		Notes:                 req.Notes,
+		Subtotal:              subtotal,
+		Discount:              discount,
+		SurgeFee:              surgeFee,
+		PlatformFee:           platformFee,
+		CommissionRuleID:      quote.RuleIDPtr(),
+		CommissionRuleVersion: &quote.RuleVersion,
+		Total:                 total,
+		CouponCode:            req.CouponCode,
 		// Note: Using WalletHold field as temporary storage
 		WalletHold: total,
 		Items:      items,
@@ -957,17 +980,28 @@ func (s *service) CompleteOrder(ctx context.Context, providerID, orderID string)
 		}
 	}
 
-	// 2. Transfer funds to provider using existing wallet service
+	// 2. Credit the provider. The customer was already charged by the capture
+	// above, so this is a credit rather than a transfer from their wallet.
 	provider, err := s.repo.GetProviderByID(ctx, providerID)
 	if err == nil && provider != nil {
-		providerAmount := order.Total - order.PlatformFee
-		transferReq := walletdto.TransferFundsRequest{
-			RecipientID: provider.UserID, // Use provider's UserID for wallet transfer
-			Amount:      providerAmount,
-			Description: fmt.Sprintf("Earnings from order %s", order.Code),
+		providerAmount := order.Total.Sub(order.PlatformFee)
+		metadata := map[string]interface{}{
+			"commissionRuleId":      order.CommissionRuleID,
+			"commissionRuleVersion": order.CommissionRuleVersion,
+			"commission":            order.PlatformFee.Float64(),
+			"grossAmount":           order.Total.Float64(),
 		}
-		if _, err := s.walletService.TransferFunds(ctx, order.UserID, transferReq); err != nil {
-			logger.Error("failed to transfer to provider", "error", err, "providerID", providerID)
+		if _, err := s.walletService.CreditWallet(
+			ctx,
+			provider.UserID, // Use provider's UserID for wallet credit
+			providerAmount,
+			order.PlatformFee,
+			"service_order",
+			order.ID,
+			fmt.Sprintf("Earnings from order %s", order.Code),
+			metadata,
+		); err != nil {
+			logger.Error("failed to credit provider", "error", err, "providerID", providerID)
 			// Don't fail the completion, but log for manual reconciliation
 		}
 	}
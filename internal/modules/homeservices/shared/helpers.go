@@ -4,21 +4,23 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // CalculatePlatformCommission calculates the platform commission
-func CalculatePlatformCommission(total float64) float64 {
-	return RoundToTwoDecimals(total * PlatformCommissionRate)
+func CalculatePlatformCommission(total money.Amount) money.Amount {
+	return total.Mul(PlatformCommissionRate, money.HalfUp)
 }
 
 // CalculateProviderEarnings calculates what provider earns after commission
-func CalculateProviderEarnings(total float64) float64 {
-	commission := CalculatePlatformCommission(total)
-	return RoundToTwoDecimals(total - commission)
+func CalculateProviderEarnings(total money.Amount) money.Amount {
+	return total.Sub(CalculatePlatformCommission(total))
 }
 
-// CalculateCancellationFee calculates the cancellation fee based on order status
-func CalculateCancellationFee(status string, totalPrice float64) (cancellationFee, refundAmount float64) {
+// CalculateCancellationFee calculates the cancellation fee based on order
+// status. The refund is whatever the fee leaves, so the two add up to the total.
+func CalculateCancellationFee(status string, totalPrice money.Amount) (cancellationFee, refundAmount money.Amount) {
 	var feeRate float64
 
 	switch status {
@@ -30,47 +32,43 @@ func CalculateCancellationFee(status string, totalPrice float64) (cancellationFe
 		feeRate = CancellationFeeAfterStart
 	default:
 		// Cannot cancel
-		return 0, 0
+		zero := money.Zero(totalPrice.Currency())
+		return zero, zero
 	}
 
-	cancellationFee = RoundToTwoDecimals(totalPrice * feeRate)
-	refundAmount = RoundToTwoDecimals(totalPrice - cancellationFee)
+	cancellationFee = totalPrice.Mul(feeRate, money.HalfUp)
+	refundAmount = totalPrice.Sub(cancellationFee)
 
 	return cancellationFee, refundAmount
 }
 
-// RoundToTwoDecimals rounds a float to two decimal places
-func RoundToTwoDecimals(value float64) float64 {
-	return math.Round(value*100) / 100
-}
-
 // CalculateServicesTotal calculates total price for selected services
-func CalculateServicesTotal(services []ServiceItem) float64 {
-	var total float64
+func CalculateServicesTotal(services []ServiceItem) money.Amount {
+	var total money.Amount
 	for _, s := range services {
-		total += s.Price * float64(s.Quantity)
+		total = total.Add(s.Price.MulInt(int64(s.Quantity)))
 	}
-	return RoundToTwoDecimals(total)
+	return total
 }
 
 // CalculateAddonsTotal calculates total price for selected addons
-func CalculateAddonsTotal(addons []AddonItem) float64 {
-	var total float64
+func CalculateAddonsTotal(addons []AddonItem) money.Amount {
+	var total money.Amount
 	for _, a := range addons {
-		total += a.Price * float64(a.Quantity)
+		total = total.Add(a.Price.MulInt(int64(a.Quantity)))
 	}
-	return RoundToTwoDecimals(total)
+	return total
 }
 
 // ServiceItem represents a service for calculation
 type ServiceItem struct {
-	Price    float64
+	Price    money.Amount
 	Quantity int
 }
 
 // AddonItem represents an addon for calculation
 type AddonItem struct {
-	Price    float64
+	Price    money.Amount
 	Quantity int
 }
 
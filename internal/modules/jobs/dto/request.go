@@ -0,0 +1,17 @@
+package dto
+
+type ListRunsRequest struct {
+	Job    string `form:"job"`
+	Status string `form:"status" binding:"omitempty,oneof=running succeeded failed"`
+	Page   int    `form:"page" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (r *ListRunsRequest) SetDefaults() {
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.Limit == 0 {
+		r.Limit = 20
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+)
+
+// SchedulerResponse describes the scheduler as seen by the instance that
+// served the request, plus which instance currently leads
+type SchedulerResponse struct {
+	InstanceID string        `json:"instanceId"`
+	Enabled    bool          `json:"enabled"`
+	IsLeader   bool          `json:"isLeader"`
+	LeaderID   string        `json:"leaderId,omitempty"`
+	Jobs       []JobResponse `json:"jobs"`
+}
+
+type JobResponse struct {
+	Name      string          `json:"name"`
+	Interval  string          `json:"interval"`
+	Running   bool            `json:"running"` // on this instance
+	NextRunAt *time.Time      `json:"nextRunAt,omitempty"`
+	LastRun   *JobRunResponse `json:"lastRun,omitempty"`
+}
+
+type JobRunResponse struct {
+	ID         string     `json:"id"`
+	JobName    string     `json:"jobName"`
+	InstanceID string     `json:"instanceId"`
+	Status     string     `json:"status"`
+	Affected   int64      `json:"affected"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	DurationMs *int64     `json:"durationMs,omitempty"`
+}
+
+func ToJobRunResponse(run *models.JobRun) *JobRunResponse {
+	return &JobRunResponse{
+		ID:         run.ID,
+		JobName:    run.JobName,
+		InstanceID: run.InstanceID,
+		Status:     run.Status,
+		Affected:   run.Affected,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		DurationMs: run.DurationMs,
+	}
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/modules/jobs/dto"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetScheduler godoc
+// @Summary List background jobs (Admin)
+// @Description Registered jobs with their interval and last run, and which instance currently leads the scheduler
+// @Tags jobs
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.SchedulerResponse}
+// @Router /admin/jobs [get]
+func (h *Handler) GetScheduler(c *gin.Context) {
+	scheduler, err := h.service.GetScheduler(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, scheduler, "Jobs retrieved successfully")
+}
+
+// ListRuns godoc
+// @Summary List background job runs (Admin)
+// @Tags jobs
+// @Security BearerAuth
+// @Produce json
+// @Param job query string false "Job name"
+// @Param status query string false "running, succeeded or failed"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} response.Response{data=[]dto.JobRunResponse}
+// @Router /admin/jobs/runs [get]
+func (h *Handler) ListRuns(c *gin.Context) {
+	var req dto.ListRunsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+	req.SetDefaults()
+
+	runs, total, err := h.service.ListRuns(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := response.NewPaginationMeta(total, req.Page, req.Limit)
+	response.Paginated(c, runs, pagination, "Job runs retrieved successfully")
+}
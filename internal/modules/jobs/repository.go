@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/jobs/dto"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateRun(ctx context.Context, run *models.JobRun) error
+	FinishRun(ctx context.Context, run *models.JobRun) error
+	ListRuns(ctx context.Context, req dto.ListRunsRequest) ([]*models.JobRun, int64, error)
+	FindLastRuns(ctx context.Context) (map[string]*models.JobRun, error)
+	DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateRun(ctx context.Context, run *models.JobRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+func (r *repository) FinishRun(ctx context.Context, run *models.JobRun) error {
+	return r.db.WithContext(ctx).
+		Model(&models.JobRun{}).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":      run.Status,
+			"affected":    run.Affected,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+			"duration_ms": run.DurationMs,
+		}).Error
+}
+
+func (r *repository) ListRuns(ctx context.Context, req dto.ListRunsRequest) ([]*models.JobRun, int64, error) {
+	var runs []*models.JobRun
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.JobRun{})
+	if req.Job != "" {
+		query = query.Where("job_name = ?", req.Job)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("started_at DESC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&runs).Error
+	return runs, total, err
+}
+
+// FindLastRuns returns the most recent run of every job that has run
+func (r *repository) FindLastRuns(ctx context.Context) (map[string]*models.JobRun, error) {
+	var runs []*models.JobRun
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT DISTINCT ON (job_name) *
+		FROM job_runs
+		ORDER BY job_name, started_at DESC
+	`).Scan(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	last := make(map[string]*models.JobRun, len(runs))
+	for _, run := range runs {
+		last[run.JobName] = run
+	}
+	return last, nil
+}
+
+func (r *repository) DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("started_at < ?", before).
+		Delete(&models.JobRun{})
+	return result.RowsAffected, result.Error
+}
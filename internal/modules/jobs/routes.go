@@ -0,0 +1,18 @@
+package jobs
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
+	jobs := router.Group("/admin/jobs")
+	jobs.Use(authMiddleware)
+	jobs.Use(middleware.RequirePermission(checker, models.PermissionJobsView))
+	{
+		jobs.GET("", handler.GetScheduler)
+		jobs.GET("/runs", handler.ListRuns)
+	}
+}
@@ -0,0 +1,290 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+const leaderLockKey = "scheduler:leader"
+
+// Job is a periodic task. Run returns how many rows it changed, which is
+// recorded in the run history. Jobs must be safe to run again after a
+// crash: a run cut short by a restart is simply run again later.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) (int64, error)
+}
+
+// Scheduler runs registered jobs on one instance at a time. Every instance
+// runs the loop, but only the one holding the Redis leader lock starts jobs;
+// if the leader dies its lock expires after LeaderTTL and another instance
+// takes over, picking up each job's schedule from the run history.
+type Scheduler struct {
+	repo       Repository
+	cfg        config.SchedulerConfig
+	instanceID string
+
+	mu     sync.Mutex
+	jobs   []*scheduledJob
+	leader bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type scheduledJob struct {
+	Job
+	nextRun time.Time
+	running bool
+}
+
+// JobStatus is a job as this instance sees it
+type JobStatus struct {
+	Job
+	NextRun time.Time // zero until this instance has led
+	Running bool
+}
+
+func NewScheduler(repo Repository, cfg config.SchedulerConfig) *Scheduler {
+	hostname, _ := os.Hostname()
+
+	return &Scheduler{
+		repo:       repo,
+		cfg:        cfg,
+		instanceID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+// Register adds a job. Call it before Start.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &scheduledJob{Job: job})
+}
+
+// Start launches the election/scheduling loop unless the scheduler is disabled
+func (s *Scheduler) Start() {
+	if s.cfg.Disabled {
+		logger.Info("job scheduler disabled")
+		return
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.wg.Add(1)
+	go s.run()
+
+	logger.Info("job scheduler started",
+		"instanceID", s.instanceID,
+		"jobs", len(s.jobs),
+		"leaderTTL", s.cfg.LeaderTTL,
+	)
+}
+
+// Stop waits for running jobs to return and hands leadership over straight
+// away instead of letting the lock expire
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+
+	if s.IsLeader() {
+		if err := cache.ReleaseLock(context.Background(), leaderLockKey, s.instanceID); err != nil {
+			logger.Warn("failed to release scheduler leadership", "error", err)
+		}
+	}
+	logger.Info("job scheduler stopped", "instanceID", s.instanceID)
+}
+
+func (s *Scheduler) InstanceID() string {
+	return s.instanceID
+}
+
+func (s *Scheduler) Enabled() bool {
+	return !s.cfg.Disabled
+}
+
+func (s *Scheduler) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader
+}
+
+// Jobs returns the registered jobs sorted by name
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, JobStatus{Job: job.Job, NextRun: job.nextRun, Running: job.running})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *Scheduler) run() {
+	defer s.wg.Done()
+
+	// Renew well before the lock can expire
+	ticker := time.NewTicker(s.cfg.LeaderTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		if s.elect(s.ctx) {
+			s.runDue(time.Now())
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// elect renews leadership or tries to take it, and reports whether this
+// instance leads now. Redis errors count as not leading, so two instances
+// never run jobs at once because of a flaky connection.
+func (s *Scheduler) elect(ctx context.Context) bool {
+	if s.IsLeader() {
+		renewed, err := cache.ExtendLock(ctx, leaderLockKey, s.instanceID, s.cfg.LeaderTTL)
+		if err == nil && renewed {
+			return true
+		}
+		if ctx.Err() == nil {
+			logger.Warn("lost scheduler leadership", "instanceID", s.instanceID, "error", err)
+		}
+		s.setLeader(false)
+		return false
+	}
+
+	acquired, err := cache.SetNX(ctx, leaderLockKey, s.instanceID, s.cfg.LeaderTTL)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("failed to acquire scheduler leadership", "error", err)
+		}
+		return false
+	}
+	if !acquired {
+		return false
+	}
+
+	s.loadSchedule(ctx)
+	s.setLeader(true)
+	logger.Info("became scheduler leader", "instanceID", s.instanceID)
+	return true
+}
+
+func (s *Scheduler) setLeader(leader bool) {
+	s.mu.Lock()
+	s.leader = leader
+	s.mu.Unlock()
+}
+
+// loadSchedule continues each job's schedule from its last recorded run, so
+// a new leader does not rerun everything the old one just ran
+func (s *Scheduler) loadSchedule(ctx context.Context) {
+	lastRuns, err := s.repo.FindLastRuns(ctx)
+	if err != nil {
+		logger.Warn("failed to load job history, running all jobs now", "error", err)
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		job.nextRun = now
+		if last, ok := lastRuns[job.Name]; ok && last.StartedAt.Add(job.Interval).After(now) {
+			job.nextRun = last.StartedAt.Add(job.Interval)
+		}
+	}
+}
+
+// runDue starts every job whose time has come and that is not still running
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.running || now.Before(job.nextRun) {
+			continue
+		}
+		job.running = true
+		job.nextRun = now.Add(job.Interval)
+
+		s.wg.Add(1)
+		go s.runJob(job)
+	}
+}
+
+func (s *Scheduler) runJob(job *scheduledJob) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+	}()
+
+	run := &models.JobRun{
+		JobName:    job.Name,
+		InstanceID: s.instanceID,
+		Status:     models.JobRunStatusRunning,
+		StartedAt:  time.Now(),
+	}
+	// Without history the job still runs; it is only missing from the list
+	recorded := true
+	if err := s.repo.CreateRun(s.ctx, run); err != nil {
+		logger.Error("failed to record job run", "error", err, "job", job.Name)
+		recorded = false
+	}
+
+	affected, err := s.execute(job)
+
+	finished := time.Now()
+	duration := finished.Sub(run.StartedAt).Milliseconds()
+	run.Affected = affected
+	run.FinishedAt = &finished
+	run.DurationMs = &duration
+	run.Status = models.JobRunStatusSucceeded
+	if err != nil {
+		run.Status = models.JobRunStatusFailed
+		run.Error = err.Error()
+		logger.Error("job failed", "error", err, "job", job.Name, "durationMs", duration)
+	} else if affected > 0 {
+		logger.Info("job finished", "job", job.Name, "affected", affected, "durationMs", duration)
+	}
+
+	if recorded {
+		// Record the outcome even when shutting down
+		if err := s.repo.FinishRun(context.Background(), run); err != nil {
+			logger.Error("failed to record job result", "error", err, "job", job.Name)
+		}
+	}
+}
+
+// execute runs the job, turning a panic into an error so one bad job cannot
+// take the scheduler (and the API) down
+func (s *Scheduler) execute(job *scheduledJob) (affected int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(s.ctx)
+}
@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/umar5678/go-backend/internal/modules/jobs/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+type Service interface {
+	GetScheduler(ctx context.Context) (*dto.SchedulerResponse, error)
+	ListRuns(ctx context.Context, req dto.ListRunsRequest) ([]*dto.JobRunResponse, int64, error)
+}
+
+type service struct {
+	repo      Repository
+	scheduler *Scheduler
+}
+
+func NewService(repo Repository, scheduler *Scheduler) Service {
+	return &service{
+		repo:      repo,
+		scheduler: scheduler,
+	}
+}
+
+// GetScheduler lists the registered jobs with their last run. Any instance
+// can answer: the last run comes from the shared history, and the leader is
+// read from the lock.
+func (s *service) GetScheduler(ctx context.Context) (*dto.SchedulerResponse, error) {
+	lastRuns, err := s.repo.FindLastRuns(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch job history", err)
+	}
+
+	// Empty when nobody leads right now (or Redis is down); still show the jobs
+	leaderID, _ := cache.Get(ctx, leaderLockKey)
+
+	resp := &dto.SchedulerResponse{
+		InstanceID: s.scheduler.InstanceID(),
+		Enabled:    s.scheduler.Enabled(),
+		IsLeader:   s.scheduler.IsLeader(),
+		LeaderID:   leaderID,
+		Jobs:       []dto.JobResponse{},
+	}
+
+	for _, job := range s.scheduler.Jobs() {
+		item := dto.JobResponse{
+			Name:     job.Name,
+			Interval: job.Interval.String(),
+			Running:  job.Running,
+		}
+		if !job.NextRun.IsZero() {
+			nextRun := job.NextRun
+			item.NextRunAt = &nextRun
+		}
+		if last, ok := lastRuns[job.Name]; ok {
+			item.LastRun = dto.ToJobRunResponse(last)
+		}
+		resp.Jobs = append(resp.Jobs, item)
+	}
+
+	return resp, nil
+}
+
+func (s *service) ListRuns(ctx context.Context, req dto.ListRunsRequest) ([]*dto.JobRunResponse, int64, error) {
+	req.SetDefaults()
+
+	runs, total, err := s.repo.ListRuns(ctx, req)
+	if err != nil {
+		return nil, 0, response.InternalServerError("Failed to fetch job runs", err)
+	}
+
+	responses := make([]*dto.JobRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = dto.ToJobRunResponse(run)
+	}
+
+	return responses, total, nil
+}
@@ -2,6 +2,8 @@ package dto
 
 import (
 	"fmt"
+
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // CreateLaundryOrderRequest - Create new laundry order with products
@@ -185,7 +187,7 @@ func (r *ResolveIssueRequest) Validate() error {
 		}
 	}
 
-	if r.RefundAmount != nil && *r.RefundAmount < 0 {
+	if r.RefundAmount != nil && r.RefundAmount.IsNegative() {
 		return fmt.Errorf("refundAmount cannot be negative")
 	}
 
@@ -209,9 +211,9 @@ type ReportIssueRequest struct {
 
 // ResolveIssueRequest represents a request to resolve an issue
 type ResolveIssueRequest struct {
-	Resolution       string   `json:"resolution" binding:"required"`
-	RefundAmount     *float64 `json:"refundAmount"`
-	CompensationType string   `json:"compensationType" binding:"omitempty,oneof=refund discount re_clean replacement voucher"`
+	Resolution       string        `json:"resolution" binding:"required"`
+	RefundAmount     *money.Amount `json:"refundAmount"`
+	CompensationType string        `json:"compensationType" binding:"omitempty,oneof=refund discount re_clean replacement voucher"`
 }
 
 // OrderService represents a service being ordered (legacy support)
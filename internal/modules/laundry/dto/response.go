@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // LaundryOrderResponse represents the complete order response
@@ -14,8 +15,8 @@ type LaundryOrderResponse struct {
 	ProviderID  string                `json:"providerId"`
 	ServiceSlug string                `json:"serviceSlug"`
 	Status      string                `json:"status"`
-	TotalPrice  float64               `json:"totalPrice"`
-	Tip         *float64              `json:"tip,omitempty"`
+	TotalPrice  money.Amount          `json:"totalPrice"`
+	Tip         *money.Amount         `json:"tip,omitempty"`
 	IsExpress   bool                  `json:"isExpress"`
 	Address     string                `json:"address"`
 	Lat         float64               `json:"lat"`
@@ -29,21 +30,21 @@ type LaundryOrderResponse struct {
 
 // LaundryOrderItemDTO represents an order item
 type LaundryOrderItemDTO struct {
-	ID               string     `json:"id"`
-	OrderID          string     `json:"orderId"`
-	ProductSlug      string     `json:"productSlug"`
-	ItemType         string     `json:"itemType"`
-	Quantity         int        `json:"quantity"`
-	Weight           *float64   `json:"weight,omitempty"`
-	QRCode           string     `json:"qrCode"`
-	Status           string     `json:"status"`
-	HasIssue         bool       `json:"hasIssue"`
-	IssueDescription *string    `json:"issueDescription,omitempty"`
-	Price            float64    `json:"price"`
-	ReceivedAt       *time.Time `json:"receivedAt,omitempty"`
-	PackedAt         *time.Time `json:"packedAt,omitempty"`
-	DeliveredAt      *time.Time `json:"deliveredAt,omitempty"`
-	CreatedAt        time.Time  `json:"createdAt"`
+	ID               string       `json:"id"`
+	OrderID          string       `json:"orderId"`
+	ProductSlug      string       `json:"productSlug"`
+	ItemType         string       `json:"itemType"`
+	Quantity         int          `json:"quantity"`
+	Weight           *float64     `json:"weight,omitempty"`
+	QRCode           string       `json:"qrCode"`
+	Status           string       `json:"status"`
+	HasIssue         bool         `json:"hasIssue"`
+	IssueDescription *string      `json:"issueDescription,omitempty"`
+	Price            money.Amount `json:"price"`
+	ReceivedAt       *time.Time   `json:"receivedAt,omitempty"`
+	PackedAt         *time.Time   `json:"packedAt,omitempty"`
+	DeliveredAt      *time.Time   `json:"deliveredAt,omitempty"`
+	CreatedAt        time.Time    `json:"createdAt"`
 }
 
 // LaundryPickupDTO represents pickup information
@@ -80,20 +81,20 @@ type LaundryDeliveryDTO struct {
 
 // LaundryIssueDTO represents an issue/complaint
 type LaundryIssueDTO struct {
-	ID               string     `json:"id"`
-	OrderID          string     `json:"orderId"`
-	CustomerID       string     `json:"customerId"`
-	ProviderID       string     `json:"providerId"`
-	IssueType        string     `json:"issueType"`
-	Description      string     `json:"description"`
-	Priority         string     `json:"priority"`
-	Status           string     `json:"status"`
-	Resolution       *string    `json:"resolution,omitempty"`
-	RefundAmount     *float64   `json:"refundAmount,omitempty"`
-	CompensationType *string    `json:"compensationType,omitempty"`
-	ResolvedAt       *time.Time `json:"resolvedAt,omitempty"`
-	CreatedAt        time.Time  `json:"createdAt"`
-	UpdatedAt        time.Time  `json:"updatedAt"`
+	ID               string        `json:"id"`
+	OrderID          string        `json:"orderId"`
+	CustomerID       string        `json:"customerId"`
+	ProviderID       string        `json:"providerId"`
+	IssueType        string        `json:"issueType"`
+	Description      string        `json:"description"`
+	Priority         string        `json:"priority"`
+	Status           string        `json:"status"`
+	Resolution       *string       `json:"resolution,omitempty"`
+	RefundAmount     *money.Amount `json:"refundAmount,omitempty"`
+	CompensationType *string       `json:"compensationType,omitempty"`
+	ResolvedAt       *time.Time    `json:"resolvedAt,omitempty"`
+	CreatedAt        time.Time     `json:"createdAt"`
+	UpdatedAt        time.Time     `json:"updatedAt"`
 }
 
 // LaundryServiceDTO represents a service in catalog
@@ -103,10 +104,10 @@ type LaundryServiceDTO struct {
 	Title           string            `json:"title"`
 	Description     string            `json:"description"`
 	ColorCode       string            `json:"colorCode"`
-	BasePrice       float64           `json:"basePrice"`
+	BasePrice       money.Amount      `json:"basePrice"`
 	PricingUnit     string            `json:"pricingUnit"`
 	TurnaroundHours int               `json:"turnaroundHours"`
-	ExpressFee      float64           `json:"expressFee"`
+	ExpressFee      money.Amount      `json:"expressFee"`
 	ExpressHours    int               `json:"expressHours"`
 	CategorySlug    string            `json:"categorySlug"`
 	IsActive        bool              `json:"isActive"`
@@ -133,20 +134,20 @@ type OrderStatusSummary struct {
 type PriceEstimateResponse struct {
 	ServiceSlug    string               `json:"serviceSlug"`
 	Items          []ItemPriceBreakdown `json:"items"`
-	SubTotal       float64              `json:"subTotal"`
-	ExpressFee     float64              `json:"expressFee"`
-	TotalPrice     float64              `json:"totalPrice"`
+	SubTotal       money.Amount         `json:"subTotal"`
+	ExpressFee     money.Amount         `json:"expressFee"`
+	TotalPrice     money.Amount         `json:"totalPrice"`
 	TotalWeight    float64              `json:"totalWeight"`
 	EstimatedHours int                  `json:"estimatedHours"`
 }
 
 type ItemPriceBreakdown struct {
-	ProductSlug string  `json:"productSlug"`
-	ProductName string  `json:"productName"`
-	Quantity    int     `json:"quantity"`
-	UnitPrice   float64 `json:"unitPrice"`
-	Weight      float64 `json:"weight"`
-	ItemTotal   float64 `json:"itemTotal"`
+	ProductSlug string       `json:"productSlug"`
+	ProductName string       `json:"productName"`
+	Quantity    int          `json:"quantity"`
+	UnitPrice   money.Amount `json:"unitPrice"`
+	Weight      float64      `json:"weight"`
+	ItemTotal   money.Amount `json:"itemTotal"`
 }
 
 // StandardResponse is a generic API response
@@ -184,10 +185,10 @@ type GetServicesWithProductsResponse struct {
 	Title           string            `json:"title"`
 	Description     string            `json:"description"`
 	ColorCode       string            `json:"colorCode"`
-	BasePrice       float64           `json:"basePrice"`
+	BasePrice       money.Amount      `json:"basePrice"`
 	PricingUnit     string            `json:"pricingUnit"`
 	TurnaroundHours int               `json:"turnaroundHours"`
-	ExpressFee      float64           `json:"expressFee"`
+	ExpressFee      money.Amount      `json:"expressFee"`
 	ExpressHours    int               `json:"expressHours"`
 	CategorySlug    string            `json:"categorySlug"`
 	ProductCount    int               `json:"productCount"`
@@ -195,17 +196,17 @@ type GetServicesWithProductsResponse struct {
 }
 
 type ProductResponse struct {
-	ID                  string   `json:"id"`
-	Name                string   `json:"name"`
-	Slug                string   `json:"slug"`
-	Description         string   `json:"description"`
-	IconURL             *string  `json:"iconUrl,omitempty"`
-	Price               *float64 `json:"price,omitempty"`
-	PricingUnit         *string  `json:"pricingUnit,omitempty"`
-	TypicalWeight       *float64 `json:"typicalWeight,omitempty"`
-	RequiresSpecialCare bool     `json:"requiresSpecialCare"`
-	SpecialCareFee      float64  `json:"specialCareFee"`
-	CategorySlug        string   `json:"categorySlug"`
+	ID                  string        `json:"id"`
+	Name                string        `json:"name"`
+	Slug                string        `json:"slug"`
+	Description         string        `json:"description"`
+	IconURL             *string       `json:"iconUrl,omitempty"`
+	Price               *money.Amount `json:"price,omitempty"`
+	PricingUnit         *string       `json:"pricingUnit,omitempty"`
+	TypicalWeight       *float64      `json:"typicalWeight,omitempty"`
+	RequiresSpecialCare bool          `json:"requiresSpecialCare"`
+	SpecialCareFee      money.Amount  `json:"specialCareFee"`
+	CategorySlug        string        `json:"categorySlug"`
 }
 
 // LaundryPickupResponse represents a pickup event response
@@ -241,47 +242,47 @@ type LaundryDeliveryResponse struct {
 
 // LaundryOrderItemResponse represents an item in a laundry order
 type LaundryOrderItemResponse struct {
-	ID          string    `json:"id"`
-	OrderID     string    `json:"orderId"`
-	QRCode      string    `json:"qrCode"`
-	ItemType    string    `json:"itemType"`
-	Quantity    int       `json:"quantity"`
-	ServiceSlug string    `json:"serviceSlug"`
-	Weight      *float64  `json:"weight,omitempty"`
-	Price       float64   `json:"price"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          string       `json:"id"`
+	OrderID     string       `json:"orderId"`
+	QRCode      string       `json:"qrCode"`
+	ItemType    string       `json:"itemType"`
+	Quantity    int          `json:"quantity"`
+	ServiceSlug string       `json:"serviceSlug"`
+	Weight      *float64     `json:"weight,omitempty"`
+	Price       money.Amount `json:"price"`
+	Status      string       `json:"status"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
 }
 
 // LaundryIssueResponse represents an issue reported on an order
 type LaundryIssueResponse struct {
-	ID           string     `json:"id"`
-	OrderID      string     `json:"orderId"`
-	IssueType    string     `json:"issueType"`
-	Description  string     `json:"description"`
-	Priority     string     `json:"priority"`
-	Status       string     `json:"status"`
-	Resolution   *string    `json:"resolution,omitempty"`
-	RefundAmount *float64   `json:"refundAmount,omitempty"`
-	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
-	CreatedAt    time.Time  `json:"createdAt"`
-	UpdatedAt    time.Time  `json:"updatedAt"`
+	ID           string        `json:"id"`
+	OrderID      string        `json:"orderId"`
+	IssueType    string        `json:"issueType"`
+	Description  string        `json:"description"`
+	Priority     string        `json:"priority"`
+	Status       string        `json:"status"`
+	Resolution   *string       `json:"resolution,omitempty"`
+	RefundAmount *money.Amount `json:"refundAmount,omitempty"`
+	ResolvedAt   *time.Time    `json:"resolvedAt,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
 }
 
 // LaundryServiceResponse represents a service in the catalog
 type LaundryServiceResponse struct {
-	ID              string  `json:"id"`
-	Slug            string  `json:"slug"`
-	Title           string  `json:"title"`
-	Description     string  `json:"description"`
-	ColorCode       string  `json:"colorCode"`
-	BasePrice       float64 `json:"basePrice"`
-	PricingUnit     string  `json:"pricingUnit"`
-	TurnaroundHours int     `json:"turnaroundHours"`
-	ExpressFee      float64 `json:"expressFee"`
-	ExpressHours    int     `json:"expressHours"`
-	CategorySlug    string  `json:"categorySlug"`
+	ID              string       `json:"id"`
+	Slug            string       `json:"slug"`
+	Title           string       `json:"title"`
+	Description     string       `json:"description"`
+	ColorCode       string       `json:"colorCode"`
+	BasePrice       money.Amount `json:"basePrice"`
+	PricingUnit     string       `json:"pricingUnit"`
+	TurnaroundHours int          `json:"turnaroundHours"`
+	ExpressFee      money.Amount `json:"expressFee"`
+	ExpressHours    int          `json:"expressHours"`
+	CategorySlug    string       `json:"categorySlug"`
 }
 
 // ToLaundryOrderResponse converts a LaundryOrder model to response DTO
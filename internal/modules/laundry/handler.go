@@ -537,24 +537,19 @@ func (h *Handler) ResolveIssue(c *gin.Context) {
 		return
 	}
 
-	var req gin.H
+	var req dto.ResolveIssueRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.Error(response.BadRequest("Invalid request body: " + err.Error()))
 		return
 	}
 
-	resolution := ""
-	if r, exists := req["resolution"]; exists {
-		resolution = r.(string)
-	}
-
-	var refundAmount *float64
-	if r, exists := req["refundAmount"]; exists {
-		amount := r.(float64)
-		refundAmount = &amount
+	// Validate request
+	if err := req.Validate(); err != nil {
+		c.Error(response.BadRequest("Validation failed: " + err.Error()))
+		return
 	}
 
-	if err := h.service.ResolveIssue(c, issueID, resolution, refundAmount); err != nil {
+	if err := h.service.ResolveIssue(c, issueID, req.Resolution, req.RefundAmount); err != nil {
 		c.Error(response.InternalServerError("Failed to resolve issue", err))
 		return
 	}
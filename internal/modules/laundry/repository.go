@@ -6,6 +6,7 @@ import (
 
 	"github.com/umar5678/go-backend/internal/models"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +26,9 @@ type Repository interface {
 	AddProviderService(ctx context.Context, providerID, serviceSlug string) error
 	GetProviderServices(ctx context.Context, providerID string) ([]string, error)
 	GetAvailableOrdersByCategory(ctx context.Context, category string, serviceSlugs []string) ([]*models.LaundryOrder, error)
+	FindUnassignedOrders(ctx context.Context, createdBefore time.Time, limit int) ([]*models.LaundryOrder, error)
+	CancelUnassignedOrder(ctx context.Context, orderID string) (bool, error)
+	CreateStatusHistory(ctx context.Context, history *models.LaundryOrderStatusHistory) error
 
 	// Pickups & Deliveries (handled by provider)
 	CreatePickup(ctx context.Context, pickup *models.LaundryPickup) error
@@ -47,7 +51,7 @@ type Repository interface {
 	CreateIssue(ctx context.Context, issue *models.LaundryIssue) error
 	GetIssuesByProvider(ctx context.Context, providerID string, statuses []string) ([]*models.LaundryIssue, error)
 	GetIssuesByOrder(ctx context.Context, orderID string) ([]*models.LaundryIssue, error)
-	UpdateIssueStatus(ctx context.Context, issueID, status string, resolution *string, refundAmount *float64) error
+	UpdateIssueStatus(ctx context.Context, issueID, status string, resolution *string, refundAmount *money.Amount) error
 
 	// Services & Products
 	GetServicesWithProducts(ctx context.Context) ([]*models.LaundryServiceCatalog, error)
@@ -311,7 +315,7 @@ func (r *repository) GetIssuesByOrder(ctx context.Context, orderID string) ([]*m
 	return issues, err
 }
 
-func (r *repository) UpdateIssueStatus(ctx context.Context, issueID, status string, resolution *string, refundAmount *float64) error {
+func (r *repository) UpdateIssueStatus(ctx context.Context, issueID, status string, resolution *string, refundAmount *money.Amount) error {
 	updates := map[string]interface{}{"status": status}
 	if resolution != nil {
 		updates["resolution"] = resolution
@@ -398,3 +402,40 @@ func (r *repository) GetAvailableOrdersByCategory(ctx context.Context, category
 		Find(&orders).Error
 	return orders, err
 }
+
+// unassignedOrder matches pending orders no provider has taken: no provider
+// and no pickup started
+const unassignedOrder = "status = 'pending' AND provider_id IS NULL AND " +
+	"NOT EXISTS (SELECT 1 FROM laundry_pickups p WHERE p.order_id = laundry_orders.id)"
+
+// FindUnassignedOrders returns up to limit unassigned orders created before
+// createdBefore, oldest first
+func (r *repository) FindUnassignedOrders(ctx context.Context, createdBefore time.Time, limit int) ([]*models.LaundryOrder, error) {
+	var orders []*models.LaundryOrder
+	err := r.db.WithContext(ctx).
+		Where(unassignedOrder).
+		Where("created_at < ?", createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// CancelUnassignedOrder cancels an order only if it is still unassigned, so a
+// provider taking it at the same moment wins. Returns false when the order
+// had already moved on.
+func (r *repository) CancelUnassignedOrder(ctx context.Context, orderID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.LaundryOrder{}).
+		Where("id = ?", orderID).
+		Where(unassignedOrder).
+		Updates(map[string]interface{}{
+			"status":     "cancelled",
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) CreateStatusHistory(ctx context.Context, history *models.LaundryOrderStatusHistory) error {
+	return r.db.WithContext(ctx).Create(history).Error
+}
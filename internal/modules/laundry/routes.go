@@ -4,13 +4,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/umar5678/go-backend/internal/config"
 	"github.com/umar5678/go-backend/internal/middleware"
-	"gorm.io/gorm"
 )
 
-func RegisterRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
-	// Initialize repository and service
-	repo := NewRepository(db)
-	service := NewService(repo, db)
+func RegisterRoutes(router *gin.Engine, service Service, cfg *config.Config, authMiddleware gin.HandlerFunc) {
+	// Initialize handler
 	handler := NewHandler(service)
 
 	// Public routes - Get service catalog and products
@@ -25,10 +22,10 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 
 	// Customer routes (authenticated as customer/rider)
 	customer := router.Group("/api/v1/laundry")
-	customer.Use(middleware.Auth(cfg))
+	customer.Use(authMiddleware)
 	{
 		// Order management
-		customer.POST("/orders", handler.CreateOrder)
+		customer.POST("/orders", middleware.Idempotency(), handler.CreateOrder)
 		customer.GET("/orders/:id", handler.GetOrder)
 
 		// Pickup & Delivery management (customer can also manage these)
@@ -43,7 +40,7 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
 
 	// Provider routes (authenticated as service provider)
 	provider := router.Group("/api/v1/laundry/provider")
-	provider.Use(middleware.Auth(cfg))
+	provider.Use(authMiddleware)
 	provider.Use(middleware.RequireRole("service_provider")) // Ensure user is a provider
 	{
 		// View available orders for provider
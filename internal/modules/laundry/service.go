@@ -9,8 +9,16 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/commission"
+	commissiondto "github.com/umar5678/go-backend/internal/modules/commission/dto"
+	"github.com/umar5678/go-backend/internal/modules/geofences"
 	"github.com/umar5678/go-backend/internal/modules/laundry/dto"
+	"github.com/umar5678/go-backend/internal/modules/wallet"
+	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/websocket"
+	websocketutil "github.com/umar5678/go-backend/internal/websocket/websocketutils"
 	"gorm.io/gorm"
 )
 
@@ -25,6 +33,7 @@ type Service interface {
 	GetOrder(ctx context.Context, orderID string) (*dto.LaundryOrderResponse, error)
 	GetOrderWithDetails(ctx context.Context, orderID string) (*models.LaundryOrder, error)
 	GetAvailableOrders(ctx context.Context, providerID string) ([]*models.LaundryOrder, error)
+	CancelUnassignedOrders(ctx context.Context, olderThan time.Duration) (int64, error)
 
 	// Pickups
 	InitiatePickup(ctx context.Context, orderID string, providerID string) (*models.LaundryPickup, error)
@@ -44,16 +53,28 @@ type Service interface {
 	// Issues
 	ReportIssue(ctx context.Context, orderID, customerID, providerID string, req *dto.ReportIssueRequest) (*models.LaundryIssue, error)
 	GetProviderIssues(ctx context.Context, providerID string) ([]*models.LaundryIssue, error)
-	ResolveIssue(ctx context.Context, issueID string, resolution string, refundAmount *float64) error
+	ResolveIssue(ctx context.Context, issueID string, resolution string, refundAmount *money.Amount) error
 }
 
+// refTypeLaundryOrder is the wallet reference type for money moved for an order
+const refTypeLaundryOrder = "laundry_order"
+
 type service struct {
-	repo Repository
-	db   *gorm.DB
+	repo              Repository
+	db                *gorm.DB
+	geofenceService   geofences.Service
+	commissionService commission.Service
+	walletService     wallet.Service
 }
 
-func NewService(repo Repository, db *gorm.DB) Service {
-	return &service{repo: repo, db: db}
+func NewService(repo Repository, db *gorm.DB, geofenceService geofences.Service, commissionService commission.Service, walletService wallet.Service) Service {
+	return &service{
+		repo:              repo,
+		db:                db,
+		geofenceService:   geofenceService,
+		commissionService: commissionService,
+		walletService:     walletService,
+	}
 }
 
 // =====================================================
@@ -139,6 +160,11 @@ func (s *service) CreateOrder(ctx context.Context, customerID string, req *dto.C
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Pickup and delivery happen at the order address
+	if err := s.geofenceService.CheckLocation(ctx, models.ServiceLineLaundry, req.Lat, req.Lng); err != nil {
+		return nil, err
+	}
+
 	// Get service catalog to verify service exists
 	service, err := s.repo.GetServiceBySlug(ctx, req.ServiceSlug)
 	if err != nil {
@@ -149,10 +175,12 @@ func (s *service) CreateOrder(ctx context.Context, customerID string, req *dto.C
 		return nil, fmt.Errorf("service not found: %w", err)
 	}
 
-	// Calculate total price from products
-	totalPrice := 0.0
+	// Calculate total price from products. Item prices are kept so the order
+	// items below add up to the same total.
+	totalPrice := money.Zero(money.DefaultCurrency)
+	itemPrices := make([]money.Amount, len(req.Items))
 
-	for _, item := range req.Items {
+	for i, item := range req.Items {
 		// Get product details
 		product, err := s.repo.GetProductBySlug(ctx, req.ServiceSlug, item.ProductSlug)
 		if err != nil {
@@ -163,39 +191,21 @@ func (s *service) CreateOrder(ctx context.Context, customerID string, req *dto.C
 			return nil, fmt.Errorf("product '%s' not found", item.ProductSlug)
 		}
 
-		// Calculate price based on pricing unit
-		itemPrice := 0.0
-
-		if service.PricingUnit == "kg" {
-			// For weight-based services, only use product price (no base price per kg)
-			if product.Price != nil {
-				itemPrice = *product.Price * float64(item.Quantity)
-			}
-		} else {
-			// Item-based pricing: base_price + product_price per item
-			price := service.BasePrice
-			if product.Price != nil {
-				price += *product.Price // Add product price to base price
-			}
-			itemPrice = price * float64(item.Quantity)
-		}
-
-		// Add special care fee if required
-		if product.RequiresSpecialCare {
-			itemPrice += product.SpecialCareFee * float64(item.Quantity)
-		}
-
-		totalPrice += itemPrice
+		itemPrices[i] = calculateItemPrice(service, product, item.Quantity)
+		totalPrice = totalPrice.Add(itemPrices[i])
 	}
 
 	// Add express fee if requested
 	if req.IsExpress {
-		totalPrice += service.ExpressFee
+		totalPrice = totalPrice.Add(service.ExpressFee)
 	}
 
 	// Add tip if provided
+	var tip *money.Amount
 	if req.Tip != nil && *req.Tip > 0 {
-		totalPrice += *req.Tip
+		t := money.FromFloat(*req.Tip, money.DefaultCurrency, money.HalfUp)
+		tip = &t
+		totalPrice = totalPrice.Add(t)
 	}
 
 	logger.Info("CreateOrder: calculated pricing",
@@ -225,7 +235,7 @@ func (s *service) CreateOrder(ctx context.Context, customerID string, req *dto.C
 		Longitude:    req.Lng,
 		ServiceDate:  nil, // Will be set when pickup is created
 		Total:        totalPrice,
-		Tip:          req.Tip,       // Store the tip
+		Tip:          tip,           // Store the tip
 		IsExpress:    req.IsExpress, // Store the express flag
 		ProviderID:   nil,           // Will be assigned when provider accepts
 		CreatedAt:    now,
@@ -251,28 +261,6 @@ func (s *service) CreateOrder(ctx context.Context, customerID string, req *dto.C
 	// Create order items with pricing
 	items := make([]*models.LaundryOrderItem, len(req.Items))
 	for i, item := range req.Items {
-		product, _ := s.repo.GetProductBySlug(ctx, req.ServiceSlug, item.ProductSlug)
-
-		// Calculate item price (use same logic as total calculation)
-		itemPrice := 0.0
-		if service.PricingUnit == "kg" {
-			// For weight-based services, only use product price (no base price per kg)
-			if product.Price != nil {
-				itemPrice = *product.Price * float64(item.Quantity)
-			}
-		} else {
-			// Item-based pricing: base_price + product_price per item
-			price := service.BasePrice
-			if product.Price != nil {
-				price += *product.Price
-			}
-			itemPrice = price * float64(item.Quantity)
-		}
-
-		if product.RequiresSpecialCare {
-			itemPrice += product.SpecialCareFee * float64(item.Quantity)
-		}
-
 		items[i] = &models.LaundryOrderItem{
 			OrderID:     orderID,
 			ServiceSlug: req.ServiceSlug,
@@ -281,7 +269,7 @@ func (s *service) CreateOrder(ctx context.Context, customerID string, req *dto.C
 			Quantity:    item.Quantity,
 			Weight:      item.Weight,
 			Status:      "pending",
-			Price:       itemPrice,
+			Price:       itemPrices[i],
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
@@ -569,6 +557,91 @@ func (s *service) GetAvailableOrders(ctx context.Context, providerID string) ([]
 	return orders, nil
 }
 
+// unassignedOrderBatch caps how many orders one sweep cancels
+const unassignedOrderBatch = 100
+
+// CancelUnassignedOrders cancels orders no provider picked up within
+// olderThan, releases any wallet holds placed for them and tells the
+// customer. Run periodically by the job scheduler; returns how many orders
+// were cancelled.
+func (s *service) CancelUnassignedOrders(ctx context.Context, olderThan time.Duration) (int64, error) {
+	orders, err := s.repo.FindUnassignedOrders(ctx, time.Now().Add(-olderThan), unassignedOrderBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unassigned orders: %w", err)
+	}
+
+	const reason = "No provider picked up the order in time"
+
+	var cancelled int64
+	for _, order := range orders {
+		ok, err := s.repo.CancelUnassignedOrder(ctx, order.ID)
+		if err != nil {
+			logger.Error("failed to cancel unassigned laundry order", "error", err, "orderID", order.ID)
+			continue
+		}
+		if !ok {
+			// Taken by a provider since the scan
+			continue
+		}
+
+		released := s.releaseOrderHolds(ctx, order)
+
+		history := &models.LaundryOrderStatusHistory{
+			OrderID:       order.ID,
+			FromStatus:    order.Status,
+			ToStatus:      "cancelled",
+			ChangedByRole: "system",
+			Notes:         reason,
+			Metadata:      models.StatusHistoryMetadata{"releasedHolds": released},
+		}
+		if err := s.repo.CreateStatusHistory(ctx, history); err != nil {
+			logger.Error("failed to record laundry order status", "error", err, "orderID", order.ID)
+		}
+
+		if order.UserID != nil {
+			websocketutil.SendToUser(*order.UserID, websocket.TypeNotification, map[string]interface{}{
+				"type":        "laundry_order_cancelled",
+				"orderId":     order.ID,
+				"orderNumber": order.OrderNumber,
+				"reason":      reason,
+			})
+		}
+
+		logger.Info("unassigned laundry order cancelled", "orderID", order.ID, "customerID", order.UserID)
+		cancelled++
+	}
+
+	return cancelled, nil
+}
+
+// releaseOrderHolds gives back any wallet holds the customer placed for the
+// order. Orders are only charged on delivery, so a hold is all there can be.
+// Returns the IDs of the holds released.
+func (s *service) releaseOrderHolds(ctx context.Context, order *models.LaundryOrder) []string {
+	released := []string{}
+	if order.UserID == nil {
+		return released
+	}
+
+	holds, err := s.walletService.GetHoldsByReference(ctx, refTypeLaundryOrder, order.ID)
+	if err != nil {
+		logger.Error("failed to find laundry order holds", "error", err, "orderID", order.ID)
+		return released
+	}
+
+	for _, hold := range holds {
+		if hold.Status != models.TransactionStatusHeld {
+			continue
+		}
+		if err := s.walletService.ReleaseHold(ctx, *order.UserID, walletdto.ReleaseHoldRequest{HoldID: hold.ID}); err != nil {
+			logger.Error("failed to release wallet hold", "error", err, "holdID", hold.ID, "orderID", order.ID)
+			continue
+		}
+		released = append(released, hold.ID)
+	}
+	return released
+}
+
 // =====================================================
 // Pickups
 // =====================================================
@@ -655,7 +728,7 @@ func (s *service) AddItems(ctx context.Context, orderID string, req *dto.AddLaun
 			ItemType:    itemReq.ItemType,
 			Quantity:    itemReq.Quantity,
 			Weight:      itemReq.Weight,
-			Price:       itemReq.Price,
+			Price:       money.FromFloat(itemReq.Price, money.DefaultCurrency, money.HalfUp),
 			Status:      "pending",
 			HasIssue:    false,
 			CreatedAt:   now,
@@ -765,20 +838,91 @@ func (s *service) CompleteDelivery(ctx context.Context, orderID string, req *dto
 			"updated_at":   now,
 		})
 
-	// Update order status to "completed"
-	if err := s.db.WithContext(ctx).
+	// Update order status to "completed"; only the call that completes the
+	// order pays the provider, so a retried request is not paid twice
+	result := s.db.WithContext(ctx).
 		Model(&models.LaundryOrder{}).
-		Where("id = ?", orderID).
+		Where("id = ? AND status <> ?", orderID, "completed").
 		Updates(map[string]interface{}{
 			"status":     "completed",
 			"updated_at": now,
-		}).Error; err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update order status: %w", result.Error)
 	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	s.payProvider(ctx, orderID)
 
 	return nil
 }
 
+// payProvider credits the provider of a completed order with the order total
+// minus the platform commission in force now. The tip goes to the provider in
+// full. Failures are logged for manual reconciliation rather than undoing the
+// delivery.
+func (s *service) payProvider(ctx context.Context, orderID string) {
+	order, err := s.GetOrderWithDetails(ctx, orderID)
+	if err != nil {
+		logger.Error("failed to load laundry order for payout", "error", err, "orderID", orderID)
+		return
+	}
+
+	providerID := ""
+	if order.ProviderID != nil {
+		providerID = *order.ProviderID
+	} else if delivery, err := s.repo.GetDeliveryByOrder(ctx, orderID); err == nil && delivery != nil && delivery.ProviderID != nil {
+		providerID = *delivery.ProviderID
+	}
+	if providerID == "" {
+		logger.Warn("completed laundry order has no provider to pay", "orderID", orderID)
+		return
+	}
+
+	quote, err := s.commissionService.Calculate(ctx, commissiondto.CalculateRequest{
+		ServiceLine: models.ServiceLineLaundry,
+		City:        s.geofenceService.ResolveServiceCity(ctx, models.ServiceLineLaundry, order.Latitude, order.Longitude),
+		Category:    order.CategorySlug,
+		Amount:      order.Total,
+	})
+	if err != nil {
+		logger.Error("failed to calculate laundry commission", "error", err, "orderID", orderID)
+		return
+	}
+
+	payout := quote.Payout
+	if order.Tip != nil {
+		payout = payout.Add(*order.Tip)
+	}
+
+	metadata := quote.Metadata()
+	metadata["grossAmount"] = order.Total.Float64()
+
+	if _, err := s.walletService.CreditWallet(
+		ctx,
+		providerID,
+		payout,
+		quote.Commission,
+		refTypeLaundryOrder,
+		order.ID,
+		fmt.Sprintf("Earnings from laundry order %s", order.OrderNumber),
+		metadata,
+	); err != nil {
+		logger.Error("failed to credit laundry provider", "error", err, "orderID", orderID, "providerID", providerID)
+		return
+	}
+
+	logger.Info("laundry provider paid",
+		"orderID", orderID,
+		"providerID", providerID,
+		"payout", payout,
+		"commission", quote.Commission,
+		"commissionRuleVersion", quote.RuleVersion,
+	)
+}
+
 func (s *service) GetProviderDeliveries(ctx context.Context, providerID string) ([]*models.LaundryDelivery, error) {
 	// Get deliveries that are scheduled or in_route (not yet completed)
 	return s.repo.GetDeliveriesByProvider(ctx, providerID, []string{"scheduled", "en_route", "arrived"})
@@ -838,7 +982,7 @@ func (s *service) GetProviderIssues(ctx context.Context, providerID string) ([]*
 	return s.repo.GetIssuesByProvider(ctx, providerID, []string{})
 }
 
-func (s *service) ResolveIssue(ctx context.Context, issueID string, resolution string, refundAmount *float64) error {
+func (s *service) ResolveIssue(ctx context.Context, issueID string, resolution string, refundAmount *money.Amount) error {
 	if issueID == "" {
 		return errors.New("issue_id is required")
 	}
@@ -866,3 +1010,20 @@ func (s *service) ResolveIssue(ctx context.Context, issueID string, resolution s
 // =====================================================
 // Helpers
 // =====================================================
+
+// calculateItemPrice prices one order line. Weight-based services charge the
+// product price only; item-based services charge base price plus product
+// price per item. Special care is charged per item on top.
+func calculateItemPrice(service *models.LaundryServiceCatalog, product *models.LaundryServiceProduct, quantity int) money.Amount {
+	unit := money.Zero(money.DefaultCurrency)
+	if service.PricingUnit != "kg" {
+		unit = service.BasePrice
+	}
+	if product.Price != nil {
+		unit = unit.Add(*product.Price)
+	}
+	if product.RequiresSpecialCare {
+		unit = unit.Add(product.SpecialCareFee)
+	}
+	return unit.MulInt(int64(quantity))
+}
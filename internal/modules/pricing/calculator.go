@@ -4,7 +4,7 @@ import (
 	"math"
 
 	"github.com/umar5678/go-backend/internal/models"
-	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 // FareCalculator handles fare calculations
@@ -14,48 +14,49 @@ func NewFareCalculator() *FareCalculator {
 	return &FareCalculator{}
 }
 
-// CalculateEstimate calculates estimated fare before ride
+// CalculateEstimate calculates estimated fare before ride from the routed
+// distance (km) and duration (seconds)
 func (c *FareCalculator) CalculateEstimate(
-	pickupLat, pickupLon, dropoffLat, dropoffLon float64,
+	estimatedDistance float64,
+	estimatedDuration int,
 	vehicleType *models.VehicleType,
 	surgeMultiplier float64,
 ) *models.FareEstimate {
+	// Distance shown to the rider is rounded; the fare uses the same value
+	return c.calculate(math.Round(estimatedDistance*100)/100, estimatedDuration, vehicleType, surgeMultiplier)
+}
 
-	// Calculate straight-line distance (in reality, use routing API)
-	distanceKm := location.HaversineDistance(pickupLat, pickupLon, dropoffLat, dropoffLon)
-
-	// Add 20% for actual road distance (rough estimate)
-	estimatedDistance := distanceKm * 1.2
-
-	// Calculate estimated duration (assume average speed 40 km/h in city)
-	averageSpeedKmh := 40.0
-	estimatedDuration := location.CalculateETA(estimatedDistance, averageSpeedKmh)
-
-	// Calculate fare components
-	distanceFare := estimatedDistance * vehicleType.PerKmRate
-	durationMinutes := float64(estimatedDuration) / 60.0
-	durationFare := durationMinutes * vehicleType.PerMinuteRate
-
-	subTotal := vehicleType.BaseFare + distanceFare + durationFare
-	surgeAmount := subTotal * (surgeMultiplier - 1.0)
-	totalFare := subTotal + surgeAmount + vehicleType.BookingFee
-
-	// Round to 2 decimal places
-	totalFare = math.Round(totalFare*100) / 100
-
-	return &models.FareEstimate{
-		BaseFare:          vehicleType.BaseFare,
-		DistanceFare:      math.Round(distanceFare*100) / 100,
-		DurationFare:      math.Round(durationFare*100) / 100,
-		BookingFee:        vehicleType.BookingFee,
-		SurgeMultiplier:   surgeMultiplier,
-		SubTotal:          math.Round(subTotal*100) / 100,
-		SurgeAmount:       math.Round(surgeAmount*100) / 100,
-		TotalFare:         totalFare,
-		EstimatedDistance: math.Round(estimatedDistance*100) / 100,
-		EstimatedDuration: estimatedDuration,
-		VehicleTypeName:   vehicleType.DisplayName,
+// CalculateLegsEstimate calculates the estimated fare of a trip through
+// intermediate stops from the routed distance and duration of each leg. Each
+// leg is priced and rounded on its own and the trip's distance and duration
+// fares are the sums, so the legs always add up to the trip's components.
+func (c *FareCalculator) CalculateLegsEstimate(
+	legs []models.FareLeg,
+	vehicleType *models.VehicleType,
+	surgeMultiplier float64,
+) *models.FareEstimate {
+	currency := vehicleType.BaseFare.Currency()
+	distanceFare := money.Zero(currency)
+	durationFare := money.Zero(currency)
+	var distanceKm float64
+	var durationSec int
+
+	priced := make([]models.FareLeg, len(legs))
+	for i, leg := range legs {
+		leg.DistanceKm = math.Round(leg.DistanceKm*100) / 100
+		leg.DistanceFare = vehicleType.PerKmRate.Mul(leg.DistanceKm, money.HalfUp)
+		leg.DurationFare = vehicleType.PerMinuteRate.Mul(float64(leg.DurationSec)/60.0, money.HalfUp)
+		priced[i] = leg
+
+		distanceFare = distanceFare.Add(leg.DistanceFare)
+		durationFare = durationFare.Add(leg.DurationFare)
+		distanceKm += leg.DistanceKm
+		durationSec += leg.DurationSec
 	}
+
+	estimate := c.total(math.Round(distanceKm*100)/100, durationSec, distanceFare, durationFare, vehicleType, surgeMultiplier)
+	estimate.Legs = priced
+	return estimate
 }
 
 // CalculateActualFare calculates final fare after ride completion
@@ -65,36 +66,55 @@ func (c *FareCalculator) CalculateActualFare(
 	vehicleType *models.VehicleType,
 	surgeMultiplier float64,
 ) *models.FareEstimate {
+	return c.calculate(actualDistanceKm, actualDurationSec, vehicleType, surgeMultiplier)
+}
+
+// calculate rounds every component to the cent once and builds the totals
+// from the rounded components, so the breakdown always adds up to TotalFare
+func (c *FareCalculator) calculate(
+	distanceKm float64,
+	durationSec int,
+	vehicleType *models.VehicleType,
+	surgeMultiplier float64,
+) *models.FareEstimate {
 
 	// Calculate fare components
-	distanceFare := actualDistanceKm * vehicleType.PerKmRate
-	durationMinutes := float64(actualDurationSec) / 60.0
-	durationFare := durationMinutes * vehicleType.PerMinuteRate
+	distanceFare := vehicleType.PerKmRate.Mul(distanceKm, money.HalfUp)
+	durationMinutes := float64(durationSec) / 60.0
+	durationFare := vehicleType.PerMinuteRate.Mul(durationMinutes, money.HalfUp)
 
-	subTotal := vehicleType.BaseFare + distanceFare + durationFare
-	surgeAmount := subTotal * (surgeMultiplier - 1.0)
-	totalFare := subTotal + surgeAmount + vehicleType.BookingFee
+	return c.total(distanceKm, durationSec, distanceFare, durationFare, vehicleType, surgeMultiplier)
+}
 
-	// Round to 2 decimal places
-	totalFare = math.Round(totalFare*100) / 100
+// total adds base fare, surge and booking fee to the distance and duration fares
+func (c *FareCalculator) total(
+	distanceKm float64,
+	durationSec int,
+	distanceFare, durationFare money.Amount,
+	vehicleType *models.VehicleType,
+	surgeMultiplier float64,
+) *models.FareEstimate {
+	subTotal := money.Sum(vehicleType.BaseFare, distanceFare, durationFare)
+	surgeAmount := subTotal.Mul(surgeMultiplier-1.0, money.HalfUp)
+	totalFare := money.Sum(subTotal, surgeAmount, vehicleType.BookingFee)
 
 	return &models.FareEstimate{
 		BaseFare:          vehicleType.BaseFare,
-		DistanceFare:      math.Round(distanceFare*100) / 100,
-		DurationFare:      math.Round(durationFare*100) / 100,
+		DistanceFare:      distanceFare,
+		DurationFare:      durationFare,
 		BookingFee:        vehicleType.BookingFee,
 		SurgeMultiplier:   surgeMultiplier,
-		SubTotal:          math.Round(subTotal*100) / 100,
-		SurgeAmount:       math.Round(surgeAmount*100) / 100,
+		SubTotal:          subTotal,
+		SurgeAmount:       surgeAmount,
 		TotalFare:         totalFare,
-		EstimatedDistance: actualDistanceKm,
-		EstimatedDuration: actualDurationSec,
+		EstimatedDistance: distanceKm,
+		EstimatedDuration: durationSec,
 		VehicleTypeName:   vehicleType.DisplayName,
 	}
 }
 
 // CalculateMinimumFare returns minimum fare for vehicle type
-func (c *FareCalculator) CalculateMinimumFare(vehicleType *models.VehicleType) float64 {
+func (c *FareCalculator) CalculateMinimumFare(vehicleType *models.VehicleType) money.Amount {
 	// Minimum fare = base fare + booking fee
-	return vehicleType.BaseFare + vehicleType.BookingFee
+	return vehicleType.BaseFare.Add(vehicleType.BookingFee)
 }
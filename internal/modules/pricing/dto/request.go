@@ -9,6 +9,15 @@ type FareEstimateRequest struct {
 	DropoffLat    float64 `json:"dropoffLat" binding:"required,min=-90,max=90"`
 	DropoffLon    float64 `json:"dropoffLon" binding:"required,min=-180,max=180"`
 	VehicleTypeID string  `json:"vehicleTypeId" binding:"required,uuid"`
+
+	// Intermediate stops, in visiting order, between pickup and dropoff
+	Stops []StopPoint `json:"stops" binding:"omitempty,max=3,dive"`
+}
+
+// StopPoint is an intermediate stop of a multi-stop trip
+type StopPoint struct {
+	Lat float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lon float64 `json:"lon" binding:"required,min=-180,max=180"`
 }
 
 func (r *FareEstimateRequest) Validate() error {
@@ -33,6 +42,12 @@ func (r *FareEstimateRequest) Validate() error {
 		return errors.New("pickup and dropoff locations must be different")
 	}
 
+	for _, stop := range r.Stops {
+		if stop.Lat < -90 || stop.Lat > 90 || stop.Lon < -180 || stop.Lon > 180 {
+			return errors.New("invalid stop location")
+		}
+	}
+
 	return nil
 }
 
@@ -42,3 +57,12 @@ type CalculateActualFareRequest struct {
 	VehicleTypeID     string  `json:"vehicleTypeId" binding:"required,uuid"`
 	SurgeMultiplier   float64 `json:"surgeMultiplier" binding:"omitempty,min=1,max=5"`
 }
+
+// UpdateSurgeCityRequest creates or updates a city's surge settings
+type UpdateSurgeCityRequest struct {
+	CenterLat     float64  `json:"centerLat" binding:"required,min=-90,max=90"`
+	CenterLon     float64  `json:"centerLon" binding:"required,min=-180,max=180"`
+	RadiusKm      float64  `json:"radiusKm" binding:"required,gt=0,max=500"`
+	Enabled       *bool    `json:"enabled" binding:"required"`
+	MaxMultiplier *float64 `json:"maxMultiplier" binding:"omitempty,min=1,max=5"`
+}
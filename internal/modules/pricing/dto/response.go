@@ -1,36 +1,102 @@
 // internal/modules/pricing/dto/response.go
 package dto
 
+import (
+	"time"
+
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
 type FareEstimateResponse struct {
-	BaseFare          float64 `json:"baseFare"`
-	DistanceFare      float64 `json:"distanceFare"`
-	DurationFare      float64 `json:"durationFare"`
-	BookingFee        float64 `json:"bookingFee"`
-	SurgeMultiplier   float64 `json:"surgeMultiplier"`
-	SubTotal          float64 `json:"subTotal"`
-	SurgeAmount       float64 `json:"surgeAmount"`
-	TotalFare         float64 `json:"totalFare"`
-	EstimatedDistance float64 `json:"estimatedDistance"` // km
-	EstimatedDuration int     `json:"estimatedDuration"` // seconds
-	VehicleTypeName   string  `json:"vehicleTypeName"`
-	Currency          string  `json:"currency"`
+	BaseFare          money.Amount `json:"baseFare"`
+	DistanceFare      money.Amount `json:"distanceFare"`
+	DurationFare      money.Amount `json:"durationFare"`
+	BookingFee        money.Amount `json:"bookingFee"`
+	SurgeMultiplier   float64      `json:"surgeMultiplier"`
+	SubTotal          money.Amount `json:"subTotal"`
+	SurgeAmount       money.Amount `json:"surgeAmount"`
+	TotalFare         money.Amount `json:"totalFare"`
+	EstimatedDistance float64      `json:"estimatedDistance"` // km
+	EstimatedDuration int          `json:"estimatedDuration"` // seconds
+	VehicleTypeName   string       `json:"vehicleTypeName"`
+	Currency          string       `json:"currency"`
+
+	// Per-leg breakdown of a multi-stop trip; the legs' distance and
+	// duration fares add up to DistanceFare and DurationFare
+	Legs []FareLegResponse `json:"legs,omitempty"`
+
+	// Geofences: flat airport fees (included in TotalFare) and, for an
+	// airport pickup, the designated pickup point the trip was priced from
+	AirportFee  money.Amount `json:"airportFee"`
+	PickupPoint *PickupPoint `json:"pickupPoint,omitempty"`
+
+	// Upfront quote: pass QuoteID when booking to lock this fare
+	QuoteID        string     `json:"quoteId,omitempty"`
+	QuoteExpiresAt *time.Time `json:"quoteExpiresAt,omitempty"`
+}
+
+type FareLegResponse struct {
+	FromStop     int          `json:"fromStop"` // 0 is the pickup, n the nth stop
+	DistanceKm   float64      `json:"distanceKm"`
+	DurationSec  int          `json:"durationSec"`
+	DistanceFare money.Amount `json:"distanceFare"`
+	DurationFare money.Amount `json:"durationFare"`
+	Fare         money.Amount `json:"fare"` // distance + duration, before surge
+}
+
+// PickupPoint is the designated airport pickup point a ride is moved to
+type PickupPoint struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// FareQuote is an estimate a rider can book at the quoted price until it expires
+type FareQuote struct {
+	ID                string       `json:"id"`
+	VehicleTypeID     string       `json:"vehicleTypeId"`
+	PickupLat         float64      `json:"pickupLat"`
+	PickupLon         float64      `json:"pickupLon"`
+	DropoffLat        float64      `json:"dropoffLat"`
+	DropoffLon        float64      `json:"dropoffLon"`
+	Stops             []StopPoint  `json:"stops,omitempty"`
+	PickupPoint       *PickupPoint `json:"pickupPoint,omitempty"`
+	TotalFare         money.Amount `json:"totalFare"`
+	AirportFee        money.Amount `json:"airportFee"`
+	SurgeMultiplier   float64      `json:"surgeMultiplier"`
+	EstimatedDistance float64      `json:"estimatedDistance"` // km
+	EstimatedDuration int          `json:"estimatedDuration"` // seconds
+	ExpiresAt         time.Time    `json:"expiresAt"`
 }
 
 type SurgeZoneResponse struct {
-	ID         string  `json:"id"`
-	AreaName   string  `json:"areaName"`
-	Multiplier float64 `json:"multiplier"`
-	RadiusKm   float64 `json:"radiusKm"`
-	IsActive   bool    `json:"isActive"`
+	ID          string  `json:"id"`
+	AreaName    string  `json:"areaName"`
+	Geohash     string  `json:"geohash"`
+	Multiplier  float64 `json:"multiplier"`
+	RadiusKm    float64 `json:"radiusKm"`
+	IsActive    bool    `json:"isActive"`
+	Source      string  `json:"source"` // manual, computed
+	DemandCount int     `json:"demandCount,omitempty"`
+	SupplyCount int     `json:"supplyCount,omitempty"`
+}
+
+type SurgeCityResponse struct {
+	City          string   `json:"city"`
+	CenterLat     float64  `json:"centerLat"`
+	CenterLon     float64  `json:"centerLon"`
+	RadiusKm      float64  `json:"radiusKm"`
+	Enabled       bool     `json:"enabled"`
+	MaxMultiplier *float64 `json:"maxMultiplier,omitempty"`
 }
 
 type FareBreakdownResponse struct {
 	Components []FareComponent `json:"components"`
-	Total      float64         `json:"total"`
+	Total      money.Amount    `json:"total"`
 }
 
 type FareComponent struct {
-	Name   string  `json:"name"`
-	Amount float64 `json:"amount"`
-	Type   string  `json:"type"` // base, distance, duration, surge, booking_fee
+	Name   string       `json:"name"`
+	Amount money.Amount `json:"amount"`
+	Type   string       `json:"type"` // base, distance, duration, surge, booking_fee, airport_fee
 }
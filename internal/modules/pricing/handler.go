@@ -96,6 +96,50 @@ func (h *Handler) GetActiveSurgeZones(c *gin.Context) {
 	response.Success(c, zones, "Active surge zones retrieved successfully")
 }
 
+// ListSurgeCities godoc
+// @Summary List surge settings per city (Admin)
+// @Tags pricing
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response{data=[]dto.SurgeCityResponse}
+// @Router /pricing/surge/cities [get]
+func (h *Handler) ListSurgeCities(c *gin.Context) {
+	cities, err := h.service.ListSurgeCities(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, cities, "Surge city settings retrieved successfully")
+}
+
+// UpdateSurgeCity godoc
+// @Summary Create or update a city's surge settings (Admin)
+// @Description Set enabled=false to turn computed and manual surge off in the city
+// @Tags pricing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param city path string true "City name"
+// @Param request body dto.UpdateSurgeCityRequest true "Surge settings"
+// @Success 200 {object} response.Response{data=dto.SurgeCityResponse}
+// @Router /pricing/surge/cities/{city} [put]
+func (h *Handler) UpdateSurgeCity(c *gin.Context) {
+	var req dto.UpdateSurgeCityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	city, err := h.service.UpdateSurgeCity(c.Request.Context(), c.Param("city"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, city, "Surge city settings updated successfully")
+}
+
 func (h *Handler) getSurgeMessage(multiplier float64) string {
 	switch {
 	case multiplier == 1.0:
@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/modules/pricing/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+// Quote IDs handed to clients look like "<id>.<expiresUnix>.<signature>".
+// The signature lets us reject forged or tampered IDs without a Redis
+// round trip; the fare itself only ever comes from Redis.
+
+func quoteCacheKey(id string) string {
+	return fmt.Sprintf("fare:quote:%s", id)
+}
+
+func quoteUsedKey(id string) string {
+	return fmt.Sprintf("fare:quote:used:%s", id)
+}
+
+// issueQuote stores the estimate and returns its signed quote ID
+func (s *service) issueQuote(ctx context.Context, req dto.FareEstimateRequest, fare *dto.FareEstimateResponse) (string, time.Time, error) {
+	id := uuid.New().String()
+	expiresAt := time.Now().Add(s.cfg.QuoteTTL)
+
+	quote := &dto.FareQuote{
+		ID:                id,
+		VehicleTypeID:     req.VehicleTypeID,
+		PickupLat:         req.PickupLat,
+		PickupLon:         req.PickupLon,
+		DropoffLat:        req.DropoffLat,
+		DropoffLon:        req.DropoffLon,
+		Stops:             req.Stops,
+		PickupPoint:       fare.PickupPoint,
+		TotalFare:         fare.TotalFare,
+		AirportFee:        fare.AirportFee,
+		SurgeMultiplier:   fare.SurgeMultiplier,
+		EstimatedDistance: fare.EstimatedDistance,
+		EstimatedDuration: fare.EstimatedDuration,
+		ExpiresAt:         expiresAt,
+	}
+
+	if err := cache.SetJSON(ctx, quoteCacheKey(id), quote, s.cfg.QuoteTTL); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return s.signQuoteID(id, expiresAt), expiresAt, nil
+}
+
+func (s *service) signQuoteID(id string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%s.%d", id, expiresAt.Unix())
+	return payload + "." + s.quoteSignature(payload)
+}
+
+func (s *service) quoteSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.QuoteSecret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GetQuote verifies a quote ID and returns the quoted fare
+func (s *service) GetQuote(ctx context.Context, quoteID string) (*dto.FareQuote, error) {
+	parts := strings.Split(quoteID, ".")
+	if len(parts) != 3 {
+		return nil, response.BadRequest("Invalid fare quote")
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.quoteSignature(payload))) {
+		return nil, response.BadRequest("Invalid fare quote")
+	}
+
+	expiresUnix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, response.BadRequest("Invalid fare quote")
+	}
+	if time.Now().After(time.Unix(expiresUnix, 0)) {
+		return nil, response.BadRequest("Fare quote has expired, please request a new estimate")
+	}
+
+	var quote dto.FareQuote
+	if err := cache.GetJSON(ctx, quoteCacheKey(parts[0]), &quote); err != nil {
+		return nil, response.BadRequest("Fare quote has expired, please request a new estimate")
+	}
+
+	return &quote, nil
+}
+
+// ConsumeQuote marks a quote as booked so it cannot be used for a second ride
+func (s *service) ConsumeQuote(ctx context.Context, quote *dto.FareQuote) error {
+	ttl := time.Until(quote.ExpiresAt)
+	if ttl <= 0 {
+		return response.BadRequest("Fare quote has expired, please request a new estimate")
+	}
+
+	acquired, err := cache.SetNX(ctx, quoteUsedKey(quote.ID), "1", ttl)
+	if err != nil {
+		logger.Error("failed to consume fare quote", "error", err, "quoteID", quote.ID)
+		return response.InternalServerError("Failed to use fare quote", err)
+	}
+	if !acquired {
+		return response.BadRequest("Fare quote has already been used")
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/umar5678/go-backend/internal/utils/location"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
@@ -18,6 +19,17 @@ type Repository interface {
 	CreateSurgeZone(ctx context.Context, zone *models.SurgePricingZone) error
 	UpdateSurgeZone(ctx context.Context, zone *models.SurgePricingZone) error
 	DeactivateSurgeZone(ctx context.Context, id string) error
+
+	// Surge engine
+	GetComputedSurgeZones(ctx context.Context) ([]*models.SurgePricingZone, error)
+	UpsertComputedSurgeZone(ctx context.Context, zone *models.SurgePricingZone) error
+	DeactivateComputedSurgeZones(ctx context.Context, geohashes []string) error
+	FindSearchingRidePickups(ctx context.Context) ([]location.Point, error)
+	FindAvailableDriverPositions(ctx context.Context) ([]location.Point, error)
+
+	// Surge city settings
+	GetSurgeCitySettings(ctx context.Context) ([]*models.SurgeCitySetting, error)
+	UpsertSurgeCitySetting(ctx context.Context, setting *models.SurgeCitySetting) error
 }
 
 type repository struct {
@@ -95,3 +107,95 @@ func (r *repository) DeactivateSurgeZone(ctx context.Context, id string) error {
 		Where("id = ?", id).
 		Update("is_active", false).Error
 }
+
+// GetComputedSurgeZones returns the live zones written by the surge engine
+func (r *repository) GetComputedSurgeZones(ctx context.Context) ([]*models.SurgePricingZone, error) {
+	var zones []*models.SurgePricingZone
+
+	err := r.db.WithContext(ctx).
+		Where("source = ?", models.SurgeSourceComputed).
+		Where("is_active = ?", true).
+		Where("active_until >= ?", time.Now()).
+		Find(&zones).Error
+
+	return zones, err
+}
+
+// UpsertComputedSurgeZone writes the engine's zone for a cell, reusing the
+// cell's existing row so there is only ever one computed zone per geohash
+func (r *repository) UpsertComputedSurgeZone(ctx context.Context, zone *models.SurgePricingZone) error {
+	zone.Source = models.SurgeSourceComputed
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "area_geohash"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "source = 'computed' AND deleted_at IS NULL"}}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"area_name", "multiplier", "active_from", "active_until",
+				"is_active", "demand_count", "supply_count", "updated_at",
+			}),
+		}).
+		Create(zone).Error
+}
+
+func (r *repository) DeactivateComputedSurgeZones(ctx context.Context, geohashes []string) error {
+	if len(geohashes) == 0 {
+		return nil
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&models.SurgePricingZone{}).
+		Where("source = ?", models.SurgeSourceComputed).
+		Where("area_geohash IN ?", geohashes).
+		Updates(map[string]interface{}{
+			"is_active":    false,
+			"multiplier":   1.0,
+			"active_until": time.Now(),
+		}).Error
+}
+
+// FindSearchingRidePickups returns the pickup point of every ride still
+// waiting for a driver
+func (r *repository) FindSearchingRidePickups(ctx context.Context) ([]location.Point, error) {
+	var points []location.Point
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Select("pickup_lat AS latitude, pickup_lon AS longitude").
+		Where("status = ?", "searching").
+		Scan(&points).Error
+
+	return points, err
+}
+
+// FindAvailableDriverPositions returns the position of every online driver
+func (r *repository) FindAvailableDriverPositions(ctx context.Context) ([]location.Point, error) {
+	var points []location.Point
+
+	err := r.db.WithContext(ctx).
+		Model(&models.DriverProfile{}).
+		Select("ST_Y(current_location::geometry) AS latitude, ST_X(current_location::geometry) AS longitude").
+		Where("status = ?", "online").
+		Where("is_verified = ?", true).
+		Where("current_location IS NOT NULL").
+		Scan(&points).Error
+
+	return points, err
+}
+
+func (r *repository) GetSurgeCitySettings(ctx context.Context) ([]*models.SurgeCitySetting, error) {
+	var settings []*models.SurgeCitySetting
+	err := r.db.WithContext(ctx).Order("city ASC").Find(&settings).Error
+	return settings, err
+}
+
+func (r *repository) UpsertSurgeCitySetting(ctx context.Context, setting *models.SurgeCitySetting) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "city"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"center_lat", "center_lon", "radius_km", "enabled", "max_multiplier", "updated_at",
+			}),
+		}).
+		Create(setting).Error
+}
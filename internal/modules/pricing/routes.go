@@ -2,14 +2,25 @@ package pricing
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler *Handler) {
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
 	pricing := router.Group("/pricing")
 	{
 		// Public endpoints (no auth required)
 		pricing.POST("/estimate", handler.GetFareEstimate)
 		pricing.GET("/surge", handler.GetSurgeMultiplier)
 		pricing.GET("/surge/zones", handler.GetActiveSurgeZones)
+
+		// Admin: surge engine settings per city
+		cities := pricing.Group("/surge/cities")
+		cities.Use(authMiddleware, middleware.RequirePermission(checker, models.PermissionPricingManage))
+		{
+			cities.GET("", handler.ListSurgeCities)
+			cities.PUT("/:city", handler.UpdateSurgeCity)
+		}
 	}
 }
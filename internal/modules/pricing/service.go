@@ -4,12 +4,16 @@ package pricing
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/umar5678/go-backend/internal/config"
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/geofences"
 	"github.com/umar5678/go-backend/internal/modules/pricing/dto"
 	vehiclesrepo "github.com/umar5678/go-backend/internal/modules/vehicles"
 	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/services/routing"
 
 	"github.com/umar5678/go-backend/internal/utils/location"
 	"github.com/umar5678/go-backend/internal/utils/logger"
@@ -18,10 +22,19 @@ import (
 
 type Service interface {
 	GetFareEstimate(ctx context.Context, req dto.FareEstimateRequest) (*dto.FareEstimateResponse, error)
+	EstimateTripFare(ctx context.Context, req dto.FareEstimateRequest, surgeMultiplier float64) (*dto.FareEstimateResponse, error)
 	CalculateActualFare(ctx context.Context, req dto.CalculateActualFareRequest) (*dto.FareEstimateResponse, error)
 	GetSurgeMultiplier(ctx context.Context, lat, lon float64) (float64, error)
 	GetActiveSurgeZones(ctx context.Context) ([]*dto.SurgeZoneResponse, error)
 	GetFareBreakdown(ctx context.Context, estimate *models.FareEstimate) *dto.FareBreakdownResponse
+
+	// Upfront quotes
+	GetQuote(ctx context.Context, quoteID string) (*dto.FareQuote, error)
+	ConsumeQuote(ctx context.Context, quote *dto.FareQuote) error
+
+	// Admin
+	ListSurgeCities(ctx context.Context) ([]*dto.SurgeCityResponse, error)
+	UpdateSurgeCity(ctx context.Context, city string, req dto.UpdateSurgeCityRequest) (*dto.SurgeCityResponse, error)
 }
 
 type service struct {
@@ -29,14 +42,20 @@ type service struct {
 	vehiclesRepo vehiclesrepo.Repository
 	calculator   *FareCalculator
 	surgeManager *SurgeManager
+	router       routing.RoutingProvider
+	geofences    geofences.Service
+	cfg          config.PricingConfig
 }
 
-func NewService(repo Repository, vehiclesRepo vehiclesrepo.Repository) Service {
+func NewService(repo Repository, vehiclesRepo vehiclesrepo.Repository, router routing.RoutingProvider, geofenceService geofences.Service, cfg config.PricingConfig) Service {
 	return &service{
 		repo:         repo,
 		vehiclesRepo: vehiclesRepo,
 		calculator:   NewFareCalculator(),
 		surgeManager: NewSurgeManager(repo),
+		router:       router,
+		geofences:    geofenceService,
+		cfg:          cfg,
 	}
 }
 
@@ -45,13 +64,75 @@ func (s *service) GetFareEstimate(ctx context.Context, req dto.FareEstimateReque
 		return nil, response.BadRequest(err.Error())
 	}
 
-	// Validate minimum distance (0.5 km)
-	distance := location.HaversineDistance(req.PickupLat, req.PickupLon, req.DropoffLat, req.DropoffLon)
+	// Get surge multiplier for pickup location
+	surgeMultiplier, err := s.surgeManager.GetSurgeMultiplier(ctx, req.PickupLat, req.PickupLon)
+	if err != nil {
+		logger.Error("failed to get surge multiplier", "error", err)
+		surgeMultiplier = 1.0 // Default to no surge on error
+	}
+
+	fareResponse, err := s.estimateTrip(ctx, req, surgeMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock the fare behind a quote the rider can book with
+	quoteID, expiresAt, err := s.issueQuote(ctx, req, fareResponse)
+	if err != nil {
+		logger.Error("failed to issue fare quote", "error", err)
+	} else {
+		fareResponse.QuoteID = quoteID
+		fareResponse.QuoteExpiresAt = &expiresAt
+	}
+
+	// Cache estimate for 1 minute
+	cacheKey := fmt.Sprintf("fare:estimate:%s:%f:%f:%f:%f",
+		req.VehicleTypeID, req.PickupLat, req.PickupLon, req.DropoffLat, req.DropoffLon)
+	for _, stop := range req.Stops {
+		cacheKey += fmt.Sprintf(":%f:%f", stop.Lat, stop.Lon)
+	}
+	cache.SetJSON(ctx, cacheKey, fareResponse, 1*time.Minute)
+
+	return fareResponse, nil
+}
+
+// EstimateTripFare prices a trip at the given surge multiplier instead of
+// the current one, e.g. to reprice a ride at its booked surge when its stops
+// change. No quote is issued.
+func (s *service) EstimateTripFare(ctx context.Context, req dto.FareEstimateRequest, surgeMultiplier float64) (*dto.FareEstimateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+	if surgeMultiplier < 1 {
+		surgeMultiplier = 1.0
+	}
+	return s.estimateTrip(ctx, req, surgeMultiplier)
+}
+
+// estimateTrip routes the trip leg by leg (pickup, each stop, dropoff) and
+// prices it
+func (s *service) estimateTrip(ctx context.Context, req dto.FareEstimateRequest, surgeMultiplier float64) (*dto.FareEstimateResponse, error) {
+	// Unserved pickups and dropoffs are rejected; an airport pickup is priced
+	// from its designated pickup point
+	zones, err := s.geofences.CheckTrip(ctx,
+		location.Point{Latitude: req.PickupLat, Longitude: req.PickupLon},
+		location.Point{Latitude: req.DropoffLat, Longitude: req.DropoffLon},
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.PickupLat, req.PickupLon = zones.PickupLat, zones.PickupLon
+
+	points := tripPoints(req)
+
+	// Validate minimum (0.5 km) and maximum (100 km) distance along the stops
+	var distance float64
+	for i := 1; i < len(points); i++ {
+		distance += location.CalculateDistance(points[i-1], points[i])
+	}
 	if distance < 0.5 {
 		return nil, response.BadRequest("Minimum trip distance is 0.5 km")
 	}
-
-	// Validate maximum distance (100 km)
 	if distance > 100 {
 		return nil, response.BadRequest("Maximum trip distance is 100 km")
 	}
@@ -66,20 +147,26 @@ func (s *service) GetFareEstimate(ctx context.Context, req dto.FareEstimateReque
 		return nil, response.BadRequest("Vehicle type is not available")
 	}
 
-	// Get surge multiplier for pickup location
-	surgeMultiplier, err := s.surgeManager.GetSurgeMultiplier(ctx, req.PickupLat, req.PickupLon)
-	if err != nil {
-		logger.Error("failed to get surge multiplier", "error", err)
-		surgeMultiplier = 1.0 // Default to no surge on error
+	// Road distance and duration for each leg of the trip
+	legs := make([]models.FareLeg, 0, len(points)-1)
+	var routeSource string
+	for i := 1; i < len(points); i++ {
+		route, err := s.router.Route(ctx, points[i-1], points[i])
+		if err != nil {
+			logger.Error("failed to route trip", "error", err, "leg", i)
+			return nil, response.InternalServerError("Failed to calculate route", err)
+		}
+		legs = append(legs, models.FareLeg{DistanceKm: route.DistanceKm, DurationSec: route.DurationSec})
+		routeSource = route.Source
 	}
 
 	// Calculate fare estimate
-	estimate := s.calculator.CalculateEstimate(
-		req.PickupLat, req.PickupLon,
-		req.DropoffLat, req.DropoffLon,
-		vehicleType,
-		surgeMultiplier,
-	)
+	var estimate *models.FareEstimate
+	if len(legs) == 1 {
+		estimate = s.calculator.CalculateEstimate(legs[0].DistanceKm, legs[0].DurationSec, vehicleType, surgeMultiplier)
+	} else {
+		estimate = s.calculator.CalculateLegsEstimate(legs, vehicleType, surgeMultiplier)
+	}
 
 	// Convert to response
 	fareResponse := &dto.FareEstimateResponse{
@@ -90,29 +177,55 @@ func (s *service) GetFareEstimate(ctx context.Context, req dto.FareEstimateReque
 		SurgeMultiplier:   estimate.SurgeMultiplier,
 		SubTotal:          estimate.SubTotal,
 		SurgeAmount:       estimate.SurgeAmount,
-		TotalFare:         estimate.TotalFare,
+		TotalFare:         estimate.TotalFare.Add(zones.AirportFee),
 		EstimatedDistance: estimate.EstimatedDistance,
 		EstimatedDuration: estimate.EstimatedDuration,
 		VehicleTypeName:   estimate.VehicleTypeName,
 		Currency:          "USD",
+		AirportFee:        zones.AirportFee,
+	}
+	if zones.PickupPoint != nil {
+		fareResponse.PickupPoint = &dto.PickupPoint{
+			Name: zones.PickupPoint.Name,
+			Lat:  zones.PickupPoint.Lat,
+			Lon:  zones.PickupPoint.Lon,
+		}
+	}
+	for i, leg := range estimate.Legs {
+		fareResponse.Legs = append(fareResponse.Legs, dto.FareLegResponse{
+			FromStop:     i,
+			DistanceKm:   leg.DistanceKm,
+			DurationSec:  leg.DurationSec,
+			DistanceFare: leg.DistanceFare,
+			DurationFare: leg.DurationFare,
+			Fare:         leg.DistanceFare.Add(leg.DurationFare),
+		})
 	}
-
-	// Cache estimate for 1 minute
-	cacheKey := fmt.Sprintf("fare:estimate:%s:%f:%f:%f:%f",
-		req.VehicleTypeID, req.PickupLat, req.PickupLon, req.DropoffLat, req.DropoffLon)
-	cache.SetJSON(ctx, cacheKey, fareResponse, 1*time.Minute)
 
 	logger.Info("fare estimate calculated",
 		"vehicleType", vehicleType.Name,
 		"distance", estimate.EstimatedDistance,
 		"duration", estimate.EstimatedDuration,
+		"legs", len(legs),
+		"routeSource", routeSource,
 		"surge", surgeMultiplier,
-		"totalFare", estimate.TotalFare,
+		"airportFee", zones.AirportFee,
+		"totalFare", fareResponse.TotalFare,
 	)
 
 	return fareResponse, nil
 }
 
+// tripPoints returns pickup, the stops in order, then dropoff
+func tripPoints(req dto.FareEstimateRequest) []location.Point {
+	points := make([]location.Point, 0, len(req.Stops)+2)
+	points = append(points, location.Point{Latitude: req.PickupLat, Longitude: req.PickupLon})
+	for _, stop := range req.Stops {
+		points = append(points, location.Point{Latitude: stop.Lat, Longitude: stop.Lon})
+	}
+	return append(points, location.Point{Latitude: req.DropoffLat, Longitude: req.DropoffLon})
+}
+
 func (s *service) CalculateActualFare(ctx context.Context, req dto.CalculateActualFareRequest) (*dto.FareEstimateResponse, error) {
 	if req.ActualDistanceKm <= 0 {
 		return nil, response.BadRequest("Invalid distance")
@@ -192,8 +305,8 @@ func (s *service) GetSurgeMultiplier(ctx context.Context, lat, lon float64) (flo
 		return 1.0, nil // Default to no surge on error
 	}
 
-	// Cache for 5 minutes
-	cache.Set(ctx, cacheKey, fmt.Sprintf("%.2f", multiplier), 5*time.Minute)
+	// Short TTL: computed zones change every surge engine run
+	cache.Set(ctx, cacheKey, fmt.Sprintf("%.2f", multiplier), time.Minute)
 
 	return multiplier, nil
 }
@@ -219,16 +332,20 @@ func (s *service) GetActiveSurgeZones(ctx context.Context) ([]*dto.SurgeZoneResp
 	result := make([]*dto.SurgeZoneResponse, len(zones))
 	for i, zone := range zones {
 		result[i] = &dto.SurgeZoneResponse{
-			ID:         zone.ID,
-			AreaName:   zone.AreaName,
-			Multiplier: zone.Multiplier,
-			RadiusKm:   zone.RadiusKm,
-			IsActive:   zone.IsActive,
+			ID:          zone.ID,
+			AreaName:    zone.AreaName,
+			Geohash:     zone.AreaGeohash,
+			Multiplier:  zone.Multiplier,
+			RadiusKm:    zone.RadiusKm,
+			IsActive:    zone.IsActive,
+			Source:      zone.Source,
+			DemandCount: zone.DemandCount,
+			SupplyCount: zone.SupplyCount,
 		}
 	}
 
-	// Cache for 5 minutes
-	cache.SetJSON(ctx, cacheKey, result, 5*time.Minute)
+	// Cleared by the surge engine after every run
+	cache.SetJSON(ctx, cacheKey, result, time.Minute)
 
 	return result, nil
 }
@@ -271,3 +388,58 @@ func (s *service) GetFareBreakdown(ctx context.Context, estimate *models.FareEst
 		Total:      estimate.TotalFare,
 	}
 }
+
+func (s *service) ListSurgeCities(ctx context.Context) ([]*dto.SurgeCityResponse, error) {
+	settings, err := s.repo.GetSurgeCitySettings(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch surge city settings", err)
+	}
+
+	result := make([]*dto.SurgeCityResponse, len(settings))
+	for i, setting := range settings {
+		result[i] = toSurgeCityResponse(setting)
+	}
+	return result, nil
+}
+
+func (s *service) UpdateSurgeCity(ctx context.Context, city string, req dto.UpdateSurgeCityRequest) (*dto.SurgeCityResponse, error) {
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return nil, response.BadRequest("City is required")
+	}
+
+	setting := &models.SurgeCitySetting{
+		City:          city,
+		CenterLat:     req.CenterLat,
+		CenterLon:     req.CenterLon,
+		RadiusKm:      req.RadiusKm,
+		Enabled:       *req.Enabled,
+		MaxMultiplier: req.MaxMultiplier,
+	}
+
+	if err := s.repo.UpsertSurgeCitySetting(ctx, setting); err != nil {
+		logger.Error("failed to save surge city setting", "error", err, "city", city)
+		return nil, response.InternalServerError("Failed to save surge city setting", err)
+	}
+
+	cache.Delete(ctx, surgeCitiesCacheKey)
+
+	logger.Info("surge city setting updated",
+		"city", city,
+		"enabled", *req.Enabled,
+		"maxMultiplier", req.MaxMultiplier,
+	)
+
+	return toSurgeCityResponse(setting), nil
+}
+
+func toSurgeCityResponse(setting *models.SurgeCitySetting) *dto.SurgeCityResponse {
+	return &dto.SurgeCityResponse{
+		City:          setting.City,
+		CenterLat:     setting.CenterLat,
+		CenterLon:     setting.CenterLon,
+		RadiusKm:      setting.RadiusKm,
+		Enabled:       setting.Enabled,
+		MaxMultiplier: setting.MaxMultiplier,
+	}
+}
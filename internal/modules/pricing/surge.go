@@ -3,10 +3,17 @@ package pricing
 
 import (
 	"context"
-	"math/rand"
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+const (
+	surgeCitiesCacheKey = "surge:cities"
+	surgeCitiesCacheTTL = time.Minute
 )
 
 // SurgeManager handles surge pricing logic
@@ -26,9 +33,14 @@ func (m *SurgeManager) GetSurgeMultiplier(ctx context.Context, lat, lon float64)
 		return 1.0, err // Default to no surge
 	}
 
-	// If no surge zones found, return default or random fake surge
+	// No surge zone here means no surge
 	if len(zones) == 0 {
-		return m.getFakeSurgeMultiplier(), nil
+		return 1.0, nil
+	}
+
+	// Surge switched off for this city
+	if city := m.cityForPoint(ctx, lat, lon); city != nil && !city.Enabled {
+		return 1.0, nil
 	}
 
 	// Return highest surge multiplier if in multiple zones
@@ -42,23 +54,41 @@ func (m *SurgeManager) GetSurgeMultiplier(ctx context.Context, lat, lon float64)
 	return maxMultiplier, nil
 }
 
-// getFakeSurgeMultiplier generates random surge for demo
-func (m *SurgeManager) getFakeSurgeMultiplier() float64 {
-	rand.Seed(time.Now().UnixNano())
-
-	// 70% chance: no surge (1.0x)
-	// 20% chance: 1.5x surge
-	// 10% chance: 2.0x surge
-	random := rand.Float64()
-
-	switch {
-	case random < 0.70:
-		return 1.0
-	case random < 0.90:
-		return 1.5
-	default:
-		return 2.0
+// cityForPoint returns the surge settings of the city containing the point,
+// or nil when the point is outside every configured city
+func (m *SurgeManager) cityForPoint(ctx context.Context, lat, lon float64) *models.SurgeCitySetting {
+	return findSurgeCity(m.loadCitySettings(ctx), lat, lon)
+}
+
+func (m *SurgeManager) loadCitySettings(ctx context.Context) []*models.SurgeCitySetting {
+	var cached []*models.SurgeCitySetting
+	if err := cache.GetJSON(ctx, surgeCitiesCacheKey, &cached); err == nil {
+		return cached
+	}
+
+	settings, err := m.repo.GetSurgeCitySettings(ctx)
+	if err != nil {
+		logger.Error("failed to load surge city settings", "error", err)
+		return nil
+	}
+
+	cache.SetJSON(ctx, surgeCitiesCacheKey, settings, surgeCitiesCacheTTL)
+	return settings
+}
+
+// findSurgeCity picks the smallest configured city containing the point, so
+// a city nested inside a larger metro area wins
+func findSurgeCity(settings []*models.SurgeCitySetting, lat, lon float64) *models.SurgeCitySetting {
+	var found *models.SurgeCitySetting
+	for _, city := range settings {
+		if !location.IsWithinRadius(city.CenterLat, city.CenterLon, lat, lon, city.RadiusKm) {
+			continue
+		}
+		if found == nil || city.RadiusKm < found.RadiusKm {
+			found = city
+		}
 	}
+	return found
 }
 
 // GetSurgeZoneByGeohash returns surge zone by geohash
@@ -77,6 +107,10 @@ func (m *SurgeManager) IsLocationInSurgeZone(ctx context.Context, lat, lon float
 		return false, 1.0, nil
 	}
 
+	if city := m.cityForPoint(ctx, lat, lon); city != nil && !city.Enabled {
+		return false, 1.0, nil
+	}
+
 	// Return highest multiplier
 	maxMultiplier := 1.0
 	for _, zone := range zones {
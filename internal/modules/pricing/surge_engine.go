@@ -0,0 +1,248 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+// Computed multipliers below this are treated as no surge
+const minSurgeMultiplier = 1.05
+
+const surgeEngineLockKey = "surge:engine:lock"
+
+// SurgeEngine periodically recomputes surge per geohash cell from the ratio
+// of rides waiting for a driver to online drivers in the cell, and writes the
+// result as computed SurgePricingZone rows that expire on their own if the
+// engine stops.
+type SurgeEngine struct {
+	repo Repository
+	cfg  config.SurgeConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSurgeEngine(repo Repository, cfg config.SurgeConfig) *SurgeEngine {
+	return &SurgeEngine{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// Start launches the recompute loop unless the engine is disabled
+func (e *SurgeEngine) Start() {
+	if e.cfg.Disabled {
+		logger.Info("surge engine disabled")
+		return
+	}
+
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+
+	e.wg.Add(1)
+	go e.run()
+
+	logger.Info("surge engine started",
+		"interval", e.cfg.Interval,
+		"precision", e.cfg.GeohashPrecision,
+		"maxMultiplier", e.cfg.MaxMultiplier,
+	)
+}
+
+func (e *SurgeEngine) Stop() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	e.wg.Wait()
+	logger.Info("surge engine stopped")
+}
+
+func (e *SurgeEngine) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Recompute(e.ctx); err != nil && e.ctx.Err() == nil {
+			logger.Error("surge recompute failed", "error", err)
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Recompute runs one pass over all cells with demand or a live computed zone.
+// Only one instance does the work per interval.
+func (e *SurgeEngine) Recompute(ctx context.Context) error {
+	acquired, err := cache.SetNX(ctx, surgeEngineLockKey, "1", e.cfg.Interval*4/5)
+	if err != nil {
+		return fmt.Errorf("failed to acquire surge lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+
+	pickups, err := e.repo.FindSearchingRidePickups(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load demand: %w", err)
+	}
+	drivers, err := e.repo.FindAvailableDriverPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load supply: %w", err)
+	}
+	existing, err := e.repo.GetComputedSurgeZones(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load computed zones: %w", err)
+	}
+	cities, err := e.repo.GetSurgeCitySettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load city settings: %w", err)
+	}
+
+	demand := e.countByCell(pickups)
+	supply := e.countByCell(drivers)
+
+	previous := make(map[string]*models.SurgePricingZone, len(existing))
+	for _, zone := range existing {
+		previous[zone.AreaGeohash] = zone
+	}
+
+	// Cells with demand, plus cells that are surging now so they can decay
+	cells := make(map[string]struct{}, len(demand)+len(previous))
+	for cell := range demand {
+		cells[cell] = struct{}{}
+	}
+	for cell := range previous {
+		cells[cell] = struct{}{}
+	}
+
+	now := time.Now()
+	var ended []string
+	surging := 0
+
+	for cell := range cells {
+		minLat, maxLat, minLon, maxLon := location.DecodeBounds(cell)
+		centerLat, centerLon := (minLat+maxLat)/2, (minLon+maxLon)/2
+
+		maxMultiplier := e.cfg.MaxMultiplier
+		city := findSurgeCity(cities, centerLat, centerLon)
+		if city != nil && city.MaxMultiplier != nil {
+			maxMultiplier = math.Min(maxMultiplier, *city.MaxMultiplier)
+		}
+
+		multiplier := 1.0
+		if city == nil || city.Enabled {
+			prev := 1.0
+			if zone, ok := previous[cell]; ok {
+				prev = zone.Multiplier
+			}
+			target := e.targetMultiplier(demand[cell], supply[cell], maxMultiplier)
+			multiplier = smoothMultiplier(prev, target, e.cfg.Smoothing, maxMultiplier)
+		}
+
+		if multiplier < minSurgeMultiplier {
+			if _, ok := previous[cell]; ok {
+				ended = append(ended, cell)
+			}
+			continue
+		}
+
+		areaName := fmt.Sprintf("Auto %s", cell)
+		if city != nil {
+			areaName = fmt.Sprintf("%s %s", city.City, cell)
+		}
+
+		zone := &models.SurgePricingZone{
+			AreaName:    areaName,
+			AreaGeohash: cell,
+			CenterLat:   centerLat,
+			CenterLon:   centerLon,
+			// Circle around the cell; overlaps with neighbours resolve to the max
+			RadiusKm:    math.Ceil(location.HaversineDistance(centerLat, centerLon, maxLat, maxLon)*100) / 100,
+			Multiplier:  multiplier,
+			ActiveFrom:  now,
+			ActiveUntil: now.Add(e.cfg.ZoneTTL),
+			IsActive:    true,
+			DemandCount: demand[cell],
+			SupplyCount: supply[cell],
+		}
+		if err := e.repo.UpsertComputedSurgeZone(ctx, zone); err != nil {
+			logger.Error("failed to write surge zone", "error", err, "geohash", cell)
+			continue
+		}
+		surging++
+	}
+
+	if err := e.repo.DeactivateComputedSurgeZones(ctx, ended); err != nil {
+		return fmt.Errorf("failed to end surge zones: %w", err)
+	}
+
+	cache.Delete(ctx, "surge:zones:active")
+
+	logger.Info("surge recomputed",
+		"openRequests", len(pickups),
+		"availableDrivers", len(drivers),
+		"cells", len(cells),
+		"surging", surging,
+		"ended", len(ended),
+	)
+
+	return nil
+}
+
+func (e *SurgeEngine) countByCell(points []location.Point) map[string]int {
+	counts := make(map[string]int)
+	for _, p := range points {
+		counts[location.Encode(p.Latitude, p.Longitude, e.cfg.GeohashPrecision)]++
+	}
+	return counts
+}
+
+// targetMultiplier is the un-smoothed surge for a cell: 1.0 until demand
+// outruns supply by RatioThreshold, then rising linearly with the ratio
+func (e *SurgeEngine) targetMultiplier(demand, supply int, maxMultiplier float64) float64 {
+	if demand < e.cfg.MinDemand {
+		return 1.0
+	}
+
+	ratio := float64(demand) / math.Max(float64(supply), 1)
+	if ratio <= e.cfg.RatioThreshold {
+		return 1.0
+	}
+
+	return math.Min(1+e.cfg.Sensitivity*(ratio-e.cfg.RatioThreshold), maxMultiplier)
+}
+
+// smoothMultiplier moves from the previous multiplier towards the target so
+// prices do not jump between runs, then snaps to 0.1 steps within the cap.
+// Falling values round down so a decaying cell always reaches 1.0; rising
+// values move at least one step, up to the rounded target, so a small
+// smoothing factor cannot keep a surge from ever starting.
+func smoothMultiplier(prev, target, smoothing, maxMultiplier float64) float64 {
+	m := prev + smoothing*(target-prev)
+	switch {
+	case target < prev:
+		m = math.Floor(m*10) / 10
+	case target > prev:
+		m = math.Max(math.Round(m*10), math.Round(prev*10)+1) / 10
+		m = math.Min(m, math.Round(target*10)/10)
+	default:
+		m = math.Round(m*10) / 10
+	}
+	return math.Max(1.0, math.Min(m, maxMultiplier))
+}
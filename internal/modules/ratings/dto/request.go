@@ -5,3 +5,49 @@ type CreateRatingRequest struct {
 	Score   int     `json:"score" binding:"required,min=1,max=5"`
 	Comment *string `json:"comment" binding:"omitempty,max=500"`
 }
+
+// RateRideRequest rates the other side of a completed ride. Tags come from
+// GET /ratings/rides/tags for the caller's role.
+type RateRideRequest struct {
+	Score   int      `json:"score" binding:"required,min=1,max=5"`
+	Tags    []string `json:"tags" binding:"omitempty,max=5,dive,max=50"`
+	Comment *string  `json:"comment" binding:"omitempty,max=500"`
+}
+
+// ListLowRatedUsersRequest lists drivers or riders whose average ride rating
+// is below the review threshold
+type ListLowRatedUsersRequest struct {
+	Role  string `form:"role" binding:"required,oneof=driver rider"`
+	Page  int    `form:"page" binding:"omitempty,min=1"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (r *ListLowRatedUsersRequest) SetDefaults() {
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.Limit == 0 {
+		r.Limit = 20
+	}
+}
+
+// ListLowRideRatingsRequest lists individual ride ratings at or below
+// MaxScore, newest first
+type ListLowRideRatingsRequest struct {
+	MaxScore  int    `form:"maxScore" binding:"omitempty,min=1,max=5"`
+	RaterRole string `form:"raterRole" binding:"omitempty,oneof=driver rider"`
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (r *ListLowRideRatingsRequest) SetDefaults() {
+	if r.MaxScore == 0 {
+		r.MaxScore = 2
+	}
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.Limit == 0 {
+		r.Limit = 20
+	}
+}
@@ -27,3 +27,63 @@ func ToRatingResponse(rating *models.Rating) *RatingResponse {
 		CreatedAt:  rating.CreatedAt,
 	}
 }
+
+type RideRatingResponse struct {
+	ID        string    `json:"id"`
+	RideID    string    `json:"rideId"`
+	RaterRole string    `json:"raterRole"`
+	RaterID   string    `json:"raterId"`
+	RateeID   string    `json:"rateeId"`
+	Score     int       `json:"score"`
+	Tags      []string  `json:"tags"`
+	Comment   *string   `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func ToRideRatingResponse(rating *models.RideRating) *RideRatingResponse {
+	tags := []string(rating.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return &RideRatingResponse{
+		ID:        rating.ID,
+		RideID:    rating.RideID,
+		RaterRole: rating.RaterRole,
+		RaterID:   rating.RaterID,
+		RateeID:   rating.RateeID,
+		Score:     rating.Score,
+		Tags:      tags,
+		Comment:   rating.Comment,
+		CreatedAt: rating.CreatedAt,
+	}
+}
+
+// RideRatingTagsResponse lists the tags each side can use, keyed by rater
+// role (rider rates the driver, driver rates the rider)
+type RideRatingTagsResponse struct {
+	Rider  []string `json:"rider"`
+	Driver []string `json:"driver"`
+}
+
+// LowRatedUserResponse is a driver or rider in the review queue
+type LowRatedUserResponse struct {
+	UserID      string    `json:"userId"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	Average     float64   `json:"average"`
+	RatingCount int       `json:"ratingCount"`
+	LowCount    int       `json:"lowCount"`
+	LastRatedAt time.Time `json:"lastRatedAt"`
+}
+
+func ToLowRatedUserResponse(summary *models.RideRatingSummary, role string) *LowRatedUserResponse {
+	return &LowRatedUserResponse{
+		UserID:      summary.UserID,
+		Name:        summary.Name,
+		Role:        role,
+		Average:     summary.Average,
+		RatingCount: summary.RatingCount,
+		LowCount:    summary.LowCount,
+		LastRatedAt: summary.LastRatedAt,
+	}
+}
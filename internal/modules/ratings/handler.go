@@ -46,3 +46,107 @@ func (h *Handler) CreateRating(c *gin.Context) {
 
 	response.Success(c, rating, "Rating submitted successfully")
 }
+
+// RateRide godoc
+// @Summary Rate a completed ride
+// @Description The rider rates the driver or the driver rates the rider, once each, within the rating window after completion
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rideId path string true "Ride ID"
+// @Param request body dto.RateRideRequest true "Rating details"
+// @Success 200 {object} response.Response{data=dto.RideRatingResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /ratings/rides/{rideId} [post]
+func (h *Handler) RateRide(c *gin.Context) {
+	var req dto.RateRideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	rating, err := h.service.RateRide(c.Request.Context(), userID.(string), c.Param("rideId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, rating, "Rating submitted successfully")
+}
+
+// GetRideRatingTags godoc
+// @Summary List ride rating tags
+// @Description Tags a rider can give a driver and a driver can give a rider
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=dto.RideRatingTagsResponse}
+// @Router /ratings/rides/tags [get]
+func (h *Handler) GetRideRatingTags(c *gin.Context) {
+	response.Success(c, h.service.GetRideRatingTags(), "Rating tags retrieved successfully")
+}
+
+// ListLowRatedUsers godoc
+// @Summary Low-rated drivers or riders (Admin)
+// @Description Users whose average ride rating is below the review threshold, lowest first
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param role query string true "driver or rider"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} response.Response{data=[]dto.LowRatedUserResponse}
+// @Router /admin/ratings/low-rated [get]
+func (h *Handler) ListLowRatedUsers(c *gin.Context) {
+	var req dto.ListLowRatedUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+	req.SetDefaults()
+
+	users, total, err := h.service.ListLowRatedUsers(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := response.NewPaginationMeta(total, req.Page, req.Limit)
+	response.Paginated(c, users, pagination, "Low rated users retrieved successfully")
+}
+
+// ListLowRideRatings godoc
+// @Summary Low ride ratings (Admin)
+// @Description Individual ride ratings at or below maxScore (default 2), newest first
+// @Tags ratings
+// @Produce json
+// @Security BearerAuth
+// @Param maxScore query int false "Highest score to include"
+// @Param raterRole query string false "driver or rider"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} response.Response{data=[]dto.RideRatingResponse}
+// @Router /admin/ratings/rides [get]
+func (h *Handler) ListLowRideRatings(c *gin.Context) {
+	var req dto.ListLowRideRatingsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+	req.SetDefaults()
+
+	ratings, total, err := h.service.ListLowRideRatings(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := response.NewPaginationMeta(total, req.Page, req.Limit)
+	response.Paginated(c, ratings, pagination, "Ride ratings retrieved successfully")
+}
@@ -2,10 +2,13 @@ package ratings
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/ratings/dto"
 )
 
 type Repository interface {
@@ -14,6 +17,13 @@ type Repository interface {
 	GetProviderRatings(ctx context.Context, providerID string, limit int) ([]models.Rating, error)
 	GetProviderAverageRating(ctx context.Context, providerID string) (float64, error)
 	UpdateProviderRating(ctx context.Context, providerID string, newAverage float64) error
+
+	// Ride ratings
+	CreateRideRating(ctx context.Context, rating *models.RideRating) error
+	FindRideRating(ctx context.Context, rideID, raterRole string) (*models.RideRating, error)
+	GetRideRatingAverage(ctx context.Context, rateeID, raterRole string) (float64, error)
+	ListLowRatedUsers(ctx context.Context, raterRole string, threshold float64, minCount int, page, limit int) ([]*models.RideRatingSummary, int64, error)
+	ListLowRideRatings(ctx context.Context, req dto.ListLowRideRatingsRequest) ([]*models.RideRating, int64, error)
 }
 
 type repository struct {
@@ -60,3 +70,90 @@ func (r *repository) UpdateProviderRating(ctx context.Context, providerID string
 		Where("id = ?", providerID).
 		Update("rating", newAverage).Error
 }
+
+// ============================================================================
+// RIDE RATINGS
+// ============================================================================
+
+// errRideAlreadyRated is returned by CreateRideRating when the ride already
+// has a rating from that side, e.g. when two submissions race past the check
+var errRideAlreadyRated = errors.New("ride already rated")
+
+func (r *repository) CreateRideRating(ctx context.Context, rating *models.RideRating) error {
+	err := r.db.WithContext(ctx).Create(rating).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "uq_ride_ratings_ride_rater" {
+		return errRideAlreadyRated
+	}
+	return err
+}
+
+func (r *repository) FindRideRating(ctx context.Context, rideID, raterRole string) (*models.RideRating, error) {
+	var rating models.RideRating
+	err := r.db.WithContext(ctx).
+		Where("ride_id = ? AND rater_role = ?", rideID, raterRole).
+		First(&rating).Error
+	return &rating, err
+}
+
+// GetRideRatingAverage averages the ratings rateeID received from raterRole.
+// A user nobody has rated yet keeps the 5.0 they start with.
+func (r *repository) GetRideRatingAverage(ctx context.Context, rateeID, raterRole string) (float64, error) {
+	var avg float64
+	err := r.db.WithContext(ctx).
+		Model(&models.RideRating{}).
+		Where("ratee_id = ? AND rater_role = ?", rateeID, raterRole).
+		Select("COALESCE(AVG(score), 5.0)").
+		Scan(&avg).Error
+	return avg, err
+}
+
+// ListLowRatedUsers summarises the users rated by raterRole whose average is
+// below threshold over at least minCount ratings, lowest average first
+func (r *repository) ListLowRatedUsers(ctx context.Context, raterRole string, threshold float64, minCount int, page, limit int) ([]*models.RideRatingSummary, int64, error) {
+	summaries := r.db.WithContext(ctx).
+		Table("ride_ratings rr").
+		Select(`rr.ratee_id AS user_id, u.name AS name,
+			AVG(rr.score) AS average,
+			COUNT(*) AS rating_count,
+			COUNT(*) FILTER (WHERE rr.score <= 2) AS low_count,
+			MAX(rr.created_at) AS last_rated_at`).
+		Joins("JOIN users u ON u.id = rr.ratee_id").
+		Where("rr.rater_role = ?", raterRole).
+		Group("rr.ratee_id, u.name").
+		Having("COUNT(*) >= ? AND AVG(rr.score) < ?", minCount, threshold)
+
+	var total int64
+	if err := r.db.WithContext(ctx).Table("(?) AS summaries", summaries).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var result []*models.RideRatingSummary
+	err := summaries.
+		Order("average ASC, rating_count DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&result).Error
+	return result, total, err
+}
+
+func (r *repository) ListLowRideRatings(ctx context.Context, req dto.ListLowRideRatingsRequest) ([]*models.RideRating, int64, error) {
+	var ratings []*models.RideRating
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.RideRating{}).Where("score <= ?", req.MaxScore)
+	if req.RaterRole != "" {
+		query = query.Where("rater_role = ?", req.RaterRole)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&ratings).Error
+	return ratings, total, err
+}
@@ -2,12 +2,28 @@ package ratings
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
 	ratings := router.Group("/ratings")
 	ratings.Use(authMiddleware)
 	{
 		ratings.POST("", handler.CreateRating)
+
+		// Rides (rider and driver rate each other)
+		ratings.GET("/rides/tags", handler.GetRideRatingTags)
+		ratings.POST("/rides/:rideId", handler.RateRide)
+	}
+
+	// Review queues
+	admin := router.Group("/admin/ratings")
+	admin.Use(authMiddleware)
+	admin.Use(middleware.RequirePermission(checker, models.PermissionRatingsReview))
+	{
+		admin.GET("/low-rated", handler.ListLowRatedUsers)
+		admin.GET("/rides", handler.ListLowRideRatings)
 	}
 }
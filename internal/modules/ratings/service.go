@@ -2,30 +2,61 @@ package ratings
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/umar5678/go-backend/internal/config"
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/drivers"
 	"github.com/umar5678/go-backend/internal/modules/homeservices"
 	"github.com/umar5678/go-backend/internal/modules/ratings/dto"
+	"github.com/umar5678/go-backend/internal/modules/riders"
+	"github.com/umar5678/go-backend/internal/modules/rides"
+	"github.com/umar5678/go-backend/internal/services/cache"
 	"github.com/umar5678/go-backend/internal/utils/logger"
 	"github.com/umar5678/go-backend/internal/utils/response"
 )
 
 type Service interface {
 	CreateRating(ctx context.Context, userID string, req dto.CreateRatingRequest) (*dto.RatingResponse, error)
+
+	// Ride ratings
+	RateRide(ctx context.Context, userID, rideID string, req dto.RateRideRequest) (*dto.RideRatingResponse, error)
+	GetRideRatingTags() *dto.RideRatingTagsResponse
+
+	// Admin review queues
+	ListLowRatedUsers(ctx context.Context, req dto.ListLowRatedUsersRequest) ([]*dto.LowRatedUserResponse, int64, error)
+	ListLowRideRatings(ctx context.Context, req dto.ListLowRideRatingsRequest) ([]*dto.RideRatingResponse, int64, error)
 }
 
 type service struct {
 	repo             Repository
 	homeServicesRepo homeservices.Repository
+	ridesRepo        rides.Repository
+	driversRepo      drivers.Repository
+	ridersService    riders.Service
+	cfg              config.RatingsConfig
 }
 
-func NewService(repo Repository, homeServicesRepo homeservices.Repository) Service {
+func NewService(
+	repo Repository,
+	homeServicesRepo homeservices.Repository,
+	ridesRepo rides.Repository,
+	driversRepo drivers.Repository,
+	ridersService riders.Service,
+	cfg config.RatingsConfig,
+) Service {
 	return &service{
 		repo:             repo,
 		homeServicesRepo: homeServicesRepo,
+		ridesRepo:        ridesRepo,
+		driversRepo:      driversRepo,
+		ridersService:    ridersService,
+		cfg:              cfg,
 	}
 }
 
@@ -84,3 +115,162 @@ func (s *service) CreateRating(ctx context.Context, userID string, req dto.Creat
 
 	return dto.ToRatingResponse(rating), nil
 }
+
+// RateRide records the caller's rating of the other side of a completed ride
+// and recomputes the ratee's average on their driver or rider profile
+func (s *service) RateRide(ctx context.Context, userID, rideID string, req dto.RateRideRequest) (*dto.RideRatingResponse, error) {
+	ride, err := s.ridesRepo.FindRideByID(ctx, rideID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, response.NotFoundError("Ride")
+		}
+		return nil, response.InternalServerError("Failed to fetch ride", err)
+	}
+
+	if ride.DriverID == nil {
+		return nil, response.BadRequest("No driver assigned to this ride")
+	}
+	driver, err := s.driversRepo.FindDriverByID(ctx, *ride.DriverID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch driver", err)
+	}
+
+	// 1. The caller rates the other side
+	var raterRole, rateeID string
+	switch userID {
+	case ride.RiderID:
+		raterRole, rateeID = models.RideRatingByRider, driver.UserID
+	case driver.UserID:
+		raterRole, rateeID = models.RideRatingByDriver, ride.RiderID
+	default:
+		return nil, response.ForbiddenError("You don't have access to this ride")
+	}
+
+	// 2. Only completed rides, within the rating window
+	if ride.Status != "completed" || ride.CompletedAt == nil {
+		return nil, response.BadRequest("Can only rate completed rides")
+	}
+	if time.Since(*ride.CompletedAt) > s.cfg.RideWindow {
+		return nil, response.BadRequest("The rating window for this ride has closed")
+	}
+
+	// 3. Tags must be ones the caller's side can use
+	tags := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		if !models.IsRideRatingTag(raterRole, tag) {
+			return nil, response.BadRequest(fmt.Sprintf("Unknown tag %q", tag))
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	// 4. One rating per side
+	if _, err := s.repo.FindRideRating(ctx, rideID, raterRole); err == nil {
+		return nil, response.ConflictError("Ride already rated")
+	}
+
+	rating := &models.RideRating{
+		ID:        uuid.New().String(),
+		RideID:    rideID,
+		RaterRole: raterRole,
+		RaterID:   userID,
+		RateeID:   rateeID,
+		Score:     req.Score,
+		Tags:      tags,
+		Comment:   req.Comment,
+	}
+
+	if err := s.repo.CreateRideRating(ctx, rating); err != nil {
+		if errors.Is(err, errRideAlreadyRated) {
+			return nil, response.ConflictError("Ride already rated")
+		}
+		logger.Error("failed to create ride rating", "error", err, "rideID", rideID)
+		return nil, response.InternalServerError("Failed to create rating", err)
+	}
+
+	// 5. Recompute the ratee's average; dispatch ranking reads the driver's
+	s.updateAverage(ctx, raterRole, rateeID, driver)
+
+	logger.Info("ride rated",
+		"ratingID", rating.ID,
+		"rideID", rideID,
+		"raterRole", raterRole,
+		"score", req.Score,
+		"tags", len(tags),
+	)
+
+	return dto.ToRideRatingResponse(rating), nil
+}
+
+// updateAverage stores the ratee's new average on their profile. A failure
+// is logged; the next rating recomputes it from scratch.
+func (s *service) updateAverage(ctx context.Context, raterRole, rateeID string, driver *models.DriverProfile) {
+	average, err := s.repo.GetRideRatingAverage(ctx, rateeID, raterRole)
+	if err != nil {
+		logger.Error("failed to compute rating average", "error", err, "userID", rateeID)
+		return
+	}
+
+	if raterRole == models.RideRatingByDriver {
+		if err := s.ridersService.UpdateRating(ctx, rateeID, average); err != nil {
+			logger.Error("failed to update rider rating", "error", err, "userID", rateeID)
+		}
+		return
+	}
+
+	if err := s.driversRepo.UpdateRating(ctx, driver.ID, average); err != nil {
+		logger.Error("failed to update driver rating", "error", err, "driverID", driver.ID)
+		return
+	}
+	cache.Delete(ctx, fmt.Sprintf("driver:profile:%s", driver.UserID))
+}
+
+func (s *service) GetRideRatingTags() *dto.RideRatingTagsResponse {
+	return &dto.RideRatingTagsResponse{
+		Rider:  models.RideRatingTags[models.RideRatingByRider],
+		Driver: models.RideRatingTags[models.RideRatingByDriver],
+	}
+}
+
+// ListLowRatedUsers is the review queue of drivers or riders whose average is
+// below the configured threshold over enough ratings
+func (s *service) ListLowRatedUsers(ctx context.Context, req dto.ListLowRatedUsersRequest) ([]*dto.LowRatedUserResponse, int64, error) {
+	req.SetDefaults()
+
+	// Drivers are rated by riders and riders by drivers
+	raterRole := models.RideRatingByRider
+	if req.Role == "rider" {
+		raterRole = models.RideRatingByDriver
+	}
+
+	summaries, total, err := s.repo.ListLowRatedUsers(ctx, raterRole, s.cfg.LowThreshold, s.cfg.MinCount, req.Page, req.Limit)
+	if err != nil {
+		return nil, 0, response.InternalServerError("Failed to list low rated users", err)
+	}
+
+	result := make([]*dto.LowRatedUserResponse, len(summaries))
+	for i, summary := range summaries {
+		result[i] = dto.ToLowRatedUserResponse(summary, req.Role)
+	}
+	return result, total, nil
+}
+
+// ListLowRideRatings is the review queue of individual low scores, with
+// their tags and comments
+func (s *service) ListLowRideRatings(ctx context.Context, req dto.ListLowRideRatingsRequest) ([]*dto.RideRatingResponse, int64, error) {
+	req.SetDefaults()
+
+	ratings, total, err := s.repo.ListLowRideRatings(ctx, req)
+	if err != nil {
+		return nil, 0, response.InternalServerError("Failed to list ride ratings", err)
+	}
+
+	result := make([]*dto.RideRatingResponse, len(ratings))
+	for i, rating := range ratings {
+		result[i] = dto.ToRideRatingResponse(rating)
+	}
+	return result, total, nil
+}
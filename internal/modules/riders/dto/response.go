@@ -6,6 +6,7 @@ import (
 	"github.com/umar5678/go-backend/internal/models"
 	authdto "github.com/umar5678/go-backend/internal/modules/auth/dto"
 	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 type AddressResponse struct {
@@ -29,10 +30,10 @@ type RiderProfileResponse struct {
 }
 
 type RiderStatsResponse struct {
-	TotalRides    int     `json:"totalRides"`
-	Rating        float64 `json:"rating"`
-	WalletBalance float64 `json:"walletBalance"`
-	MemberSince   string  `json:"memberSince"`
+	TotalRides    int          `json:"totalRides"`
+	Rating        float64      `json:"rating"`
+	WalletBalance money.Amount `json:"walletBalance"`
+	MemberSince   string       `json:"memberSince"`
 }
 
 func ToAddressResponse(addr *models.Address) *AddressResponse {
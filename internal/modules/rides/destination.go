@@ -0,0 +1,333 @@
+package rides
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/models"
+	pricingdto "github.com/umar5678/go-backend/internal/modules/pricing/dto"
+	"github.com/umar5678/go-backend/internal/modules/rides/dto"
+	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"github.com/umar5678/go-backend/internal/websocket"
+	"gorm.io/gorm"
+)
+
+// A rider can move the dropoff of a started ride. The trip is repriced from
+// where the driver is now, at the ride's booked surge, and the change waits
+// in Redis until the driver accepts or declines it over the websocket. On
+// accept the wallet hold grows to cover the new fare before the ride is
+// updated.
+
+// destinationChangeTimeout is how long the driver has to answer
+const destinationChangeTimeout = 60 * time.Second
+
+// pendingDestinationChange is a change waiting for the driver
+type pendingDestinationChange struct {
+	ID                string    `json:"id"`
+	DropoffLat        float64   `json:"dropoffLat"`
+	DropoffLon        float64   `json:"dropoffLon"`
+	DropoffAddress    string    `json:"dropoffAddress"`
+	EstimatedDistance float64   `json:"estimatedDistance"`
+	EstimatedDuration int       `json:"estimatedDuration"`
+	EstimatedFare     float64   `json:"estimatedFare"`
+	AirportFee        float64   `json:"airportFee"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+func destinationChangeKey(rideID string) string {
+	return fmt.Sprintf("ride:destination:pending:%s", rideID)
+}
+
+func (s *service) ChangeDestination(ctx context.Context, riderID, rideID string, req dto.ChangeDestinationRequest) (*dto.DestinationChangeResponse, error) {
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return nil, response.NotFoundError("Ride")
+	}
+	if ride.RiderID != riderID {
+		return nil, response.ForbiddenError("Not authorized to change this ride")
+	}
+	if ride.Status != "started" || ride.DriverID == nil {
+		return nil, response.BadRequest("Destination can only be changed during the trip")
+	}
+	if req.DropoffLat == ride.DropoffLat && req.DropoffLon == ride.DropoffLon {
+		return nil, response.BadRequest("New destination is the same as the current one")
+	}
+
+	driverLocation, err := s.trackingService.GetDriverLocation(ctx, *ride.DriverID)
+	if err != nil {
+		return nil, response.ServiceUnavailable("Driver location is not available. Please try again.")
+	}
+
+	// Price the trip as driven so far, then from the driver's position
+	// through the remaining stops to the new dropoff
+	var reached, pending []models.RideStop
+	for _, stop := range ride.Stops {
+		if stop.Reached() {
+			reached = append(reached, stop)
+		} else {
+			pending = append(pending, stop)
+		}
+	}
+	points := toPricingStops(reached)
+	points = append(points, pricingdto.StopPoint{Lat: driverLocation.Latitude, Lon: driverLocation.Longitude})
+	points = append(points, toPricingStops(pending)...)
+
+	fare, err := s.pricingService.EstimateTripFare(ctx, pricingdto.FareEstimateRequest{
+		PickupLat:     ride.PickupLat,
+		PickupLon:     ride.PickupLon,
+		DropoffLat:    req.DropoffLat,
+		DropoffLon:    req.DropoffLon,
+		VehicleTypeID: ride.VehicleTypeID,
+		Stops:         points,
+	}, ride.SurgeMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fail early if the rider could not cover a larger hold; the hold
+	// itself only changes once the driver accepts
+	holdAmount, err := s.rideHoldAmount(ctx, ride)
+	if err != nil {
+		return nil, err
+	}
+	if extra := fare.TotalFare.Float64() - holdAmount; extra > 0 {
+		wallet, err := s.walletService.GetWallet(ctx, riderID)
+		if err != nil {
+			return nil, response.InternalServerError("Failed to check wallet balance", err)
+		}
+		if wallet.AvailableBalance.Float64() < extra {
+			return nil, response.BadRequest("Insufficient wallet balance for the new destination. Please add funds.")
+		}
+	}
+
+	change := pendingDestinationChange{
+		ID:                uuid.New().String(),
+		DropoffLat:        req.DropoffLat,
+		DropoffLon:        req.DropoffLon,
+		DropoffAddress:    req.DropoffAddress,
+		EstimatedDistance: fare.EstimatedDistance,
+		EstimatedDuration: fare.EstimatedDuration,
+		EstimatedFare:     fare.TotalFare.Float64(),
+		AirportFee:        fare.AirportFee.Float64(),
+		ExpiresAt:         time.Now().Add(destinationChangeTimeout).UTC(),
+	}
+	data, err := json.Marshal(change)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to save destination change", err)
+	}
+
+	stored, err := cache.SetNX(ctx, destinationChangeKey(rideID), data, destinationChangeTimeout)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to save destination change", err)
+	}
+	if !stored {
+		return nil, response.BadRequest("A destination change is already waiting for the driver")
+	}
+
+	s.wsHelper.SendTripEvent(ctx, "", s.driverUserID(ctx, ride), websocket.TypeRideDestinationChangeRequested, map[string]interface{}{
+		"rideId":            rideID,
+		"changeId":          change.ID,
+		"dropoffLat":        change.DropoffLat,
+		"dropoffLon":        change.DropoffLon,
+		"dropoffAddress":    change.DropoffAddress,
+		"estimatedDistance": change.EstimatedDistance,
+		"estimatedDuration": change.EstimatedDuration,
+		"estimatedFare":     change.EstimatedFare,
+		"expiresAt":         change.ExpiresAt,
+	})
+
+	logger.Info("ride destination change requested",
+		"rideID", rideID,
+		"riderID", riderID,
+		"changeID", change.ID,
+		"estimatedFare", change.EstimatedFare,
+		"previousFare", ride.EstimatedFare,
+	)
+
+	return &dto.DestinationChangeResponse{
+		ChangeID:       change.ID,
+		Status:         "pending",
+		DropoffLat:     change.DropoffLat,
+		DropoffLon:     change.DropoffLon,
+		DropoffAddress: change.DropoffAddress,
+		Fare:           fare,
+		ExpiresAt:      change.ExpiresAt,
+	}, nil
+}
+
+// RespondDestinationChange applies or drops a pending destination change on
+// the driver's answer. Only the first answer counts.
+func (s *service) RespondDestinationChange(ctx context.Context, userID, rideID, changeID string, accept bool) (*dto.RideResponse, error) {
+	driver, err := s.driversRepo.FindDriverByUserID(ctx, userID)
+	if err != nil {
+		return nil, response.NotFoundError("Driver profile not found")
+	}
+
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return nil, response.NotFoundError("Ride")
+	}
+	if ride.DriverID == nil || *ride.DriverID != driver.ID {
+		return nil, response.ForbiddenError("Not authorized")
+	}
+
+	// Claim the change so a repeated answer cannot apply it twice
+	var change pendingDestinationChange
+	key := destinationChangeKey(rideID)
+	if err := cache.TakeJSON(ctx, key, &change); err != nil {
+		return nil, response.BadRequest("Destination change has expired or was already answered")
+	}
+	if change.ID != changeID {
+		// An answer to an older change; put the current one back
+		if ttl := time.Until(change.ExpiresAt); ttl > 0 {
+			if data, err := json.Marshal(change); err == nil {
+				cache.SetNX(ctx, key, data, ttl)
+			}
+		}
+		return nil, response.BadRequest("Destination change has expired or was already answered")
+	}
+
+	if !accept {
+		s.rejectDestinationChange(ctx, ride.RiderID, rideID, change.ID, "Your driver declined the new destination")
+		logger.Info("ride destination change declined", "rideID", rideID, "changeID", change.ID, "driverID", driver.ID)
+		return dto.ToRideResponse(ride), nil
+	}
+
+	if ride.Status != "started" {
+		s.rejectDestinationChange(ctx, ride.RiderID, rideID, change.ID, "The trip is no longer in progress")
+		return nil, response.BadRequest("Ride is no longer in progress")
+	}
+
+	updates := map[string]interface{}{
+		"estimated_distance": change.EstimatedDistance,
+		"estimated_duration": change.EstimatedDuration,
+		"estimated_fare":     change.EstimatedFare,
+		"airport_fee":        change.AirportFee,
+	}
+
+	holdAmount, err := s.rideHoldAmount(ctx, ride)
+	if err != nil {
+		s.rejectDestinationChange(ctx, ride.RiderID, rideID, change.ID, "The new destination could not be applied")
+		return nil, err
+	}
+	var replacedHoldID string
+	if change.EstimatedFare > holdAmount {
+		if replacedHoldID, err = s.coverDestinationFare(ctx, ride, change.EstimatedFare, change.EstimatedDuration, updates); err != nil {
+			s.rejectDestinationChange(ctx, ride.RiderID, rideID, change.ID, "Insufficient wallet balance for the new destination")
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateDropoff(ctx, rideID, change.DropoffLat, change.DropoffLon, change.DropoffAddress, updates); err != nil {
+		// A larger hold is harmless: capture takes the actual fare and
+		// releases the rest. A new hold the ride never pointed at is not.
+		if newHoldID, ok := updates["wallet_hold_id"].(string); ok {
+			s.walletService.ReleaseHold(ctx, ride.RiderID, walletdto.ReleaseHoldRequest{HoldID: newHoldID})
+		}
+		s.rejectDestinationChange(ctx, ride.RiderID, rideID, change.ID, "The new destination could not be applied")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.BadRequest("Ride is no longer in progress")
+		}
+		return nil, response.InternalServerError("Failed to change destination", err)
+	}
+
+	if replacedHoldID != "" {
+		if err := s.walletService.ReleaseHold(ctx, ride.RiderID, walletdto.ReleaseHoldRequest{HoldID: replacedHoldID}); err != nil {
+			logger.Error("failed to release replaced hold", "error", err, "rideID", rideID, "holdID", replacedHoldID)
+		}
+	}
+
+	cache.Delete(ctx, fmt.Sprintf("ride:active:%s", rideID))
+
+	ride, _ = s.repo.FindRideByID(ctx, rideID)
+
+	s.wsHelper.SendTripEvent(ctx, ride.RiderID, userID, websocket.TypeRideDestinationChanged, map[string]interface{}{
+		"rideId":            rideID,
+		"changeId":          change.ID,
+		"dropoffLat":        ride.DropoffLat,
+		"dropoffLon":        ride.DropoffLon,
+		"dropoffAddress":    ride.DropoffAddress,
+		"estimatedDistance": ride.EstimatedDistance,
+		"estimatedDuration": ride.EstimatedDuration,
+		"estimatedFare":     ride.EstimatedFare,
+		"message":           "Destination updated",
+	})
+
+	logger.Info("ride destination changed",
+		"rideID", rideID,
+		"changeID", change.ID,
+		"driverID", driver.ID,
+		"estimatedFare", ride.EstimatedFare,
+	)
+
+	return dto.ToRideResponse(ride), nil
+}
+
+// rideHoldAmount returns the amount currently held for the ride, or 0 if it
+// has no live hold
+func (s *service) rideHoldAmount(ctx context.Context, ride *models.Ride) (float64, error) {
+	if ride.WalletHoldID == nil {
+		return 0, nil
+	}
+
+	holds, err := s.walletService.GetHoldsByReference(ctx, "ride", ride.ID)
+	if err != nil {
+		return 0, response.InternalServerError("Failed to load ride hold", err)
+	}
+	for _, hold := range holds {
+		if hold.ID == *ride.WalletHoldID && hold.Status == models.TransactionStatusHeld {
+			return hold.Amount.Float64(), nil
+		}
+	}
+	return 0, nil
+}
+
+// coverDestinationFare grows the ride's hold to fare. If that fails (e.g.
+// the hold lapsed) it places a new hold for the whole fare, records it in
+// updates and returns the ID of the hold it replaces, which the caller
+// releases once the ride points at the new one.
+func (s *service) coverDestinationFare(ctx context.Context, ride *models.Ride, fare float64, tripSeconds int, updates map[string]interface{}) (string, error) {
+	amount := money.FromFloat(fare, money.DefaultCurrency, money.HalfUp)
+	if ride.WalletHoldID != nil {
+		_, err := s.walletService.IncreaseHold(ctx, ride.RiderID, walletdto.IncreaseHoldRequest{
+			HoldID: *ride.WalletHoldID,
+			Amount: amount,
+		})
+		if err == nil {
+			return "", nil
+		}
+		logger.Warn("failed to increase ride hold, placing a new one", "error", err, "rideID", ride.ID, "holdID", *ride.WalletHoldID)
+	}
+
+	hold, err := s.walletService.HoldFunds(ctx, ride.RiderID, walletdto.HoldFundsRequest{
+		Amount:        amount,
+		ReferenceType: "ride",
+		ReferenceID:   ride.ID,
+		HoldDuration:  tripHoldMinutes(tripSeconds),
+	})
+	if err != nil {
+		return "", response.BadRequest("Rider has insufficient wallet balance for the new destination")
+	}
+	updates["wallet_hold_id"] = hold.ID
+
+	if ride.WalletHoldID != nil {
+		return *ride.WalletHoldID, nil
+	}
+	return "", nil
+}
+
+func (s *service) rejectDestinationChange(ctx context.Context, riderID, rideID, changeID, message string) {
+	s.wsHelper.SendTripEvent(ctx, riderID, "", websocket.TypeRideDestinationChangeRejected, map[string]interface{}{
+		"rideId":   rideID,
+		"changeId": changeID,
+		"message":  message,
+	})
+}
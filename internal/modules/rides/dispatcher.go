@@ -0,0 +1,281 @@
+package rides
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"gorm.io/gorm"
+)
+
+// Dispatcher runs driver searches from the ride_dispatch_jobs table.
+//
+// Each worker claims a job with a lease and keeps the lease alive while the
+// search runs. If the process dies mid-search the lease expires and any
+// instance picks the job up again, so searches survive restarts and deploys.
+type Dispatcher struct {
+	repo     Repository
+	service  Service
+	cfg      config.DispatchConfig
+	workerID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(repo Repository, service Service, cfg config.DispatchConfig) *Dispatcher {
+	hostname, _ := os.Hostname()
+
+	return &Dispatcher{
+		repo:     repo,
+		service:  service,
+		cfg:      cfg,
+		workerID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+// Start resumes in-flight searches and launches the worker pool
+func (d *Dispatcher) Start() {
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	// Searching rides without a job (created before the dispatcher existed,
+	// or whose job insert failed) get one now. Jobs left running by a dead
+	// instance are reclaimed by the workers once their lease expires.
+	created, err := d.repo.EnqueueOrphanedSearchingRides(d.ctx, d.cfg.MaxAttempts)
+	if err != nil {
+		logger.Error("failed to enqueue orphaned searching rides", "error", err)
+	} else if created > 0 {
+		logger.Info("resumed orphaned searching rides", "count", created)
+	}
+
+	for i := 0; i < d.cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.runWorker(i)
+	}
+
+	logger.Info("ride dispatcher started",
+		"workerID", d.workerID,
+		"workers", d.cfg.Workers,
+		"pollInterval", d.cfg.PollInterval,
+		"lease", d.cfg.LeaseDuration,
+	)
+}
+
+// Stop cancels in-flight searches, hands their jobs back to the queue and
+// waits for all workers to exit
+func (d *Dispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+	logger.Info("ride dispatcher stopped", "workerID", d.workerID)
+}
+
+func (d *Dispatcher) runWorker(index int) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything that is due before going back to sleep
+		for d.ctx.Err() == nil {
+			jobs, err := d.repo.ClaimDispatchJobs(d.ctx, d.workerID, d.cfg.LeaseDuration, 1)
+			if err != nil {
+				if d.ctx.Err() == nil {
+					logger.Error("failed to claim dispatch jobs", "error", err, "worker", index)
+				}
+				break
+			}
+			if len(jobs) == 0 {
+				break
+			}
+			d.process(jobs[0])
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) process(job *models.RideDispatchJob) {
+	jobCtx, cancelJob := context.WithCancel(d.ctx)
+	defer cancelJob()
+
+	logger.Info("dispatch job claimed",
+		"jobID", job.ID,
+		"rideID", job.RideID,
+		"attempt", job.Attempts,
+		"workerID", d.workerID,
+	)
+
+	var leaseLost atomic.Bool
+	heartbeatDone := make(chan struct{})
+	go d.heartbeat(jobCtx, cancelJob, job, &leaseLost, heartbeatDone)
+
+	searchErr := d.service.FindDriverForRide(jobCtx, job.RideID)
+	cancelJob()
+	<-heartbeatDone
+
+	if leaseLost.Load() {
+		// Another worker owns the job now; leave it alone
+		return
+	}
+
+	// Everything below must run even while shutting down
+	ctx := context.Background()
+
+	if d.ctx.Err() != nil {
+		// Shutting down: give the job back so another instance resumes it
+		// straight away instead of waiting for the lease to expire
+		d.updateJob(ctx, job.ID, map[string]interface{}{
+			"status":       models.DispatchStatusPending,
+			"attempts":     gorm.Expr("GREATEST(attempts - 1, 0)"),
+			"locked_by":    nil,
+			"locked_until": nil,
+			"next_run_at":  time.Now(),
+		})
+		logger.Info("dispatch job released on shutdown", "jobID", job.ID, "rideID", job.RideID)
+		return
+	}
+
+	ride, err := d.repo.FindRideByID(ctx, job.RideID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			d.finish(ctx, job, models.DispatchStatusCancelled, nil, "ride not found")
+			return
+		}
+		d.retryOrFail(ctx, job, err)
+		return
+	}
+
+	switch ride.Status {
+	case "searching":
+		if searchErr == nil {
+			searchErr = errors.New("search finished without assigning a driver")
+		}
+		if errors.Is(searchErr, ErrNoDriversAvailable) || errors.Is(searchErr, ErrNoDriverAccepted) {
+			d.fail(ctx, job, searchErr)
+			return
+		}
+		d.retryOrFail(ctx, job, searchErr)
+
+	case "cancelled":
+		d.finish(ctx, job, models.DispatchStatusCancelled, nil, "")
+
+	default:
+		// A driver accepted, either through this search or directly
+		d.finish(ctx, job, models.DispatchStatusCompleted, ride.DriverID, "")
+	}
+}
+
+// heartbeat keeps the job lease alive while the search runs. If the lease is
+// lost (another worker reclaimed it) the search is cancelled so the ride is
+// never dispatched twice.
+func (d *Dispatcher) heartbeat(ctx context.Context, cancelJob context.CancelFunc, job *models.RideDispatchJob, leaseLost *atomic.Bool, done chan<- struct{}) {
+	defer close(done)
+
+	interval := d.cfg.LeaseDuration / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := d.repo.ExtendDispatchLease(ctx, job.ID, d.workerID, d.cfg.LeaseDuration)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				logger.Warn("dispatch job lease lost, stopping search",
+					"jobID", job.ID,
+					"rideID", job.RideID,
+				)
+				leaseLost.Store(true)
+				cancelJob()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				logger.Error("failed to extend dispatch lease", "error", err, "jobID", job.ID)
+			}
+		}
+	}
+}
+
+// retryOrFail reschedules a job that failed for an infrastructure reason,
+// backing off linearly, until it runs out of attempts
+func (d *Dispatcher) retryOrFail(ctx context.Context, job *models.RideDispatchJob, cause error) {
+	if job.Attempts >= job.MaxAttempts {
+		d.fail(ctx, job, cause)
+		return
+	}
+
+	backoff := time.Duration(job.Attempts) * 5 * time.Second
+	d.updateJob(ctx, job.ID, map[string]interface{}{
+		"status":       models.DispatchStatusPending,
+		"last_error":   cause.Error(),
+		"locked_by":    nil,
+		"locked_until": nil,
+		"next_run_at":  time.Now().Add(backoff),
+	})
+
+	logger.Warn("dispatch job rescheduled",
+		"jobID", job.ID,
+		"rideID", job.RideID,
+		"attempt", job.Attempts,
+		"retryIn", backoff,
+		"error", cause,
+	)
+}
+
+// fail gives up on the search: the ride is cancelled and the hold released
+func (d *Dispatcher) fail(ctx context.Context, job *models.RideDispatchJob, cause error) {
+	if err := d.service.HandleDispatchFailure(ctx, job.RideID); err != nil {
+		logger.Error("failed to handle dispatch failure", "error", err, "rideID", job.RideID)
+	}
+	d.finish(ctx, job, models.DispatchStatusFailed, nil, cause.Error())
+}
+
+func (d *Dispatcher) finish(ctx context.Context, job *models.RideDispatchJob, status string, driverID *string, lastError string) {
+	updates := map[string]interface{}{
+		"status":       status,
+		"locked_by":    nil,
+		"locked_until": nil,
+		"finished_at":  time.Now(),
+	}
+	if driverID != nil {
+		updates["assigned_driver_id"] = *driverID
+	}
+	if lastError != "" {
+		updates["last_error"] = lastError
+	}
+	d.updateJob(ctx, job.ID, updates)
+
+	logger.Info("dispatch job finished",
+		"jobID", job.ID,
+		"rideID", job.RideID,
+		"status", status,
+		"attempts", job.Attempts,
+	)
+}
+
+func (d *Dispatcher) updateJob(ctx context.Context, jobID string, updates map[string]interface{}) {
+	if err := d.repo.UpdateDispatchJob(ctx, jobID, updates); err != nil {
+		logger.Error("failed to update dispatch job", "error", err, "jobID", jobID)
+	}
+}
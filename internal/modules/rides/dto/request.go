@@ -1,6 +1,9 @@
 package dto
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type CreateRideRequest struct {
 	PickupLat      float64 `json:"pickupLat" binding:"required,min=-90,max=90"`
@@ -11,6 +14,56 @@ type CreateRideRequest struct {
 	DropoffAddress string  `json:"dropoffAddress" binding:"required,max=500"`
 	VehicleTypeID  string  `json:"vehicleTypeId" binding:"required,uuid"`
 	RiderNotes     string  `json:"riderNotes" binding:"omitempty,max=500"`
+	QuoteID        string  `json:"quoteId" binding:"omitempty,max=200"` // from /pricing/estimate; locks the quoted fare
+
+	ScheduledAt *time.Time `json:"scheduledAt" binding:"omitempty"` // book ahead for this pickup time; omit to ride now
+
+	Stops []StopRequest `json:"stops" binding:"omitempty,max=3,dive"` // intermediate stops in visiting order
+}
+
+// StopRequest is an intermediate stop between pickup and dropoff
+type StopRequest struct {
+	Lat     float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lon     float64 `json:"lon" binding:"required,min=-180,max=180"`
+	Address string  `json:"address" binding:"required,max=500"`
+}
+
+// UpdateStopsRequest replaces the stops the driver has not reached yet.
+// An empty list removes them.
+type UpdateStopsRequest struct {
+	Stops []StopRequest `json:"stops" binding:"max=3,dive"`
+}
+
+// ExportRidesRequest selects the trips for a CSV export. From and To are
+// calendar dates (YYYY-MM-DD, UTC), both included.
+type ExportRidesRequest struct {
+	Role string `form:"role" binding:"required,oneof=rider driver"`
+	From string `form:"from" binding:"required,datetime=2006-01-02"`
+	To   string `form:"to" binding:"required,datetime=2006-01-02"`
+}
+
+// Range returns the export window as [from, to) instants
+func (r *ExportRidesRequest) Range() (time.Time, time.Time, error) {
+	from, err := time.Parse("2006-01-02", r.From)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid from date")
+	}
+	to, err := time.Parse("2006-01-02", r.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid to date")
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("to must not be before from")
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// ChangeDestinationRequest moves the dropoff of a started ride. The driver
+// has to accept the change before it applies.
+type ChangeDestinationRequest struct {
+	DropoffLat     float64 `json:"dropoffLat" binding:"required,min=-90,max=90"`
+	DropoffLon     float64 `json:"dropoffLon" binding:"required,min=-180,max=180"`
+	DropoffAddress string  `json:"dropoffAddress" binding:"required,max=500"`
 }
 
 func (r *CreateRideRequest) Validate() error {
@@ -23,9 +76,27 @@ func (r *CreateRideRequest) Validate() error {
 	if r.DropoffAddress == "" {
 		return errors.New("dropoff address is required")
 	}
+	return validateStops(r.Stops)
+}
+
+func (r *UpdateStopsRequest) Validate() error {
+	return validateStops(r.Stops)
+}
+
+func validateStops(stops []StopRequest) error {
+	for _, stop := range stops {
+		if stop.Address == "" {
+			return errors.New("stop address is required")
+		}
+	}
 	return nil
 }
 
+// IsScheduled reports whether the ride is booked for a later pickup
+func (r *CreateRideRequest) IsScheduled() bool {
+	return r.ScheduledAt != nil
+}
+
 type AcceptRideRequest struct {
 	RideID string `json:"rideId" binding:"required,uuid"`
 }
@@ -39,15 +110,17 @@ type CancelRideRequest struct {
 	Reason string `json:"reason" binding:"omitempty,max=500"`
 }
 
+// CompleteRideRequest carries the distance and duration measured by the driver
+// app. They are only compared against the GPS trace; fares use the GPS figures.
 type CompleteRideRequest struct {
-	ActualDistance float64 `json:"actualDistance" binding:"required,min=0"`
-	ActualDuration int     `json:"actualDuration" binding:"required,min=0"`
+	ActualDistance float64 `json:"actualDistance" binding:"omitempty,min=0"`
+	ActualDuration int     `json:"actualDuration" binding:"omitempty,min=0"`
 }
 
 type ListRidesRequest struct {
 	Page   int    `form:"page" binding:"omitempty,min=1"`
 	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
-	Status string `form:"status" binding:"omitempty,oneof=searching accepted arrived started completed cancelled"`
+	Status string `form:"status" binding:"omitempty,oneof=scheduled searching accepted arrived started completed cancelled"`
 }
 
 func (r *ListRidesRequest) SetDefaults() {
@@ -59,6 +132,20 @@ func (r *ListRidesRequest) SetDefaults() {
 	}
 }
 
+type ListScheduledRidesRequest struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (r *ListScheduledRidesRequest) SetDefaults() {
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.Limit == 0 {
+		r.Limit = 20
+	}
+}
+
 // // internal/modules/rides/dto/request.go
 // package dto
 
@@ -5,7 +5,10 @@ import (
 
 	"github.com/umar5678/go-backend/internal/models"
 	authdto "github.com/umar5678/go-backend/internal/modules/auth/dto"
+	pricingdto "github.com/umar5678/go-backend/internal/modules/pricing/dto"
 	vehicledto "github.com/umar5678/go-backend/internal/modules/vehicles/dto"
+	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 type RideResponse struct {
@@ -32,6 +35,12 @@ type RideResponse struct {
 	ActualDistance *float64 `json:"actualDistance,omitempty"`
 	ActualDuration *int     `json:"actualDuration,omitempty"`
 	ActualFare     *float64 `json:"actualFare,omitempty"`
+	Quoted         bool     `json:"quoted,omitempty"`
+	FareCapped     bool     `json:"fareCapped,omitempty"`
+	AirportFee     float64  `json:"airportFee,omitempty"` // included in the fares
+
+	RoutePolyline string `json:"routePolyline,omitempty"`
+	RouteFlagged  bool   `json:"routeFlagged,omitempty"`
 
 	SurgeMultiplier    float64 `json:"surgeMultiplier"`
 	RiderNotes         string  `json:"riderNotes,omitempty"`
@@ -39,6 +48,7 @@ type RideResponse struct {
 	CancelledBy        *string `json:"cancelledBy,omitempty"`
 
 	RequestedAt time.Time  `json:"requestedAt"`
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
 	AcceptedAt  *time.Time `json:"acceptedAt,omitempty"`
 	ArrivedAt   *time.Time `json:"arrivedAt,omitempty"`
 	StartedAt   *time.Time `json:"startedAt,omitempty"`
@@ -48,16 +58,78 @@ type RideResponse struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 
+	Stops []RideStopResponse `json:"stops,omitempty"`
+
 	DriverLocation *LocationDTO `json:"driverLocation,omitempty"`
 }
 
+type RideStopResponse struct {
+	ID         string     `json:"id"`
+	Sequence   int        `json:"sequence"`
+	Lat        float64    `json:"lat"`
+	Lon        float64    `json:"lon"`
+	Address    string     `json:"address"`
+	Status     string     `json:"status"`
+	ArrivedAt  *time.Time `json:"arrivedAt,omitempty"`
+	DepartedAt *time.Time `json:"departedAt,omitempty"`
+}
+
+// UpdateStopsResponse is the ride with its new stops and the fare repriced
+// for them, leg by leg
+type UpdateStopsResponse struct {
+	Ride *RideResponse                    `json:"ride"`
+	Fare *pricingdto.FareEstimateResponse `json:"fare"`
+}
+
+// DestinationChangeResponse is a destination change waiting for the driver.
+// It lapses at ExpiresAt if the driver does not answer.
+type DestinationChangeResponse struct {
+	ChangeID       string                           `json:"changeId"`
+	Status         string                           `json:"status"` // pending
+	DropoffLat     float64                          `json:"dropoffLat"`
+	DropoffLon     float64                          `json:"dropoffLon"`
+	DropoffAddress string                           `json:"dropoffAddress"`
+	Fare           *pricingdto.FareEstimateResponse `json:"fare"`
+	ExpiresAt      time.Time                        `json:"expiresAt"`
+}
+
+// ReceiptResponse is the rider's receipt for a completed ride, or for a
+// cancelled ride that was charged a fee
+type ReceiptResponse struct {
+	ReceiptNumber   string                            `json:"receiptNumber"`
+	RideID          string                            `json:"rideId"`
+	Status          string                            `json:"status"`
+	IssuedAt        time.Time                         `json:"issuedAt"`
+	RiderName       string                            `json:"riderName"`
+	DriverName      string                            `json:"driverName,omitempty"`
+	VehicleType     string                            `json:"vehicleType"`
+	PickupAddress   string                            `json:"pickupAddress"`
+	DropoffAddress  string                            `json:"dropoffAddress"`
+	Stops           []string                          `json:"stops,omitempty"` // addresses of the stops reached
+	RequestedAt     time.Time                         `json:"requestedAt"`
+	StartedAt       *time.Time                        `json:"startedAt,omitempty"`
+	CompletedAt     *time.Time                        `json:"completedAt,omitempty"`
+	CancelledAt     *time.Time                        `json:"cancelledAt,omitempty"`
+	DistanceKm      float64                           `json:"distanceKm"`
+	DurationSec     int                               `json:"durationSec"`
+	SurgeMultiplier float64                           `json:"surgeMultiplier"`
+	Fare            *pricingdto.FareBreakdownResponse `json:"fare,omitempty"` // completed rides
+	FareCapped      bool                              `json:"fareCapped"`
+	CancellationFee *money.Amount                     `json:"cancellationFee,omitempty"` // cancelled rides
+	Total           money.Amount                      `json:"total"`
+	Currency        string                            `json:"currency"`
+	Payments        []*walletdto.TransactionResponse  `json:"payments"` // wallet transactions for the ride
+	RoutePolyline   string                            `json:"routePolyline,omitempty"`
+}
+
 type RideListResponse struct {
-	ID             string    `json:"id"`
-	Status         string    `json:"status"`
-	PickupAddress  string    `json:"pickupAddress"`
-	DropoffAddress string    `json:"dropoffAddress"`
-	EstimatedFare  float64   `json:"estimatedFare"`
-	RequestedAt    time.Time `json:"requestedAt"`
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`
+	PickupAddress  string     `json:"pickupAddress"`
+	DropoffAddress string     `json:"dropoffAddress"`
+	EstimatedFare  float64    `json:"estimatedFare"`
+	RequestedAt    time.Time  `json:"requestedAt"`
+	ScheduledAt    *time.Time `json:"scheduledAt,omitempty"`
 }
 
 func ToRideResponse(ride *models.Ride) *RideResponse {
@@ -79,11 +151,17 @@ func ToRideResponse(ride *models.Ride) *RideResponse {
 		ActualDistance:     ride.ActualDistance,
 		ActualDuration:     ride.ActualDuration,
 		ActualFare:         ride.ActualFare,
+		Quoted:             ride.QuoteID != nil,
+		FareCapped:         ride.FareCapped,
+		AirportFee:         ride.AirportFee,
+		RoutePolyline:      ride.RoutePolyline,
+		RouteFlagged:       ride.RouteFlagged,
 		SurgeMultiplier:    ride.SurgeMultiplier,
 		RiderNotes:         ride.RiderNotes,
 		CancellationReason: ride.CancellationReason,
 		CancelledBy:        ride.CancelledBy,
 		RequestedAt:        ride.RequestedAt,
+		ScheduledAt:        ride.ScheduledAt,
 		AcceptedAt:         ride.AcceptedAt,
 		ArrivedAt:          ride.ArrivedAt,
 		StartedAt:          ride.StartedAt,
@@ -102,10 +180,26 @@ func ToRideResponse(ride *models.Ride) *RideResponse {
 	if ride.VehicleType.ID != "" {
 		resp.VehicleType = vehicledto.ToVehicleTypeResponse(&ride.VehicleType)
 	}
+	for _, stop := range ride.Stops {
+		resp.Stops = append(resp.Stops, ToRideStopResponse(&stop))
+	}
 
 	return resp
 }
 
+func ToRideStopResponse(stop *models.RideStop) RideStopResponse {
+	return RideStopResponse{
+		ID:         stop.ID,
+		Sequence:   stop.Sequence,
+		Lat:        stop.Lat,
+		Lon:        stop.Lon,
+		Address:    stop.Address,
+		Status:     stop.Status,
+		ArrivedAt:  stop.ArrivedAt,
+		DepartedAt: stop.DepartedAt,
+	}
+}
+
 func ToRideListResponse(ride *models.Ride) *RideListResponse {
 	return &RideListResponse{
 		ID:             ride.ID,
@@ -114,6 +208,7 @@ func ToRideListResponse(ride *models.Ride) *RideListResponse {
 		DropoffAddress: ride.DropoffAddress,
 		EstimatedFare:  ride.EstimatedFare,
 		RequestedAt:    ride.RequestedAt,
+		ScheduledAt:    ride.ScheduledAt,
 	}
 }
 
@@ -126,3 +221,66 @@ type LocationDTO struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 }
+
+// DispatchStateResponse describes where a ride's driver search stands
+type DispatchStateResponse struct {
+	RideID           string                    `json:"rideId"`
+	RideStatus       string                    `json:"rideStatus"`
+	JobStatus        string                    `json:"jobStatus"`
+	Attempts         int                       `json:"attempts"`
+	MaxAttempts      int                       `json:"maxAttempts"`
+	CurrentRadiusKm  float64                   `json:"currentRadiusKm"`
+	DriversContacted int                       `json:"driversContacted"`
+	AssignedDriverID *string                   `json:"assignedDriverId,omitempty"`
+	LastError        string                    `json:"lastError,omitempty"`
+	LockedBy         *string                   `json:"lockedBy,omitempty"`
+	LockedUntil      *time.Time                `json:"lockedUntil,omitempty"`
+	NextRunAt        time.Time                 `json:"nextRunAt"`
+	StartedAt        *time.Time                `json:"startedAt,omitempty"`
+	FinishedAt       *time.Time                `json:"finishedAt,omitempty"`
+	Requests         []DispatchRequestResponse `json:"requests"`
+}
+
+type DispatchRequestResponse struct {
+	ID              string     `json:"id"`
+	DriverID        string     `json:"driverId"`
+	Status          string     `json:"status"`
+	SentAt          time.Time  `json:"sentAt"`
+	RespondedAt     *time.Time `json:"respondedAt,omitempty"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	RejectionReason string     `json:"rejectionReason,omitempty"`
+}
+
+func ToDispatchStateResponse(rideStatus string, job *models.RideDispatchJob, requests []*models.RideRequest) *DispatchStateResponse {
+	resp := &DispatchStateResponse{
+		RideID:           job.RideID,
+		RideStatus:       rideStatus,
+		JobStatus:        job.Status,
+		Attempts:         job.Attempts,
+		MaxAttempts:      job.MaxAttempts,
+		CurrentRadiusKm:  job.CurrentRadiusKm,
+		DriversContacted: job.DriversContacted,
+		AssignedDriverID: job.AssignedDriverID,
+		LastError:        job.LastError,
+		LockedBy:         job.LockedBy,
+		LockedUntil:      job.LockedUntil,
+		NextRunAt:        job.NextRunAt,
+		StartedAt:        job.StartedAt,
+		FinishedAt:       job.FinishedAt,
+		Requests:         make([]DispatchRequestResponse, 0, len(requests)),
+	}
+
+	for _, req := range requests {
+		resp.Requests = append(resp.Requests, DispatchRequestResponse{
+			ID:              req.ID,
+			DriverID:        req.DriverID,
+			Status:          req.Status,
+			SentAt:          req.SentAt,
+			RespondedAt:     req.RespondedAt,
+			ExpiresAt:       req.ExpiresAt,
+			RejectionReason: req.RejectionReason,
+		})
+	}
+
+	return resp
+}
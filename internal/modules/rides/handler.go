@@ -1,6 +1,9 @@
 package rides
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/umar5678/go-backend/internal/modules/rides/dto"
 	"github.com/umar5678/go-backend/internal/utils/response"
@@ -16,6 +19,7 @@ func NewHandler(service Service) *Handler {
 
 // CreateRide godoc
 // @Summary Create a new ride request
+// @Description Set scheduledAt to book the ride ahead; the search then starts shortly before pickup
 // @Tags rides
 // @Security BearerAuth
 // @Accept json
@@ -38,7 +42,11 @@ func (h *Handler) CreateRide(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, ride, "Ride requested successfully")
+	message := "Ride requested successfully"
+	if req.IsScheduled() {
+		message = "Ride scheduled successfully"
+	}
+	response.Success(c, ride, message)
 }
 
 // GetRide godoc
@@ -98,6 +106,35 @@ func (h *Handler) ListRides(c *gin.Context) {
 	response.Paginated(c, rides, pagination, "Rides retrieved successfully")
 }
 
+// ListScheduledRides godoc
+// @Summary List the rider's upcoming scheduled rides
+// @Tags rides
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} response.Response{data=[]dto.RideListResponse}
+// @Router /rides/scheduled [get]
+func (h *Handler) ListScheduledRides(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.ListScheduledRidesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+	req.SetDefaults()
+
+	rides, total, err := h.service.ListScheduledRides(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := response.NewPaginationMeta(total, req.Page, req.Limit)
+	response.Paginated(c, rides, pagination, "Scheduled rides retrieved successfully")
+}
+
 // AcceptRide godoc
 // @Summary Accept a ride request (Driver)
 // @Tags rides
@@ -243,3 +280,196 @@ func (h *Handler) CancelRide(c *gin.Context) {
 
 	response.Success(c, nil, "Ride cancelled successfully")
 }
+
+// UpdateStops godoc
+// @Summary Change the stops of a ride (Rider)
+// @Description Replaces the stops the driver has not reached yet and reprices the ride
+// @Tags rides
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Param request body dto.UpdateStopsRequest true "Remaining stops in visiting order"
+// @Success 200 {object} response.Response{data=dto.UpdateStopsResponse}
+// @Router /rides/{id}/stops [put]
+func (h *Handler) UpdateStops(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	rideID := c.Param("id")
+
+	var req dto.UpdateStopsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	result, err := h.service.UpdateStops(c.Request.Context(), userID.(string), rideID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, result, "Stops updated successfully")
+}
+
+// GetReceipt godoc
+// @Summary Get a ride receipt (Rider)
+// @Description Fare breakdown, surge, cancellation fee, wallet payments and route for a completed ride or a cancelled ride that was charged
+// @Tags rides
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Success 200 {object} response.Response{data=dto.ReceiptResponse}
+// @Router /rides/{id}/receipt [get]
+func (h *Handler) GetReceipt(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	receipt, err := h.service.GetReceipt(c.Request.Context(), userID.(string), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, receipt, "Receipt retrieved successfully")
+}
+
+// GetReceiptPDF godoc
+// @Summary Download a ride receipt as PDF (Rider)
+// @Tags rides
+// @Security BearerAuth
+// @Produce application/pdf
+// @Param id path string true "Ride ID"
+// @Success 200 {file} file
+// @Router /rides/{id}/receipt/pdf [get]
+func (h *Handler) GetReceiptPDF(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	rideID := c.Param("id")
+
+	document, err := h.service.GetReceiptPDF(c.Request.Context(), userID.(string), rideID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="receipt-%s.pdf"`, rideID))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+// ExportRides godoc
+// @Summary Export trip history as CSV
+// @Description Completed trips requested between from and to (inclusive dates, UTC), for expense reports
+// @Tags rides
+// @Security BearerAuth
+// @Produce text/csv
+// @Param role query string true "User role (rider or driver)"
+// @Param from query string true "First day (YYYY-MM-DD)"
+// @Param to query string true "Last day (YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Router /rides/export [get]
+func (h *Handler) ExportRides(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	var req dto.ExportRidesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(response.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	export, err := h.service.ExportRides(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="trips-%s-%s.csv"`, req.From, req.To))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", export)
+}
+
+// ChangeDestination godoc
+// @Summary Change the destination of a started ride (Rider)
+// @Description Reprices the trip from the driver's current location and asks the driver to accept over websocket. The change applies once the driver accepts.
+// @Tags rides
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Param request body dto.ChangeDestinationRequest true "New destination"
+// @Success 200 {object} response.Response{data=dto.DestinationChangeResponse}
+// @Router /rides/{id}/destination [post]
+func (h *Handler) ChangeDestination(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	rideID := c.Param("id")
+
+	var req dto.ChangeDestinationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	change, err := h.service.ChangeDestination(c.Request.Context(), userID.(string), rideID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, change, "Destination change sent to driver")
+}
+
+// MarkStopArrived godoc
+// @Summary Mark driver as arrived at a stop (Driver)
+// @Tags rides
+// @Security BearerAuth
+// @Param id path string true "Ride ID"
+// @Param stopId path string true "Stop ID"
+// @Success 200 {object} response.Response{data=dto.RideResponse}
+// @Router /rides/{id}/stops/{stopId}/arrived [post]
+func (h *Handler) MarkStopArrived(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	ride, err := h.service.MarkStopArrived(c.Request.Context(), userID.(string), c.Param("id"), c.Param("stopId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, ride, "Marked as arrived at stop")
+}
+
+// MarkStopDeparted godoc
+// @Summary Mark driver as departed from a stop (Driver)
+// @Tags rides
+// @Security BearerAuth
+// @Param id path string true "Ride ID"
+// @Param stopId path string true "Stop ID"
+// @Success 200 {object} response.Response{data=dto.RideResponse}
+// @Router /rides/{id}/stops/{stopId}/departed [post]
+func (h *Handler) MarkStopDeparted(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	ride, err := h.service.MarkStopDeparted(c.Request.Context(), userID.(string), c.Param("id"), c.Param("stopId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, ride, "Marked as departed from stop")
+}
+
+// GetDispatchState godoc
+// @Summary Get driver search state for a ride (support)
+// @Tags rides
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Ride ID"
+// @Success 200 {object} response.Response{data=dto.DispatchStateResponse}
+// @Router /rides/{id}/dispatch [get]
+func (h *Handler) GetDispatchState(c *gin.Context) {
+	rideID := c.Param("id")
+
+	state, err := h.service.GetDispatchState(c.Request.Context(), rideID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, state, "Dispatch state retrieved successfully")
+}
@@ -0,0 +1,59 @@
+package rides
+
+import (
+	"errors"
+
+	pricingdto "github.com/umar5678/go-backend/internal/modules/pricing/dto"
+	"github.com/umar5678/go-backend/internal/modules/rides/dto"
+	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+const (
+	// Pickup and dropoff may move this far from the quoted points (km),
+	// enough for a pin nudged on the map but not a different trip
+	quoteLocationToleranceKm = 0.2
+
+	// Extra distance (km) a trip must also exceed, on top of the ratio, before
+	// the quote cap is lifted; keeps short trips from losing the cap on noise
+	quoteDeviationMinKm = 1.0
+)
+
+// validateQuote checks that a quote was issued for the ride being booked
+func validateQuote(quote *pricingdto.FareQuote, req dto.CreateRideRequest) error {
+	if quote.VehicleTypeID != req.VehicleTypeID {
+		return errors.New("fare quote was issued for a different vehicle type")
+	}
+	if location.HaversineDistance(quote.PickupLat, quote.PickupLon, req.PickupLat, req.PickupLon) > quoteLocationToleranceKm {
+		return errors.New("pickup location does not match the fare quote")
+	}
+	if location.HaversineDistance(quote.DropoffLat, quote.DropoffLon, req.DropoffLat, req.DropoffLon) > quoteLocationToleranceKm {
+		return errors.New("dropoff location does not match the fare quote")
+	}
+	if len(quote.Stops) != len(req.Stops) {
+		return errors.New("stops do not match the fare quote")
+	}
+	for i, stop := range req.Stops {
+		if location.HaversineDistance(quote.Stops[i].Lat, quote.Stops[i].Lon, stop.Lat, stop.Lon) > quoteLocationToleranceKm {
+			return errors.New("stops do not match the fare quote")
+		}
+	}
+	return nil
+}
+
+// capToQuote limits the fare of a quoted ride to the quote plus the configured
+// tolerance. The cap is lifted when the trip was materially longer than quoted
+// (detour, changed destination), in which case the metered fare stands.
+func (s *service) capToQuote(quotedFare money.Amount, quotedDistance, actualDistance float64, fare money.Amount) (money.Amount, bool) {
+	extra := actualDistance - quotedDistance
+	if extra > quoteDeviationMinKm && extra > quotedDistance*s.cfg.Pricing.RouteDeviationRatio {
+		return fare, false
+	}
+
+	// Rounded down so the cap never charges a fraction of a cent over the tolerance
+	limit := quotedFare.Mul(1+s.cfg.Pricing.FareCapTolerance, money.Down)
+	if !fare.GreaterThan(limit) {
+		return fare, false
+	}
+	return limit, true
+}
@@ -0,0 +1,180 @@
+package rides
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	trackingdto "github.com/umar5678/go-backend/internal/modules/tracking/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"gorm.io/gorm"
+)
+
+// DriverRanker orders candidate drivers for a ride, best first.
+// Implementations must return every candidate they were given.
+type DriverRanker interface {
+	Rank(ctx context.Context, ride *models.Ride, candidates []trackingdto.DriverLocationResponse) ([]trackingdto.DriverLocationResponse, error)
+}
+
+// CityResolver maps a pickup point to the city whose ranking weights apply.
+// An empty string means no city-specific weights.
+type CityResolver interface {
+	ResolveCity(ctx context.Context, lat, lon float64) string
+}
+
+// Weights used when no row in dispatch_ranking_weights matches
+var defaultRankingWeights = models.DispatchRankingWeights{
+	ETAWeight:          0.5,
+	RatingWeight:       0.2,
+	AcceptanceWeight:   0.2,
+	IdleWeight:         0.1,
+	CancellationWeight: 0,
+	MaxETASeconds:      900,
+	MaxIdleMinutes:     60,
+}
+
+const rankingWeightsCacheTTL = 5 * time.Minute
+
+type weightedDriverRanker struct {
+	repo   Repository
+	cities CityResolver
+}
+
+// NewWeightedDriverRanker scores drivers on ETA, rating, acceptance rate and
+// time since their last trip, using weights configured per city / vehicle type.
+// cities may be nil, in which case only vehicle-type and global weights apply;
+// the server passes the geofences service so per-city rows take effect.
+func NewWeightedDriverRanker(repo Repository, cities CityResolver) DriverRanker {
+	if cities == nil {
+		logger.Warn("dispatch ranker has no city resolver; per-city ranking weights are ignored")
+	}
+	return &weightedDriverRanker{
+		repo:   repo,
+		cities: cities,
+	}
+}
+
+type scoredDriver struct {
+	driver trackingdto.DriverLocationResponse
+	score  float64
+}
+
+func (r *weightedDriverRanker) Rank(ctx context.Context, ride *models.Ride, candidates []trackingdto.DriverLocationResponse) ([]trackingdto.DriverLocationResponse, error) {
+	if len(candidates) <= 1 {
+		return candidates, nil
+	}
+
+	city := ""
+	if r.cities != nil {
+		city = r.cities.ResolveCity(ctx, ride.PickupLat, ride.PickupLon)
+	}
+
+	weights := r.loadWeights(ctx, city, ride.VehicleTypeID)
+
+	driverIDs := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		driverIDs = append(driverIDs, c.DriverID)
+	}
+
+	lastTrips, err := r.repo.FindLastTripTimes(ctx, driverIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load last trip times: %w", err)
+	}
+
+	now := time.Now()
+	scored := make([]scoredDriver, 0, len(candidates))
+	for _, c := range candidates {
+		scored = append(scored, scoredDriver{
+			driver: c,
+			score:  scoreDriver(weights, c, lastTrips, now),
+		})
+	}
+
+	// Stable so equal scores keep the tracking module's distance order
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	ranked := make([]trackingdto.DriverLocationResponse, 0, len(scored))
+	for _, s := range scored {
+		ranked = append(ranked, s.driver)
+	}
+
+	logger.Debug("drivers ranked",
+		"rideID", ride.ID,
+		"city", city,
+		"candidates", len(ranked),
+		"topDriverID", ranked[0].DriverID,
+		"topScore", scored[0].score,
+	)
+
+	return ranked, nil
+}
+
+// scoreDriver combines normalised factors (each 0..1) into a weighted score
+func scoreDriver(w *models.DispatchRankingWeights, c trackingdto.DriverLocationResponse, lastTrips map[string]time.Time, now time.Time) float64 {
+	// ETA: 1 when the driver is at the pickup, 0 at MaxETASeconds or beyond
+	etaScore := 1 - clamp01(float64(c.ETA)/float64(w.MaxETASeconds))
+
+	// Profile factors; drivers without a loaded profile get neutral scores
+	ratingScore, acceptanceScore, cancellationScore := 0.5, 0.5, 0.0
+	if c.Driver != nil {
+		ratingScore = clamp01((c.Driver.Rating - 1) / 4)
+		acceptanceScore = clamp01(c.Driver.AcceptanceRate / 100)
+		cancellationScore = clamp01(c.Driver.CancellationRate / 100)
+	}
+
+	// Idle time: drivers who have waited longest get a boost. A driver who
+	// has never completed a trip counts as fully idle.
+	idleScore := 1.0
+	if last, ok := lastTrips[c.DriverID]; ok {
+		idleScore = clamp01(now.Sub(last).Minutes() / float64(w.MaxIdleMinutes))
+	}
+
+	return w.ETAWeight*etaScore +
+		w.RatingWeight*ratingScore +
+		w.AcceptanceWeight*acceptanceScore +
+		w.IdleWeight*idleScore -
+		w.CancellationWeight*cancellationScore
+}
+
+func (r *weightedDriverRanker) loadWeights(ctx context.Context, city, vehicleTypeID string) *models.DispatchRankingWeights {
+	cacheKey := fmt.Sprintf("dispatch:weights:%s:%s", city, vehicleTypeID)
+
+	var cached models.DispatchRankingWeights
+	if err := cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return &cached
+	}
+
+	weights, err := r.repo.FindRankingWeights(ctx, city, vehicleTypeID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Warn("failed to load ranking weights, using defaults",
+				"error", err,
+				"city", city,
+				"vehicleTypeID", vehicleTypeID,
+			)
+		}
+		defaults := defaultRankingWeights
+		return &defaults
+	}
+
+	if weights.MaxETASeconds <= 0 {
+		weights.MaxETASeconds = defaultRankingWeights.MaxETASeconds
+	}
+	if weights.MaxIdleMinutes <= 0 {
+		weights.MaxIdleMinutes = defaultRankingWeights.MaxIdleMinutes
+	}
+
+	cache.SetJSON(ctx, cacheKey, weights, rankingWeightsCacheTTL)
+	return weights
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,412 @@
+package rides
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	pricingdto "github.com/umar5678/go-backend/internal/modules/pricing/dto"
+	"github.com/umar5678/go-backend/internal/modules/rides/dto"
+	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/pdf"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+// Receipts are built on request from the ride, the pricing rules and the
+// rider's wallet transactions for the ride; nothing is stored.
+
+const (
+	// maxExportDays and maxExportRides bound a trip history export
+	maxExportDays  = 366
+	maxExportRides = 5000
+)
+
+// receiptRefTypes are the wallet reference types a rider pays a ride under
+var receiptRefTypes = []string{"ride"}
+
+// GetReceipt builds the rider's receipt for a completed ride, or for a
+// cancelled ride that was charged a fee
+func (s *service) GetReceipt(ctx context.Context, riderID, rideID string) (*dto.ReceiptResponse, error) {
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return nil, response.NotFoundError("Ride")
+	}
+	if ride.RiderID != riderID {
+		return nil, response.ForbiddenError("Not authorized to view this receipt")
+	}
+	if ride.Status != "completed" && ride.Status != "cancelled" {
+		return nil, response.BadRequest("Receipts are available once a ride is completed")
+	}
+
+	payments, err := s.walletService.ListTransactionsByReference(ctx, riderID, receiptRefTypes, rideID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt := &dto.ReceiptResponse{
+		ReceiptNumber:   receiptNumber(ride),
+		RideID:          ride.ID,
+		Status:          ride.Status,
+		IssuedAt:        time.Now().UTC(),
+		RiderName:       ride.Rider.Name,
+		VehicleType:     ride.VehicleType.DisplayName,
+		PickupAddress:   ride.PickupAddress,
+		DropoffAddress:  ride.DropoffAddress,
+		RequestedAt:     ride.RequestedAt,
+		StartedAt:       ride.StartedAt,
+		CompletedAt:     ride.CompletedAt,
+		CancelledAt:     ride.CancelledAt,
+		SurgeMultiplier: ride.SurgeMultiplier,
+		FareCapped:      ride.FareCapped,
+		Currency:        string(money.DefaultCurrency),
+		Payments:        payments,
+		RoutePolyline:   ride.RoutePolyline,
+	}
+
+	if ride.DriverID != nil {
+		if driver, err := s.driversRepo.FindDriverByID(ctx, *ride.DriverID); err == nil {
+			receipt.DriverName = driver.User.Name
+		}
+	}
+
+	for _, stop := range ride.Stops {
+		if stop.Reached() {
+			receipt.Stops = append(receipt.Stops, stop.Address)
+		}
+	}
+
+	if ride.Status == "cancelled" {
+		// Only a cancellation that cost the rider something has a receipt
+		fee := money.Zero(money.DefaultCurrency)
+		for _, payment := range payments {
+			switch payment.Type {
+			case models.TransactionTypeDebit:
+				fee = fee.Add(payment.Amount)
+			case models.TransactionTypeRefund:
+				fee = fee.Sub(payment.Amount)
+			}
+		}
+		if !fee.IsPositive() {
+			return nil, response.BadRequest("No receipt: this ride was cancelled without a fee")
+		}
+		receipt.CancellationFee = &fee
+		receipt.Total = fee
+		return receipt, nil
+	}
+
+	if ride.ActualDistance != nil {
+		receipt.DistanceKm = *ride.ActualDistance
+	}
+	if ride.ActualDuration != nil {
+		receipt.DurationSec = *ride.ActualDuration
+	}
+	if ride.ActualFare != nil {
+		receipt.Total = money.FromFloat(*ride.ActualFare, money.DefaultCurrency, money.HalfUp)
+	}
+
+	fare, err := s.fareBreakdown(ctx, ride, receipt.Total)
+	if err != nil {
+		// The receipt is still useful with the total and payments alone
+		logger.Error("failed to build receipt fare breakdown", "error", err, "rideID", rideID)
+	}
+	receipt.Fare = fare
+
+	return receipt, nil
+}
+
+// fareBreakdown reprices the completed trip with the ride's vehicle type and
+// surge, then adds the difference to what was charged (a fare capped to its
+// quote) as its own line so the components add up to the total
+func (s *service) fareBreakdown(ctx context.Context, ride *models.Ride, charged money.Amount) (*pricingdto.FareBreakdownResponse, error) {
+	if ride.ActualDistance == nil || ride.ActualDuration == nil {
+		return nil, fmt.Errorf("ride has no actual distance or duration")
+	}
+
+	metered, err := s.pricingService.CalculateActualFare(ctx, pricingdto.CalculateActualFareRequest{
+		ActualDistanceKm:  *ride.ActualDistance,
+		ActualDurationSec: *ride.ActualDuration,
+		VehicleTypeID:     ride.VehicleTypeID,
+		SurgeMultiplier:   ride.SurgeMultiplier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := s.pricingService.GetFareBreakdown(ctx, &models.FareEstimate{
+		BaseFare:          metered.BaseFare,
+		DistanceFare:      metered.DistanceFare,
+		DurationFare:      metered.DurationFare,
+		BookingFee:        metered.BookingFee,
+		SurgeMultiplier:   metered.SurgeMultiplier,
+		SubTotal:          metered.SubTotal,
+		SurgeAmount:       metered.SurgeAmount,
+		TotalFare:         metered.TotalFare,
+		EstimatedDistance: metered.EstimatedDistance,
+		EstimatedDuration: metered.EstimatedDuration,
+		VehicleTypeName:   metered.VehicleTypeName,
+	})
+
+	if ride.AirportFee > 0 {
+		airportFee := money.FromFloat(ride.AirportFee, money.DefaultCurrency, money.HalfUp)
+		breakdown.Components = append(breakdown.Components, pricingdto.FareComponent{
+			Name:   "Airport Fee",
+			Amount: airportFee,
+			Type:   "airport_fee",
+		})
+		breakdown.Total = breakdown.Total.Add(airportFee)
+	}
+
+	if adjustment := charged.Sub(breakdown.Total); !adjustment.IsZero() {
+		name := "Adjustment"
+		if ride.FareCapped {
+			name = "Upfront price adjustment"
+		}
+		breakdown.Components = append(breakdown.Components, pricingdto.FareComponent{
+			Name:   name,
+			Amount: adjustment,
+			Type:   "adjustment",
+		})
+		breakdown.Total = charged
+	}
+
+	return breakdown, nil
+}
+
+// receiptNumber is a short, stable reference for the ride's receipt
+func receiptNumber(ride *models.Ride) string {
+	return fmt.Sprintf("R-%s-%s", ride.RequestedAt.UTC().Format("20060102"), strings.ToUpper(ride.ID[:8]))
+}
+
+// GetReceiptPDF renders the rider's receipt as a PDF
+func (s *service) GetReceiptPDF(ctx context.Context, riderID, rideID string) ([]byte, error) {
+	receipt, err := s.GetReceipt(ctx, riderID, rideID)
+	if err != nil {
+		return nil, err
+	}
+	return renderReceiptPDF(receipt), nil
+}
+
+// receiptPage lays out a receipt top to bottom
+type receiptPage struct {
+	doc *pdf.Document
+	y   float64
+}
+
+const (
+	receiptMarginX    = 50.0
+	receiptValueX     = 180.0
+	receiptLineHeight = 16.0
+	receiptValueChars = 70 // what fits between the value column and the margin at 10pt
+)
+
+func (p *receiptPage) text(size float64, bold bool, s string) {
+	p.doc.Text(receiptMarginX, p.y, size, bold, s)
+	p.y += size + 6
+}
+
+func (p *receiptPage) row(label, value string, bold bool) {
+	p.doc.Text(receiptMarginX, p.y, 10, bold, label)
+	if runes := []rune(value); len(runes) > receiptValueChars {
+		value = string(runes[:receiptValueChars-3]) + "..."
+	}
+	p.doc.Text(receiptValueX, p.y, 10, bold, value)
+	p.y += receiptLineHeight
+}
+
+func (p *receiptPage) rule() {
+	p.y += 2
+	p.doc.Line(receiptMarginX, p.y, pdf.PageWidth-receiptMarginX, p.y, 0.5)
+	p.y += receiptLineHeight
+}
+
+func renderReceiptPDF(receipt *dto.ReceiptResponse) []byte {
+	p := &receiptPage{doc: pdf.New(), y: 60}
+
+	p.text(20, true, "Ride Receipt")
+	p.row("Receipt number", receipt.ReceiptNumber, false)
+	p.row("Issued", receipt.IssuedAt.Format("02 Jan 2006 15:04 MST"), false)
+	p.rule()
+
+	p.row("Rider", receipt.RiderName, false)
+	if receipt.DriverName != "" {
+		p.row("Driver", receipt.DriverName, false)
+	}
+	p.row("Vehicle", receipt.VehicleType, false)
+	p.row("Requested", receipt.RequestedAt.UTC().Format("02 Jan 2006 15:04 MST"), false)
+	if receipt.CompletedAt != nil {
+		p.row("Completed", receipt.CompletedAt.UTC().Format("02 Jan 2006 15:04 MST"), false)
+	}
+	if receipt.CancelledAt != nil {
+		p.row("Cancelled", receipt.CancelledAt.UTC().Format("02 Jan 2006 15:04 MST"), false)
+	}
+	p.rule()
+
+	p.row("From", receipt.PickupAddress, false)
+	for i, stop := range receipt.Stops {
+		p.row(fmt.Sprintf("Stop %d", i+1), stop, false)
+	}
+	p.row("To", receipt.DropoffAddress, false)
+	if receipt.Fare != nil {
+		p.row("Distance", fmt.Sprintf("%.2f km", receipt.DistanceKm), false)
+		p.row("Duration", fmt.Sprintf("%d min", int(math.Round(float64(receipt.DurationSec)/60))), false)
+	}
+	p.rule()
+
+	if receipt.Fare != nil {
+		for _, component := range receipt.Fare.Components {
+			p.row(component.Name, component.Amount.String()+" "+receipt.Currency, false)
+		}
+	}
+	if receipt.CancellationFee != nil {
+		p.row("Cancellation fee", receipt.CancellationFee.String()+" "+receipt.Currency, false)
+	}
+	p.row("Total", receipt.Total.String()+" "+receipt.Currency, true)
+	p.rule()
+
+	if len(receipt.Payments) > 0 {
+		p.text(12, true, "Payments")
+		for _, payment := range receipt.Payments {
+			p.row(fmt.Sprintf("%s  %s", payment.CreatedAt.UTC().Format("02 Jan 2006 15:04"), payment.Type), payment.Amount.String()+" "+receipt.Currency, false)
+		}
+		p.rule()
+	}
+
+	if points := location.DecodePolyline(receipt.RoutePolyline); len(points) > 1 {
+		const mapHeight = 200
+		if p.y+mapHeight+receiptLineHeight > pdf.PageHeight-receiptMarginX {
+			p.doc.AddPage()
+			p.y = 60
+		}
+		p.text(12, true, "Route")
+		drawRoute(p.doc, points, receiptMarginX, p.y, pdf.PageWidth-2*receiptMarginX, mapHeight)
+	}
+
+	return p.doc.Bytes()
+}
+
+// drawRoute plots the trip inside a box, north up, keeping its proportions
+func drawRoute(doc *pdf.Document, points []location.Point, x, y, w, h float64) {
+	doc.Rect(x, y, w, h, 0.5)
+
+	minLat, maxLat := points[0].Latitude, points[0].Latitude
+	minLon, maxLon := points[0].Longitude, points[0].Longitude
+	for _, pt := range points[1:] {
+		minLat, maxLat = math.Min(minLat, pt.Latitude), math.Max(maxLat, pt.Latitude)
+		minLon, maxLon = math.Min(minLon, pt.Longitude), math.Max(maxLon, pt.Longitude)
+	}
+
+	// Longitude degrees shrink with latitude
+	lonScale := math.Cos((minLat + maxLat) / 2 * math.Pi / 180)
+	spanX := math.Max((maxLon-minLon)*lonScale, 1e-9)
+	spanY := math.Max(maxLat-minLat, 1e-9)
+
+	const padding = 10.0
+	scale := math.Min((w-2*padding)/spanX, (h-2*padding)/spanY)
+	offsetX := x + (w-spanX*scale)/2
+	offsetY := y + (h-spanY*scale)/2
+
+	plotted := make([][2]float64, len(points))
+	for i, pt := range points {
+		plotted[i] = [2]float64{
+			offsetX + (pt.Longitude-minLon)*lonScale*scale,
+			offsetY + (maxLat-pt.Latitude)*scale,
+		}
+	}
+	doc.Polyline(plotted, 1.5)
+}
+
+// ExportRides writes the user's completed trips in the date range as CSV
+func (s *service) ExportRides(ctx context.Context, userID string, req dto.ExportRidesRequest) ([]byte, error) {
+	from, to, err := req.Range()
+	if err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+	if to.Sub(from) > maxExportDays*24*time.Hour {
+		return nil, response.BadRequest(fmt.Sprintf("Export range can be at most %d days", maxExportDays))
+	}
+
+	// Rides store the driver's profile ID
+	ownerID := userID
+	if req.Role == "driver" {
+		driver, err := s.driversRepo.FindDriverByUserID(ctx, userID)
+		if err != nil {
+			return nil, response.NotFoundError("Driver profile not found")
+		}
+		ownerID = driver.ID
+	}
+
+	rides, err := s.repo.ListRidesForExport(ctx, req.Role, ownerID, from, to, maxExportRides)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch rides", err)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{
+		"Ride ID", "Receipt Number", "Requested At", "Completed At",
+		"Pickup", "Dropoff", "Vehicle Type",
+		"Distance (km)", "Duration (min)", "Surge", "Fare", "Currency",
+	})
+
+	for _, ride := range rides {
+		completedAt, distance, duration, fare := "", "", "", ""
+		if ride.CompletedAt != nil {
+			completedAt = ride.CompletedAt.UTC().Format(time.RFC3339)
+		}
+		if ride.ActualDistance != nil {
+			distance = strconv.FormatFloat(*ride.ActualDistance, 'f', 2, 64)
+		}
+		if ride.ActualDuration != nil {
+			duration = strconv.Itoa(int(math.Round(float64(*ride.ActualDuration) / 60)))
+		}
+		if ride.ActualFare != nil {
+			fare = money.FromFloat(*ride.ActualFare, money.DefaultCurrency, money.HalfUp).String()
+		}
+
+		w.Write([]string{
+			ride.ID,
+			receiptNumber(ride),
+			ride.RequestedAt.UTC().Format(time.RFC3339),
+			completedAt,
+			csvText(ride.PickupAddress),
+			csvText(ride.DropoffAddress),
+			ride.VehicleType.DisplayName,
+			distance,
+			duration,
+			strconv.FormatFloat(ride.SurgeMultiplier, 'f', 2, 64),
+			fare,
+			string(money.DefaultCurrency),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, response.InternalServerError("Failed to write export", err)
+	}
+
+	logger.Info("ride history exported",
+		"userID", userID,
+		"role", req.Role,
+		"from", req.From,
+		"to", req.To,
+		"rides", len(rides),
+	)
+
+	return buf.Bytes(), nil
+}
+
+// csvText keeps free text from being read as a formula by spreadsheets
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
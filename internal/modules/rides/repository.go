@@ -11,7 +11,9 @@ import (
 
 type Repository interface {
 	// Ride CRUD
-	CreateRide(ctx context.Context, ride *models.Ride) error
+	// CreateRide inserts the ride and its stops. A non-nil job is queued in
+	// the same transaction, so a searching ride always has its driver search.
+	CreateRide(ctx context.Context, ride *models.Ride, job *models.RideDispatchJob) error
 	FindRideByID(ctx context.Context, id string) (*models.Ride, error)
 	UpdateRide(ctx context.Context, ride *models.Ride) error
 	UpdateRideStatus(ctx context.Context, rideID, status string) error
@@ -24,13 +26,41 @@ type Repository interface {
 	FindPendingRequestsForDriver(ctx context.Context, driverID string) ([]*models.RideRequest, error)
 	FindPendingRequestsForRide(ctx context.Context, rideID string) ([]*models.RideRequest, error)
 	UpdateRideRequestStatus(ctx context.Context, requestID, status string, rejectionReason *string) error
-	ExpireOldRequests(ctx context.Context) error
+	ExpireOldRequests(ctx context.Context) (int64, error)
 	FindActiveRideByDriverID(ctx context.Context, driverID string) (*models.Ride, error)
 
 	// NEW CRITICAL METHODS
 	UpdateRideStatusAndDriver(ctx context.Context, rideID, newStatus, expectedStatus string, driverID string) error
 	CancelPendingRequestsExcept(ctx context.Context, rideID, acceptedDriverID string) error
 
+	// Dispatch jobs
+	FindDispatchJobByRideID(ctx context.Context, rideID string) (*models.RideDispatchJob, error)
+	ClaimDispatchJobs(ctx context.Context, workerID string, lease time.Duration, limit int) ([]*models.RideDispatchJob, error)
+	ExtendDispatchLease(ctx context.Context, jobID, workerID string, lease time.Duration) error
+	UpdateDispatchJob(ctx context.Context, jobID string, updates map[string]interface{}) error
+	UpdateDispatchJobByRideID(ctx context.Context, rideID string, updates map[string]interface{}) error
+	EnqueueOrphanedSearchingRides(ctx context.Context, maxAttempts int) (int64, error)
+	FindAbandonedSearchingRides(ctx context.Context, requestedBefore time.Time, limit int) ([]*models.Ride, error)
+	FindRideRequestsByRideID(ctx context.Context, rideID string) ([]*models.RideRequest, error)
+
+	// Scheduled rides
+	ListScheduledRides(ctx context.Context, riderID string, page, limit int) ([]*models.Ride, int64, error)
+	FindDueScheduledRides(ctx context.Context, pickupBefore time.Time, limit int) ([]*models.Ride, error)
+	SetScheduledRideHold(ctx context.Context, rideID, holdID string) (bool, error)
+	StartScheduledRide(ctx context.Context, rideID string, job *models.RideDispatchJob) (bool, error)
+	CancelScheduledRide(ctx context.Context, rideID, reason string) (bool, error)
+
+	// Stops
+	ReplacePendingStops(ctx context.Context, rideID string, stops []models.RideStop, rideUpdates map[string]interface{}) error
+	UpdateStopStatus(ctx context.Context, stopID, fromStatus, toStatus string) (bool, error)
+	CloseStops(ctx context.Context, rideID string) error
+	UpdateDropoff(ctx context.Context, rideID string, lat, lon float64, address string, rideUpdates map[string]interface{}) error
+	ListRidesForExport(ctx context.Context, role, userID string, from, to time.Time, limit int) ([]*models.Ride, error)
+
+	// Driver ranking
+	FindRankingWeights(ctx context.Context, city, vehicleTypeID string) (*models.DispatchRankingWeights, error)
+	FindLastTripTimes(ctx context.Context, driverIDs []string) (map[string]time.Time, error)
+
 	// Statistics
 	GetRiderStats(ctx context.Context, riderID string) (totalRides int, totalSpent float64, err error)
 	GetDriverStats(ctx context.Context, driverID string) (totalTrips int, totalEarnings float64, err error)
@@ -46,30 +76,50 @@ func NewRepository(db *gorm.DB) Repository {
 
 // Ride CRUD
 
-func (r *repository) CreateRide(ctx context.Context, ride *models.Ride) error {
+func (r *repository) CreateRide(ctx context.Context, ride *models.Ride, job *models.RideDispatchJob) error {
 	pickupPoint := fmt.Sprintf("POINT(%f %f)", ride.PickupLon, ride.PickupLat)
 	dropoffPoint := fmt.Sprintf("POINT(%f %f)", ride.DropoffLon, ride.DropoffLat)
 
-	return r.db.WithContext(ctx).Exec(`
-		INSERT INTO rides (
-			id, rider_id, vehicle_type_id, status,
-			pickup_location, pickup_lat, pickup_lon, pickup_address,
-			dropoff_location, dropoff_lat, dropoff_lon, dropoff_address,
-			estimated_distance, estimated_duration, estimated_fare,
-			surge_multiplier, wallet_hold_id, rider_notes, requested_at
-		) VALUES (
-			?, ?, ?, ?,
-			ST_GeomFromText(?, 4326), ?, ?, ?,
-			ST_GeomFromText(?, 4326), ?, ?, ?,
-			?, ?, ?,
-			?, ?, ?, ?
-		)
-	`, ride.ID, ride.RiderID, ride.VehicleTypeID, ride.Status,
-		pickupPoint, ride.PickupLat, ride.PickupLon, ride.PickupAddress,
-		dropoffPoint, ride.DropoffLat, ride.DropoffLon, ride.DropoffAddress,
-		ride.EstimatedDistance, ride.EstimatedDuration, ride.EstimatedFare,
-		ride.SurgeMultiplier, ride.WalletHoldID, ride.RiderNotes, ride.RequestedAt,
-	).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO rides (
+				id, rider_id, vehicle_type_id, status,
+				pickup_location, pickup_lat, pickup_lon, pickup_address,
+				dropoff_location, dropoff_lat, dropoff_lon, dropoff_address,
+				estimated_distance, estimated_duration, estimated_fare, airport_fee,
+				surge_multiplier, quote_id, wallet_hold_id, rider_notes,
+				requested_at, scheduled_at
+			) VALUES (
+				?, ?, ?, ?,
+				ST_GeomFromText(?, 4326), ?, ?, ?,
+				ST_GeomFromText(?, 4326), ?, ?, ?,
+				?, ?, ?, ?,
+				?, ?, ?, ?,
+				?, ?
+			)
+		`, ride.ID, ride.RiderID, ride.VehicleTypeID, ride.Status,
+			pickupPoint, ride.PickupLat, ride.PickupLon, ride.PickupAddress,
+			dropoffPoint, ride.DropoffLat, ride.DropoffLon, ride.DropoffAddress,
+			ride.EstimatedDistance, ride.EstimatedDuration, ride.EstimatedFare, ride.AirportFee,
+			ride.SurgeMultiplier, ride.QuoteID, ride.WalletHoldID, ride.RiderNotes,
+			ride.RequestedAt, ride.ScheduledAt,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		if len(ride.Stops) > 0 {
+			if err := tx.Create(&ride.Stops).Error; err != nil {
+				return err
+			}
+		}
+
+		if job == nil {
+			return nil
+		}
+		job.RideID = ride.ID
+		return tx.Create(job).Error
+	})
 }
 
 func (r *repository) FindRideByID(ctx context.Context, id string) (*models.Ride, error) {
@@ -78,6 +128,9 @@ func (r *repository) FindRideByID(ctx context.Context, id string) (*models.Ride,
 		Preload("Rider").
 		Preload("Driver").
 		Preload("VehicleType").
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
 		Where("id = ?", id).
 		First(&ride).Error
 	return &ride, err
@@ -214,12 +267,13 @@ func (r *repository) UpdateRideRequestStatus(ctx context.Context, requestID, sta
 		Updates(updates).Error
 }
 
-func (r *repository) ExpireOldRequests(ctx context.Context) error {
-	return r.db.WithContext(ctx).
+func (r *repository) ExpireOldRequests(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
 		Model(&models.RideRequest{}).
 		Where("status = ?", "pending").
 		Where("expires_at < ?", time.Now()).
-		Update("status", "expired").Error
+		Update("status", "expired")
+	return result.RowsAffected, result.Error
 }
 
 func (r *repository) FindActiveRideByDriverID(ctx context.Context, driverID string) (*models.Ride, error) {
@@ -269,6 +323,385 @@ func (r *repository) CancelPendingRequestsExcept(ctx context.Context, rideID, ac
 		}).Error
 }
 
+// ============================================================================
+// DISPATCH JOBS
+// ============================================================================
+
+func (r *repository) FindDispatchJobByRideID(ctx context.Context, rideID string) (*models.RideDispatchJob, error) {
+	var job models.RideDispatchJob
+	err := r.db.WithContext(ctx).
+		Where("ride_id = ?", rideID).
+		First(&job).Error
+	return &job, err
+}
+
+// ClaimDispatchJobs leases up to limit runnable jobs to workerID.
+// A job is runnable when it is pending and due, or when it is running but
+// its lease has expired (the worker that held it crashed or was restarted).
+// SKIP LOCKED lets several instances poll the table without blocking each other.
+func (r *repository) ClaimDispatchJobs(ctx context.Context, workerID string, lease time.Duration, limit int) ([]*models.RideDispatchJob, error) {
+	var jobs []*models.RideDispatchJob
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE ride_dispatch_jobs
+		SET status = ?,
+			locked_by = ?,
+			locked_until = NOW() + (? * INTERVAL '1 second'),
+			attempts = attempts + 1,
+			started_at = COALESCE(started_at, NOW())
+		WHERE id IN (
+			SELECT id FROM ride_dispatch_jobs
+			WHERE (status = ? AND next_run_at <= NOW())
+			   OR (status = ? AND locked_until < NOW())
+			ORDER BY next_run_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, models.DispatchStatusRunning, workerID, int(lease.Seconds()),
+		models.DispatchStatusPending, models.DispatchStatusRunning, limit).
+		Scan(&jobs).Error
+	return jobs, err
+}
+
+// ExtendDispatchLease pushes out the lease of a job still held by workerID
+func (r *repository) ExtendDispatchLease(ctx context.Context, jobID, workerID string, lease time.Duration) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.RideDispatchJob{}).
+		Where("id = ? AND locked_by = ? AND status = ?", jobID, workerID, models.DispatchStatusRunning).
+		Update("locked_until", time.Now().Add(lease))
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repository) UpdateDispatchJob(ctx context.Context, jobID string, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).
+		Model(&models.RideDispatchJob{}).
+		Where("id = ?", jobID).
+		Updates(updates).Error
+}
+
+func (r *repository) UpdateDispatchJobByRideID(ctx context.Context, rideID string, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).
+		Model(&models.RideDispatchJob{}).
+		Where("ride_id = ?", rideID).
+		Updates(updates).Error
+}
+
+// EnqueueOrphanedSearchingRides creates dispatch jobs for searching rides that
+// have none, e.g. rides created before the dispatcher existed or whose job
+// insert failed. Returns the number of jobs created.
+func (r *repository) EnqueueOrphanedSearchingRides(ctx context.Context, maxAttempts int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO ride_dispatch_jobs (ride_id, status, max_attempts, next_run_at)
+		SELECT r.id, ?, ?, NOW()
+		FROM rides r
+		LEFT JOIN ride_dispatch_jobs j ON j.ride_id = r.id
+		WHERE r.status = 'searching' AND r.deleted_at IS NULL AND j.id IS NULL
+		ON CONFLICT (ride_id) DO NOTHING
+	`, models.DispatchStatusPending, maxAttempts)
+	return result.RowsAffected, result.Error
+}
+
+// FindAbandonedSearchingRides returns rides still searching that were
+// requested before requestedBefore and whose search no worker holds a live
+// lease on: no dispatch job, a finished or queued one, or an expired lease.
+func (r *repository) FindAbandonedSearchingRides(ctx context.Context, requestedBefore time.Time, limit int) ([]*models.Ride, error) {
+	var rides []*models.Ride
+	err := r.db.WithContext(ctx).
+		Joins("LEFT JOIN ride_dispatch_jobs j ON j.ride_id = rides.id").
+		Where("rides.status = ? AND rides.requested_at < ?", "searching", requestedBefore).
+		Where("j.id IS NULL OR j.status <> ? OR j.locked_until < NOW()", models.DispatchStatusRunning).
+		Order("rides.requested_at ASC").
+		Limit(limit).
+		Find(&rides).Error
+	return rides, err
+}
+
+func (r *repository) FindRideRequestsByRideID(ctx context.Context, rideID string) ([]*models.RideRequest, error) {
+	var requests []*models.RideRequest
+	err := r.db.WithContext(ctx).
+		Where("ride_id = ?", rideID).
+		Order("sent_at ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// ============================================================================
+// SCHEDULED RIDES
+// ============================================================================
+
+// ListScheduledRides returns a rider's upcoming bookings, soonest first
+func (r *repository) ListScheduledRides(ctx context.Context, riderID string, page, limit int) ([]*models.Ride, int64, error) {
+	var rides []*models.Ride
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Where("rider_id = ? AND status = ?", riderID, "scheduled")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("VehicleType").
+		Order("scheduled_at ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&rides).Error
+
+	return rides, total, err
+}
+
+// FindDueScheduledRides returns scheduled rides picking up before pickupBefore
+func (r *repository) FindDueScheduledRides(ctx context.Context, pickupBefore time.Time, limit int) ([]*models.Ride, error) {
+	var rides []*models.Ride
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND scheduled_at < ?", "scheduled", pickupBefore).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&rides).Error
+	return rides, err
+}
+
+// SetScheduledRideHold records the wallet hold of a ride that is still
+// scheduled. Returns false if the ride was cancelled or started meanwhile.
+func (r *repository) SetScheduledRideHold(ctx context.Context, rideID, holdID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Where("id = ? AND status = ?", rideID, "scheduled").
+		Update("wallet_hold_id", holdID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// StartScheduledRide moves a scheduled ride to searching and queues job for
+// it in the same transaction. requested_at is reset so search timeouts count
+// from now rather than from the booking. Returns false if the ride is no
+// longer scheduled.
+func (r *repository) StartScheduledRide(ctx context.Context, rideID string, job *models.RideDispatchJob) (bool, error) {
+	started := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Ride{}).
+			Where("id = ? AND status = ?", rideID, "scheduled").
+			Updates(map[string]interface{}{
+				"status":       "searching",
+				"requested_at": time.Now(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		job.RideID = rideID
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		started = true
+		return nil
+	})
+	return started, err
+}
+
+// CancelScheduledRide cancels a ride on the system's behalf if it is still
+// scheduled. Returns false if it is not.
+func (r *repository) CancelScheduledRide(ctx context.Context, rideID, reason string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Where("id = ? AND status = ?", rideID, "scheduled").
+		Updates(map[string]interface{}{
+			"status":              "cancelled",
+			"cancelled_by":        "system",
+			"cancellation_reason": reason,
+			"cancelled_at":        time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListRidesForExport returns the completed rides of a rider (role rider,
+// rider user ID) or driver (role driver, driver profile ID) requested in
+// [from, to), oldest first
+func (r *repository) ListRidesForExport(ctx context.Context, role, userID string, from, to time.Time, limit int) ([]*models.Ride, error) {
+	var rides []*models.Ride
+
+	column := "rider_id"
+	if role == "driver" {
+		column = "driver_id"
+	}
+
+	err := r.db.WithContext(ctx).
+		Preload("VehicleType").
+		Where(column+" = ?", userID).
+		Where("status = ?", "completed").
+		Where("requested_at >= ? AND requested_at < ?", from, to).
+		Order("requested_at ASC").
+		Limit(limit).
+		Find(&rides).Error
+	return rides, err
+}
+
+// ============================================================================
+// STOPS
+// ============================================================================
+
+// ReplacePendingStops swaps the stops not reached yet for stops and applies
+// rideUpdates (repriced estimate, new hold) in the same transaction. Fails
+// with gorm.ErrRecordNotFound if the ride has ended meanwhile.
+func (r *repository) ReplacePendingStops(ctx context.Context, rideID string, stops []models.RideStop, rideUpdates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Ride{}).
+			Where("id = ? AND status NOT IN ?", rideID, []string{"completed", "cancelled"}).
+			Updates(rideUpdates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("ride_id = ? AND status = ?", rideID, models.RideStopStatusPending).
+			Delete(&models.RideStop{}).Error; err != nil {
+			return err
+		}
+		if len(stops) == 0 {
+			return nil
+		}
+		return tx.Create(&stops).Error
+	})
+}
+
+// UpdateStopStatus moves a stop from fromStatus to toStatus, stamping
+// arrived_at or departed_at. Returns false if the stop was not in fromStatus.
+func (r *repository) UpdateStopStatus(ctx context.Context, stopID, fromStatus, toStatus string) (bool, error) {
+	updates := map[string]interface{}{
+		"status": toStatus,
+	}
+
+	now := time.Now()
+	switch toStatus {
+	case models.RideStopStatusArrived:
+		updates["arrived_at"] = now
+	case models.RideStopStatusDeparted:
+		updates["departed_at"] = now
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.RideStop{}).
+		Where("id = ? AND status = ?", stopID, fromStatus).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CloseStops settles the stops of a finished trip: the stop the driver is
+// waiting at is departed now and stops never reached are skipped
+func (r *repository) CloseStops(ctx context.Context, rideID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RideStop{}).
+			Where("ride_id = ? AND status = ?", rideID, models.RideStopStatusArrived).
+			Updates(map[string]interface{}{
+				"status":      models.RideStopStatusDeparted,
+				"departed_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.RideStop{}).
+			Where("ride_id = ? AND status = ?", rideID, models.RideStopStatusPending).
+			Update("status", models.RideStopStatusSkipped).Error
+	})
+}
+
+// UpdateDropoff moves the dropoff of a started ride and applies rideUpdates
+// (repriced estimate, hold) with it. Fails with gorm.ErrRecordNotFound if the
+// ride is no longer started.
+func (r *repository) UpdateDropoff(ctx context.Context, rideID string, lat, lon float64, address string, rideUpdates map[string]interface{}) error {
+	updates := map[string]interface{}{
+		"dropoff_location": gorm.Expr("ST_GeomFromText(?, 4326)", fmt.Sprintf("POINT(%f %f)", lon, lat)),
+		"dropoff_lat":      lat,
+		"dropoff_lon":      lon,
+		"dropoff_address":  address,
+	}
+	for column, value := range rideUpdates {
+		updates[column] = value
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Where("id = ? AND status = ?", rideID, "started").
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ============================================================================
+// DRIVER RANKING
+// ============================================================================
+
+// FindRankingWeights returns the most specific active weights row for a city
+// and vehicle type: city + vehicle type, then city, then vehicle type, then global
+func (r *repository) FindRankingWeights(ctx context.Context, city, vehicleTypeID string) (*models.DispatchRankingWeights, error) {
+	var weights models.DispatchRankingWeights
+
+	query := r.db.WithContext(ctx).Where("is_active = ?", true)
+
+	if city != "" {
+		query = query.Where("(city = ? OR city IS NULL)", city)
+	} else {
+		query = query.Where("city IS NULL")
+	}
+
+	if vehicleTypeID != "" {
+		query = query.Where("(vehicle_type_id = ? OR vehicle_type_id IS NULL)", vehicleTypeID)
+	} else {
+		query = query.Where("vehicle_type_id IS NULL")
+	}
+
+	err := query.
+		Order("(city IS NOT NULL) DESC").
+		Order("(vehicle_type_id IS NOT NULL) DESC").
+		First(&weights).Error
+	return &weights, err
+}
+
+// FindLastTripTimes returns when each driver last completed a ride.
+// Drivers with no completed rides are absent from the map.
+func (r *repository) FindLastTripTimes(ctx context.Context, driverIDs []string) (map[string]time.Time, error) {
+	result := make(map[string]time.Time, len(driverIDs))
+	if len(driverIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		DriverID   string
+		LastTripAt time.Time
+	}
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Ride{}).
+		Select("driver_id, MAX(completed_at) as last_trip_at").
+		Where("driver_id IN ?", driverIDs).
+		Where("status = ? AND completed_at IS NOT NULL", "completed").
+		Group("driver_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.DriverID] = row.LastTripAt
+	}
+	return result, nil
+}
+
 // Statistics
 
 func (r *repository) GetRiderStats(ctx context.Context, riderID string) (totalRides int, totalSpent float64, err error) {
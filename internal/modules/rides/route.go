@@ -0,0 +1,101 @@
+package rides
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/utils/location"
+)
+
+const (
+	// GPS fixes less accurate than this are dropped before measuring the route
+	maxFixAccuracyMeters = 100.0
+	// Douglas-Peucker tolerance used to smooth GPS jitter (km)
+	routeSimplifyToleranceKm = 0.01
+
+	// Reported figures are flagged when they differ from GPS by more than
+	// both the absolute and the relative threshold
+	distanceDiscrepancyKm    = 0.5
+	durationDiscrepancySec   = 120
+	relativeDiscrepancyRatio = 0.20
+)
+
+// gpsRoute is the trip as measured from the driver's location history
+type gpsRoute struct {
+	DistanceKm  float64
+	DurationSec int
+	Polyline    string
+	Points      int
+	HasGPS      bool // false when there were too few fixes to measure the route
+}
+
+// buildGPSRoute measures the trip from the driver's stored location history
+// between start and end. Duration always comes from the server timestamps.
+func (s *service) buildGPSRoute(ctx context.Context, driverID string, start, end time.Time) (*gpsRoute, error) {
+	route := &gpsRoute{
+		DurationSec: int(end.Sub(start).Seconds()),
+	}
+
+	history, err := s.trackingRepo.GetLocationHistory(ctx, driverID, start, end, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load location history: %w", err)
+	}
+
+	// History comes newest first
+	points := make([]location.Point, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		fix := history[i]
+		if fix.Accuracy > maxFixAccuracyMeters {
+			continue
+		}
+		points = append(points, location.Point{
+			Latitude:  fix.Latitude,
+			Longitude: fix.Longitude,
+		})
+	}
+
+	if len(points) < 2 {
+		return route, nil
+	}
+
+	simplified := location.SimplifyPolyline(points, routeSimplifyToleranceKm)
+
+	distance := 0.0
+	for i := 1; i < len(simplified); i++ {
+		distance += location.CalculateDistance(simplified[i-1], simplified[i])
+	}
+
+	route.DistanceKm = math.Round(distance*100) / 100
+	route.Polyline = location.EncodePolyline(simplified)
+	route.Points = len(simplified)
+	route.HasGPS = true
+
+	return route, nil
+}
+
+// routeDiscrepancy describes how the driver's reported figures disagree with
+// the GPS route, or returns "" when they agree closely enough
+func routeDiscrepancy(route *gpsRoute, reportedDistance float64, reportedDuration int) string {
+	var reason string
+
+	if route.HasGPS && reportedDistance > 0 {
+		diff := math.Abs(reportedDistance - route.DistanceKm)
+		if diff > distanceDiscrepancyKm && diff > route.DistanceKm*relativeDiscrepancyRatio {
+			reason = fmt.Sprintf("reported distance %.2fkm vs GPS %.2fkm", reportedDistance, route.DistanceKm)
+		}
+	}
+
+	if reportedDuration > 0 {
+		diff := math.Abs(float64(reportedDuration - route.DurationSec))
+		if diff > durationDiscrepancySec && diff > float64(route.DurationSec)*relativeDiscrepancyRatio {
+			if reason != "" {
+				reason += "; "
+			}
+			reason += fmt.Sprintf("reported duration %ds vs measured %ds", reportedDuration, route.DurationSec)
+		}
+	}
+
+	return reason
+}
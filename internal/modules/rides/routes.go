@@ -2,17 +2,26 @@ package rides
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
 	rides := router.Group("/rides")
 	rides.Use(authMiddleware)
 	{
 		// Rider endpoints
-		rides.POST("", handler.CreateRide)
+		rides.POST("", middleware.Idempotency(), handler.CreateRide)
 		rides.GET("", handler.ListRides)
+		rides.GET("/scheduled", handler.ListScheduledRides)
+		rides.GET("/export", handler.ExportRides)
 		rides.GET("/:id", handler.GetRide)
 		rides.POST("/:id/cancel", handler.CancelRide)
+		rides.GET("/:id/receipt", handler.GetReceipt)
+		rides.GET("/:id/receipt/pdf", handler.GetReceiptPDF)
+		rides.PUT("/:id/stops", handler.UpdateStops)
+		rides.POST("/:id/destination", handler.ChangeDestination)
 
 		// Driver endpoints
 		rides.POST("/:id/accept", handler.AcceptRide)
@@ -20,5 +29,10 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 		rides.POST("/:id/arrived", handler.MarkArrived)
 		rides.POST("/:id/start", handler.StartRide)
 		rides.POST("/:id/complete", handler.CompleteRide)
+		rides.POST("/:id/stops/:stopId/arrived", handler.MarkStopArrived)
+		rides.POST("/:id/stops/:stopId/departed", handler.MarkStopDeparted)
+
+		// Support endpoints
+		rides.GET("/:id/dispatch", middleware.RequirePermission(checker, models.PermissionDispatchView), handler.GetDispatchState)
 	}
 }
@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/config"
 	"github.com/umar5678/go-backend/internal/models"
+	commissionservice "github.com/umar5678/go-backend/internal/modules/commission"
+	commissiondto "github.com/umar5678/go-backend/internal/modules/commission/dto"
 	driversrepo "github.com/umar5678/go-backend/internal/modules/drivers"
 	pricingservice "github.com/umar5678/go-backend/internal/modules/pricing"
 	pricingdto "github.com/umar5678/go-backend/internal/modules/pricing/dto"
@@ -20,6 +24,7 @@ import (
 	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
 	"github.com/umar5678/go-backend/internal/services/cache"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"github.com/umar5678/go-backend/internal/utils/response"
 	"github.com/umar5678/go-backend/internal/websocket"
 	websocketutil "github.com/umar5678/go-backend/internal/websocket/websocketutils"
@@ -31,7 +36,13 @@ type Service interface {
 	CreateRide(ctx context.Context, riderID string, req dto.CreateRideRequest) (*dto.RideResponse, error)
 	GetRide(ctx context.Context, userID, rideID string) (*dto.RideResponse, error)
 	ListRides(ctx context.Context, userID string, role string, req dto.ListRidesRequest) ([]*dto.RideListResponse, int64, error)
+	ListScheduledRides(ctx context.Context, riderID string, req dto.ListScheduledRidesRequest) ([]*dto.RideListResponse, int64, error)
 	CancelRide(ctx context.Context, userID, rideID string, req dto.CancelRideRequest) error
+	UpdateStops(ctx context.Context, riderID, rideID string, req dto.UpdateStopsRequest) (*dto.UpdateStopsResponse, error)
+	ChangeDestination(ctx context.Context, riderID, rideID string, req dto.ChangeDestinationRequest) (*dto.DestinationChangeResponse, error)
+	GetReceipt(ctx context.Context, riderID, rideID string) (*dto.ReceiptResponse, error)
+	GetReceiptPDF(ctx context.Context, riderID, rideID string) ([]byte, error)
+	ExportRides(ctx context.Context, userID string, req dto.ExportRidesRequest) ([]byte, error)
 
 	// Driver actions
 	AcceptRide(ctx context.Context, driverID, rideID string) (*dto.RideResponse, error)
@@ -39,20 +50,43 @@ type Service interface {
 	MarkArrived(ctx context.Context, driverID, rideID string) (*dto.RideResponse, error)
 	StartRide(ctx context.Context, driverID, rideID string) (*dto.RideResponse, error)
 	CompleteRide(ctx context.Context, driverID, rideID string, req dto.CompleteRideRequest) (*dto.RideResponse, error)
+	MarkStopArrived(ctx context.Context, driverID, rideID, stopID string) (*dto.RideResponse, error)
+	MarkStopDeparted(ctx context.Context, driverID, rideID, stopID string) (*dto.RideResponse, error)
+	RespondDestinationChange(ctx context.Context, userID, rideID, changeID string, accept bool) (*dto.RideResponse, error)
+
+	// Support
+	GetDispatchState(ctx context.Context, rideID string) (*dto.DispatchStateResponse, error)
 
 	// Internal
 	FindDriverForRide(ctx context.Context, rideID string) error
+	HandleDispatchFailure(ctx context.Context, rideID string) error
 	ProcessRideRequestTimeout(ctx context.Context, requestID string) error
+
+	// Background jobs
+	ExpireRideRequests(ctx context.Context) (int64, error)
+	CancelAbandonedSearches(ctx context.Context, olderThan time.Duration) (int64, error)
+	StartScheduledRides(ctx context.Context) (int64, error)
 }
 
+// Terminal search outcomes. The dispatcher gives up on these instead of retrying.
+var (
+	ErrNoDriversAvailable = errors.New("no drivers available in the area")
+	ErrNoDriverAccepted   = errors.New("no driver accepted the ride request")
+)
+
 type service struct {
-	repo            Repository
-	driversRepo     driversrepo.Repository
-	ridersRepo      ridersrepo.Repository
-	pricingService  pricingservice.Service
-	trackingService trackingservice.Service
-	walletService   walletservice.Service
-	wsHelper        *RideWebSocketHelper
+	repo              Repository
+	driversRepo       driversrepo.Repository
+	ridersRepo        ridersrepo.Repository
+	pricingService    pricingservice.Service
+	trackingService   trackingservice.Service
+	trackingRepo      trackingservice.Repository
+	walletService     walletservice.Service
+	commissionService commissionservice.Service
+	cities            CityResolver
+	wsHelper          *RideWebSocketHelper
+	ranker            DriverRanker
+	cfg               *config.Config
 }
 
 func NewService(
@@ -61,36 +95,99 @@ func NewService(
 	ridersRepo ridersrepo.Repository,
 	pricingService pricingservice.Service,
 	trackingService trackingservice.Service,
+	trackingRepo trackingservice.Repository,
 	walletService walletservice.Service,
+	commissionService commissionservice.Service,
+	cities CityResolver,
+	cfg *config.Config,
 ) Service {
 	return &service{
-		repo:            repo,
-		driversRepo:     driversRepo,
-		ridersRepo:      ridersRepo,
-		pricingService:  pricingService,
-		trackingService: trackingService,
-		walletService:   walletService,
-		wsHelper:        NewRideWebSocketHelper(),
+		repo:              repo,
+		driversRepo:       driversRepo,
+		ridersRepo:        ridersRepo,
+		pricingService:    pricingService,
+		trackingService:   trackingService,
+		trackingRepo:      trackingRepo,
+		walletService:     walletService,
+		commissionService: commissionService,
+		cities:            cities,
+		wsHelper:          NewRideWebSocketHelper(),
+		ranker:            NewWeightedDriverRanker(repo, cities),
+		cfg:               cfg,
 	}
 }
 
+// pickupCity names the city the ride starts in, or "" when no city source
+// is configured
+func (s *service) pickupCity(ctx context.Context, ride *models.Ride) string {
+	if s.cities == nil {
+		return ""
+	}
+	return s.cities.ResolveCity(ctx, ride.PickupLat, ride.PickupLon)
+}
+
 func (s *service) CreateRide(ctx context.Context, riderID string, req dto.CreateRideRequest) (*dto.RideResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, response.BadRequest(err.Error())
 	}
+	if req.IsScheduled() {
+		if err := s.validatePickupTime(*req.ScheduledAt); err != nil {
+			return nil, response.BadRequest(err.Error())
+		}
+	}
+
+	// 1. Price the ride. A quote locks the fare and surge the rider was
+	// shown; without one the ride is priced now. The quote is only consumed
+	// once the fare is held, so a rider who has to top up first can still
+	// book with it.
+	var fareEstimate *pricingdto.FareEstimateResponse
+	var quote *pricingdto.FareQuote
+	var quoteID *string
+
+	if req.QuoteID != "" {
+		var err error
+		quote, err = s.pricingService.GetQuote(ctx, req.QuoteID)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateQuote(quote, req); err != nil {
+			return nil, response.BadRequest(err.Error())
+		}
+
+		fareEstimate = &pricingdto.FareEstimateResponse{
+			TotalFare:         quote.TotalFare,
+			AirportFee:        quote.AirportFee,
+			PickupPoint:       quote.PickupPoint,
+			SurgeMultiplier:   quote.SurgeMultiplier,
+			EstimatedDistance: quote.EstimatedDistance,
+			EstimatedDuration: quote.EstimatedDuration,
+		}
+		quoteID = &quote.ID
+	} else {
+		fareReq := pricingdto.FareEstimateRequest{
+			PickupLat:     req.PickupLat,
+			PickupLon:     req.PickupLon,
+			DropoffLat:    req.DropoffLat,
+			DropoffLon:    req.DropoffLon,
+			VehicleTypeID: req.VehicleTypeID,
+			Stops:         toPricingStops(newRideStops("", 0, req.Stops)),
+		}
+
+		estimate, err := s.pricingService.GetFareEstimate(ctx, fareReq)
+		if err != nil {
+			return nil, err
+		}
+		fareEstimate = estimate
+	}
 
-	// 1. Get fare estimate
-	fareReq := pricingdto.FareEstimateRequest{
-		PickupLat:     req.PickupLat,
-		PickupLon:     req.PickupLon,
-		DropoffLat:    req.DropoffLat,
-		DropoffLon:    req.DropoffLon,
-		VehicleTypeID: req.VehicleTypeID,
+	// Airport pickups happen at the designated point the fare was priced from
+	if point := fareEstimate.PickupPoint; point != nil {
+		req.PickupLat, req.PickupLon = point.Lat, point.Lon
+		req.PickupAddress = point.Name
 	}
 
-	fareEstimate, err := s.pricingService.GetFareEstimate(ctx, fareReq)
-	if err != nil {
-		return nil, err
+	if req.IsScheduled() {
+		return s.scheduleRide(ctx, riderID, req, fareEstimate, quote)
 	}
 
 	rideID := uuid.New().String()
@@ -100,7 +197,7 @@ func (s *service) CreateRide(ctx context.Context, riderID string, req dto.Create
 		Amount:        fareEstimate.TotalFare,
 		ReferenceType: "ride",
 		ReferenceID:   rideID,
-		HoldDuration:  1800, // 30 minutes
+		HoldDuration:  rideHoldMinutes,
 	}
 
 	holdResp, err := s.walletService.HoldFunds(ctx, riderID, holdReq)
@@ -108,6 +205,10 @@ func (s *service) CreateRide(ctx context.Context, riderID string, req dto.Create
 		return nil, response.BadRequest("Insufficient wallet balance. Please add funds.")
 	}
 
+	if err := s.consumeQuote(ctx, riderID, quote, &holdResp.ID); err != nil {
+		return nil, err
+	}
+
 	// 3. Create ride
 	ride := &models.Ride{
 		ID:                rideID,
@@ -122,14 +223,19 @@ func (s *service) CreateRide(ctx context.Context, riderID string, req dto.Create
 		DropoffAddress:    req.DropoffAddress,
 		EstimatedDistance: fareEstimate.EstimatedDistance,
 		EstimatedDuration: fareEstimate.EstimatedDuration,
-		EstimatedFare:     fareEstimate.TotalFare,
+		EstimatedFare:     fareEstimate.TotalFare.Float64(),
+		AirportFee:        fareEstimate.AirportFee.Float64(),
 		SurgeMultiplier:   fareEstimate.SurgeMultiplier,
+		QuoteID:           quoteID,
 		WalletHoldID:      &holdResp.ID,
 		RiderNotes:        req.RiderNotes,
 		RequestedAt:       time.Now(),
+		Stops:             newRideStops(rideID, 0, req.Stops),
 	}
 
-	if err := s.repo.CreateRide(ctx, ride); err != nil {
+	// The driver search is queued with the ride. The dispatcher picks it up
+	// from the ride_dispatch_jobs table, so the search survives restarts.
+	if err := s.repo.CreateRide(ctx, ride, s.newDispatchJob()); err != nil {
 		s.walletService.ReleaseHold(ctx, riderID, walletdto.ReleaseHoldRequest{HoldID: holdResp.ID})
 		logger.Error("failed to create ride", "error", err, "riderID", riderID)
 		return nil, response.InternalServerError("Failed to create ride", err)
@@ -139,56 +245,179 @@ func (s *service) CreateRide(ctx context.Context, riderID string, req dto.Create
 	cacheKey := fmt.Sprintf("ride:active:%s", rideID)
 	cache.SetJSON(ctx, cacheKey, ride, 30*time.Minute)
 
-	// 5. Start finding driver asynchronously
-	go func() {
-		bgCtx := context.Background()
-		if err := s.FindDriverForRide(bgCtx, rideID); err != nil {
-			logger.Error("failed to find driver", "error", err, "rideID", rideID)
-
-			// ✅ CRITICAL FIX: Check ride status before canceling
-			currentRide, statusErr := s.repo.FindRideByID(bgCtx, rideID)
-			if statusErr != nil {
-				logger.Error("failed to fetch ride status", "error", statusErr, "rideID", rideID)
-				return
-			}
+	// 5. Notify rider via WebSocket
+	s.wsHelper.SendRideStatusToBoth(ctx, riderID, "", rideID, "searching", "Searching for nearby drivers...")
 
-			// ✅ If ride is already accepted/arrived/started, don't cancel
-			if currentRide.Status != "searching" {
-				logger.Info("ride already accepted by driver, not canceling",
-					"rideID", rideID,
-					"currentStatus", currentRide.Status,
-					"driverID", currentRide.DriverID,
-				)
-				return
-			}
+	logger.Info("ride created",
+		"rideID", rideID,
+		"riderID", riderID,
+		"estimatedFare", fareEstimate.TotalFare,
+		"surge", fareEstimate.SurgeMultiplier,
+		"quoted", quoteID != nil,
+	)
 
-			// Only cancel if still searching
-			if err := s.repo.UpdateRideStatus(bgCtx, rideID, "cancelled"); err != nil {
-				logger.Error("failed to update ride status", "error", err, "rideID", rideID)
-			}
+	ride, _ = s.repo.FindRideByID(ctx, rideID)
+	return dto.ToRideResponse(ride), nil
+}
 
-			if err := s.walletService.ReleaseHold(bgCtx, riderID, walletdto.ReleaseHoldRequest{HoldID: holdResp.ID}); err != nil {
-				logger.Error("failed to release hold", "error", err, "rideID", rideID)
-			}
+// rideHoldMinutes is how long a ride's wallet hold lasts once the search is
+// under way. It is renewed when a driver accepts and again when the trip
+// starts (see extendRideHold), and captured when the trip completes.
+const rideHoldMinutes = 30
+
+// tripHoldMinutes is how long a hold placed or renewed for a trip of
+// tripSeconds must last: the trip itself plus rideHoldMinutes of slack
+func tripHoldMinutes(tripSeconds int) int {
+	return rideHoldMinutes + int(math.Ceil(float64(tripSeconds)/60))
+}
+
+// extendRideHold keeps the ride's hold alive for another minutes, so a long
+// pickup or trip does not outlive it
+func (s *service) extendRideHold(ctx context.Context, ride *models.Ride, minutes int) {
+	if ride.WalletHoldID == nil {
+		return
+	}
+	if _, err := s.walletService.ExtendHold(ctx, ride.RiderID, walletdto.ExtendHoldRequest{
+		HoldID:       *ride.WalletHoldID,
+		HoldDuration: minutes,
+	}); err != nil {
+		logger.Error("failed to extend ride hold", "error", err, "rideID", ride.ID, "holdID", *ride.WalletHoldID)
+	}
+}
+
+// validatePickupTime checks a book-ahead pickup against the advance window
+func (s *service) validatePickupTime(pickupAt time.Time) error {
+	ahead := time.Until(pickupAt)
+	if ahead < s.cfg.Dispatch.ScheduledMinAdvance {
+		return fmt.Errorf("scheduled rides must be booked at least %s ahead", s.cfg.Dispatch.ScheduledMinAdvance)
+	}
+	if ahead > s.cfg.Dispatch.ScheduledMaxAdvance {
+		return fmt.Errorf("scheduled rides can be booked at most %s ahead", s.cfg.Dispatch.ScheduledMaxAdvance)
+	}
+	return nil
+}
+
+// scheduleRide books a ride for req.ScheduledAt. The fare is priced now, but
+// it is held and the search started only as pickup nears (see
+// StartScheduledRides), unless the booking already falls inside the hold
+// window, in which case the hold is placed straight away.
+func (s *service) scheduleRide(
+	ctx context.Context,
+	riderID string,
+	req dto.CreateRideRequest,
+	fareEstimate *pricingdto.FareEstimateResponse,
+	quote *pricingdto.FareQuote,
+) (*dto.RideResponse, error) {
+	rideID := uuid.New().String()
+	pickupAt := *req.ScheduledAt
 
-			s.wsHelper.SendRideStatusToBoth(bgCtx, riderID, "", rideID, "cancelled", "No drivers available. Your payment has been refunded.")
+	var holdID *string
+	if time.Until(pickupAt) <= s.cfg.Dispatch.ScheduledHoldLead {
+		hold, err := s.holdScheduledFare(ctx, riderID, rideID, fareEstimate.TotalFare, pickupAt)
+		if err != nil {
+			return nil, response.BadRequest("Insufficient wallet balance. Please add funds.")
 		}
-	}()
+		holdID = &hold.ID
+	}
 
-	// 6. Notify rider via WebSocket
-	s.wsHelper.SendRideStatusToBoth(ctx, riderID, "", rideID, "searching", "Searching for nearby drivers...")
+	if err := s.consumeQuote(ctx, riderID, quote, holdID); err != nil {
+		return nil, err
+	}
 
-	logger.Info("ride created",
+	var quoteID *string
+	if quote != nil {
+		quoteID = &quote.ID
+	}
+
+	ride := &models.Ride{
+		ID:                rideID,
+		RiderID:           riderID,
+		VehicleTypeID:     req.VehicleTypeID,
+		Status:            "scheduled",
+		PickupLat:         req.PickupLat,
+		PickupLon:         req.PickupLon,
+		PickupAddress:     req.PickupAddress,
+		DropoffLat:        req.DropoffLat,
+		DropoffLon:        req.DropoffLon,
+		DropoffAddress:    req.DropoffAddress,
+		EstimatedDistance: fareEstimate.EstimatedDistance,
+		EstimatedDuration: fareEstimate.EstimatedDuration,
+		EstimatedFare:     fareEstimate.TotalFare.Float64(),
+		AirportFee:        fareEstimate.AirportFee.Float64(),
+		SurgeMultiplier:   fareEstimate.SurgeMultiplier,
+		QuoteID:           quoteID,
+		WalletHoldID:      holdID,
+		RiderNotes:        req.RiderNotes,
+		RequestedAt:       time.Now(),
+		Stops:             newRideStops(rideID, 0, req.Stops),
+		ScheduledAt:       &pickupAt,
+	}
+
+	if err := s.repo.CreateRide(ctx, ride, nil); err != nil {
+		if holdID != nil {
+			s.walletService.ReleaseHold(ctx, riderID, walletdto.ReleaseHoldRequest{HoldID: *holdID})
+		}
+		logger.Error("failed to schedule ride", "error", err, "riderID", riderID)
+		return nil, response.InternalServerError("Failed to schedule ride", err)
+	}
+
+	s.wsHelper.SendRideStatusToBoth(ctx, riderID, "", rideID, "scheduled", "Your ride has been scheduled")
+
+	logger.Info("ride scheduled",
 		"rideID", rideID,
 		"riderID", riderID,
+		"scheduledAt", pickupAt,
 		"estimatedFare", fareEstimate.TotalFare,
-		"surge", fareEstimate.SurgeMultiplier,
+		"held", holdID != nil,
 	)
 
 	ride, _ = s.repo.FindRideByID(ctx, rideID)
 	return dto.ToRideResponse(ride), nil
 }
 
+// consumeQuote marks the ride's quote used, if it was booked with one. It runs
+// after the fare is held; when the quote can no longer be used (already
+// booked, expired) the hold is released again.
+func (s *service) consumeQuote(ctx context.Context, riderID string, quote *pricingdto.FareQuote, holdID *string) error {
+	if quote == nil {
+		return nil
+	}
+
+	if err := s.pricingService.ConsumeQuote(ctx, quote); err != nil {
+		if holdID != nil {
+			if releaseErr := s.walletService.ReleaseHold(ctx, riderID, walletdto.ReleaseHoldRequest{HoldID: *holdID}); releaseErr != nil {
+				logger.Error("failed to release hold after quote was rejected", "error", releaseErr, "holdID", *holdID)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// holdScheduledFare holds a scheduled ride's fare from now until
+// rideHoldMinutes after pickup
+func (s *service) holdScheduledFare(ctx context.Context, riderID, rideID string, fare money.Amount, pickupAt time.Time) (*walletdto.HoldResponse, error) {
+	untilPickup := int(math.Ceil(time.Until(pickupAt).Minutes()))
+	if untilPickup < 0 {
+		untilPickup = 0
+	}
+
+	return s.walletService.HoldFunds(ctx, riderID, walletdto.HoldFundsRequest{
+		Amount:        fare,
+		ReferenceType: "ride",
+		ReferenceID:   rideID,
+		HoldDuration:  untilPickup + rideHoldMinutes,
+	})
+}
+
+// rescanInterval is how long the search waits before scanning the radius
+// schedule again once every driver found so far has been offered the ride
+const rescanInterval = 5 * time.Second
+
+// FindDriverForRide offers the ride to nearby drivers in waves, following
+// the dispatch policy in config: WaveSize drivers at a time, each wave given
+// WaveTimeout to accept, radii widened per RadiusScheduleKm once every driver
+// in the current radius was offered, until MaxSearchTime runs out.
 func (s *service) FindDriverForRide(ctx context.Context, rideID string) error {
 	ride, err := s.repo.FindRideByID(ctx, rideID)
 	if err != nil {
@@ -199,63 +428,179 @@ func (s *service) FindDriverForRide(ctx context.Context, rideID string) error {
 		return nil
 	}
 
-	radii := []float64{3.0, 5.0, 8.0}
-	var nearbyDrivers *trackingdto.NearbyDriversResponse
+	// A resumed search may find requests left pending by the previous attempt;
+	// expire them so drivers cannot accept a request nobody is watching
+	if stale, err := s.repo.FindPendingRequestsForRide(ctx, rideID); err == nil {
+		for _, req := range stale {
+			s.repo.UpdateRideRequestStatus(ctx, req.ID, "cancelled_by_system", nil)
+		}
+	}
+
+	policy := s.cfg.Dispatch
+	searchCtx, cancel := context.WithTimeout(ctx, policy.MaxSearchTime)
+	defer cancel()
+
+	offered := make(map[string]bool)
+
+	for searchCtx.Err() == nil {
+		for _, radius := range policy.RadiusScheduleKm {
+			if searchCtx.Err() != nil {
+				break
+			}
+
+			candidates, err := s.findDispatchCandidates(searchCtx, ride, radius, offered)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if searchCtx.Err() != nil {
+					break
+				}
+				return fmt.Errorf("failed to find nearby drivers: %w", err)
+			}
+
+			for start := 0; start < len(candidates) && searchCtx.Err() == nil; start += policy.WaveSize {
+				wave := candidates[start:min(start+policy.WaveSize, len(candidates))]
+				for _, d := range wave {
+					offered[d.DriverID] = true
+				}
+
+				s.recordDispatchProgress(ctx, rideID, map[string]interface{}{
+					"drivers_contacted": gorm.Expr("drivers_contacted + ?", len(wave)),
+				})
+
+				logger.Info("sending ride offer wave",
+					"rideID", rideID,
+					"radius", radius,
+					"waveSize", len(wave),
+					"offeredSoFar", len(offered),
+				)
+
+				acceptedDriverID := s.runOfferWave(searchCtx, ride, wave, policy.WaveTimeout)
+
+				// The driver's own AcceptRide call normally assigns the ride;
+				// only assign here if that has not happened yet
+				current, err := s.repo.FindRideByID(ctx, rideID)
+				if err == nil && current.Status != "searching" {
+					return nil
+				}
+				if acceptedDriverID != "" {
+					return s.assignDriverToRide(ctx, rideID, acceptedDriverID)
+				}
+			}
+		}
 
-	for _, radius := range radii {
-		nearbyReq := trackingdto.FindNearbyDriversRequest{
-			Latitude:      ride.PickupLat,
-			Longitude:     ride.PickupLon,
-			RadiusKm:      radius,
-			VehicleTypeID: ride.VehicleTypeID,
-			Limit:         15,
-			OnlyAvailable: true,
+		// Every driver found so far has been offered the ride; give new
+		// drivers a chance to come online before scanning again
+		select {
+		case <-searchCtx.Done():
+		case <-time.After(rescanInterval):
 		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(offered) == 0 {
+		return ErrNoDriversAvailable
+	}
+	return ErrNoDriverAccepted
+}
+
+// findDispatchCandidates returns ranked available drivers within radius that
+// have not been offered the ride yet
+func (s *service) findDispatchCandidates(ctx context.Context, ride *models.Ride, radius float64, offered map[string]bool) ([]trackingdto.DriverLocationResponse, error) {
+	s.recordDispatchProgress(ctx, ride.ID, map[string]interface{}{"current_radius_km": radius})
+
+	nearbyReq := trackingdto.FindNearbyDriversRequest{
+		Latitude:      ride.PickupLat,
+		Longitude:     ride.PickupLon,
+		RadiusKm:      radius,
+		VehicleTypeID: ride.VehicleTypeID,
+		Limit:         s.cfg.Dispatch.CandidateLimit,
+		OnlyAvailable: true,
+	}
+
+	nearbyDrivers, err := s.trackingService.FindNearbyDrivers(ctx, nearbyReq)
+	if err != nil {
+		return nil, err
+	}
 
-		nearbyDrivers, err = s.trackingService.FindNearbyDrivers(ctx, nearbyReq)
-		if err == nil && nearbyDrivers.Count > 0 {
-			logger.Info("found nearby drivers at radius",
-				"radius", radius,
-				"count", nearbyDrivers.Count,
-				"rideID", rideID)
-			break
+	candidates := make([]trackingdto.DriverLocationResponse, 0, len(nearbyDrivers.Drivers))
+	for _, d := range nearbyDrivers.Drivers {
+		if !offered[d.DriverID] {
+			candidates = append(candidates, d)
 		}
-		time.Sleep(1 * time.Second)
 	}
 
-	if err != nil || nearbyDrivers == nil || nearbyDrivers.Count == 0 {
-		return errors.New("no drivers available in the area")
+	if len(candidates) == 0 {
+		return candidates, nil
 	}
 
-	logger.Info("found nearby drivers",
-		"rideID", rideID,
-		"driverCount", nearbyDrivers.Count,
+	logger.Info("found nearby drivers at radius",
+		"radius", radius,
+		"count", len(candidates),
+		"rideID", ride.ID,
 	)
 
-	maxConcurrentRequests := 3
-	timeout := 30 * time.Second
+	// Order candidates by the configured ranking instead of raw distance
+	ranked, err := s.ranker.Rank(ctx, ride, candidates)
+	if err != nil {
+		logger.Warn("failed to rank drivers, falling back to distance order",
+			"error", err,
+			"rideID", ride.ID,
+		)
+		return candidates, nil
+	}
 
-	resultChan := make(chan string, 1)
-	errorChan := make(chan error, 1)
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
+	return ranked, nil
+}
+
+// runOfferWave sends the ride to every driver in the wave and waits until one
+// accepts, all of them decline or expire, or the wave times out. It returns
+// the accepting driver's ID, or "" if nobody accepted.
+func (s *service) runOfferWave(ctx context.Context, ride *models.Ride, wave []trackingdto.DriverLocationResponse, timeout time.Duration) string {
+	waveCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	driversToContact := min(maxConcurrentRequests, nearbyDrivers.Count)
-	for i := 0; i < driversToContact; i++ {
-		driver := nearbyDrivers.Drivers[i]
-		go s.sendRideRequestToDriver(ctxWithTimeout, ride, driver, resultChan, errorChan)
+	resultChan := make(chan string, len(wave))
+	errorChan := make(chan error, len(wave))
+
+	var wg sync.WaitGroup
+	for _, driver := range wave {
+		wg.Add(1)
+		go func(driver trackingdto.DriverLocationResponse) {
+			defer wg.Done()
+			s.sendRideRequestToDriver(waveCtx, ride, driver, timeout, resultChan, errorChan)
+		}(driver)
 	}
 
-	select {
-	case acceptedDriverID := <-resultChan:
-		cancel()
-		return s.assignDriverToRide(ctx, rideID, acceptedDriverID)
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
 
-	case <-ctxWithTimeout.Done():
-		return errors.New("no driver accepted the ride request")
+	for {
+		select {
+		case acceptedDriverID := <-resultChan:
+			return acceptedDriverID
+
+		case err := <-errorChan:
+			// One unreachable driver should not sink the whole wave
+			logger.Warn("failed to offer ride to driver", "error", err, "rideID", ride.ID)
+
+		case <-allDone:
+			select {
+			case acceptedDriverID := <-resultChan:
+				return acceptedDriverID
+			default:
+				return ""
+			}
 
-	case err := <-errorChan:
-		return err
+		case <-waveCtx.Done():
+			return ""
+		}
 	}
 }
 
@@ -263,11 +608,12 @@ func (s *service) sendRideRequestToDriver(
 	ctx context.Context,
 	ride *models.Ride,
 	driver trackingdto.DriverLocationResponse,
+	expiresIn time.Duration,
 	resultChan chan<- string,
 	errorChan chan<- error,
 ) {
 	requestID := uuid.New().String()
-	expiresAt := time.Now().Add(10 * time.Second)
+	expiresAt := time.Now().Add(expiresIn)
 
 	driverDetails, err := s.driversRepo.FindDriverByID(ctx, driver.DriverID)
 	if err != nil {
@@ -316,7 +662,7 @@ func (s *service) sendRideRequestToDriver(
 		"estimatedFare": ride.EstimatedFare,
 		"distance":      driver.Distance,
 		"eta":           driver.ETA,
-		"expiresIn":     10,
+		"expiresIn":     int(expiresIn.Seconds()),
 		"riderNotes":    ride.RiderNotes,
 	}
 
@@ -507,6 +853,8 @@ func (s *service) AcceptRide(ctx context.Context, userID, rideID string) (*dto.R
 		return nil, err
 	}
 
+	s.extendRideHold(ctx, ride, rideHoldMinutes)
+
 	logger.Info("ride successfully accepted by driver",
 		"rideID", rideID,
 		"driverID", driverID,
@@ -671,6 +1019,9 @@ func (s *service) StartRide(ctx context.Context, userID, rideID string) (*dto.Ri
 		return nil, response.InternalServerError("Failed to start ride", err)
 	}
 
+	// The hold has to outlast the trip itself
+	s.extendRideHold(ctx, ride, tripHoldMinutes(ride.EstimatedDuration))
+
 	// ✅ Update driver status to on_trip
 	s.driversRepo.UpdateDriverStatus(ctx, driverID, "on_trip")
 
@@ -739,10 +1090,61 @@ func (s *service) CompleteRide(ctx context.Context, userID, rideID string, req d
 		return nil, response.BadRequest("Ride must be started first")
 	}
 
+	// Measure the trip from the GPS trace instead of trusting the driver app
+	completedAt := time.Now()
+	startedAt := completedAt
+	if ride.StartedAt != nil {
+		startedAt = *ride.StartedAt
+	}
+
+	route, err := s.buildGPSRoute(ctx, driverID, startedAt, completedAt)
+	if err != nil {
+		logger.Error("failed to build GPS route",
+			"error", err,
+			"rideID", rideID,
+			"driverID", driverID,
+		)
+		route = &gpsRoute{DurationSec: int(completedAt.Sub(startedAt).Seconds())}
+	}
+
+	actualDistance := route.DistanceKm
+	actualDuration := route.DurationSec
+	flagReason := routeDiscrepancy(route, req.ActualDistance, req.ActualDuration)
+
+	if !route.HasGPS {
+		// Without a usable trace the driver's figure cannot be verified;
+		// bill the quoted distance and leave the ride for review
+		actualDistance = ride.EstimatedDistance
+		if flagReason != "" {
+			flagReason += "; "
+		}
+		flagReason += "insufficient GPS data, billed estimated distance"
+	}
+
+	// Pricing rejects zero values; a ride that never moved pays the minimum
+	if actualDistance <= 0 {
+		actualDistance = 0.01
+	}
+	if actualDuration <= 0 {
+		actualDuration = 1
+	}
+
+	if flagReason != "" {
+		logger.Warn("ride route flagged",
+			"rideID", rideID,
+			"driverID", driverID,
+			"reason", flagReason,
+			"gpsDistance", route.DistanceKm,
+			"gpsPoints", route.Points,
+			"reportedDistance", req.ActualDistance,
+			"reportedDuration", req.ActualDuration,
+		)
+	}
+
 	// Calculate actual fare
 	actualFareReq := pricingdto.CalculateActualFareRequest{
-		ActualDistanceKm:  req.ActualDistance,
-		ActualDurationSec: req.ActualDuration,
+		ActualDistanceKm:  actualDistance,
+		ActualDurationSec: actualDuration,
 		VehicleTypeID:     ride.VehicleTypeID,
 		SurgeMultiplier:   ride.SurgeMultiplier,
 	}
@@ -752,27 +1154,45 @@ func (s *service) CompleteRide(ctx context.Context, userID, rideID string, req d
 		return nil, err
 	}
 
-	// Update ride
-	ride.ActualDistance = &req.ActualDistance
-	ride.ActualDuration = &req.ActualDuration
-	ride.ActualFare = &actualFareResp.TotalFare
-	ride.Status = "completed"
-	ride.CompletedAt = &time.Time{}
-	*ride.CompletedAt = time.Now()
+	// Airport fees are flat and come on top of the metered fare
+	airportFee := money.FromFloat(ride.AirportFee, money.DefaultCurrency, money.HalfUp)
+	actualFareResp.TotalFare = actualFareResp.TotalFare.Add(airportFee)
 
-	if err := s.repo.UpdateRide(ctx, ride); err != nil {
-		return nil, response.InternalServerError("Failed to complete ride", err)
+	// A rider who booked on a quote pays at most the quote plus tolerance
+	if ride.QuoteID != nil {
+		meteredFare := actualFareResp.TotalFare
+		actualFareResp.TotalFare, ride.FareCapped = s.capToQuote(money.FromFloat(ride.EstimatedFare, money.DefaultCurrency, money.HalfUp), ride.EstimatedDistance, actualDistance, meteredFare)
+		if ride.FareCapped {
+			logger.Info("ride fare capped to quote",
+				"rideID", rideID,
+				"quotedFare", ride.EstimatedFare,
+				"meteredFare", meteredFare,
+				"chargedFare", actualFareResp.TotalFare,
+			)
+		}
 	}
 
-	// Capture hold
-	if ride.WalletHoldID != nil {
-		captureReq := walletdto.CaptureHoldRequest{
-			HoldID:      *ride.WalletHoldID,
-			Amount:      &actualFareResp.TotalFare,
-			Description: fmt.Sprintf("Payment for ride %s", rideID),
-		}
+	// Driver payout is the fare minus the platform commission in force when
+	// the ride completed. The platform passes airport fees on to the airport,
+	// so they are not part of the split.
+	quote, err := s.commissionService.Calculate(ctx, commissiondto.CalculateRequest{
+		ServiceLine:   models.ServiceLineRide,
+		City:          s.pickupCity(ctx, ride),
+		VehicleTypeID: ride.VehicleTypeID,
+		Tier:          driver.Tier,
+		Amount:        money.Max(money.Zero(money.DefaultCurrency), actualFareResp.TotalFare.Sub(airportFee)),
+		At:            completedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	driverEarnings := quote.Payout.Float64()
+	actualFare := actualFareResp.TotalFare.Float64()
 
-		if _, err := s.walletService.CaptureHold(ctx, ride.RiderID, captureReq); err != nil {
+	// Collect the fare before the ride is marked completed; if the rider
+	// cannot be charged the driver must not be paid
+	if ride.WalletHoldID != nil {
+		if err := s.captureRideFare(ctx, ride, actualFareResp.TotalFare); err != nil {
 			logger.Error("failed to capture hold",
 				"error", err,
 				"rideID", rideID,
@@ -781,24 +1201,54 @@ func (s *service) CompleteRide(ctx context.Context, userID, rideID string, req d
 				"driverUserID", driverUserID,
 				"driverName", driver.User.Name,
 			)
+			return nil, response.InternalServerError("Payment processing failed", err)
 		}
 	}
 
-	// Credit driver (80% commission)
-	driverEarnings := actualFareResp.TotalFare * 0.80
+	// Update ride
+	ride.ActualDistance = &actualDistance
+	ride.ActualDuration = &actualDuration
+	ride.ActualFare = &actualFare
+	if req.ActualDistance > 0 {
+		ride.ReportedDistance = &req.ActualDistance
+	}
+	if req.ActualDuration > 0 {
+		ride.ReportedDuration = &req.ActualDuration
+	}
+	ride.RoutePolyline = route.Polyline
+	ride.RouteFlagged = flagReason != ""
+	ride.RouteFlagReason = flagReason
+	ride.Status = "completed"
+	ride.CompletedAt = &completedAt
+
+	if err := s.repo.UpdateRide(ctx, ride); err != nil {
+		return nil, response.InternalServerError("Failed to complete ride", err)
+	}
+
+	if len(ride.Stops) > 0 {
+		if err := s.repo.CloseStops(ctx, rideID); err != nil {
+			logger.Error("failed to close ride stops", "error", err, "rideID", rideID)
+		}
+	}
+
+	// Credit driver
+
+	metadata := quote.Metadata()
+	metadata["totalFare"] = actualFare
+	if airportFee.IsPositive() {
+		metadata["airportFee"] = ride.AirportFee
+	}
 
 	// ✅ Use driver user ID for wallet credit
 	s.walletService.CreditWallet(
 		ctx,
 		driverUserID, // ✅ Use driver's user ID, not driver profile ID
-		driverEarnings,
+		quote.Payout,
+		quote.Commission,
 		"ride",
 		rideID,
 		fmt.Sprintf("Earnings from ride %s", rideID),
-		map[string]interface{}{
-			"totalFare":  actualFareResp.TotalFare,
-			"commission": 0.20,
-		},
+		metadata,
 	)
 
 	// Update stats
@@ -846,8 +1296,10 @@ func (s *service) CompleteRide(ctx context.Context, userID, rideID string, req d
 		"riderID", ride.RiderID,
 		"actualFare", actualFareResp.TotalFare,
 		"driverEarnings", driverEarnings,
-		"actualDistance", req.ActualDistance,
-		"actualDuration", req.ActualDuration,
+		"commissionRuleVersion", quote.RuleVersion,
+		"actualDistance", actualDistance,
+		"actualDuration", actualDuration,
+		"routeFlagged", ride.RouteFlagged,
 	)
 
 	// Refresh ride data from DB
@@ -857,6 +1309,30 @@ func (s *service) CompleteRide(ctx context.Context, userID, rideID string, req d
 }
 
 // ✅ UPDATED assignDriverToRide with all status updates + improvements
+// captureRideFare charges fare from the ride's hold, growing the hold first
+// when the metered fare came out above what was held
+func (s *service) captureRideFare(ctx context.Context, ride *models.Ride, fare money.Amount) error {
+	held, err := s.rideHoldAmount(ctx, ride)
+	if err != nil {
+		return err
+	}
+	if fare.GreaterThan(money.FromFloat(held, money.DefaultCurrency, money.HalfUp)) {
+		if _, err := s.walletService.IncreaseHold(ctx, ride.RiderID, walletdto.IncreaseHoldRequest{
+			HoldID: *ride.WalletHoldID,
+			Amount: fare,
+		}); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.walletService.CaptureHold(ctx, ride.RiderID, walletdto.CaptureHoldRequest{
+		HoldID:      *ride.WalletHoldID,
+		Amount:      &fare,
+		Description: fmt.Sprintf("Payment for ride %s", ride.ID),
+	})
+	return err
+}
+
 func (s *service) assignDriverToRide(ctx context.Context, rideID, driverID string) error {
 	fmt.Println("Run func: assignDriverToRide")
 
@@ -923,9 +1399,14 @@ func (s *service) assignDriverToRide(ctx context.Context, rideID, driverID strin
 		driverLat = driverLocation.Latitude
 		driverLon = driverLocation.Longitude
 
-		// Calculate real ETA based on distance
-		distance := calculateDistance(driverLat, driverLon, ride.PickupLat, ride.PickupLon)
-		calculatedETA = int((distance / 30.0) * 60) // 30 km/h avg city speed
+		// ETA to pickup in minutes, over the road network
+		etaSec, etaErr := s.trackingService.CalculateETA(ctx, driverLat, driverLon, ride.PickupLat, ride.PickupLon)
+		if etaErr != nil {
+			logger.Warn("failed to calculate ETA to pickup", "error", etaErr, "rideID", rideID)
+			calculatedETA = 10
+		} else {
+			calculatedETA = int(math.Ceil(float64(etaSec) / 60))
+		}
 		if calculatedETA < 1 {
 			calculatedETA = 1
 		}
@@ -990,6 +1471,71 @@ func (s *service) assignDriverToRide(ctx context.Context, rideID, driverID strin
 	return nil
 }
 
+// HandleDispatchFailure cancels a ride whose driver search was exhausted,
+// releases the rider's hold and tells the rider
+func (s *service) HandleDispatchFailure(ctx context.Context, rideID string) error {
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return err
+	}
+
+	// A driver may have accepted while the search was winding down
+	if ride.Status != "searching" {
+		logger.Info("ride left searching before dispatch failed, not canceling",
+			"rideID", rideID,
+			"currentStatus", ride.Status,
+			"driverID", ride.DriverID,
+		)
+		return nil
+	}
+
+	if err := s.repo.UpdateRideStatus(ctx, rideID, "cancelled"); err != nil {
+		return err
+	}
+
+	if ride.WalletHoldID != nil {
+		if err := s.walletService.ReleaseHold(ctx, ride.RiderID, walletdto.ReleaseHoldRequest{HoldID: *ride.WalletHoldID}); err != nil {
+			logger.Error("failed to release hold", "error", err, "rideID", rideID)
+		}
+	}
+
+	cache.Delete(ctx, fmt.Sprintf("ride:active:%s", rideID))
+
+	s.wsHelper.SendRideStatusToBoth(ctx, ride.RiderID, "", rideID, "cancelled", "No drivers available. Your payment has been refunded.")
+	return nil
+}
+
+// GetDispatchState returns the dispatch job and every request sent for a ride
+func (s *service) GetDispatchState(ctx context.Context, rideID string) (*dto.DispatchStateResponse, error) {
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return nil, response.NotFoundError("Ride")
+	}
+
+	job, err := s.repo.FindDispatchJobByRideID(ctx, rideID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.NotFoundError("Dispatch job")
+		}
+		return nil, response.InternalServerError("Failed to fetch dispatch job", err)
+	}
+
+	requests, err := s.repo.FindRideRequestsByRideID(ctx, rideID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch ride requests", err)
+	}
+
+	return dto.ToDispatchStateResponse(ride.Status, job, requests), nil
+}
+
+// recordDispatchProgress updates the ride's dispatch job so support can see
+// how far the search has got. Failures are logged, never fatal to the search.
+func (s *service) recordDispatchProgress(ctx context.Context, rideID string, updates map[string]interface{}) {
+	if err := s.repo.UpdateDispatchJobByRideID(ctx, rideID, updates); err != nil {
+		logger.Warn("failed to record dispatch progress", "error", err, "rideID", rideID)
+	}
+}
+
 func (s *service) ProcessRideRequestTimeout(ctx context.Context, requestID string) error {
 	request, err := s.repo.FindRideRequestByID(ctx, requestID)
 	if err != nil {
@@ -1003,6 +1549,183 @@ func (s *service) ProcessRideRequestTimeout(ctx context.Context, requestID strin
 	return nil
 }
 
+// ExpireRideRequests marks driver offers that were never answered as expired
+func (s *service) ExpireRideRequests(ctx context.Context) (int64, error) {
+	return s.repo.ExpireOldRequests(ctx)
+}
+
+// abandonedSearchBatch caps how many rides one sweep cancels
+const abandonedSearchBatch = 100
+
+// CancelAbandonedSearches cancels rides that have been searching for longer
+// than olderThan with no worker running their search (e.g. the job was lost
+// or gave up without cancelling the ride), releasing the rider's hold
+func (s *service) CancelAbandonedSearches(ctx context.Context, olderThan time.Duration) (int64, error) {
+	rides, err := s.repo.FindAbandonedSearchingRides(ctx, time.Now().Add(-olderThan), abandonedSearchBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var cancelled int64
+	for _, ride := range rides {
+		if err := s.HandleDispatchFailure(ctx, ride.ID); err != nil {
+			logger.Error("failed to cancel abandoned ride", "error", err, "rideID", ride.ID)
+			continue
+		}
+
+		now := time.Now()
+		s.recordDispatchProgress(ctx, ride.ID, map[string]interface{}{
+			"status":       models.DispatchStatusFailed,
+			"last_error":   "search abandoned",
+			"locked_by":    nil,
+			"locked_until": nil,
+			"finished_at":  now,
+		})
+
+		logger.Info("abandoned ride search cancelled", "rideID", ride.ID, "requestedAt", ride.RequestedAt)
+		cancelled++
+	}
+
+	return cancelled, nil
+}
+
+// scheduledRideBatch caps how many scheduled rides one sweep handles
+const scheduledRideBatch = 100
+
+// errNoLongerScheduled means the rider cancelled (or another sweep started)
+// the ride while a sweep was working on it
+var errNoLongerScheduled = errors.New("ride is no longer scheduled")
+
+// StartScheduledRides moves booked rides along as pickup nears. Within
+// ScheduledHoldLead of pickup the fare is held, or held again if the earlier
+// hold is gone; within ScheduledDispatchLead the driver search starts. A ride
+// whose fare still cannot be held when its search is due is cancelled.
+// Returns the number of rides held, started or cancelled.
+func (s *service) StartScheduledRides(ctx context.Context) (int64, error) {
+	now := time.Now()
+	rides, err := s.repo.FindDueScheduledRides(ctx, now.Add(s.cfg.Dispatch.ScheduledHoldLead), scheduledRideBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var affected int64
+	for _, ride := range rides {
+		placed, err := s.ensureScheduledHold(ctx, ride)
+		if errors.Is(err, errNoLongerScheduled) {
+			continue
+		}
+		if err != nil {
+			logger.Warn("failed to hold scheduled ride fare", "error", err, "rideID", ride.ID, "riderID", ride.RiderID)
+		}
+		if placed {
+			affected++
+		}
+
+		if ride.ScheduledAt.Sub(now) > s.cfg.Dispatch.ScheduledDispatchLead {
+			continue
+		}
+
+		if ride.WalletHoldID == nil {
+			if err := s.cancelUnfundedScheduledRide(ctx, ride); err != nil {
+				logger.Error("failed to cancel unfunded scheduled ride", "error", err, "rideID", ride.ID)
+				continue
+			}
+		} else if err := s.startScheduledRide(ctx, ride); err != nil {
+			if !errors.Is(err, errNoLongerScheduled) {
+				logger.Error("failed to start scheduled ride", "error", err, "rideID", ride.ID)
+			}
+			continue
+		}
+		if !placed {
+			affected++
+		}
+	}
+
+	return affected, nil
+}
+
+// ensureScheduledHold makes sure a scheduled ride has a live wallet hold,
+// placing a new one if it has none or its hold was released or expired.
+// Reports whether a hold was placed.
+func (s *service) ensureScheduledHold(ctx context.Context, ride *models.Ride) (bool, error) {
+	if ride.WalletHoldID != nil {
+		holds, err := s.walletService.GetHoldsByReference(ctx, "ride", ride.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, hold := range holds {
+			if hold.ID == *ride.WalletHoldID && hold.Status == models.TransactionStatusHeld {
+				return false, nil
+			}
+		}
+		ride.WalletHoldID = nil
+	}
+
+	hold, err := s.holdScheduledFare(ctx, ride.RiderID, ride.ID, money.FromFloat(ride.EstimatedFare, money.DefaultCurrency, money.HalfUp), *ride.ScheduledAt)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := s.repo.SetScheduledRideHold(ctx, ride.ID, hold.ID)
+	if err != nil || !updated {
+		s.walletService.ReleaseHold(ctx, ride.RiderID, walletdto.ReleaseHoldRequest{HoldID: hold.ID})
+		if err == nil {
+			err = errNoLongerScheduled
+		}
+		return false, err
+	}
+
+	ride.WalletHoldID = &hold.ID
+	logger.Info("scheduled ride fare held", "rideID", ride.ID, "holdID", hold.ID, "scheduledAt", ride.ScheduledAt)
+	return true, nil
+}
+
+// startScheduledRide moves a scheduled ride to searching and queues its
+// driver search, the same way CreateRide does for an immediate ride
+func (s *service) startScheduledRide(ctx context.Context, ride *models.Ride) error {
+	// Still scheduled if this fails, so the next sweep tries again
+	started, err := s.repo.StartScheduledRide(ctx, ride.ID, s.newDispatchJob())
+	if err != nil {
+		return err
+	}
+	if !started {
+		return errNoLongerScheduled
+	}
+
+	ride.Status = "searching"
+	cacheKey := fmt.Sprintf("ride:active:%s", ride.ID)
+	cache.SetJSON(ctx, cacheKey, ride, 30*time.Minute)
+
+	s.wsHelper.SendRideStatusToBoth(ctx, ride.RiderID, "", ride.ID, "searching", "Searching for drivers for your scheduled ride...")
+
+	logger.Info("scheduled ride search started", "rideID", ride.ID, "scheduledAt", ride.ScheduledAt)
+	return nil
+}
+
+// newDispatchJob is a driver search ready to run now; the repository sets
+// its ride
+func (s *service) newDispatchJob() *models.RideDispatchJob {
+	return &models.RideDispatchJob{
+		Status:      models.DispatchStatusPending,
+		MaxAttempts: s.cfg.Dispatch.MaxAttempts,
+		NextRunAt:   time.Now(),
+	}
+}
+
+// cancelUnfundedScheduledRide cancels a scheduled ride whose fare could not
+// be held by the time its search was due
+func (s *service) cancelUnfundedScheduledRide(ctx context.Context, ride *models.Ride) error {
+	cancelled, err := s.repo.CancelScheduledRide(ctx, ride.ID, "insufficient wallet balance")
+	if err != nil || !cancelled {
+		return err
+	}
+
+	s.wsHelper.SendRideStatusToBoth(ctx, ride.RiderID, "", ride.ID, "cancelled", "Your scheduled ride was cancelled: insufficient wallet balance.")
+
+	logger.Info("unfunded scheduled ride cancelled", "rideID", ride.ID, "riderID", ride.RiderID, "scheduledAt", ride.ScheduledAt)
+	return nil
+}
+
 // ✅ UPDATED GetRide with driver location enrichment
 func (s *service) GetRide(ctx context.Context, userID, rideID string) (*dto.RideResponse, error) {
 	// Try cache first
@@ -1084,6 +1807,23 @@ func (s *service) ListRides(ctx context.Context, userID string, role string, req
 	return result, total, nil
 }
 
+// ListScheduledRides returns the rider's upcoming bookings, soonest first
+func (s *service) ListScheduledRides(ctx context.Context, riderID string, req dto.ListScheduledRidesRequest) ([]*dto.RideListResponse, int64, error) {
+	req.SetDefaults()
+
+	rides, total, err := s.repo.ListScheduledRides(ctx, riderID, req.Page, req.Limit)
+	if err != nil {
+		return nil, 0, response.InternalServerError("Failed to fetch scheduled rides", err)
+	}
+
+	result := make([]*dto.RideListResponse, len(rides))
+	for i, ride := range rides {
+		result[i] = dto.ToRideListResponse(ride)
+	}
+
+	return result, total, nil
+}
+
 // ✅ FIXED: Handle both rider and driver authorization correctly
 func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto.CancelRideRequest) error {
 	ride, err := s.repo.FindRideByID(ctx, rideID)
@@ -1137,8 +1877,8 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 
 	// ✅ Fee Structure based on ride status
 	switch ride.Status {
-	case "searching":
-		// No fees during search
+	case "scheduled", "searching":
+		// No fees before a driver is assigned
 		riderCancellationFee = 0.0
 		driverPenalty = 0.0
 
@@ -1163,6 +1903,8 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 		}
 	}
 
+	wasSearching := ride.Status == "searching"
+
 	// Update ride
 	ride.Status = "cancelled"
 	ride.CancellationReason = req.Reason
@@ -1174,6 +1916,16 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 		return response.InternalServerError("Failed to cancel ride", err)
 	}
 
+	// Stop the driver search; the worker notices on its next lease heartbeat
+	if wasSearching {
+		s.recordDispatchProgress(ctx, rideID, map[string]interface{}{
+			"status":       models.DispatchStatusCancelled,
+			"locked_by":    nil,
+			"locked_until": nil,
+			"finished_at":  time.Now(),
+		})
+	}
+
 	logger.Info("ride cancellation initiated",
 		"rideID", rideID,
 		"cancelledBy", cancelledBy,
@@ -1205,13 +1957,15 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 
 	// ✅ UPDATED: Wallet transaction processing based on scenario
 	if ride.WalletHoldID != nil {
+		cancellationFee := money.FromFloat(riderCancellationFee, money.DefaultCurrency, money.HalfUp)
+		penalty := money.FromFloat(driverPenalty, money.DefaultCurrency, money.HalfUp)
 		switch {
 		// Scenario 1: Rider cancels ongoing ride
 		case ride.Status == "started" && isRider:
 			// Capture full cancellation fee from rider's hold
 			if _, err := s.walletService.CaptureHold(ctx, ride.RiderID, walletdto.CaptureHoldRequest{
 				HoldID:      *ride.WalletHoldID,
-				Amount:      &riderCancellationFee,
+				Amount:      &cancellationFee,
 				Description: "Cancellation fee for ongoing ride",
 			}); err != nil {
 				logger.Error("failed to capture ongoing ride cancellation fee", "error", err, "rideID", rideID)
@@ -1227,7 +1981,8 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 					s.walletService.CreditWallet(
 						ctx,
 						driver.UserID,
-						riderCancellationFee,
+						cancellationFee,
+						money.Zero(money.DefaultCurrency),
 						"cancellation_fee",
 						rideID,
 						"Compensation for cancelled ongoing ride",
@@ -1262,7 +2017,7 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 				if _, err := s.walletService.DebitWallet(
 					ctx,
 					driver.UserID,
-					driverPenalty,
+					penalty,
 					"cancellation_penalty",
 					rideID,
 					"Penalty for cancelling ongoing ride",
@@ -1281,7 +2036,8 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 					s.walletService.CreditWallet(
 						ctx,
 						ride.RiderID,
-						compensationAmount,
+						money.FromFloat(compensationAmount, money.DefaultCurrency, money.HalfUp),
+						money.Zero(money.DefaultCurrency),
 						"cancellation_compensation",
 						rideID,
 						"Compensation for driver cancelling ongoing ride",
@@ -1301,7 +2057,7 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 			// Capture standard cancellation fee
 			if _, err := s.walletService.CaptureHold(ctx, ride.RiderID, walletdto.CaptureHoldRequest{
 				HoldID:      *ride.WalletHoldID,
-				Amount:      &riderCancellationFee,
+				Amount:      &cancellationFee,
 				Description: "Cancellation fee",
 			}); err != nil {
 				logger.Error("failed to capture cancellation fee", "error", err, "rideID", rideID)
@@ -1317,7 +2073,8 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 					s.walletService.CreditWallet(
 						ctx,
 						driver.UserID,
-						riderCancellationFee,
+						cancellationFee,
+						money.Zero(money.DefaultCurrency),
 						"cancellation_fee",
 						rideID,
 						"Cancellation fee compensation",
@@ -1345,7 +2102,7 @@ func (s *service) CancelRide(ctx context.Context, userID, rideID string, req dto
 			if _, err := s.walletService.DebitWallet(
 				ctx,
 				driver.UserID,
-				driverPenalty,
+				penalty,
 				"cancellation_penalty",
 				rideID,
 				"Penalty for cancelling accepted ride",
@@ -1709,20 +2466,3 @@ func min(a, b int) int {
 	}
 	return b
 }
-
-// ✅ HELPER FUNCTION: Calculate distance between two coordinates
-func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	// Haversine formula
-	const earthRadius = 6371.0 // kilometers
-
-	dLat := (lat2 - lat1) * (3.14159265359 / 180.0)
-	dLon := (lon2 - lon1) * (3.14159265359 / 180.0)
-
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*(3.14159265359/180.0))*math.Cos(lat2*(3.14159265359/180.0))*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-
-	return earthRadius * c
-}
@@ -0,0 +1,279 @@
+package rides
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/umar5678/go-backend/internal/models"
+	pricingdto "github.com/umar5678/go-backend/internal/modules/pricing/dto"
+	"github.com/umar5678/go-backend/internal/modules/rides/dto"
+	walletdto "github.com/umar5678/go-backend/internal/modules/wallet/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"github.com/umar5678/go-backend/internal/websocket"
+	"gorm.io/gorm"
+)
+
+// Stops are visited in sequence. The driver marks arrival and departure at
+// each one while the trip is under way; the rider can change the stops not
+// reached yet at any point before the trip ends, which reprices the ride at
+// its booked surge and moves the wallet hold to the new fare.
+
+// maxRideStops matches the max=3 binding on the stop lists in the DTOs
+const maxRideStops = 3
+
+// editableStatuses are the ride statuses in which the rider may change stops
+var editableStatuses = map[string]bool{
+	"scheduled": true,
+	"searching": true,
+	"accepted":  true,
+	"arrived":   true,
+	"started":   true,
+}
+
+// newRideStops builds pending stops numbered from after+1
+func newRideStops(rideID string, after int, stops []dto.StopRequest) []models.RideStop {
+	result := make([]models.RideStop, len(stops))
+	for i, stop := range stops {
+		result[i] = models.RideStop{
+			RideID:   rideID,
+			Sequence: after + i + 1,
+			Lat:      stop.Lat,
+			Lon:      stop.Lon,
+			Address:  stop.Address,
+			Status:   models.RideStopStatusPending,
+		}
+	}
+	return result
+}
+
+func toPricingStops(stops []models.RideStop) []pricingdto.StopPoint {
+	points := make([]pricingdto.StopPoint, len(stops))
+	for i, stop := range stops {
+		points[i] = pricingdto.StopPoint{Lat: stop.Lat, Lon: stop.Lon}
+	}
+	return points
+}
+
+func (s *service) UpdateStops(ctx context.Context, riderID, rideID string, req dto.UpdateStopsRequest) (*dto.UpdateStopsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, response.BadRequest(err.Error())
+	}
+
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return nil, response.NotFoundError("Ride")
+	}
+	if ride.RiderID != riderID {
+		return nil, response.ForbiddenError("Not authorized to change this ride")
+	}
+	if !editableStatuses[ride.Status] {
+		return nil, response.BadRequest("Stops can only be changed before the ride ends")
+	}
+
+	// Stops already reached stay; the rest are replaced
+	var reached []models.RideStop
+	for _, stop := range ride.Stops {
+		if stop.Reached() {
+			reached = append(reached, stop)
+		}
+	}
+	if len(reached)+len(req.Stops) > maxRideStops {
+		return nil, response.BadRequest(fmt.Sprintf("A ride can have at most %d stops", maxRideStops))
+	}
+
+	lastSequence := 0
+	if len(reached) > 0 {
+		lastSequence = reached[len(reached)-1].Sequence
+	}
+	pending := newRideStops(rideID, lastSequence, req.Stops)
+
+	// Reprice the whole trip at the surge the ride was booked with
+	fare, err := s.pricingService.EstimateTripFare(ctx, pricingdto.FareEstimateRequest{
+		PickupLat:     ride.PickupLat,
+		PickupLon:     ride.PickupLon,
+		DropoffLat:    ride.DropoffLat,
+		DropoffLon:    ride.DropoffLon,
+		VehicleTypeID: ride.VehicleTypeID,
+		Stops:         toPricingStops(append(reached, pending...)),
+	}, ride.SurgeMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"estimated_distance": fare.EstimatedDistance,
+		"estimated_duration": fare.EstimatedDuration,
+		"estimated_fare":     fare.TotalFare.Float64(),
+		"airport_fee":        fare.AirportFee.Float64(),
+	}
+
+	// Hold the new fare before letting go of the old hold, so the ride is
+	// never left uncovered
+	var newHoldID string
+	if ride.WalletHoldID != nil && fare.TotalFare.Float64() != ride.EstimatedFare {
+		holdReq := walletdto.HoldFundsRequest{
+			Amount:        fare.TotalFare,
+			ReferenceType: "ride",
+			ReferenceID:   rideID,
+			HoldDuration:  tripHoldMinutes(fare.EstimatedDuration),
+		}
+
+		var hold *walletdto.HoldResponse
+		if ride.Status == "scheduled" {
+			hold, err = s.holdScheduledFare(ctx, riderID, rideID, holdReq.Amount, *ride.ScheduledAt)
+		} else {
+			hold, err = s.walletService.HoldFunds(ctx, riderID, holdReq)
+		}
+		if err != nil {
+			return nil, response.BadRequest("Insufficient wallet balance for the new stops. Please add funds.")
+		}
+		newHoldID = hold.ID
+		updates["wallet_hold_id"] = newHoldID
+	}
+
+	if err := s.repo.ReplacePendingStops(ctx, rideID, pending, updates); err != nil {
+		if newHoldID != "" {
+			s.walletService.ReleaseHold(ctx, riderID, walletdto.ReleaseHoldRequest{HoldID: newHoldID})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, response.BadRequest("Stops can only be changed before the ride ends")
+		}
+		return nil, response.InternalServerError("Failed to update stops", err)
+	}
+
+	if newHoldID != "" {
+		if err := s.walletService.ReleaseHold(ctx, riderID, walletdto.ReleaseHoldRequest{HoldID: *ride.WalletHoldID}); err != nil {
+			logger.Error("failed to release replaced hold", "error", err, "rideID", rideID, "holdID", *ride.WalletHoldID)
+		}
+	}
+
+	cache.Delete(ctx, fmt.Sprintf("ride:active:%s", rideID))
+
+	ride, _ = s.repo.FindRideByID(ctx, rideID)
+	rideResp := dto.ToRideResponse(ride)
+
+	s.wsHelper.SendTripEvent(ctx, ride.RiderID, s.driverUserID(ctx, ride), websocket.TypeRideStopsUpdated, map[string]interface{}{
+		"rideId":        rideID,
+		"stops":         rideResp.Stops,
+		"estimatedFare": ride.EstimatedFare,
+	})
+
+	logger.Info("ride stops updated",
+		"rideID", rideID,
+		"riderID", riderID,
+		"status", ride.Status,
+		"reached", len(reached),
+		"pending", len(pending),
+		"estimatedFare", ride.EstimatedFare,
+		"holdReplaced", newHoldID != "",
+	)
+
+	return &dto.UpdateStopsResponse{Ride: rideResp, Fare: fare}, nil
+}
+
+// MarkStopArrived records the driver reaching the next stop of a started trip
+func (s *service) MarkStopArrived(ctx context.Context, userID, rideID, stopID string) (*dto.RideResponse, error) {
+	return s.moveStop(ctx, userID, rideID, stopID, models.RideStopStatusPending, models.RideStopStatusArrived)
+}
+
+// MarkStopDeparted records the driver leaving the stop they are waiting at
+func (s *service) MarkStopDeparted(ctx context.Context, userID, rideID, stopID string) (*dto.RideResponse, error) {
+	return s.moveStop(ctx, userID, rideID, stopID, models.RideStopStatusArrived, models.RideStopStatusDeparted)
+}
+
+func (s *service) moveStop(ctx context.Context, userID, rideID, stopID, fromStatus, toStatus string) (*dto.RideResponse, error) {
+	driver, err := s.driversRepo.FindDriverByUserID(ctx, userID)
+	if err != nil {
+		return nil, response.NotFoundError("Driver profile not found")
+	}
+
+	ride, err := s.repo.FindRideByID(ctx, rideID)
+	if err != nil {
+		return nil, response.NotFoundError("Ride")
+	}
+	if ride.DriverID == nil || *ride.DriverID != driver.ID {
+		return nil, response.ForbiddenError("Not authorized")
+	}
+	if ride.Status != "started" {
+		return nil, response.BadRequest("Ride must be started first")
+	}
+
+	var stop *models.RideStop
+	for i := range ride.Stops {
+		if ride.Stops[i].ID == stopID {
+			stop = &ride.Stops[i]
+			break
+		}
+	}
+	if stop == nil {
+		return nil, response.NotFoundError("Stop")
+	}
+	if stop.Status != fromStatus {
+		return nil, response.BadRequest(fmt.Sprintf("Stop is %s", stop.Status))
+	}
+
+	// Stops are visited in order, one at a time
+	if toStatus == models.RideStopStatusArrived {
+		for _, other := range ride.Stops {
+			if other.Status == models.RideStopStatusArrived {
+				return nil, response.BadRequest("Depart the current stop first")
+			}
+			if other.Status == models.RideStopStatusPending && other.Sequence < stop.Sequence {
+				return nil, response.BadRequest("Stops must be visited in order")
+			}
+		}
+	}
+
+	moved, err := s.repo.UpdateStopStatus(ctx, stopID, fromStatus, toStatus)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to update stop", err)
+	}
+	if !moved {
+		return nil, response.BadRequest("Stop was updated by another request")
+	}
+
+	cache.Delete(ctx, fmt.Sprintf("ride:active:%s", rideID))
+
+	ride, _ = s.repo.FindRideByID(ctx, rideID)
+
+	msgType := websocket.TypeRideStopArrived
+	message := fmt.Sprintf("Your driver has arrived at stop %d", stop.Sequence)
+	if toStatus == models.RideStopStatusDeparted {
+		msgType = websocket.TypeRideStopDeparted
+		message = fmt.Sprintf("Your driver has left stop %d", stop.Sequence)
+	}
+	s.wsHelper.SendTripEvent(ctx, ride.RiderID, driver.UserID, msgType, map[string]interface{}{
+		"rideId":   rideID,
+		"stopId":   stopID,
+		"sequence": stop.Sequence,
+		"address":  stop.Address,
+		"status":   toStatus,
+		"message":  message,
+	})
+
+	logger.Info("ride stop "+toStatus,
+		"rideID", rideID,
+		"stopID", stopID,
+		"sequence", stop.Sequence,
+		"driverID", driver.ID,
+	)
+
+	return dto.ToRideResponse(ride), nil
+}
+
+// driverUserID returns the user ID of the ride's driver, or "" if none is
+// assigned or the profile cannot be loaded
+func (s *service) driverUserID(ctx context.Context, ride *models.Ride) string {
+	if ride.DriverID == nil {
+		return ""
+	}
+	driver, err := s.driversRepo.FindDriverByID(ctx, *ride.DriverID)
+	if err != nil {
+		logger.Warn("failed to load ride driver", "error", err, "rideID", ride.ID, "driverID", *ride.DriverID)
+		return ""
+	}
+	return driver.UserID
+}
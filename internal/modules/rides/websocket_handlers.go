@@ -0,0 +1,52 @@
+package rides
+
+import (
+	"context"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/websocket"
+)
+
+// wsRequestTimeout bounds the work done for one websocket message
+const wsRequestTimeout = 10 * time.Second
+
+// RegisterWebSocketHandlers registers the ride messages that need the rides
+// service, such as the driver's answer to a destination change
+func RegisterWebSocketHandlers(manager *websocket.Manager, service Service) {
+	manager.RegisterHandler(websocket.TypeRideDestinationChangeResponse, func(client *websocket.Client, msg *websocket.Message) error {
+		return handleDestinationChangeResponse(service, client, msg)
+	})
+}
+
+// handleDestinationChangeResponse applies the driver's answer to a pending
+// destination change: {"rideId": "...", "changeId": "...", "accept": true}
+func handleDestinationChangeResponse(service Service, client *websocket.Client, msg *websocket.Message) error {
+	rideID, ok := msg.Data["rideId"].(string)
+	if !ok {
+		return client.SendError("rideId required", msg.RequestID)
+	}
+
+	changeID, ok := msg.Data["changeId"].(string)
+	if !ok {
+		return client.SendError("changeId required", msg.RequestID)
+	}
+
+	accept, ok := msg.Data["accept"].(bool)
+	if !ok {
+		return client.SendError("accept required", msg.RequestID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wsRequestTimeout)
+	defer cancel()
+
+	ride, err := service.RespondDestinationChange(ctx, client.UserID, rideID, changeID, accept)
+	if err != nil {
+		return client.SendError(err.Error(), msg.RequestID)
+	}
+
+	return client.SendAck(msg.RequestID, map[string]interface{}{
+		"success":  true,
+		"accepted": accept,
+		"ride":     ride,
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/websocket"
 	websocketutil "github.com/umar5678/go-backend/internal/websocket/websocketutils"
 )
 
@@ -51,6 +52,26 @@ func (h *RideWebSocketHelper) SendRideStatusToBoth(ctx context.Context, riderID,
 	}
 }
 
+// SendTripEvent sends a trip event (stop arrival or departure, changed stops
+// or destination) to the rider and the driver; an empty ID is skipped
+func (h *RideWebSocketHelper) SendTripEvent(ctx context.Context, riderID, driverUserID string, msgType websocket.MessageType, data map[string]interface{}) {
+	data["timestamp"] = time.Now().UTC()
+
+	for _, userID := range []string{riderID, driverUserID} {
+		if userID == "" {
+			continue
+		}
+		if err := websocketutil.SendToUser(userID, msgType, data); err != nil {
+			logger.Error("failed to send ride trip event",
+				"error", err,
+				"userID", userID,
+				"rideID", data["rideId"],
+				"type", msgType,
+			)
+		}
+	}
+}
+
 // CheckUserOnline checks if a user is currently connected via WebSocket
 func (h *RideWebSocketHelper) CheckUserOnline(userID string) bool {
 	return websocketutil.IsUserOnline(userID)
@@ -37,7 +37,7 @@ func (r *repository) SaveLocation(ctx context.Context, location *models.DriverLo
 	locationStr := fmt.Sprintf("POINT(%f %f)", location.Longitude, location.Latitude)
 
 	return r.db.WithContext(ctx).Exec(`
-		INSERT INTO driver_locations_history 
+		INSERT INTO driver_locations
 		(driver_id, location, latitude, longitude, heading, speed, accuracy, timestamp, created_at)
 		VALUES (?, ST_GeomFromText(?, 4326), ?, ?, ?, ?, ?, ?, NOW())
 	`, location.DriverID, locationStr, location.Latitude, location.Longitude,
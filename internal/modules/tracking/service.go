@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
+	driverdto "github.com/umar5678/go-backend/internal/modules/drivers/dto"
 	"github.com/umar5678/go-backend/internal/modules/tracking/dto"
 	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/services/routing"
 	"github.com/umar5678/go-backend/internal/utils/location"
 	"github.com/umar5678/go-backend/internal/utils/logger"
 	"github.com/umar5678/go-backend/internal/utils/response"
@@ -22,6 +24,8 @@ type Service interface {
 	GetDriverProfileID(ctx context.Context, userID string) (string, error)
 	GetDriverActiveRide(ctx context.Context, driverID string) (rideID, riderID string, err error)
 	UpdateDriverLocationWithStreaming(ctx context.Context, driverID string, req dto.UpdateLocationRequest, activeRideID, riderID string) error
+	// CalculateETA returns the driving time in seconds between two points
+	CalculateETA(ctx context.Context, fromLat, fromLon, toLat, toLon float64) (int, error)
 	// Polyline features
 	// GeneratePolyline(ctx context.Context, driverID string, from, to time.Time) (string, error)
 	// GetRidePolyline(ctx context.Context, rideID string) (string, error)
@@ -29,11 +33,15 @@ type Service interface {
 }
 
 type service struct {
-	repo Repository
+	repo   Repository
+	router routing.RoutingProvider
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func NewService(repo Repository, router routing.RoutingProvider) Service {
+	return &service{
+		repo:   repo,
+		router: router,
+	}
 }
 
 // internal/modules/tracking/service.go
@@ -414,15 +422,16 @@ func (s *service) FindNearbyDrivers(ctx context.Context, req dto.FindNearbyDrive
 		}
 		distance := location.CalculateDistance(searchPoint, driverPoint)
 
-		// Calculate ETA
-		speed := driverLoc.Speed
-		if speed == 0 {
-			speed = 40 // Default 40 km/h
+		// ETA over the road network to the search point
+		eta, err := s.CalculateETA(ctx, driverLoc.Latitude, driverLoc.Longitude, req.Latitude, req.Longitude)
+		if err != nil {
+			logger.Debug("skipping driver without route", "driverID", driver.ID, "error", err)
+			continue
 		}
-		eta := location.CalculateETA(distance, speed)
 
 		driverResponses = append(driverResponses, dto.DriverLocationResponse{
 			DriverID: driver.ID,
+			Driver:   driverdto.ToDriverProfileResponse(driver),
 			Location: *driverLoc,
 			Distance: distance,
 			ETA:      eta,
@@ -834,3 +843,14 @@ func (s *service) StartLocationStreaming(ctx context.Context, rideID, driverID,
 		}
 	}
 }
+
+func (s *service) CalculateETA(ctx context.Context, fromLat, fromLon, toLat, toLon float64) (int, error) {
+	route, err := s.router.Route(ctx,
+		location.Point{Latitude: fromLat, Longitude: fromLon},
+		location.Point{Latitude: toLat, Longitude: toLon},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return route.DurationSec, nil
+}
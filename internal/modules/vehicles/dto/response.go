@@ -4,21 +4,22 @@ import (
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 type VehicleTypeResponse struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	DisplayName   string    `json:"displayName"`
-	BaseFare      float64   `json:"baseFare"`
-	PerKmRate     float64   `json:"perKmRate"`
-	PerMinuteRate float64   `json:"perMinuteRate"`
-	BookingFee    float64   `json:"bookingFee"`
-	Capacity      int       `json:"capacity"`
-	Description   string    `json:"description"`
-	IsActive      bool      `json:"isActive"`
-	IconURL       string    `json:"iconUrl"`
-	CreatedAt     time.Time `json:"createdAt"`
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	DisplayName   string       `json:"displayName"`
+	BaseFare      money.Amount `json:"baseFare"`
+	PerKmRate     money.Amount `json:"perKmRate"`
+	PerMinuteRate money.Amount `json:"perMinuteRate"`
+	BookingFee    money.Amount `json:"bookingFee"`
+	Capacity      int          `json:"capacity"`
+	Description   string       `json:"description"`
+	IsActive      bool         `json:"isActive"`
+	IconURL       string       `json:"iconUrl"`
+	CreatedAt     time.Time    `json:"createdAt"`
 }
 
 func ToVehicleTypeResponse(vt *models.VehicleType) *VehicleTypeResponse {
@@ -4,60 +4,79 @@ import (
 	"errors"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
+// Amounts are JSON numbers in the wallet's currency; a client may also send
+// {"amount": "12.30", "currency": "USD"}, and a currency other than the
+// wallet's is rejected
+
+// maxTopUp caps a single top-up, in major units of the wallet's currency
+const maxTopUp = "10000"
+
 type AddFundsRequest struct {
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description string  `json:"description" binding:"omitempty"`
+	Amount      money.Amount `json:"amount" binding:"required"`
+	Description string       `json:"description" binding:"omitempty"`
 }
 
 func (r *AddFundsRequest) Validate() error {
-	if r.Amount <= 0 {
+	if !r.Amount.IsPositive() {
 		return errors.New("amount must be greater than 0")
 	}
-	if r.Amount > 10000 {
+	if limit, _ := money.Parse(maxTopUp, r.Amount.Currency()); r.Amount.GreaterThan(limit) {
 		return errors.New("maximum amount is $10,000 per transaction")
 	}
 	return nil
 }
 
 type WithdrawFundsRequest struct {
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description string  `json:"description" binding:"omitempty"`
+	Amount      money.Amount       `json:"amount" binding:"required"`
+	Description string             `json:"description" binding:"omitempty"`
+	BankAccount BankAccountRequest `json:"bankAccount" binding:"required"`
 }
 
 func (r *WithdrawFundsRequest) Validate() error {
-	if r.Amount <= 0 {
+	if !r.Amount.IsPositive() {
 		return errors.New("amount must be greater than 0")
 	}
+	if r.BankAccount.HolderName == "" || r.BankAccount.AccountNumber == "" {
+		return errors.New("bankAccount holderName and accountNumber are required")
+	}
 	return nil
 }
 
+// BankAccountRequest is where a withdrawal is paid out
+type BankAccountRequest struct {
+	HolderName    string `json:"holderName" binding:"required"`
+	AccountNumber string `json:"accountNumber" binding:"required"` // account number or IBAN
+	BankCode      string `json:"bankCode" binding:"omitempty"`     // routing number, sort code or SWIFT
+}
+
 type TransferFundsRequest struct {
-	RecipientID string  `json:"recipientId" binding:"required,uuid"`
-	Amount      float64 `json:"amount" binding:"required,gt=0"`
-	Description string  `json:"description" binding:"omitempty"`
+	RecipientID string       `json:"recipientId" binding:"required,uuid"`
+	Amount      money.Amount `json:"amount" binding:"required"`
+	Description string       `json:"description" binding:"omitempty"`
 }
 
 func (r *TransferFundsRequest) Validate() error {
 	if r.RecipientID == "" {
 		return errors.New("recipientId is required")
 	}
-	if r.Amount <= 0 {
+	if !r.Amount.IsPositive() {
 		return errors.New("amount must be greater than 0")
 	}
 	return nil
 }
 
 type HoldFundsRequest struct {
-	Amount        float64 `json:"amount" binding:"required,gt=0"`
-	ReferenceType string  `json:"referenceType" binding:"required"`
-	ReferenceID   string  `json:"referenceId" binding:"required,uuid"`
-	HoldDuration  int     `json:"holdDuration" binding:"omitempty,min=1"` // minutes, default 30
+	Amount        money.Amount `json:"amount" binding:"required"`
+	ReferenceType string       `json:"referenceType" binding:"required"`
+	ReferenceID   string       `json:"referenceId" binding:"required,uuid"`
+	HoldDuration  int          `json:"holdDuration" binding:"omitempty,min=1"` // minutes, default 30
 }
 
 func (r *HoldFundsRequest) Validate() error {
-	if r.Amount <= 0 {
+	if !r.Amount.IsPositive() {
 		return errors.New("amount must be greater than 0")
 	}
 	if r.ReferenceType == "" {
@@ -76,10 +95,35 @@ type ReleaseHoldRequest struct {
 	HoldID string `json:"holdId" binding:"required,uuid"`
 }
 
+// IncreaseHoldRequest raises a hold to Amount, e.g. when a ride's fare grows
+type IncreaseHoldRequest struct {
+	HoldID string       `json:"holdId" binding:"required,uuid"`
+	Amount money.Amount `json:"amount" binding:"required"` // new total, not the difference
+}
+
+// ExtendHoldRequest keeps a hold alive until HoldDuration minutes from now
+type ExtendHoldRequest struct {
+	HoldID       string `json:"holdId" binding:"required,uuid"`
+	HoldDuration int    `json:"holdDuration" binding:"required,min=1"` // minutes from now
+}
+
 type CaptureHoldRequest struct {
-	HoldID      string   `json:"holdId" binding:"required,uuid"`
-	Amount      *float64 `json:"amount" binding:"omitempty,gt=0"` // Optional: capture partial amount
-	Description string   `json:"description" binding:"omitempty"`
+	HoldID      string        `json:"holdId" binding:"required,uuid"`
+	Amount      *money.Amount `json:"amount" binding:"omitempty"` // Optional: capture partial amount
+	Description string        `json:"description" binding:"omitempty"`
+}
+
+// RefundTopUpRequest returns a completed top-up to the card it came from.
+// Amount defaults to whatever of the top-up is not refunded yet.
+type RefundTopUpRequest struct {
+	Amount *money.Amount `json:"amount" binding:"omitempty"`
+	Reason string        `json:"reason" binding:"required"`
+}
+
+// SimulatePaymentRequest settles a pending payment on the fake gateway
+type SimulatePaymentRequest struct {
+	Outcome       string `json:"outcome" binding:"required,oneof=succeeded failed"`
+	FailureReason string `json:"failureReason" binding:"omitempty"`
 }
 
 type ListTransactionsRequest struct {
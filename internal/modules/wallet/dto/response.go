@@ -5,15 +5,16 @@ import (
 
 	"github.com/umar5678/go-backend/internal/models"
 	authdto "github.com/umar5678/go-backend/internal/modules/auth/dto"
+	"github.com/umar5678/go-backend/internal/utils/money"
 )
 
 type WalletResponse struct {
 	ID               string                `json:"id"`
 	UserID           string                `json:"userId"`
 	WalletType       models.WalletType     `json:"walletType"`
-	Balance          float64               `json:"balance"`
-	HeldBalance      float64               `json:"heldBalance"`
-	AvailableBalance float64               `json:"availableBalance"`
+	Balance          money.Amount          `json:"balance"`
+	HeldBalance      money.Amount          `json:"heldBalance"`
+	AvailableBalance money.Amount          `json:"availableBalance"`
 	Currency         string                `json:"currency"`
 	IsActive         bool                  `json:"isActive"`
 	CreatedAt        time.Time             `json:"createdAt"`
@@ -25,14 +26,15 @@ type TransactionResponse struct {
 	ID            string                   `json:"id"`
 	WalletID      string                   `json:"walletId"`
 	Type          models.TransactionType   `json:"type"`
-	Amount        float64                  `json:"amount"`
-	BalanceBefore float64                  `json:"balanceBefore"`
-	BalanceAfter  float64                  `json:"balanceAfter"`
+	Amount        money.Amount             `json:"amount"`
+	BalanceBefore money.Amount             `json:"balanceBefore"`
+	BalanceAfter  money.Amount             `json:"balanceAfter"`
 	Status        models.TransactionStatus `json:"status"`
 	ReferenceType *string                  `json:"referenceType,omitempty"`
 	ReferenceID   *string                  `json:"referenceId,omitempty"` // now varchar instead of uuid
 	Description   *string                  `json:"description,omitempty"`
 	Metadata      map[string]interface{}   `json:"metadata,omitempty"`
+	JournalID     *string                  `json:"journalId,omitempty"`
 	ProcessedAt   *time.Time               `json:"processedAt,omitempty"`
 	CreatedAt     time.Time                `json:"createdAt"`
 }
@@ -40,7 +42,7 @@ type TransactionResponse struct {
 type HoldResponse struct {
 	ID            string                   `json:"id"`
 	WalletID      string                   `json:"walletId"`
-	Amount        float64                  `json:"amount"`
+	Amount        money.Amount             `json:"amount"`
 	ReferenceType string                   `json:"referenceType"`
 	ReferenceID   string                   `json:"referenceId"`
 	Status        models.TransactionStatus `json:"status"`
@@ -49,6 +51,54 @@ type HoldResponse struct {
 	CreatedAt     time.Time                `json:"createdAt"`
 }
 
+// TopUpResponse is a pending top-up: the wallet is credited once the payment
+// gateway confirms the payment intent
+type TopUpResponse struct {
+	Transaction *TransactionResponse  `json:"transaction"`
+	Payment     PaymentIntentResponse `json:"payment"`
+}
+
+type PaymentIntentResponse struct {
+	ID           string `json:"id"`
+	Provider     string `json:"provider"`
+	Status       string `json:"status"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// ReconciliationResponse compares stored wallet balances with the ledger.
+// Balanced is true when nothing drifted and every journal sums to zero.
+type ReconciliationResponse struct {
+	GeneratedAt        time.Time                `json:"generatedAt"`
+	Balanced           bool                     `json:"balanced"`
+	WalletsChecked     int64                    `json:"walletsChecked"`
+	WalletsWithDrift   int                      `json:"walletsWithDrift"`
+	TotalDrift         money.Amount             `json:"totalDrift"` // sum of stored minus ledger balances
+	Drift              []WalletDriftResponse    `json:"drift"`
+	UnbalancedJournals []JournalImbalanceResult `json:"unbalancedJournals"`
+	PlatformAccounts   []AccountBalanceResponse `json:"platformAccounts"`
+}
+
+type WalletDriftResponse struct {
+	WalletID      string            `json:"walletId"`
+	UserID        string            `json:"userId"`
+	WalletType    models.WalletType `json:"walletType"`
+	Balance       money.Amount      `json:"balance"`
+	LedgerBalance money.Amount      `json:"ledgerBalance"`
+	Difference    money.Amount      `json:"difference"`
+	HeldBalance   money.Amount      `json:"heldBalance"`
+	ActiveHolds   money.Amount      `json:"activeHolds"`
+}
+
+type JournalImbalanceResult struct {
+	JournalID string       `json:"journalId"`
+	Total     money.Amount `json:"total"`
+}
+
+type AccountBalanceResponse struct {
+	Code    string       `json:"code"`
+	Balance money.Amount `json:"balance"`
+}
+
 func ToWalletResponse(wallet *models.Wallet) *WalletResponse {
 	resp := &WalletResponse{
 		ID:               wallet.ID,
@@ -83,6 +133,7 @@ func ToTransactionResponse(tx *models.WalletTransaction) *TransactionResponse {
 		ReferenceID:   tx.ReferenceID,
 		Description:   tx.Description,
 		Metadata:      tx.Metadata,
+		JournalID:     tx.JournalID,
 		ProcessedAt:   tx.ProcessedAt,
 		CreatedAt:     tx.CreatedAt,
 	}
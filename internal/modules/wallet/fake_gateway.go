@@ -0,0 +1,270 @@
+package wallet
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+// FakeSignatureHeader carries "t=<unix>,v1=<hex hmac>" on fake gateway webhooks
+const FakeSignatureHeader = "Fake-Signature"
+
+// FakeGateway is an in-process PaymentProvider for development and tests.
+// Nothing settles on its own: Simulate produces the signed webhook a real
+// gateway would send, so the whole top-up/withdrawal flow runs offline.
+// Objects live in memory and are forgotten on restart.
+type FakeGateway struct {
+	secret    []byte
+	tolerance time.Duration
+
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+}
+
+type fakeObject struct {
+	kind      string // payment_intent, refund, payout
+	id        string
+	reference string
+	amount    money.Amount
+	status    PaymentStatus
+	intentID  string       // refunds only
+	refunded  money.Amount // payment intents only
+}
+
+// fakeWebhook is the JSON body of a fake gateway webhook
+type fakeWebhook struct {
+	ID      string           `json:"id"`
+	Type    PaymentEventType `json:"type"`
+	Created int64            `json:"created"`
+	Data    struct {
+		ID            string       `json:"id"`
+		Reference     string       `json:"reference"`
+		Amount        money.Amount `json:"amount"`
+		Currency      string       `json:"currency"`
+		FailureReason string       `json:"failureReason,omitempty"`
+	} `json:"data"`
+}
+
+func NewFakeGateway(secret string, tolerance time.Duration) *FakeGateway {
+	return &FakeGateway{
+		secret:    []byte(secret),
+		tolerance: tolerance,
+		objects:   make(map[string]*fakeObject),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) CreatePaymentIntent(ctx context.Context, req PaymentIntentRequest) (*PaymentIntent, error) {
+	obj := g.store("payment_intent", "pi_", req.Reference, req.Amount, "")
+	return &PaymentIntent{
+		ID:           obj.id,
+		Status:       obj.status,
+		Amount:       obj.amount,
+		ClientSecret: obj.id + "_secret_" + newFakeID(),
+	}, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, req RefundRequest) (*PaymentRefund, error) {
+	g.mu.Lock()
+	// Intents created before a restart are unknown; accept their refunds
+	if intent, ok := g.objects[req.PaymentIntentID]; ok {
+		if intent.status != PaymentStatusSucceeded {
+			g.mu.Unlock()
+			return nil, errors.New("payment intent has not succeeded")
+		}
+		if intent.refunded.Add(req.Amount).GreaterThan(intent.amount) {
+			g.mu.Unlock()
+			return nil, errors.New("refund exceeds the payment amount")
+		}
+		intent.refunded = intent.refunded.Add(req.Amount)
+	}
+	g.mu.Unlock()
+
+	obj := g.store("refund", "re_", req.Reference, req.Amount, req.PaymentIntentID)
+	return &PaymentRefund{
+		ID:              obj.id,
+		PaymentIntentID: req.PaymentIntentID,
+		Status:          obj.status,
+		Amount:          obj.amount,
+	}, nil
+}
+
+func (g *FakeGateway) CreatePayout(ctx context.Context, req PayoutRequest) (*Payout, error) {
+	if req.BankAccount.AccountNumber == "" {
+		return nil, errors.New("bank account number is required")
+	}
+	obj := g.store("payout", "po_", req.Reference, req.Amount, "")
+	return &Payout{
+		ID:     obj.id,
+		Status: obj.status,
+		Amount: obj.amount,
+	}, nil
+}
+
+// Simulate settles a pending intent, refund or payout and returns the signed
+// webhook (body and headers) the gateway would deliver for it
+func (g *FakeGateway) Simulate(objectID string, succeed bool, failureReason string) ([]byte, http.Header, error) {
+	g.mu.Lock()
+	obj, ok := g.objects[objectID]
+	if !ok {
+		g.mu.Unlock()
+		return nil, nil, fmt.Errorf("unknown payment object %s", objectID)
+	}
+	if obj.status != PaymentStatusPending {
+		g.mu.Unlock()
+		return nil, nil, fmt.Errorf("payment object %s is already %s", objectID, obj.status)
+	}
+
+	obj.status = PaymentStatusSucceeded
+	if !succeed {
+		obj.status = PaymentStatusFailed
+		if failureReason == "" {
+			failureReason = "declined"
+		}
+		// A failed refund gives the intent its headroom back
+		if intent, ok := g.objects[obj.intentID]; ok && obj.kind == "refund" {
+			intent.refunded = intent.refunded.Sub(obj.amount)
+		}
+	}
+
+	var event fakeWebhook
+	event.ID = "evt_" + newFakeID()
+	event.Type = fakeEventType(obj.kind, succeed)
+	event.Created = time.Now().Unix()
+	event.Data.ID = obj.id
+	event.Data.Reference = obj.reference
+	event.Data.Amount = obj.amount
+	event.Data.Currency = string(obj.amount.Currency())
+	if !succeed {
+		event.Data.FailureReason = failureReason
+	}
+	g.mu.Unlock()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set(FakeSignatureHeader, g.sign(payload, time.Now()))
+	return payload, headers, nil
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, headers http.Header) (*PaymentEvent, error) {
+	if err := g.verify(payload, headers.Get(FakeSignatureHeader), time.Now()); err != nil {
+		return nil, err
+	}
+
+	var event fakeWebhook
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	return &PaymentEvent{
+		ID:            event.ID,
+		Type:          event.Type,
+		ObjectID:      event.Data.ID,
+		Reference:     event.Data.Reference,
+		Amount:        event.Data.Amount.WithCurrency(money.Currency(event.Data.Currency)),
+		FailureReason: event.Data.FailureReason,
+	}, nil
+}
+
+// store records a pending object; intentID is the refunded intent for
+// refunds and empty otherwise. Simulate may change the stored object as soon
+// as the lock is released, so callers get a copy.
+func (g *FakeGateway) store(kind, prefix, reference string, amount money.Amount, intentID string) fakeObject {
+	obj := &fakeObject{
+		kind:      kind,
+		id:        prefix + newFakeID(),
+		reference: reference,
+		amount:    amount,
+		status:    PaymentStatusPending,
+		intentID:  intentID,
+		refunded:  money.Zero(amount.Currency()),
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.objects[obj.id] = obj
+	return *obj
+}
+
+// sign returns "t=<unix>,v1=<hex hmac of "<unix>.<payload>">"
+func (g *FakeGateway) sign(payload []byte, at time.Time) string {
+	ts := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, g.mac(ts, payload))
+}
+
+func (g *FakeGateway) verify(payload []byte, header string, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	if ts == "" || sig == "" {
+		return errors.New("missing webhook signature")
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook timestamp")
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > g.tolerance || age < -g.tolerance {
+		return errors.New("webhook timestamp outside tolerance")
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(g.mac(ts, payload))) {
+		return errors.New("webhook signature mismatch")
+	}
+	return nil
+}
+
+func (g *FakeGateway) mac(ts string, payload []byte) string {
+	m := hmac.New(sha256.New, g.secret)
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(payload)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+func fakeEventType(kind string, succeed bool) PaymentEventType {
+	switch {
+	case kind == "payment_intent" && succeed:
+		return EventPaymentSucceeded
+	case kind == "payment_intent":
+		return EventPaymentFailed
+	case kind == "refund" && succeed:
+		return EventRefundSucceeded
+	case kind == "refund":
+		return EventRefundFailed
+	case succeed:
+		return EventPayoutPaid
+	}
+	return EventPayoutFailed
+}
+
+func newFakeID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
+}
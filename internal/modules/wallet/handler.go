@@ -56,12 +56,13 @@ func (h *Handler) GetBalance(c *gin.Context) {
 
 // AddFunds godoc
 // @Summary Add funds to wallet
+// @Description Creates a payment intent with the payment gateway. The transaction stays pending until the gateway confirms the payment.
 // @Tags wallet
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param request body dto.AddFundsRequest true "Add funds data"
-// @Success 200 {object} response.Response{data=dto.TransactionResponse}
+// @Success 200 {object} response.Response{data=dto.TopUpResponse}
 // @Router /wallet/add-funds [post]
 func (h *Handler) AddFunds(c *gin.Context) {
 	userID, _ := c.Get("userID")
@@ -72,17 +73,18 @@ func (h *Handler) AddFunds(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.service.AddFunds(c.Request.Context(), userID.(string), req)
+	topUp, err := h.service.AddFunds(c.Request.Context(), userID.(string), req)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	response.Success(c, transaction, "Funds added successfully")
+	response.Success(c, topUp, "Payment created; funds are added once it is confirmed")
 }
 
 // WithdrawFunds godoc
 // @Summary Withdraw funds from wallet
+// @Description Pays out to a bank account. The amount leaves the wallet at once; the transaction stays pending until the payout is paid, and is reversed if it fails.
 // @Tags wallet
 // @Security BearerAuth
 // @Accept json
@@ -105,7 +107,7 @@ func (h *Handler) WithdrawFunds(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, transaction, "Funds withdrawn successfully")
+	response.Success(c, transaction, "Withdrawal requested")
 }
 
 // TransferFunds godoc
@@ -265,3 +267,96 @@ func (h *Handler) CaptureHold(c *gin.Context) {
 
 	response.Success(c, transaction, "Hold captured successfully")
 }
+
+// Reconcile godoc
+// @Summary Reconcile wallet balances with the ledger (Admin)
+// @Description Reports wallets whose stored balance differs from their ledger postings or whose held balance differs from their active holds, plus any unbalanced journals
+// @Tags wallet
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response{data=dto.ReconciliationResponse}
+// @Router /admin/wallet/reconciliation [get]
+func (h *Handler) Reconcile(c *gin.Context) {
+	report, err := h.service.Reconcile(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, report, "Reconciliation completed")
+}
+
+// PaymentWebhook godoc
+// @Summary Payment gateway webhook
+// @Description Settles pending top-ups, refunds and withdrawals. The request must carry the gateway's signature.
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /wallet/webhooks/payments [post]
+func (h *Handler) PaymentWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	if err := h.service.HandlePaymentWebhook(c.Request.Context(), payload, c.Request.Header); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Webhook processed")
+}
+
+// RefundTopUp godoc
+// @Summary Refund a top-up to its card (Admin)
+// @Tags wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Top-up transaction ID"
+// @Param request body dto.RefundTopUpRequest true "Refund data"
+// @Success 200 {object} response.Response{data=dto.TransactionResponse}
+// @Router /admin/wallet/transactions/{id}/refund [post]
+func (h *Handler) RefundTopUp(c *gin.Context) {
+	var req dto.RefundTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	transaction, err := h.service.RefundTopUp(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, transaction, "Refund requested")
+}
+
+// SimulatePayment godoc
+// @Summary Settle a payment on the fake gateway (Admin)
+// @Description Only available when PAYMENTS_PROVIDER=fake. Sends the signed webhook for a pending payment intent, refund or payout.
+// @Tags wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent, refund or payout ID"
+// @Param request body dto.SimulatePaymentRequest true "Outcome"
+// @Success 200 {object} response.Response
+// @Router /admin/wallet/payments/{id}/simulate [post]
+func (h *Handler) SimulatePayment(c *gin.Context) {
+	var req dto.SimulatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(response.BadRequest("Invalid request body"))
+		return
+	}
+
+	if err := h.service.SimulatePayment(c.Request.Context(), c.Param("id"), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response.Success(c, nil, "Payment settled")
+}
@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"gorm.io/gorm"
+)
+
+// ledgerEntry moves amount into (positive) or out of (negative) an account
+type ledgerEntry struct {
+	accountID string
+	amount    money.Amount
+}
+
+// postJournal records one balanced money movement. It must run inside the
+// same DB transaction as the wallet update it describes; the database also
+// rejects unbalanced journals at commit.
+func postJournal(tx *gorm.DB, refType, refID, description string, entries ...ledgerEntry) (string, error) {
+	var total money.Amount
+	postings := make([]models.LedgerPosting, 0, len(entries))
+	for _, e := range entries {
+		if e.amount.IsZero() {
+			continue
+		}
+		total = total.Add(e.amount)
+		postings = append(postings, models.LedgerPosting{
+			AccountID: e.accountID,
+			Amount:    e.amount,
+		})
+	}
+
+	if !total.IsZero() {
+		return "", fmt.Errorf("ledger journal %s/%s does not balance (off by %s)", refType, refID, total)
+	}
+	if len(postings) == 0 {
+		return "", errors.New("ledger journal has no postings")
+	}
+
+	journal := &models.LedgerJournal{
+		ReferenceType: refType,
+		ReferenceID:   refID,
+		Description:   description,
+	}
+	if err := tx.Create(journal).Error; err != nil {
+		return "", fmt.Errorf("failed to create ledger journal: %w", err)
+	}
+
+	for i := range postings {
+		postings[i].JournalID = journal.ID
+	}
+	if err := tx.Create(&postings).Error; err != nil {
+		return "", fmt.Errorf("failed to create ledger postings: %w", err)
+	}
+
+	return journal.ID, nil
+}
+
+// walletAccountID returns the ledger account of a wallet, creating it the
+// first time the wallet moves money
+func walletAccountID(tx *gorm.DB, wallet *models.Wallet) (string, error) {
+	code := fmt.Sprintf("wallet:%s", wallet.ID)
+
+	var account models.LedgerAccount
+	err := tx.Where("code = ?", code).First(&account).Error
+	if err == nil {
+		return account.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to load ledger account: %w", err)
+	}
+
+	walletID := wallet.ID
+	account = models.LedgerAccount{
+		Code:        code,
+		AccountType: wallet.WalletType,
+		WalletID:    &walletID,
+	}
+	if err := tx.Create(&account).Error; err != nil {
+		return "", fmt.Errorf("failed to create ledger account: %w", err)
+	}
+	return account.ID, nil
+}
+
+// platformAccountID returns one of the platform accounts seeded by migration
+func platformAccountID(tx *gorm.DB, code string) (string, error) {
+	var account models.LedgerAccount
+	if err := tx.Where("code = ?", code).First(&account).Error; err != nil {
+		return "", fmt.Errorf("failed to load ledger account %s: %w", code, err)
+	}
+	return account.ID, nil
+}
+
+// postWalletJournal moves amount between a wallet and a platform account:
+// positive amounts go into the wallet, negative ones come out of it
+func postWalletJournal(tx *gorm.DB, wallet *models.Wallet, platformCode string, amount money.Amount, refType, refID, description string) (string, error) {
+	walletAccount, err := walletAccountID(tx, wallet)
+	if err != nil {
+		return "", err
+	}
+	platformAccount, err := platformAccountID(tx, platformCode)
+	if err != nil {
+		return "", err
+	}
+
+	return postJournal(tx, refType, refID, description,
+		ledgerEntry{accountID: walletAccount, amount: amount},
+		ledgerEntry{accountID: platformAccount, amount: amount.Neg()},
+	)
+}
+
+// postPayoutJournal pays amount out of clearing into a wallet. When the
+// payout carries a commission, that part of the collected payment moves from
+// clearing to platform revenue in the same journal.
+func postPayoutJournal(tx *gorm.DB, wallet *models.Wallet, amount, commission money.Amount, refType, refID, description string) (string, error) {
+	walletAccount, err := walletAccountID(tx, wallet)
+	if err != nil {
+		return "", err
+	}
+	clearingAccount, err := platformAccountID(tx, models.LedgerAccountClearing)
+	if err != nil {
+		return "", err
+	}
+
+	entries := []ledgerEntry{
+		{accountID: walletAccount, amount: amount},
+		{accountID: clearingAccount, amount: amount.Neg()},
+	}
+
+	if commission.IsPositive() {
+		revenueAccount, err := platformAccountID(tx, models.LedgerAccountRevenue)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries,
+			ledgerEntry{accountID: clearingAccount, amount: commission.Neg()},
+			ledgerEntry{accountID: revenueAccount, amount: commission},
+		)
+	}
+
+	return postJournal(tx, refType, refID, description, entries...)
+}
@@ -0,0 +1,84 @@
+package wallet
+
+import (
+	"sort"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Every balance change reads the wallet with SELECT ... FOR UPDATE inside the
+// transaction that writes it, so concurrent operations on one wallet queue up
+// instead of overwriting each other. Checks (active, sufficient balance, hold
+// status) must run on the locked row, never on a copy read before the lock.
+
+// lockWallet loads a wallet and locks its row until the transaction ends
+func lockWallet(tx *gorm.DB, walletID string) (*models.Wallet, error) {
+	var wallet models.Wallet
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", walletID).
+		First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// lockWallets locks several wallets in id order, so two transfers between the
+// same wallets in opposite directions cannot deadlock
+func lockWallets(tx *gorm.DB, walletIDs ...string) (map[string]*models.Wallet, error) {
+	ids := append([]string(nil), walletIDs...)
+	sort.Strings(ids)
+
+	wallets := make(map[string]*models.Wallet, len(ids))
+	for _, id := range ids {
+		if _, ok := wallets[id]; ok {
+			continue
+		}
+		wallet, err := lockWallet(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		wallets[id] = wallet
+	}
+	return wallets, nil
+}
+
+// lockHold loads a hold and locks its row, so it can only be released or
+// captured once
+func lockHold(tx *gorm.DB, holdID string) (*models.WalletHold, error) {
+	var hold models.WalletHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", holdID).
+		First(&hold).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// saveWalletBalances writes only the balance columns of a locked wallet
+func saveWalletBalances(tx *gorm.DB, wallet *models.Wallet) error {
+	return tx.Model(wallet).Updates(map[string]interface{}{
+		"balance":      wallet.Balance,
+		"held_balance": wallet.HeldBalance,
+	}).Error
+}
+
+// requireAvailable fails when a wallet cannot spend amount
+func requireAvailable(wallet *models.Wallet, amount money.Amount) error {
+	if wallet.GetAvailableBalance().LessThan(amount) {
+		return response.BadRequest("Insufficient balance")
+	}
+	return nil
+}
+
+// txError returns errors raised on purpose inside a transaction (AppErrors)
+// as they are and wraps anything else as an internal error
+func txError(err error, message string) error {
+	if appErr, ok := err.(*response.AppError); ok {
+		return appErr
+	}
+	return response.InternalServerError(message, err)
+}
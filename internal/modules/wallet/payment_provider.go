@@ -0,0 +1,126 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/utils/money"
+)
+
+// PaymentProvider moves money between wallets and the outside world. Every
+// call only starts an operation; the outcome arrives later as a signed
+// webhook (see ParseWebhook), which is what actually moves wallet balances.
+type PaymentProvider interface {
+	Name() string
+
+	// CreatePaymentIntent starts collecting amount from the customer (top-up)
+	CreatePaymentIntent(ctx context.Context, req PaymentIntentRequest) (*PaymentIntent, error)
+	// Refund returns part or all of a succeeded payment intent to the customer
+	Refund(ctx context.Context, req RefundRequest) (*PaymentRefund, error)
+	// CreatePayout sends amount to a bank account (withdrawal)
+	CreatePayout(ctx context.Context, req PayoutRequest) (*Payout, error)
+
+	// ParseWebhook verifies a webhook's signature and decodes its event
+	ParseWebhook(payload []byte, headers http.Header) (*PaymentEvent, error)
+}
+
+// PaymentStatus is the state of an intent, refund or payout at the gateway
+type PaymentStatus string
+
+const (
+	PaymentStatusPending   PaymentStatus = "pending"
+	PaymentStatusSucceeded PaymentStatus = "succeeded"
+	PaymentStatusFailed    PaymentStatus = "failed"
+)
+
+// PaymentEventType is what a webhook reports
+type PaymentEventType string
+
+const (
+	EventPaymentSucceeded PaymentEventType = "payment_intent.succeeded"
+	EventPaymentFailed    PaymentEventType = "payment_intent.failed"
+	EventRefundSucceeded  PaymentEventType = "refund.succeeded"
+	EventRefundFailed     PaymentEventType = "refund.failed"
+	EventPayoutPaid       PaymentEventType = "payout.paid"
+	EventPayoutFailed     PaymentEventType = "payout.failed"
+)
+
+// Succeeded reports whether the event settles its operation successfully
+func (t PaymentEventType) Succeeded() bool {
+	return t == EventPaymentSucceeded || t == EventRefundSucceeded || t == EventPayoutPaid
+}
+
+// PaymentIntentRequest asks the gateway to collect a top-up
+type PaymentIntentRequest struct {
+	Amount      money.Amount
+	Reference   string // our wallet transaction ID
+	Description string
+}
+
+type PaymentIntent struct {
+	ID           string
+	Status       PaymentStatus
+	Amount       money.Amount
+	ClientSecret string // handed to the client to complete the payment
+}
+
+type RefundRequest struct {
+	PaymentIntentID string
+	Amount          money.Amount
+	Reference       string
+	Reason          string
+}
+
+type PaymentRefund struct {
+	ID              string
+	PaymentIntentID string
+	Status          PaymentStatus
+	Amount          money.Amount
+}
+
+// BankAccount is where a payout is sent
+type BankAccount struct {
+	HolderName    string
+	AccountNumber string
+	BankCode      string
+}
+
+type PayoutRequest struct {
+	Amount      money.Amount
+	Reference   string
+	BankAccount BankAccount
+	Description string
+}
+
+type Payout struct {
+	ID     string
+	Status PaymentStatus
+	Amount money.Amount
+}
+
+// PaymentEvent is a verified webhook. ObjectID is the intent, refund or
+// payout it settles.
+type PaymentEvent struct {
+	ID            string
+	Type          PaymentEventType
+	ObjectID      string
+	Reference     string
+	Amount        money.Amount
+	FailureReason string
+}
+
+// NewPaymentProvider returns the gateway selected by PAYMENTS_PROVIDER. The
+// fake gateway settles whatever an admin tells it to, so it is refused
+// outside development.
+func NewPaymentProvider(cfg *config.PaymentsConfig, environment string) (PaymentProvider, error) {
+	switch cfg.Provider {
+	case "", "fake":
+		if environment != "development" {
+			return nil, fmt.Errorf("the fake payment provider is only available in development, not %q", environment)
+		}
+		return NewFakeGateway(cfg.WebhookSecret, cfg.WebhookTolerance), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+}
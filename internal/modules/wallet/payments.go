@@ -0,0 +1,400 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/wallet/dto"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reference types of transactions the payment gateway settles. Their
+// reference ID is the gateway's intent, refund or payout ID.
+const (
+	refTypeTopUp       = "topup"
+	refTypeTopUpRefund = "topup_refund"
+	refTypeWithdrawal  = "withdrawal"
+)
+
+// HandlePaymentWebhook verifies a gateway webhook and settles the pending
+// transaction it is about. Gateways retry webhooks, so this is idempotent: a
+// transaction that is no longer pending is left alone.
+func (s *service) HandlePaymentWebhook(ctx context.Context, payload []byte, headers http.Header) error {
+	event, err := s.payments.ParseWebhook(payload, headers)
+	if err != nil {
+		logger.Warn("rejected payment webhook", "error", err, "provider", s.payments.Name())
+		return response.BadRequest("Invalid webhook")
+	}
+
+	refType := webhookReferenceType(event.Type)
+	if refType == "" {
+		logger.Info("ignoring payment webhook", "eventID", event.ID, "type", event.Type)
+		return nil
+	}
+
+	pending, err := s.repo.FindTransactionByReference(ctx, refType, event.ObjectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) && refType != refTypeTopUp && event.Reference != "" {
+		// Payout and refund IDs are saved after the gateway call returns;
+		// until then the transaction is only known by the reference it was
+		// sent with
+		pending, err = s.repo.FindTransactionByReference(ctx, refType, event.Reference)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Not ours (or not committed yet); the gateway retries
+			logger.Warn("payment webhook for unknown transaction", "eventID", event.ID, "type", event.Type, "objectID", event.ObjectID)
+			return response.NotFoundError("Transaction")
+		}
+		return response.InternalServerError("Failed to process webhook", err)
+	}
+
+	var userID string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Wallet first, then transaction: the same order as every other
+		// balance change
+		wallet, err := lockWallet(tx, pending.WalletID)
+		if err != nil {
+			return err
+		}
+		transaction, err := lockTransaction(tx, pending.ID)
+		if err != nil {
+			return err
+		}
+		userID = wallet.UserID
+
+		if transaction.Status != models.TransactionStatusPending {
+			return nil
+		}
+		if event.Amount.Minor() != transaction.Amount.Minor() {
+			return response.BadRequest("Webhook amount does not match the transaction")
+		}
+
+		switch refType {
+		case refTypeTopUp:
+			return settleTopUp(tx, wallet, transaction, event)
+		default:
+			return settlePayout(tx, wallet, transaction, event)
+		}
+	})
+
+	if err != nil {
+		logger.Error("failed to process payment webhook", "error", err, "eventID", event.ID, "type", event.Type)
+		return txError(err, "Failed to process webhook")
+	}
+
+	s.invalidateWalletCache(ctx, userID)
+
+	logger.Info("payment webhook processed", "eventID", event.ID, "type", event.Type, "txID", pending.ID)
+
+	return nil
+}
+
+// settleTopUp credits a confirmed top-up, or marks a failed one
+func settleTopUp(tx *gorm.DB, wallet *models.Wallet, transaction *models.WalletTransaction, event *PaymentEvent) error {
+	now := time.Now()
+	transaction.ProcessedAt = &now
+
+	if !event.Type.Succeeded() {
+		transaction.Status = models.TransactionStatusFailed
+		transaction.Metadata = withMetadata(transaction.Metadata, "failureReason", event.FailureReason)
+		return tx.Save(transaction).Error
+	}
+
+	balanceBefore := wallet.Balance
+	wallet.Balance = wallet.Balance.Add(transaction.Amount)
+	if err := saveWalletBalances(tx, wallet); err != nil {
+		return err
+	}
+
+	// Money enters the system
+	journalID, err := postWalletJournal(tx, wallet, models.LedgerAccountExternal, transaction.Amount, refTypeTopUp, event.ObjectID, "Top-up confirmed")
+	if err != nil {
+		return err
+	}
+
+	transaction.Status = models.TransactionStatusCompleted
+	transaction.BalanceBefore = balanceBefore
+	transaction.BalanceAfter = wallet.Balance
+	transaction.JournalID = &journalID
+	return tx.Save(transaction).Error
+}
+
+// settlePayout completes a withdrawal or top-up refund, whose amount left the
+// wallet when it was requested. A paid payout moves the money from
+// payouts_pending out of the system; a failed one gives it back to the wallet
+// with a reversal transaction.
+func settlePayout(tx *gorm.DB, wallet *models.Wallet, transaction *models.WalletTransaction, event *PaymentEvent) error {
+	now := time.Now()
+	transaction.ProcessedAt = &now
+	refType := *transaction.ReferenceType
+
+	if event.Type.Succeeded() {
+		pendingAccount, err := platformAccountID(tx, models.LedgerAccountPayoutsPending)
+		if err != nil {
+			return err
+		}
+		externalAccount, err := platformAccountID(tx, models.LedgerAccountExternal)
+		if err != nil {
+			return err
+		}
+		if _, err := postJournal(tx, refType, event.ObjectID, "Payout settled",
+			ledgerEntry{accountID: pendingAccount, amount: transaction.Amount.Neg()},
+			ledgerEntry{accountID: externalAccount, amount: transaction.Amount},
+		); err != nil {
+			return err
+		}
+
+		transaction.Status = models.TransactionStatusCompleted
+		return tx.Save(transaction).Error
+	}
+
+	return reversePayout(tx, wallet, transaction, event.ObjectID, event.FailureReason)
+}
+
+// reversePayout marks a pending payout failed and returns its amount to the
+// wallet with a reversal transaction
+func reversePayout(tx *gorm.DB, wallet *models.Wallet, transaction *models.WalletTransaction, refID, reason string) error {
+	now := time.Now()
+	transaction.ProcessedAt = &now
+	refType := *transaction.ReferenceType
+
+	transaction.Status = models.TransactionStatusFailed
+	transaction.Metadata = withMetadata(transaction.Metadata, "failureReason", reason)
+	if err := tx.Save(transaction).Error; err != nil {
+		return err
+	}
+
+	balanceBefore := wallet.Balance
+	wallet.Balance = wallet.Balance.Add(transaction.Amount)
+	if err := saveWalletBalances(tx, wallet); err != nil {
+		return err
+	}
+
+	reversalType := refType + "_reversal"
+	description := fmt.Sprintf("Reversal of failed %s", strings.ReplaceAll(refType, "_", " "))
+	journalID, err := postWalletJournal(tx, wallet, models.LedgerAccountPayoutsPending, transaction.Amount, reversalType, refID, description)
+	if err != nil {
+		return err
+	}
+
+	reversal := &models.WalletTransaction{
+		WalletID:      wallet.ID,
+		Type:          models.TransactionTypeRefund,
+		Amount:        transaction.Amount,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  wallet.Balance,
+		Status:        models.TransactionStatusCompleted,
+		ReferenceType: &reversalType,
+		ReferenceID:   &transaction.ID,
+		Description:   &description,
+		Metadata: map[string]interface{}{
+			"failureReason": reason,
+		},
+		JournalID:   &journalID,
+		ProcessedAt: &now,
+	}
+	return tx.Create(reversal).Error
+}
+
+// RefundTopUp returns part or all of a completed top-up to the card it came
+// from. Like a withdrawal, the amount leaves the wallet now and settles when
+// the gateway reports the refund; the debit is committed before the gateway
+// is called and reversed if the gateway refuses.
+func (s *service) RefundTopUp(ctx context.Context, txID string, req dto.RefundTopUpRequest) (*dto.TransactionResponse, error) {
+	topUp, err := s.repo.FindTransactionByID(ctx, txID)
+	if err != nil {
+		return nil, response.NotFoundError("Transaction")
+	}
+
+	if topUp.ReferenceType == nil || *topUp.ReferenceType != refTypeTopUp {
+		return nil, response.BadRequest("Only top-ups can be refunded")
+	}
+
+	var transaction *models.WalletTransaction
+	var userID string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, topUp.WalletID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+		topUp, err := lockTransaction(tx, topUp.ID)
+		if err != nil {
+			return response.NotFoundError("Transaction")
+		}
+		userID = wallet.UserID
+
+		if topUp.Status != models.TransactionStatusCompleted {
+			return response.BadRequest("Only completed top-ups can be refunded")
+		}
+
+		refunded, err := refundedAmount(tx, topUp.ID)
+		if err != nil {
+			return err
+		}
+		remaining := topUp.Amount.Sub(refunded)
+
+		amount := remaining
+		if req.Amount != nil {
+			if amount, err = requestAmount(wallet, *req.Amount); err != nil {
+				return err
+			}
+		}
+		if !amount.IsPositive() || amount.GreaterThan(remaining) {
+			return response.BadRequest(fmt.Sprintf("Refund exceeds the refundable amount (%s)", remaining))
+		}
+
+		if err := requireAvailable(wallet, amount); err != nil {
+			return err
+		}
+
+		balanceBefore := wallet.Balance
+		wallet.Balance = wallet.Balance.Sub(amount)
+		if err := saveWalletBalances(tx, wallet); err != nil {
+			return err
+		}
+
+		// Referenced by its own ID until the gateway assigns a refund ID
+		refundTxID := uuid.New().String()
+		description := fmt.Sprintf("Refund of top-up %s: %s", topUp.ID, req.Reason)
+		journalID, err := postWalletJournal(tx, wallet, models.LedgerAccountPayoutsPending, amount.Neg(), refTypeTopUpRefund, refundTxID, description)
+		if err != nil {
+			return err
+		}
+
+		transaction = &models.WalletTransaction{
+			ID:            refundTxID,
+			WalletID:      wallet.ID,
+			Type:          models.TransactionTypeDebit,
+			Amount:        amount,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  wallet.Balance,
+			Status:        models.TransactionStatusPending,
+			ReferenceType: stringPtr(refTypeTopUpRefund),
+			ReferenceID:   stringPtr(refundTxID),
+			Description:   &description,
+			Metadata: map[string]interface{}{
+				"provider":           s.payments.Name(),
+				"paymentIntentId":    *topUp.ReferenceID,
+				"topUpTransactionId": topUp.ID,
+				"reason":             req.Reason,
+			},
+			JournalID: &journalID,
+		}
+		return tx.Create(transaction).Error
+	})
+
+	if err != nil {
+		logger.Error("failed to refund top-up", "error", err, "txID", txID)
+		return nil, txError(err, "Failed to refund top-up")
+	}
+
+	s.invalidateWalletCache(ctx, userID)
+
+	refund, err := s.payments.Refund(ctx, RefundRequest{
+		PaymentIntentID: *topUp.ReferenceID,
+		Amount:          transaction.Amount,
+		Reference:       transaction.ID,
+		Reason:          req.Reason,
+	})
+	if err != nil {
+		logger.Error("failed to create refund", "error", err, "txID", topUp.ID, "refundTxID", transaction.ID)
+		if err := s.reversePendingPayout(ctx, transaction, "refund request failed"); err != nil {
+			logger.Error("failed to reverse top-up refund", "error", err, "txID", topUp.ID, "refundTxID", transaction.ID)
+		}
+		s.invalidateWalletCache(ctx, userID)
+		return nil, response.ServiceUnavailable("Payment gateway refused the refund")
+	}
+
+	// Webhooks look the refund up by refund ID; until this is saved they
+	// fall back to the reference we sent, so a failure here is only logged
+	transaction.ReferenceID = stringPtr(refund.ID)
+	transaction.Metadata = withMetadata(transaction.Metadata, "refundId", refund.ID)
+	if err := s.db.WithContext(ctx).Model(transaction).Select("reference_id", "metadata").Updates(transaction).Error; err != nil {
+		logger.Error("failed to record refund ID", "error", err, "refundTxID", transaction.ID, "refundID", refund.ID)
+	}
+
+	logger.Info("top-up refund requested", "txID", txID, "refundTxID", transaction.ID, "refundID", refund.ID, "amount", transaction.Amount)
+
+	return dto.ToTransactionResponse(transaction), nil
+}
+
+// SimulatePayment settles a pending intent, refund or payout on the fake
+// gateway and feeds the signed webhook it produces through
+// HandlePaymentWebhook, exactly as if the gateway had delivered it
+func (s *service) SimulatePayment(ctx context.Context, objectID string, req dto.SimulatePaymentRequest) error {
+	fake, ok := s.payments.(*FakeGateway)
+	if !ok {
+		return response.NotFoundError("Payment simulator")
+	}
+
+	payload, headers, err := fake.Simulate(objectID, req.Outcome == string(PaymentStatusSucceeded), req.FailureReason)
+	if err != nil {
+		return response.BadRequest(err.Error())
+	}
+
+	return s.HandlePaymentWebhook(ctx, payload, headers)
+}
+
+// lockTransaction loads a wallet transaction and locks its row, so a webhook
+// delivered twice at once settles it only once
+func lockTransaction(tx *gorm.DB, txID string) (*models.WalletTransaction, error) {
+	var transaction models.WalletTransaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", txID).
+		First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// refundedAmount is how much of a top-up has been refunded or is being refunded
+func refundedAmount(tx *gorm.DB, topUpID string) (money.Amount, error) {
+	var total money.Amount
+	err := tx.Model(&models.WalletTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("reference_type = ? AND metadata->>'topUpTransactionId' = ? AND status IN ?",
+			refTypeTopUpRefund, topUpID,
+			[]models.TransactionStatus{models.TransactionStatusPending, models.TransactionStatusCompleted}).
+		Row().Scan(&total)
+	return total, err
+}
+
+// webhookReferenceType is the reference type of the transactions an event settles
+func webhookReferenceType(eventType PaymentEventType) string {
+	switch eventType {
+	case EventPaymentSucceeded, EventPaymentFailed:
+		return refTypeTopUp
+	case EventRefundSucceeded, EventRefundFailed:
+		return refTypeTopUpRefund
+	case EventPayoutPaid, EventPayoutFailed:
+		return refTypeWithdrawal
+	}
+	return ""
+}
+
+func withMetadata(metadata map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata[key] = value
+	return metadata
+}
+
+// maskAccountNumber keeps the last four digits of a bank account
+func maskAccountNumber(number string) string {
+	number = strings.ReplaceAll(number, " ", "")
+	if len(number) <= 4 {
+		return number
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
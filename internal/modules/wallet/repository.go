@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"gorm.io/gorm"
 )
 
@@ -18,6 +20,8 @@ type Repository interface {
 	// Transaction operations
 	CreateTransaction(ctx context.Context, tx *models.WalletTransaction) error
 	FindTransactionByID(ctx context.Context, id string) (*models.WalletTransaction, error)
+	FindTransactionByReference(ctx context.Context, refType, refID string) (*models.WalletTransaction, error)
+	FindWalletTransactionsByReference(ctx context.Context, walletID string, refTypes []string, refID string) ([]*models.WalletTransaction, error)
 	ListTransactions(ctx context.Context, walletID string, filters map[string]interface{}, page, limit int) ([]*models.WalletTransaction, int64, error)
 
 	// Hold operations
@@ -25,7 +29,35 @@ type Repository interface {
 	FindHoldByID(ctx context.Context, id string) (*models.WalletHold, error)
 	FindHoldsByReference(ctx context.Context, refType, refID string) ([]*models.WalletHold, error)
 	UpdateHold(ctx context.Context, hold *models.WalletHold) error
-	ReleaseExpiredHolds(ctx context.Context) error
+	ReleaseExpiredHolds(ctx context.Context) ([]string, error)
+
+	// Ledger reconciliation
+	CountWallets(ctx context.Context) (int64, error)
+	FindWalletLedgerDrift(ctx context.Context) ([]*WalletLedgerDrift, error)
+	FindUnbalancedJournals(ctx context.Context) ([]*JournalImbalance, error)
+	GetPlatformAccountBalances(ctx context.Context) ([]*LedgerAccountBalance, error)
+}
+
+// WalletLedgerDrift is a wallet whose stored balances disagree with the
+// ledger (balance) or with its active holds (held balance)
+type WalletLedgerDrift struct {
+	WalletID      string
+	UserID        string
+	WalletType    models.WalletType
+	Balance       money.Amount
+	LedgerBalance money.Amount
+	HeldBalance   money.Amount
+	ActiveHolds   money.Amount
+}
+
+type JournalImbalance struct {
+	JournalID string
+	Total     money.Amount
+}
+
+type LedgerAccountBalance struct {
+	Code    string
+	Balance money.Amount
 }
 
 type repository struct {
@@ -75,6 +107,25 @@ func (r *repository) FindTransactionByID(ctx context.Context, id string) (*model
 	return &tx, err
 }
 
+func (r *repository) FindTransactionByReference(ctx context.Context, refType, refID string) (*models.WalletTransaction, error) {
+	var tx models.WalletTransaction
+	err := r.db.WithContext(ctx).
+		Where("reference_type = ? AND reference_id = ?", refType, refID).
+		First(&tx).Error
+	return &tx, err
+}
+
+// FindWalletTransactionsByReference returns one wallet's transactions for a
+// reference, oldest first
+func (r *repository) FindWalletTransactionsByReference(ctx context.Context, walletID string, refTypes []string, refID string) ([]*models.WalletTransaction, error) {
+	var transactions []*models.WalletTransaction
+	err := r.db.WithContext(ctx).
+		Where("wallet_id = ? AND reference_type IN ? AND reference_id = ?", walletID, refTypes, refID).
+		Order("created_at ASC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
 func (r *repository) ListTransactions(ctx context.Context, walletID string, filters map[string]interface{}, page, limit int) ([]*models.WalletTransaction, int64, error) {
 	var transactions []*models.WalletTransaction
 	var total int64
@@ -129,31 +180,58 @@ func (r *repository) UpdateHold(ctx context.Context, hold *models.WalletHold) er
 	return r.db.WithContext(ctx).Save(hold).Error
 }
 
-func (r *repository) ReleaseExpiredHolds(ctx context.Context) error {
+// Holds whose ride or order is still under way are never released by expiry:
+// the ride or order captures or releases them itself when it ends
+var (
+	activeRideStatuses           = []string{"accepted", "arrived", "started"}
+	finishedServiceOrderStatuses = []string{"completed", "cancelled", "expired"}
+)
+
+// ReleaseExpiredHolds releases every held hold past its expiry, except those
+// of rides and service orders still in progress, and returns the user IDs of
+// the wallets it released, one per hold
+func (r *repository) ReleaseExpiredHolds(ctx context.Context) ([]string, error) {
 	now := time.Now()
 	var expiredHolds []*models.WalletHold
 
 	// Find expired holds
 	err := r.db.WithContext(ctx).
 		Where("status = ? AND expires_at < ?", models.TransactionStatusHeld, now).
+		Where(`NOT EXISTS (SELECT 1 FROM rides r
+			WHERE wallet_holds.reference_type = 'ride' AND r.id = wallet_holds.reference_id AND r.status IN ?)`,
+			activeRideStatuses).
+		Where(`NOT EXISTS (SELECT 1 FROM service_orders o
+			WHERE wallet_holds.reference_type = 'service_order'
+			AND (o.id = wallet_holds.reference_id OR o.code = wallet_holds.reference_id::text)
+			AND o.status NOT IN ?)`,
+			finishedServiceOrderStatuses).
 		Find(&expiredHolds).Error
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Release each hold
-	for _, hold := range expiredHolds {
-		// Update wallet
-		var wallet models.Wallet
-		if err := r.db.WithContext(ctx).Where("id = ?", hold.WalletID).First(&wallet).Error; err != nil {
-			continue
-		}
-
-		wallet.HeldBalance -= hold.Amount
-
-		// Update in transaction
+	var userIDs []string
+	for _, expired := range expiredHolds {
+		var userID string
+		released := false
+		// Lock wallet then hold, like ReleaseHold/CaptureHold, and skip holds
+		// captured or released since the scan
 		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			if err := tx.Save(&wallet).Error; err != nil {
+			wallet, err := lockWallet(tx, expired.WalletID)
+			if err != nil {
+				return err
+			}
+			hold, err := lockHold(tx, expired.ID)
+			if err != nil {
+				return err
+			}
+			if hold.Status != models.TransactionStatusHeld {
+				return nil
+			}
+
+			wallet.HeldBalance = wallet.HeldBalance.Sub(hold.Amount)
+			if err := saveWalletBalances(tx, wallet); err != nil {
 				return err
 			}
 
@@ -164,13 +242,79 @@ func (r *repository) ReleaseExpiredHolds(ctx context.Context) error {
 				return err
 			}
 
+			userID = wallet.UserID
+			released = true
 			return nil
 		})
 
 		if err != nil {
+			// One bad hold must not block the rest; it is retried next run
+			logger.Error("failed to release expired hold", "error", err, "holdID", expired.ID)
 			continue
 		}
+		if released {
+			userIDs = append(userIDs, userID)
+		}
 	}
 
-	return nil
+	return userIDs, nil
+}
+
+func (r *repository) CountWallets(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Wallet{}).Count(&count).Error
+	return count, err
+}
+
+func (r *repository) FindWalletLedgerDrift(ctx context.Context) ([]*WalletLedgerDrift, error) {
+	var drift []*WalletLedgerDrift
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT
+				w.id AS wallet_id,
+				w.user_id,
+				w.wallet_type,
+				w.balance,
+				w.held_balance,
+				COALESCE((
+					SELECT SUM(p.amount)
+					FROM ledger_postings p
+					JOIN ledger_accounts a ON a.id = p.account_id
+					WHERE a.wallet_id = w.id
+				), 0) AS ledger_balance,
+				COALESCE((
+					SELECT SUM(h.amount)
+					FROM wallet_holds h
+					WHERE h.wallet_id = w.id AND h.status = ?
+				), 0) AS active_holds
+			FROM wallets w
+		) t
+		WHERE balance <> ledger_balance OR held_balance <> active_holds
+		ORDER BY ABS(balance - ledger_balance) DESC
+	`, models.TransactionStatusHeld).Scan(&drift).Error
+	return drift, err
+}
+
+func (r *repository) FindUnbalancedJournals(ctx context.Context) ([]*JournalImbalance, error) {
+	var journals []*JournalImbalance
+	err := r.db.WithContext(ctx).
+		Model(&models.LedgerPosting{}).
+		Select("journal_id, SUM(amount) AS total").
+		Group("journal_id").
+		Having("SUM(amount) <> 0").
+		Scan(&journals).Error
+	return journals, err
+}
+
+func (r *repository) GetPlatformAccountBalances(ctx context.Context) ([]*LedgerAccountBalance, error) {
+	var balances []*LedgerAccountBalance
+	err := r.db.WithContext(ctx).
+		Table("ledger_accounts a").
+		Select("a.code, COALESCE(SUM(p.amount), 0) AS balance").
+		Joins("LEFT JOIN ledger_postings p ON p.account_id = a.id").
+		Where("a.account_type = ?", models.WalletTypePlatform).
+		Group("a.code").
+		Order("a.code").
+		Scan(&balances).Error
+	return balances, err
 }
@@ -2,9 +2,12 @@ package wallet
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc) {
+func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gin.HandlerFunc, checker permissions.Checker) {
 	wallet := router.Group("/wallet")
 	wallet.Use(authMiddleware)
 	{
@@ -13,9 +16,9 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 		wallet.GET("/balance", handler.GetBalance)
 
 		// Funds management
-		wallet.POST("/add-funds", handler.AddFunds)
-		wallet.POST("/withdraw", handler.WithdrawFunds)
-		wallet.POST("/transfer", handler.TransferFunds)
+		wallet.POST("/add-funds", middleware.Idempotency(), handler.AddFunds)
+		wallet.POST("/withdraw", middleware.Idempotency(), handler.WithdrawFunds)
+		wallet.POST("/transfer", middleware.Idempotency(), handler.TransferFunds)
 
 		// Transactions
 		wallet.GET("/transactions", handler.ListTransactions)
@@ -24,6 +27,20 @@ func RegisterRoutes(router *gin.RouterGroup, handler *Handler, authMiddleware gi
 		// Holds (for internal use by ride system, etc.)
 		wallet.POST("/hold", handler.HoldFunds)
 		wallet.POST("/hold/release", handler.ReleaseHold)
-		wallet.POST("/hold/capture", handler.CaptureHold)
+		wallet.POST("/hold/capture", middleware.Idempotency(), handler.CaptureHold)
+	}
+
+	// Payment gateway callbacks; authenticated by their signature
+	webhooks := router.Group("/wallet/webhooks")
+	{
+		webhooks.POST("/payments", handler.PaymentWebhook)
+	}
+
+	admin := router.Group("/admin/wallet")
+	admin.Use(authMiddleware)
+	{
+		admin.GET("/reconciliation", middleware.RequirePermission(checker, models.PermissionWalletView), handler.Reconcile)
+		admin.POST("/transactions/:id/refund", middleware.RequirePermission(checker, models.PermissionWalletAdjust), middleware.Idempotency(), handler.RefundTopUp)
+		admin.POST("/payments/:id/simulate", middleware.RequirePermission(checker, models.PermissionWalletAdjust), handler.SimulatePayment)
 	}
 }
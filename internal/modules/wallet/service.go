@@ -3,12 +3,15 @@ package wallet
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/umar5678/go-backend/internal/models"
 	"github.com/umar5678/go-backend/internal/modules/wallet/dto"
 	"github.com/umar5678/go-backend/internal/services/cache"
 	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
 	"github.com/umar5678/go-backend/internal/utils/response"
 	"gorm.io/gorm"
 )
@@ -19,32 +22,50 @@ type Service interface {
 	GetBalance(ctx context.Context, userID string) (float64, error)
 
 	// Transaction operations
-	AddFunds(ctx context.Context, userID string, req dto.AddFundsRequest) (*dto.TransactionResponse, error)
+	AddFunds(ctx context.Context, userID string, req dto.AddFundsRequest) (*dto.TopUpResponse, error)
 	WithdrawFunds(ctx context.Context, userID string, req dto.WithdrawFundsRequest) (*dto.TransactionResponse, error)
 	TransferFunds(ctx context.Context, senderID string, req dto.TransferFundsRequest) (*dto.TransactionResponse, error)
 	ListTransactions(ctx context.Context, userID string, req dto.ListTransactionsRequest) ([]*dto.TransactionResponse, int64, error)
 	GetTransaction(ctx context.Context, userID, txID string) (*dto.TransactionResponse, error)
+	ListTransactionsByReference(ctx context.Context, userID string, refTypes []string, refID string) ([]*dto.TransactionResponse, error)
 
 	// Hold operations (for rides, orders, etc.)
 	HoldFunds(ctx context.Context, userID string, req dto.HoldFundsRequest) (*dto.HoldResponse, error)
 	ReleaseHold(ctx context.Context, userID string, req dto.ReleaseHoldRequest) error
+	IncreaseHold(ctx context.Context, userID string, req dto.IncreaseHoldRequest) (*dto.HoldResponse, error)
+	ExtendHold(ctx context.Context, userID string, req dto.ExtendHoldRequest) (*dto.HoldResponse, error)
 	CaptureHold(ctx context.Context, userID string, req dto.CaptureHoldRequest) (*dto.TransactionResponse, error)
 	GetHoldsByReference(ctx context.Context, refType, refID string) ([]*dto.HoldResponse, error)
+	ReleaseExpiredHolds(ctx context.Context) (int64, error)
 
 	// Internal operations (used by other modules)
-	DebitWallet(ctx context.Context, userID string, amount float64, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error)
-	CreditWallet(ctx context.Context, userID string, amount float64, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error)
+	DebitWallet(ctx context.Context, userID string, amount money.Amount, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error)
+	// CreditWallet pays amount into the wallet. commission is the platform's
+	// cut of the payment the payout comes from (zero when there is none); the
+	// ledger moves it from clearing to platform revenue. Both must be in the
+	// wallet's currency.
+	CreditWallet(ctx context.Context, userID string, amount money.Amount, commission money.Amount, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error)
+
+	// Payment gateway
+	HandlePaymentWebhook(ctx context.Context, payload []byte, headers http.Header) error
+	RefundTopUp(ctx context.Context, txID string, req dto.RefundTopUpRequest) (*dto.TransactionResponse, error)
+	SimulatePayment(ctx context.Context, objectID string, req dto.SimulatePaymentRequest) error
+
+	// Admin
+	Reconcile(ctx context.Context) (*dto.ReconciliationResponse, error)
 }
 
 type service struct {
-	repo Repository
-	db   *gorm.DB
+	repo     Repository
+	db       *gorm.DB
+	payments PaymentProvider
 }
 
-func NewService(repo Repository, db *gorm.DB) Service {
+func NewService(repo Repository, db *gorm.DB, payments PaymentProvider) Service {
 	return &service{
-		repo: repo,
-		db:   db,
+		repo:     repo,
+		db:       db,
+		payments: payments,
 	}
 }
 
@@ -80,11 +101,13 @@ func (s *service) GetBalance(ctx context.Context, userID string) (float64, error
 	if err != nil {
 		return 0, err
 	}
-	return wallet.AvailableBalance, nil
+	return wallet.AvailableBalance.Float64(), nil
 }
 
-// AddFunds adds money to wallet (simulated top-up)
-func (s *service) AddFunds(ctx context.Context, userID string, req dto.AddFundsRequest) (*dto.TransactionResponse, error) {
+// AddFunds starts a top-up through the payment gateway. The transaction stays
+// pending and the wallet is only credited when the gateway confirms the
+// payment (see HandlePaymentWebhook).
+func (s *service) AddFunds(ctx context.Context, userID string, req dto.AddFundsRequest) (*dto.TopUpResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, response.BadRequest(err.Error())
 	}
@@ -95,63 +118,70 @@ func (s *service) AddFunds(ctx context.Context, userID string, req dto.AddFundsR
 		return nil, err
 	}
 
-	wallet, err := s.repo.FindWalletByID(ctx, walletResp.ID)
-	if err != nil {
-		return nil, response.NotFoundError("Wallet")
-	}
-
-	if !wallet.IsActive {
+	if !walletResp.IsActive {
 		return nil, response.BadRequest("Wallet is not active")
 	}
 
-	// Create transaction in database transaction
-	var transaction *models.WalletTransaction
-	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Record balance before
-		balanceBefore := wallet.Balance
-
-		// Update wallet balance
-		wallet.Balance += req.Amount
-
-		if err := tx.Save(wallet).Error; err != nil {
-			return err
-		}
-
-		// Create transaction record
-		now := time.Now()
-		transaction = &models.WalletTransaction{
-			WalletID:      wallet.ID,
-			Type:          models.TransactionTypeCredit,
-			Amount:        req.Amount,
-			BalanceBefore: balanceBefore,
-			BalanceAfter:  wallet.Balance,
-			Status:        models.TransactionStatusCompleted,
-			ReferenceType: stringPtr("topup"),
-			Description:   stringPtr(req.Description),
-			ProcessedAt:   &now,
-		}
-
-		if err := tx.Create(transaction).Error; err != nil {
-			return err
-		}
+	amount, err := inCurrency(money.Currency(walletResp.Currency), req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	txID := uuid.New().String()
 
-		return nil
+	intent, err := s.payments.CreatePaymentIntent(ctx, PaymentIntentRequest{
+		Amount:      amount,
+		Reference:   txID,
+		Description: req.Description,
 	})
-
 	if err != nil {
-		logger.Error("failed to add funds", "error", err, "userID", userID)
-		return nil, response.InternalServerError("Failed to add funds", err)
+		logger.Error("failed to create payment intent", "error", err, "userID", userID)
+		return nil, response.ServiceUnavailable("Payment gateway unavailable")
 	}
 
-	// Invalidate cache
-	s.invalidateWalletCache(ctx, userID)
+	// Nothing moves until the webhook; balances are filled in then
+	transaction := &models.WalletTransaction{
+		ID:            txID,
+		WalletID:      walletResp.ID,
+		Type:          models.TransactionTypeCredit,
+		Amount:        amount,
+		BalanceBefore: walletResp.Balance,
+		BalanceAfter:  walletResp.Balance,
+		Status:        models.TransactionStatusPending,
+		ReferenceType: stringPtr(refTypeTopUp),
+		ReferenceID:   stringPtr(intent.ID),
+		Description:   stringPtr(req.Description),
+		Metadata: map[string]interface{}{
+			"provider":        s.payments.Name(),
+			"paymentIntentId": intent.ID,
+		},
+	}
 
-	logger.Info("funds added", "userID", userID, "amount", req.Amount, "txID", transaction.ID)
+	if err := s.repo.CreateTransaction(ctx, transaction); err != nil {
+		logger.Error("failed to record top-up", "error", err, "userID", userID, "paymentIntentID", intent.ID)
+		return nil, response.InternalServerError("Failed to add funds", err)
+	}
 
-	return dto.ToTransactionResponse(transaction), nil
+	logger.Info("top-up started", "userID", userID, "amount", amount, "txID", transaction.ID, "paymentIntentID", intent.ID)
+
+	return &dto.TopUpResponse{
+		Transaction: dto.ToTransactionResponse(transaction),
+		Payment: dto.PaymentIntentResponse{
+			ID:           intent.ID,
+			Provider:     s.payments.Name(),
+			Status:       string(intent.Status),
+			ClientSecret: intent.ClientSecret,
+		},
+	}, nil
 }
 
-// WithdrawFunds withdraws money from wallet
+// WithdrawFunds pays money out to a bank account. The amount leaves the
+// wallet at once and waits in platform:payouts_pending until the gateway
+// reports the payout paid or failed (see HandlePaymentWebhook).
+//
+// The debit and the pending withdrawal are committed before the gateway is
+// called, so no row lock is held across the network call and a payout is
+// never sent for a debit that did not happen. If the gateway refuses, the
+// debit is reversed.
 func (s *service) WithdrawFunds(ctx context.Context, userID string, req dto.WithdrawFundsRequest) (*dto.TransactionResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, response.BadRequest(err.Error())
@@ -163,44 +193,60 @@ func (s *service) WithdrawFunds(ctx context.Context, userID string, req dto.With
 		return nil, err
 	}
 
-	wallet, err := s.repo.FindWalletByID(ctx, walletResp.ID)
-	if err != nil {
-		return nil, response.NotFoundError("Wallet")
-	}
-
-	if !wallet.IsActive {
-		return nil, response.BadRequest("Wallet is not active")
-	}
-
-	// Check available balance
-	if wallet.GetAvailableBalance() < req.Amount {
-		return nil, response.BadRequest("Insufficient balance")
-	}
-
 	// Create transaction
 	var transaction *models.WalletTransaction
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, walletResp.ID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+
+		if !wallet.IsActive {
+			return response.BadRequest("Wallet is not active")
+		}
+		amount, err := requestAmount(wallet, req.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Check available balance
+		if err := requireAvailable(wallet, amount); err != nil {
+			return err
+		}
+
 		balanceBefore := wallet.Balance
 
 		// Update wallet balance
-		wallet.Balance -= req.Amount
+		wallet.Balance = wallet.Balance.Sub(amount)
 
-		if err := tx.Save(wallet).Error; err != nil {
+		if err := saveWalletBalances(tx, wallet); err != nil {
+			return err
+		}
+
+		// Referenced by its own ID until the gateway assigns a payout ID
+		txID := uuid.New().String()
+		journalID, err := postWalletJournal(tx, wallet, models.LedgerAccountPayoutsPending, amount.Neg(), refTypeWithdrawal, txID, req.Description)
+		if err != nil {
 			return err
 		}
 
 		// Create transaction record
-		now := time.Now()
 		transaction = &models.WalletTransaction{
+			ID:            txID,
 			WalletID:      wallet.ID,
 			Type:          models.TransactionTypeDebit,
-			Amount:        req.Amount,
+			Amount:        amount,
 			BalanceBefore: balanceBefore,
 			BalanceAfter:  wallet.Balance,
-			Status:        models.TransactionStatusCompleted,
-			ReferenceType: stringPtr("withdrawal"),
+			Status:        models.TransactionStatusPending,
+			ReferenceType: stringPtr(refTypeWithdrawal),
+			ReferenceID:   stringPtr(txID),
 			Description:   stringPtr(req.Description),
-			ProcessedAt:   &now,
+			Metadata: map[string]interface{}{
+				"provider":    s.payments.Name(),
+				"bankAccount": maskAccountNumber(req.BankAccount.AccountNumber),
+			},
+			JournalID: &journalID,
 		}
 
 		if err := tx.Create(transaction).Error; err != nil {
@@ -212,17 +258,64 @@ func (s *service) WithdrawFunds(ctx context.Context, userID string, req dto.With
 
 	if err != nil {
 		logger.Error("failed to withdraw funds", "error", err, "userID", userID)
-		return nil, response.InternalServerError("Failed to withdraw funds", err)
+		return nil, txError(err, "Failed to withdraw funds")
 	}
 
 	// Invalidate cache
 	s.invalidateWalletCache(ctx, userID)
 
-	logger.Info("funds withdrawn", "userID", userID, "amount", req.Amount, "txID", transaction.ID)
+	payout, err := s.payments.CreatePayout(ctx, PayoutRequest{
+		Amount:    transaction.Amount,
+		Reference: transaction.ID,
+		BankAccount: BankAccount{
+			HolderName:    req.BankAccount.HolderName,
+			AccountNumber: req.BankAccount.AccountNumber,
+			BankCode:      req.BankAccount.BankCode,
+		},
+		Description: req.Description,
+	})
+	if err != nil {
+		logger.Error("failed to create payout", "error", err, "userID", userID, "txID", transaction.ID)
+		if err := s.reversePendingPayout(ctx, transaction, "payout request failed"); err != nil {
+			logger.Error("failed to reverse withdrawal", "error", err, "userID", userID, "txID", transaction.ID)
+		}
+		s.invalidateWalletCache(ctx, userID)
+		return nil, response.ServiceUnavailable("Payment gateway unavailable")
+	}
+
+	// Webhooks look the withdrawal up by payout ID; until this is saved they
+	// fall back to the reference we sent, so a failure here is only logged
+	transaction.ReferenceID = stringPtr(payout.ID)
+	transaction.Metadata = withMetadata(transaction.Metadata, "payoutId", payout.ID)
+	if err := s.db.WithContext(ctx).Model(transaction).Select("reference_id", "metadata").Updates(transaction).Error; err != nil {
+		logger.Error("failed to record payout ID", "error", err, "txID", transaction.ID, "payoutID", payout.ID)
+	}
+
+	logger.Info("withdrawal requested", "userID", userID, "amount", req.Amount, "txID", transaction.ID, "payoutID", payout.ID)
 
 	return dto.ToTransactionResponse(transaction), nil
 }
 
+// reversePendingPayout gives a pending withdrawal or top-up refund back to
+// the wallet when the gateway could not be asked to pay it out
+func (s *service) reversePendingPayout(ctx context.Context, pending *models.WalletTransaction, reason string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, pending.WalletID)
+		if err != nil {
+			return err
+		}
+		transaction, err := lockTransaction(tx, pending.ID)
+		if err != nil {
+			return err
+		}
+
+		if transaction.Status != models.TransactionStatusPending {
+			return nil
+		}
+		return reversePayout(tx, wallet, transaction, transaction.ID, reason)
+	})
+}
+
 // TransferFunds transfers money between wallets
 func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.TransferFundsRequest) (*dto.TransactionResponse, error) {
 	if err := req.Validate(); err != nil {
@@ -239,45 +332,70 @@ func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.Tr
 		return nil, err
 	}
 
-	senderWallet, err := s.repo.FindWalletByID(ctx, senderWalletResp.ID)
-	if err != nil {
-		return nil, response.NotFoundError("Sender wallet")
-	}
-
 	// Get recipient wallet
 	recipientWalletResp, err := s.GetWallet(ctx, req.RecipientID)
 	if err != nil {
 		return nil, response.NotFoundError("Recipient wallet")
 	}
 
-	recipientWallet, err := s.repo.FindWalletByID(ctx, recipientWalletResp.ID)
-	if err != nil {
-		return nil, response.NotFoundError("Recipient wallet")
-	}
-
-	// Check balances
-	if senderWallet.GetAvailableBalance() < req.Amount {
-		return nil, response.BadRequest("Insufficient balance")
-	}
-
-	if !senderWallet.IsActive || !recipientWallet.IsActive {
-		return nil, response.BadRequest("One or both wallets are not active")
+	if senderWalletResp.ID == recipientWalletResp.ID {
+		return nil, response.BadRequest("Cannot transfer to yourself")
 	}
 
 	// Perform transfer
 	var senderTx *models.WalletTransaction
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallets, err := lockWallets(tx, senderWalletResp.ID, recipientWalletResp.ID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+		senderWallet := wallets[senderWalletResp.ID]
+		recipientWallet := wallets[recipientWalletResp.ID]
+
+		if !senderWallet.IsActive || !recipientWallet.IsActive {
+			return response.BadRequest("One or both wallets are not active")
+		}
+		if senderWallet.Currency != recipientWallet.Currency {
+			return response.BadRequest("Wallets use different currencies")
+		}
+		amount, err := requestAmount(senderWallet, req.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Check balances
+		if err := requireAvailable(senderWallet, amount); err != nil {
+			return err
+		}
+
 		// Debit sender
 		senderBalanceBefore := senderWallet.Balance
-		senderWallet.Balance -= req.Amount
-		if err := tx.Save(senderWallet).Error; err != nil {
+		senderWallet.Balance = senderWallet.Balance.Sub(amount)
+		if err := saveWalletBalances(tx, senderWallet); err != nil {
 			return err
 		}
 
 		// Credit recipient
 		recipientBalanceBefore := recipientWallet.Balance
-		recipientWallet.Balance += req.Amount
-		if err := tx.Save(recipientWallet).Error; err != nil {
+		recipientWallet.Balance = recipientWallet.Balance.Add(amount)
+		if err := saveWalletBalances(tx, recipientWallet); err != nil {
+			return err
+		}
+
+		// Wallet to wallet; no platform account involved
+		senderAccount, err := walletAccountID(tx, senderWallet)
+		if err != nil {
+			return err
+		}
+		recipientAccount, err := walletAccountID(tx, recipientWallet)
+		if err != nil {
+			return err
+		}
+		journalID, err := postJournal(tx, "transfer", uuid.New().String(), req.Description,
+			ledgerEntry{accountID: senderAccount, amount: amount.Neg()},
+			ledgerEntry{accountID: recipientAccount, amount: amount},
+		)
+		if err != nil {
 			return err
 		}
 
@@ -287,7 +405,7 @@ func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.Tr
 		senderTx = &models.WalletTransaction{
 			WalletID:      senderWallet.ID,
 			Type:          models.TransactionTypeTransfer,
-			Amount:        req.Amount,
+			Amount:        amount,
 			BalanceBefore: senderBalanceBefore,
 			BalanceAfter:  senderWallet.Balance,
 			Status:        models.TransactionStatusCompleted,
@@ -297,6 +415,7 @@ func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.Tr
 			Metadata: map[string]interface{}{
 				"recipientId": req.RecipientID,
 			},
+			JournalID:   &journalID,
 			ProcessedAt: &now,
 		}
 		if err := tx.Create(senderTx).Error; err != nil {
@@ -307,7 +426,7 @@ func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.Tr
 		recipientTx := &models.WalletTransaction{
 			WalletID:      recipientWallet.ID,
 			Type:          models.TransactionTypeTransfer,
-			Amount:        req.Amount,
+			Amount:        amount,
 			BalanceBefore: recipientBalanceBefore,
 			BalanceAfter:  recipientWallet.Balance,
 			Status:        models.TransactionStatusCompleted,
@@ -317,6 +436,7 @@ func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.Tr
 			Metadata: map[string]interface{}{
 				"senderId": senderID,
 			},
+			JournalID:   &journalID,
 			ProcessedAt: &now,
 		}
 		if err := tx.Create(recipientTx).Error; err != nil {
@@ -328,7 +448,7 @@ func (s *service) TransferFunds(ctx context.Context, senderID string, req dto.Tr
 
 	if err != nil {
 		logger.Error("failed to transfer funds", "error", err, "senderID", senderID)
-		return nil, response.InternalServerError("Failed to transfer funds", err)
+		return nil, txError(err, "Failed to transfer funds")
 	}
 
 	// Invalidate cache for both users
@@ -352,22 +472,27 @@ func (s *service) HoldFunds(ctx context.Context, userID string, req dto.HoldFund
 		return nil, err
 	}
 
-	wallet, err := s.repo.FindWalletByID(ctx, walletResp.ID)
-	if err != nil {
-		return nil, response.NotFoundError("Wallet")
-	}
-
-	// Check available balance
-	if wallet.GetAvailableBalance() < req.Amount {
-		return nil, response.BadRequest("Insufficient balance")
-	}
-
 	// Create hold
 	var hold *models.WalletHold
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, walletResp.ID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+
+		amount, err := requestAmount(wallet, req.Amount)
+		if err != nil {
+			return err
+		}
+
+		// Check available balance
+		if err := requireAvailable(wallet, amount); err != nil {
+			return err
+		}
+
 		// Update wallet held balance
-		wallet.HeldBalance += req.Amount
-		if err := tx.Save(wallet).Error; err != nil {
+		wallet.HeldBalance = wallet.HeldBalance.Add(amount)
+		if err := saveWalletBalances(tx, wallet); err != nil {
 			return err
 		}
 
@@ -375,7 +500,7 @@ func (s *service) HoldFunds(ctx context.Context, userID string, req dto.HoldFund
 		expiresAt := time.Now().Add(time.Duration(req.HoldDuration) * time.Minute)
 		hold = &models.WalletHold{
 			WalletID:      wallet.ID,
-			Amount:        req.Amount,
+			Amount:        amount,
 			ReferenceType: req.ReferenceType,
 			ReferenceID:   req.ReferenceID,
 			Status:        models.TransactionStatusHeld,
@@ -391,7 +516,7 @@ func (s *service) HoldFunds(ctx context.Context, userID string, req dto.HoldFund
 
 	if err != nil {
 		logger.Error("failed to hold funds", "error", err, "userID", userID)
-		return nil, response.InternalServerError("Failed to hold funds", err)
+		return nil, txError(err, "Failed to hold funds")
 	}
 
 	// Invalidate cache
@@ -425,9 +550,24 @@ func (s *service) ReleaseHold(ctx context.Context, userID string, req dto.Releas
 
 	// Release hold
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Wallet first, then hold: the same order as capture
+		wallet, err := lockWallet(tx, hold.WalletID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+		hold, err := lockHold(tx, hold.ID)
+		if err != nil {
+			return response.NotFoundError("Hold")
+		}
+
+		// Re-check on the locked row; another request may have got here first
+		if hold.Status != models.TransactionStatusHeld {
+			return response.BadRequest("Hold is not in held status")
+		}
+
 		// Update wallet
-		wallet.HeldBalance -= hold.Amount
-		if err := tx.Save(wallet).Error; err != nil {
+		wallet.HeldBalance = wallet.HeldBalance.Sub(hold.Amount)
+		if err := saveWalletBalances(tx, wallet); err != nil {
 			return err
 		}
 
@@ -444,7 +584,7 @@ func (s *service) ReleaseHold(ctx context.Context, userID string, req dto.Releas
 
 	if err != nil {
 		logger.Error("failed to release hold", "error", err, "holdID", req.HoldID)
-		return response.InternalServerError("Failed to release hold", err)
+		return txError(err, "Failed to release hold")
 	}
 
 	// Invalidate cache
@@ -455,6 +595,122 @@ func (s *service) ReleaseHold(ctx context.Context, userID string, req dto.Releas
 	return nil
 }
 
+// IncreaseHold raises a live hold to req.Amount, holding the difference from
+// the available balance. The hold keeps its ID, so whatever references it
+// (a ride, an order) captures the larger amount later.
+func (s *service) IncreaseHold(ctx context.Context, userID string, req dto.IncreaseHoldRequest) (*dto.HoldResponse, error) {
+	hold, err := s.repo.FindHoldByID(ctx, req.HoldID)
+	if err != nil {
+		return nil, response.NotFoundError("Hold")
+	}
+
+	wallet, err := s.repo.FindWalletByID(ctx, hold.WalletID)
+	if err != nil {
+		return nil, response.NotFoundError("Wallet")
+	}
+
+	// Verify ownership
+	if wallet.UserID != userID {
+		return nil, response.ForbiddenError("Not authorized to change this hold")
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Wallet first, then hold: the same order as capture
+		wallet, err := lockWallet(tx, hold.WalletID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+		hold, err = lockHold(tx, hold.ID)
+		if err != nil {
+			return response.NotFoundError("Hold")
+		}
+
+		if hold.Status != models.TransactionStatusHeld {
+			return response.BadRequest("Hold is not in held status")
+		}
+
+		amount, err := requestAmount(wallet, req.Amount)
+		if err != nil {
+			return err
+		}
+		if !amount.GreaterThan(hold.Amount) {
+			return response.BadRequest("New hold amount must be greater than the current amount")
+		}
+
+		extra := amount.Sub(hold.Amount)
+		if err := requireAvailable(wallet, extra); err != nil {
+			return err
+		}
+
+		wallet.HeldBalance = wallet.HeldBalance.Add(extra)
+		if err := saveWalletBalances(tx, wallet); err != nil {
+			return err
+		}
+
+		hold.Amount = amount
+		return tx.Save(hold).Error
+	})
+
+	if err != nil {
+		logger.Error("failed to increase hold", "error", err, "holdID", req.HoldID)
+		return nil, txError(err, "Failed to increase hold")
+	}
+
+	// Invalidate cache
+	s.invalidateWalletCache(ctx, userID)
+
+	logger.Info("hold increased", "userID", userID, "holdID", req.HoldID, "amount", hold.Amount)
+
+	return dto.ToHoldResponse(hold), nil
+}
+
+// ExtendHold pushes a live hold's expiry out to req.HoldDuration minutes from
+// now, so a ride or order that runs long keeps its money held. A hold never
+// gets shorter.
+func (s *service) ExtendHold(ctx context.Context, userID string, req dto.ExtendHoldRequest) (*dto.HoldResponse, error) {
+	hold, err := s.repo.FindHoldByID(ctx, req.HoldID)
+	if err != nil {
+		return nil, response.NotFoundError("Hold")
+	}
+
+	wallet, err := s.repo.FindWalletByID(ctx, hold.WalletID)
+	if err != nil {
+		return nil, response.NotFoundError("Wallet")
+	}
+
+	// Verify ownership
+	if wallet.UserID != userID {
+		return nil, response.ForbiddenError("Not authorized to change this hold")
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		hold, err = lockHold(tx, hold.ID)
+		if err != nil {
+			return response.NotFoundError("Hold")
+		}
+
+		if hold.Status != models.TransactionStatusHeld {
+			return response.BadRequest("Hold is not in held status")
+		}
+
+		expiresAt := time.Now().Add(time.Duration(req.HoldDuration) * time.Minute)
+		if !expiresAt.After(hold.ExpiresAt) {
+			return nil
+		}
+		hold.ExpiresAt = expiresAt
+		return tx.Model(hold).Update("expires_at", expiresAt).Error
+	})
+
+	if err != nil {
+		logger.Error("failed to extend hold", "error", err, "holdID", req.HoldID)
+		return nil, txError(err, "Failed to extend hold")
+	}
+
+	logger.Info("hold extended", "userID", userID, "holdID", hold.ID, "expiresAt", hold.ExpiresAt)
+
+	return dto.ToHoldResponse(hold), nil
+}
+
 // CaptureHold captures a hold and creates a transaction
 func (s *service) CaptureHold(ctx context.Context, userID string, req dto.CaptureHoldRequest) (*dto.TransactionResponse, error) {
 	hold, err := s.repo.FindHoldByID(ctx, req.HoldID)
@@ -479,22 +735,42 @@ func (s *service) CaptureHold(ctx context.Context, userID string, req dto.Captur
 	// Determine capture amount
 	captureAmount := hold.Amount
 	if req.Amount != nil {
-		if *req.Amount > hold.Amount {
+		captureAmount, err = requestAmount(wallet, *req.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if !captureAmount.IsPositive() {
+			return nil, response.BadRequest("Capture amount must be greater than 0")
+		}
+		if captureAmount.GreaterThan(hold.Amount) {
 			return nil, response.BadRequest("Capture amount exceeds hold amount")
 		}
-		captureAmount = *req.Amount
 	}
 
 	// Capture hold
 	var transaction *models.WalletTransaction
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, hold.WalletID)
+		if err != nil {
+			return response.NotFoundError("Wallet")
+		}
+		hold, err := lockHold(tx, hold.ID)
+		if err != nil {
+			return response.NotFoundError("Hold")
+		}
+
+		// Re-check on the locked row; another request may have got here first
+		if hold.Status != models.TransactionStatusHeld {
+			return response.BadRequest("Hold is not in held status")
+		}
+
 		balanceBefore := wallet.Balance
 
 		// Deduct from balance and held balance
-		wallet.Balance -= captureAmount
-		wallet.HeldBalance -= hold.Amount // Release full hold amount
+		wallet.Balance = wallet.Balance.Sub(captureAmount)
+		wallet.HeldBalance = wallet.HeldBalance.Sub(hold.Amount) // Release full hold amount
 
-		if err := tx.Save(wallet).Error; err != nil {
+		if err := saveWalletBalances(tx, wallet); err != nil {
 			return err
 		}
 
@@ -512,6 +788,12 @@ func (s *service) CaptureHold(ctx context.Context, userID string, req dto.Captur
 			description = fmt.Sprintf("Captured from hold %s", hold.ID)
 		}
 
+		// Payment collected; sits in clearing until paid out
+		journalID, err := postWalletJournal(tx, wallet, models.LedgerAccountClearing, captureAmount.Neg(), hold.ReferenceType, hold.ReferenceID, description)
+		if err != nil {
+			return err
+		}
+
 		transaction = &models.WalletTransaction{
 			WalletID:      wallet.ID,
 			Type:          models.TransactionTypeDebit,
@@ -524,30 +806,21 @@ func (s *service) CaptureHold(ctx context.Context, userID string, req dto.Captur
 			Description:   &description,
 			Metadata: map[string]interface{}{
 				"holdId":         hold.ID,
-				"heldAmount":     hold.Amount,
-				"capturedAmount": captureAmount,
+				"heldAmount":     hold.Amount.Float64(),
+				"capturedAmount": captureAmount.Float64(),
 			},
+			JournalID:   &journalID,
 			ProcessedAt: &now,
 		}
 
-		if err := tx.Create(transaction).Error; err != nil {
-			return err
-		}
-
-		// If partial capture, release remaining
-		if captureAmount < hold.Amount {
-			wallet.Balance += (hold.Amount - captureAmount)
-			if err := tx.Save(wallet).Error; err != nil {
-				return err
-			}
-		}
-
-		return nil
+		// A partial capture needs no refund: only captureAmount left the
+		// balance and the whole hold was released above
+		return tx.Create(transaction).Error
 	})
 
 	if err != nil {
 		logger.Error("failed to capture hold", "error", err, "holdID", req.HoldID)
-		return nil, response.InternalServerError("Failed to capture hold", err)
+		return nil, txError(err, "Failed to capture hold")
 	}
 
 	// Invalidate cache
@@ -573,6 +846,21 @@ func (s *service) GetHoldsByReference(ctx context.Context, refType, refID string
 	return result, nil
 }
 
+// ReleaseExpiredHolds gives expired holds back to their wallets. Run
+// periodically by the job scheduler; returns how many holds were released.
+func (s *service) ReleaseExpiredHolds(ctx context.Context) (int64, error) {
+	userIDs, err := s.repo.ReleaseExpiredHolds(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, userID := range userIDs {
+		s.invalidateWalletCache(ctx, userID)
+	}
+
+	return int64(len(userIDs)), nil
+}
+
 // ListTransactions lists user's transactions
 func (s *service) ListTransactions(ctx context.Context, userID string, req dto.ListTransactionsRequest) ([]*dto.TransactionResponse, int64, error) {
 	req.SetDefaults()
@@ -604,6 +892,27 @@ func (s *service) ListTransactions(ctx context.Context, userID string, req dto.L
 	return result, total, nil
 }
 
+// ListTransactionsByReference returns the user's transactions for a
+// reference (e.g. the fare and fees of one ride), oldest first
+func (s *service) ListTransactionsByReference(ctx context.Context, userID string, refTypes []string, refID string) ([]*dto.TransactionResponse, error) {
+	walletResp, err := s.GetWallet(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.FindWalletTransactionsByReference(ctx, walletResp.ID, refTypes, refID)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to fetch transactions", err)
+	}
+
+	result := make([]*dto.TransactionResponse, len(transactions))
+	for i, tx := range transactions {
+		result[i] = dto.ToTransactionResponse(tx)
+	}
+
+	return result, nil
+}
+
 // GetTransaction retrieves a specific transaction
 func (s *service) GetTransaction(ctx context.Context, userID, txID string) (*dto.TransactionResponse, error) {
 	transaction, err := s.repo.FindTransactionByID(ctx, txID)
@@ -621,27 +930,37 @@ func (s *service) GetTransaction(ctx context.Context, userID, txID string) (*dto
 }
 
 // DebitWallet - Internal method for other modules
-func (s *service) DebitWallet(ctx context.Context, userID string, amount float64, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error) {
+func (s *service) DebitWallet(ctx context.Context, userID string, amountValue money.Amount, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error) {
 	walletResp, err := s.GetWallet(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	wallet, err := s.repo.FindWalletByID(ctx, walletResp.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	if wallet.GetAvailableBalance() < amount {
-		return nil, response.BadRequest("Insufficient balance")
-	}
-
 	var transaction *models.WalletTransaction
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, walletResp.ID)
+		if err != nil {
+			return err
+		}
+
+		amount, err := requestAmount(wallet, amountValue)
+		if err != nil {
+			return err
+		}
+
+		if err := requireAvailable(wallet, amount); err != nil {
+			return err
+		}
+
 		balanceBefore := wallet.Balance
-		wallet.Balance -= amount
+		wallet.Balance = wallet.Balance.Sub(amount)
 
-		if err := tx.Save(wallet).Error; err != nil {
+		if err := saveWalletBalances(tx, wallet); err != nil {
+			return err
+		}
+
+		journalID, err := postWalletJournal(tx, wallet, models.LedgerAccountClearing, amount.Neg(), refType, refID, description)
+		if err != nil {
 			return err
 		}
 
@@ -657,6 +976,7 @@ func (s *service) DebitWallet(ctx context.Context, userID string, amount float64
 			ReferenceID:   &refID,
 			Description:   &description,
 			Metadata:      metadata,
+			JournalID:     &journalID,
 			ProcessedAt:   &now,
 		}
 
@@ -672,23 +992,41 @@ func (s *service) DebitWallet(ctx context.Context, userID string, amount float64
 }
 
 // CreditWallet - Internal method for other modules
-func (s *service) CreditWallet(ctx context.Context, userID string, amount float64, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error) {
-	walletResp, err := s.GetWallet(ctx, userID)
-	if err != nil {
-		return nil, err
+func (s *service) CreditWallet(ctx context.Context, userID string, amountValue, commissionValue money.Amount, refType, refID, description string, metadata map[string]interface{}) (*models.WalletTransaction, error) {
+	if commissionValue.IsNegative() {
+		return nil, response.BadRequest("Commission cannot be negative")
 	}
 
-	wallet, err := s.repo.FindWalletByID(ctx, walletResp.ID)
+	walletResp, err := s.GetWallet(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	var transaction *models.WalletTransaction
 	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		wallet, err := lockWallet(tx, walletResp.ID)
+		if err != nil {
+			return err
+		}
+
+		amount, err := requestAmount(wallet, amountValue)
+		if err != nil {
+			return err
+		}
+		commission, err := requestAmount(wallet, commissionValue)
+		if err != nil {
+			return err
+		}
+
 		balanceBefore := wallet.Balance
-		wallet.Balance += amount
+		wallet.Balance = wallet.Balance.Add(amount)
 
-		if err := tx.Save(wallet).Error; err != nil {
+		if err := saveWalletBalances(tx, wallet); err != nil {
+			return err
+		}
+
+		journalID, err := postPayoutJournal(tx, wallet, amount, commission, refType, refID, description)
+		if err != nil {
 			return err
 		}
 
@@ -704,6 +1042,7 @@ func (s *service) CreditWallet(ctx context.Context, userID string, amount float6
 			ReferenceID:   &refID,
 			Description:   &description,
 			Metadata:      metadata,
+			JournalID:     &journalID,
 			ProcessedAt:   &now,
 		}
 
@@ -718,6 +1057,83 @@ func (s *service) CreditWallet(ctx context.Context, userID string, amount float6
 	return transaction, nil
 }
 
+// Reconcile checks every wallet's stored balance against its ledger postings
+// and its held balance against its active holds
+func (s *service) Reconcile(ctx context.Context) (*dto.ReconciliationResponse, error) {
+	walletCount, err := s.repo.CountWallets(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to count wallets", err)
+	}
+
+	drift, err := s.repo.FindWalletLedgerDrift(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to compare wallets with ledger", err)
+	}
+
+	journals, err := s.repo.FindUnbalancedJournals(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to check ledger journals", err)
+	}
+
+	accounts, err := s.repo.GetPlatformAccountBalances(ctx)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to load platform accounts", err)
+	}
+
+	report := &dto.ReconciliationResponse{
+		GeneratedAt:        time.Now(),
+		WalletsChecked:     walletCount,
+		WalletsWithDrift:   len(drift),
+		Drift:              make([]dto.WalletDriftResponse, 0, len(drift)),
+		UnbalancedJournals: make([]dto.JournalImbalanceResult, 0, len(journals)),
+		PlatformAccounts:   make([]dto.AccountBalanceResponse, 0, len(accounts)),
+	}
+
+	totalDrift := money.Zero(money.DefaultCurrency)
+	for _, d := range drift {
+		difference := d.Balance.Sub(d.LedgerBalance)
+		totalDrift = totalDrift.Add(difference)
+
+		report.Drift = append(report.Drift, dto.WalletDriftResponse{
+			WalletID:      d.WalletID,
+			UserID:        d.UserID,
+			WalletType:    d.WalletType,
+			Balance:       d.Balance,
+			LedgerBalance: d.LedgerBalance,
+			Difference:    difference,
+			HeldBalance:   d.HeldBalance,
+			ActiveHolds:   d.ActiveHolds,
+		})
+	}
+	report.TotalDrift = totalDrift
+
+	for _, j := range journals {
+		report.UnbalancedJournals = append(report.UnbalancedJournals, dto.JournalImbalanceResult{
+			JournalID: j.JournalID,
+			Total:     j.Total,
+		})
+	}
+
+	for _, a := range accounts {
+		report.PlatformAccounts = append(report.PlatformAccounts, dto.AccountBalanceResponse{
+			Code:    a.Code,
+			Balance: a.Balance,
+		})
+	}
+
+	report.Balanced = len(drift) == 0 && len(journals) == 0
+
+	if !report.Balanced {
+		logger.Warn("wallet ledger drift detected",
+			"walletsWithDrift", len(drift),
+			"totalDrift", report.TotalDrift,
+			"unbalancedJournals", len(journals),
+		)
+	}
+
+	return report, nil
+}
+
 // Helper functions
 func (s *service) invalidateWalletCache(ctx context.Context, userID string) {
 	cache.Delete(ctx, fmt.Sprintf("wallet:user:%s", userID))
@@ -726,3 +1142,17 @@ func (s *service) invalidateWalletCache(ctx context.Context, userID string) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+// requestAmount reads a request amount in the wallet's currency, refusing one
+// sent in another currency
+func requestAmount(wallet *models.Wallet, value money.Amount) (money.Amount, error) {
+	return inCurrency(money.Currency(wallet.Currency), value)
+}
+
+func inCurrency(currency money.Currency, value money.Amount) (money.Amount, error) {
+	amount, err := value.In(currency)
+	if err != nil {
+		return money.Amount{}, response.BadRequest(fmt.Sprintf("Amount must be in the wallet's currency (%s)", currency))
+	}
+	return amount, nil
+}
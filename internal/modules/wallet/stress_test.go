@@ -0,0 +1,340 @@
+package wallet_test
+
+// TestWalletStress hammers two throwaway wallets from many goroutines against
+// a real Postgres and Redis and checks that no update was lost:
+//
+//   - neither wallet ever goes negative or holds more than it has
+//   - each wallet's balance equals the sum of its ledger postings
+//   - held balance equals the sum of its active holds
+//   - the combined balance equals what the successful operations add up to
+//
+// It only runs when WALLET_STRESS_DSN names a migrated database; Redis is
+// WALLET_STRESS_REDIS (host:port, default localhost:6379):
+//
+//	WALLET_STRESS_DSN="host=localhost user=postgres dbname=app_dev sslmode=disable" \
+//	    go test ./internal/modules/wallet -run TestWalletStress -workers 50 -ops 200
+//
+// The two riders it creates are deleted afterwards together with their
+// wallets, holds, transactions and ledger journals. Never point it at
+// production.
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/modules/wallet"
+	"github.com/umar5678/go-backend/internal/modules/wallet/dto"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/money"
+	"github.com/umar5678/go-backend/internal/utils/response"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var (
+	stressWorkers = flag.Int("workers", 50, "wallet stress: concurrent goroutines")
+	stressOps     = flag.Int("ops", 200, "wallet stress: operations per goroutine")
+	stressSeed    = flag.Float64("seed", 500, "wallet stress: opening top-up per wallet")
+)
+
+// counters of successful money movements, in cents
+type tally struct {
+	credited  int64 // entered the pair of wallets (add funds)
+	debited   int64 // left the pair (withdraw, capture, debit)
+	succeeded int64
+	rejected  int64 // refused on purpose, e.g. insufficient balance
+	failed    int64 // anything else: these are bugs
+}
+
+func TestWalletStress(t *testing.T) {
+	dsn := os.Getenv("WALLET_STRESS_DSN")
+	if dsn == "" {
+		t.Skip("WALLET_STRESS_DSN not set")
+	}
+
+	// Keep the service's own logging quiet; failures are reported below
+	if err := logger.Initialize(&config.LoggerConfig{Level: "error", Format: "json"}); err != nil {
+		t.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { sqlDB.Close() })
+	}
+
+	if err := cache.ConnectRedis(redisConfig(t)); err != nil {
+		t.Fatalf("failed to connect to redis: %v", err)
+	}
+	t.Cleanup(func() { cache.CloseRedis() })
+
+	ctx := context.Background()
+	repo := wallet.NewRepository(db)
+	payments, err := wallet.NewPaymentProvider(&config.PaymentsConfig{
+		Provider:         "fake",
+		WebhookSecret:    uuid.New().String(),
+		WebhookTolerance: 5 * time.Minute,
+	}, "development")
+	if err != nil {
+		t.Fatalf("failed to create payment provider: %v", err)
+	}
+	svc := wallet.NewService(repo, db, payments)
+
+	userA, walletA := createRider(t, ctx, db, "a")
+	userB, walletB := createRider(t, ctx, db, "b")
+
+	var tl tally
+	seed := money.FromFloat(*stressSeed, money.DefaultCurrency, money.HalfUp)
+	for _, userID := range []string{userA, userB} {
+		if err := topUp(ctx, svc, userID, seed, "walletstress seed"); err != nil {
+			t.Fatalf("failed to seed wallet: %v", err)
+		}
+		tl.credited += seed.Minor()
+	}
+
+	t.Logf("hammering wallets %s and %s with %d workers x %d ops", walletA, walletB, *stressWorkers, *stressOps)
+	started := time.Now()
+
+	var wg sync.WaitGroup
+	for w := 0; w < *stressWorkers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(w)))
+			for i := 0; i < *stressOps; i++ {
+				self, other := userA, userB
+				if rng.Intn(2) == 1 {
+					self, other = userB, userA
+				}
+				runOp(t, ctx, svc, rng, self, other, &tl)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	t.Logf("done in %s: %d succeeded, %d rejected, %d failed",
+		time.Since(started).Round(time.Millisecond), tl.succeeded, tl.rejected, tl.failed)
+
+	if tl.failed > 0 {
+		t.Errorf("%d operations failed with unexpected errors", tl.failed)
+	}
+	checkInvariants(t, ctx, db, repo, []string{walletA, walletB}, tl.credited-tl.debited)
+}
+
+// runOp performs one random wallet operation as self
+func runOp(t *testing.T, ctx context.Context, svc wallet.Service, rng *rand.Rand, self, other string, tl *tally) {
+	amount := money.New(int64(rng.Intn(5000)+1), money.DefaultCurrency) // 0.01 .. 50.00
+
+	var err error
+	var credited, debited money.Amount
+
+	switch rng.Intn(6) {
+	case 0:
+		err = topUp(ctx, svc, self, amount, "walletstress")
+		credited = amount
+	case 1:
+		// The payout stays pending, but the wallet is debited up front
+		_, err = svc.WithdrawFunds(ctx, self, dto.WithdrawFundsRequest{
+			Amount:      amount,
+			Description: "walletstress",
+			BankAccount: dto.BankAccountRequest{HolderName: "walletstress", AccountNumber: "000123456789"},
+		})
+		debited = amount
+	case 2:
+		// Moves money inside the pair; the combined balance is unchanged
+		_, err = svc.TransferFunds(ctx, self, dto.TransferFundsRequest{RecipientID: other, Amount: amount, Description: "walletstress"})
+	case 3:
+		_, err = svc.DebitWallet(ctx, self, amount, "walletstress", uuid.New().String(), "walletstress", nil)
+		debited = amount
+	default:
+		// Hold then capture (part of) it or release it; concurrent with the
+		// operations above so held balance is exercised too
+		var hold *dto.HoldResponse
+		hold, err = svc.HoldFunds(ctx, self, dto.HoldFundsRequest{
+			Amount:        amount,
+			ReferenceType: "walletstress",
+			ReferenceID:   uuid.New().String(),
+		})
+		if err != nil {
+			break
+		}
+		if rng.Intn(2) == 0 {
+			err = svc.ReleaseHold(ctx, self, dto.ReleaseHoldRequest{HoldID: hold.ID})
+			break
+		}
+		capture := money.New(int64(rng.Intn(int(amount.Minor()))+1), money.DefaultCurrency)
+		_, err = svc.CaptureHold(ctx, self, dto.CaptureHoldRequest{HoldID: hold.ID, Amount: &capture})
+		debited = capture
+	}
+
+	switch {
+	case err == nil:
+		atomic.AddInt64(&tl.succeeded, 1)
+		atomic.AddInt64(&tl.credited, credited.Minor())
+		atomic.AddInt64(&tl.debited, debited.Minor())
+	case isRejection(err):
+		atomic.AddInt64(&tl.rejected, 1)
+	default:
+		atomic.AddInt64(&tl.failed, 1)
+		t.Logf("unexpected error: %v", err)
+	}
+}
+
+// topUp adds funds and confirms the payment through the fake gateway's
+// webhook, which is what actually credits the wallet
+func topUp(ctx context.Context, svc wallet.Service, userID string, amount money.Amount, description string) error {
+	resp, err := svc.AddFunds(ctx, userID, dto.AddFundsRequest{Amount: amount, Description: description})
+	if err != nil {
+		return err
+	}
+	return svc.SimulatePayment(ctx, resp.Payment.ID, dto.SimulatePaymentRequest{Outcome: "succeeded"})
+}
+
+func isRejection(err error) bool {
+	appErr, ok := err.(*response.AppError)
+	return ok && appErr.StatusCode < 500
+}
+
+// checkInvariants reads the wallets back and reports every broken invariant
+func checkInvariants(t *testing.T, ctx context.Context, db *gorm.DB, repo wallet.Repository, walletIDs []string, expectedCents int64) {
+	var wallets []models.Wallet
+	if err := db.WithContext(ctx).Where("id IN ?", walletIDs).Find(&wallets).Error; err != nil {
+		t.Fatalf("failed to load wallets: %v", err)
+	}
+
+	var totalCents int64
+	for _, w := range wallets {
+		totalCents += w.Balance.Minor()
+
+		if w.Balance.IsNegative() || w.HeldBalance.IsNegative() || w.HeldBalance.GreaterThan(w.Balance) {
+			t.Errorf("wallet %s out of range: balance %s held %s", w.ID, w.Balance, w.HeldBalance)
+		}
+		t.Logf("wallet %s: balance %s held %s", w.ID, w.Balance, w.HeldBalance)
+	}
+
+	if totalCents != expectedCents {
+		t.Errorf("combined balance %.2f, successful operations add up to %.2f",
+			float64(totalCents)/100, float64(expectedCents)/100)
+	}
+
+	drift, err := repo.FindWalletLedgerDrift(ctx)
+	if err != nil {
+		t.Fatalf("failed to compare with ledger: %v", err)
+	}
+	for _, d := range drift {
+		for _, id := range walletIDs {
+			if d.WalletID == id {
+				t.Errorf("wallet %s drifted: balance %s ledger %s, held %s active holds %s",
+					d.WalletID, d.Balance, d.LedgerBalance, d.HeldBalance, d.ActiveHolds)
+			}
+		}
+	}
+}
+
+// createRider creates a throwaway rider with an empty wallet and registers
+// its removal
+func createRider(t *testing.T, ctx context.Context, db *gorm.DB, suffix string) (string, string) {
+	email := fmt.Sprintf("walletstress-%s-%s@example.com", suffix, uuid.New().String()[:8])
+	user := &models.User{
+		Name:  "walletstress " + suffix,
+		Email: &email,
+		Role:  models.RoleRider,
+	}
+	if err := db.WithContext(ctx).Create(user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	t.Cleanup(func() { deleteRider(t, db, user.ID) })
+
+	w := &models.Wallet{
+		UserID:     user.ID,
+		WalletType: models.WalletTypeRider,
+		Currency:   "USD",
+		IsActive:   true,
+	}
+	if err := db.WithContext(ctx).Create(w).Error; err != nil {
+		t.Fatalf("failed to create test wallet: %v", err)
+	}
+
+	return user.ID, w.ID
+}
+
+// deleteRider removes a test rider and everything its wallet left behind.
+// Whole journals go, platform side included, so the ledger still balances.
+func deleteRider(t *testing.T, db *gorm.DB, userID string) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		accounts := tx.Model(&models.LedgerAccount{}).
+			Select("ledger_accounts.id").
+			Joins("JOIN wallets w ON w.id = ledger_accounts.wallet_id").
+			Where("w.user_id = ?", userID)
+
+		var journalIDs []string
+		err := tx.Model(&models.LedgerPosting{}).
+			Distinct("journal_id").
+			Where("account_id IN (?)", accounts).
+			Pluck("journal_id", &journalIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(journalIDs) > 0 {
+			if err := tx.Where("journal_id IN ?", journalIDs).Delete(&models.LedgerPosting{}).Error; err != nil {
+				return err
+			}
+			// Wallet transactions pointing at them are set to NULL
+			if err := tx.Where("id IN ?", journalIDs).Delete(&models.LedgerJournal{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id IN (?)", accounts).Delete(&models.LedgerAccount{}).Error; err != nil {
+			return err
+		}
+
+		// Wallets, their transactions and holds cascade
+		return tx.Unscoped().Where("id = ?", userID).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		t.Errorf("failed to clean up test rider %s: %v", userID, err)
+	}
+}
+
+func redisConfig(t *testing.T) *config.RedisConfig {
+	addr := os.Getenv("WALLET_STRESS_REDIS")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("WALLET_STRESS_REDIS must be host:port: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("WALLET_STRESS_REDIS must be host:port: %v", err)
+	}
+
+	return &config.RedisConfig{
+		Host:      host,
+		Port:      port,
+		PoolSize:  10,
+		MainDB:    0,
+		PubSubDB:  1,
+		CacheDB:   3,
+		SessionDB: 4,
+	}
+}
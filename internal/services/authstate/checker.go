@@ -0,0 +1,110 @@
+package authstate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/jwt"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAccountInactive is returned for suspended or banned accounts
+	ErrAccountInactive = errors.New("account is not active")
+	// ErrTokenRevoked is returned for tokens of a revoked session, an older
+	// token version or a deleted account
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// State is what auth checks need to know about a user on every request
+type State struct {
+	Status       models.UserStatus `json:"status"`
+	TokenVersion int               `json:"tokenVersion"`
+}
+
+// Checker decides whether a signature-valid token may still be used. HTTP
+// and websocket auth both go through it, so a suspension or a token version
+// bump applies to every entry point at once.
+type Checker interface {
+	Check(ctx context.Context, claims *jwt.Claims) error
+}
+
+type checker struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewChecker caches each user's state in Redis for ttl. Code that changes a
+// user's status or token version calls Invalidate, so the cache only bounds
+// how long a missed invalidation can go unnoticed.
+func NewChecker(db *gorm.DB, ttl time.Duration) Checker {
+	return &checker{db: db, ttl: ttl}
+}
+
+func stateKey(userID string) string {
+	return "auth:user_state:" + userID
+}
+
+// Invalidate drops a user's cached state; the next request reloads it
+func Invalidate(ctx context.Context, userID string) {
+	if err := cache.Delete(ctx, stateKey(userID)); err != nil {
+		logger.Error("failed to invalidate auth state", "error", err, "userId", userID)
+	}
+}
+
+// Check rejects tokens of revoked sessions, inactive accounts and older
+// token versions. Lookup failures are logged and let the request through
+// rather than locking everyone out.
+func (c *checker) Check(ctx context.Context, claims *jwt.Claims) error {
+	if claims.SessionID != "" {
+		revoked, err := cache.Exists(ctx, models.RevokedSessionKey(claims.SessionID))
+		if err != nil {
+			logger.Error("failed to check session revocation", "error", err, "sessionId", claims.SessionID)
+		} else if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	state, err := c.load(ctx, claims.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTokenRevoked
+	}
+	if err != nil {
+		logger.Error("failed to load auth state", "error", err, "userId", claims.UserID)
+		return nil
+	}
+
+	if !state.Status.CanAuthenticate() {
+		return ErrAccountInactive
+	}
+	if claims.Version != state.TokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+func (c *checker) load(ctx context.Context, userID string) (*State, error) {
+	var state State
+	if err := cache.GetJSON(ctx, stateKey(userID), &state); err == nil {
+		return &state, nil
+	}
+
+	var user models.User
+	err := c.db.WithContext(ctx).
+		Select("status", "token_version").
+		Where("id = ?", userID).
+		Take(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	state = State{Status: user.Status, TokenVersion: user.TokenVersion}
+	if err := cache.SetJSON(ctx, stateKey(userID), state, c.ttl); err != nil {
+		logger.Warn("failed to cache auth state", "error", err, "userId", userID)
+	}
+	return &state, nil
+}
@@ -173,6 +173,16 @@ func Delete(ctx context.Context, key string) error {
 	return CacheClient.Del(ctx, key).Err()
 }
 
+// TakeJSON reads a JSON value and deletes it in one step, so when several
+// callers race for the same key only one of them gets the value
+func TakeJSON(ctx context.Context, key string, dest interface{}) error {
+	data, err := CacheClient.GetDel(ctx, key).Bytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
 // Exists checks if key exists (standalone)
 func Exists(ctx context.Context, key string) (bool, error) {
 	result, err := CacheClient.Exists(ctx, key).Result()
@@ -189,11 +199,71 @@ func Set(ctx context.Context, key string, value interface{}, ttl time.Duration)
 	return CacheClient.Set(ctx, key, value, ttl).Err()
 }
 
+// SetNX stores value only if key does not exist (standalone). Returns true
+// when the key was set.
+func SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return CacheClient.SetNX(ctx, key, value, ttl).Result()
+}
+
+// extendIfOwnerScript renews a lock's TTL only while it still holds our value
+var extendIfOwnerScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// deleteIfOwnerScript deletes a lock only while it still holds our value
+var deleteIfOwnerScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// ExtendLock renews a lock taken with SetNX if value still owns it. Returns
+// false when the lock expired or was taken by someone else.
+func ExtendLock(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	n, err := extendIfOwnerScript.Run(ctx, CacheClient, []string{key}, value, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// ReleaseLock deletes a lock taken with SetNX if value still owns it
+func ReleaseLock(ctx context.Context, key, value string) error {
+	return deleteIfOwnerScript.Run(ctx, CacheClient, []string{key}, value).Err()
+}
+
 // Increment increments counter (standalone)
 func Increment(ctx context.Context, key string) (int64, error) {
 	return CacheClient.Incr(ctx, key).Result()
 }
 
+// IncrementWithExpiry increments a counter, starting its expiry when the
+// counter is new (standalone)
+func IncrementWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	val, err := CacheClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if val == 1 {
+		CacheClient.Expire(ctx, key, ttl)
+	}
+	return val, nil
+}
+
+// TTL returns how long a key has left; negative when it has no expiry or
+// does not exist (standalone)
+func TTL(ctx context.Context, key string) (time.Duration, error) {
+	return CacheClient.TTL(ctx, key).Result()
+}
+
+// CompareAndDelete deletes a key only while it still holds value. Returns
+// true for the one caller that deleted it.
+func CompareAndDelete(ctx context.Context, key, value string) (bool, error) {
+	n, err := deleteIfOwnerScript.Run(ctx, CacheClient, []string{key}, value).Int()
+	return n == 1, err
+}
+
 // package cache
 
 // import (
@@ -10,6 +10,8 @@ import (
 	"github.com/umar5678/go-backend/internal/modules/drivers"
 	"github.com/umar5678/go-backend/internal/modules/rides"
 	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/services/routing"
+	"github.com/umar5678/go-backend/internal/utils/location"
 	"github.com/umar5678/go-backend/internal/utils/logger"
 	websocketutil "github.com/umar5678/go-backend/internal/websocket/websocketutils"
 )
@@ -25,12 +27,14 @@ type LocationService interface {
 type locationService struct {
 	driverRepo drivers.Repository
 	rideRepo   rides.Repository
+	router     routing.RoutingProvider
 }
 
-func NewLocationService(driverRepo drivers.Repository, rideRepo rides.Repository) LocationService {
+func NewLocationService(driverRepo drivers.Repository, rideRepo rides.Repository, router routing.RoutingProvider) LocationService {
 	return &locationService{
 		driverRepo: driverRepo,
 		rideRepo:   rideRepo,
+		router:     router,
 	}
 }
 
@@ -155,33 +159,51 @@ func (s *locationService) GetDriverLocation(ctx context.Context, driverID string
 }
 
 func (s *locationService) CalculateRoute(ctx context.Context, startLat, startLng, endLat, endLng float64) (*models.Route, error) {
-	// TODO: Integrate with routing service (Google Maps, OSRM, Mapbox)
-	// For now, return mock data
+	route, err := s.router.Route(ctx,
+		location.Point{Latitude: startLat, Longitude: startLng},
+		location.Point{Latitude: endLat, Longitude: endLng},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	points := route.Points
+	if len(points) == 0 {
+		points = []location.Point{
+			{Latitude: startLat, Longitude: startLng},
+			{Latitude: endLat, Longitude: endLng},
+		}
+	}
+
+	bbox := []float64{points[0].Longitude, points[0].Latitude, points[0].Longitude, points[0].Latitude}
+	for _, p := range points[1:] {
+		bbox[0] = math.Min(bbox[0], p.Longitude)
+		bbox[1] = math.Min(bbox[1], p.Latitude)
+		bbox[2] = math.Max(bbox[2], p.Longitude)
+		bbox[3] = math.Max(bbox[3], p.Latitude)
+	}
+
+	summary := "Fastest route"
+	if route.Source == routing.SourceEstimate {
+		summary = "Estimated route"
+	}
+
 	return &models.Route{
-		Polyline: "mock_polyline_encoded_string",
-		Distance: 5000, // meters
-		Duration: 900,  // seconds
-		Summary:  "Fastest route",
+		Polyline:    location.EncodePolyline(points),
+		Distance:    route.DistanceKm * 1000, // meters
+		Duration:    route.DurationSec,
+		Summary:     summary,
+		BoundingBox: bbox,
 	}, nil
 }
 
 func (s *locationService) CalculateETA(ctx context.Context, driverLat, driverLng, destLat, destLng float64) (int, error) {
-	// TODO: Implement actual ETA calculation
-	// For now, return mock ETA based on distance and average speed
-	distance := calculateHaversineDistance(driverLat, driverLng, destLat, destLng)
-	eta := int((distance / 13.8889) * 3600) // 50 km/h average speed in m/s
-	return eta, nil
-}
-
-// Helper function to calculate distance between two points
-func calculateHaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	// Haversine formula implementation
-	const R = 6371000 // Earth radius in meters
-	dLat := (lat2 - lat1) * (3.14159265358979323846 / 180)
-	dLon := (lon2 - lon1) * (3.14159265358979323846 / 180)
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*(3.14159265358979323846/180))*math.Cos(lat2*(3.14159265358979323846/180))*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return R * c
+	route, err := s.router.Route(ctx,
+		location.Point{Latitude: driverLat, Longitude: driverLng},
+		location.Point{Latitude: destLat, Longitude: destLng},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return route.DurationSec, nil
 }
@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+const (
+	ProviderFile   = "file"
+	ProviderMemory = "memory"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Nothing in this package talks to an SMTP server
+// yet; both providers keep messages local so email flows can be exercised
+// offline.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer returns the provider named in cfg, falling back to an in-memory
+// outbox for unknown names
+func NewMailer(cfg config.MailConfig) Mailer {
+	switch cfg.Provider {
+	case ProviderFile:
+		return NewFileMailer(cfg.OutboxFile, cfg.From)
+	case ProviderMemory:
+		return NewMemoryMailer()
+	default:
+		logger.Warn("unknown mail provider, using in-memory outbox", "provider", cfg.Provider)
+		return NewMemoryMailer()
+	}
+}
+
+// SentMessage is an email as it was sent
+type SentMessage struct {
+	Message
+	SentAt time.Time
+}
+
+// FileMailer appends every email to a file, separated by a blank line
+type FileMailer struct {
+	path string
+	from string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path, from string) *FileMailer {
+	return &FileMailer{path: path, from: from}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return fmt.Errorf("create mail outbox directory: %w", err)
+	}
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open mail outbox: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "Date: %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().UTC().Format(time.RFC1123Z), m.from, msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("write mail outbox: %w", err)
+	}
+	return nil
+}
+
+// MemoryMailer keeps emails in memory; Messages and Last read them back
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []SentMessage
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, SentMessage{Message: msg, SentAt: time.Now()})
+	return nil
+}
+
+// Messages returns every email sent so far, oldest first
+func (m *MemoryMailer) Messages() []SentMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]SentMessage(nil), m.messages...)
+}
+
+// Last returns the latest email sent to an address
+func (m *MemoryMailer) Last(to string) (SentMessage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return SentMessage{}, false
+}
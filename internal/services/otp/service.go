@@ -0,0 +1,220 @@
+package otp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/services/sms"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"github.com/umar5678/go-backend/internal/utils/response"
+)
+
+// Purposes keep codes for different flows apart, so a login code cannot
+// complete a signup
+const (
+	PurposeSignup = "signup"
+	PurposeLogin  = "login"
+)
+
+// Challenge describes a code that was just sent
+type Challenge struct {
+	ExpiresAt   time.Time
+	ResendAfter time.Time
+}
+
+// Service sends one-time codes by SMS and checks them. Codes are stored as
+// an HMAC in Redis, never in the clear.
+type Service interface {
+	Send(ctx context.Context, purpose, phone string) (*Challenge, error)
+	Verify(ctx context.Context, purpose, phone, code string) error
+}
+
+type service struct {
+	cfg    config.OTPConfig
+	sender sms.SMSSender
+}
+
+func NewService(cfg config.OTPConfig, sender sms.SMSSender) Service {
+	return &service{cfg: cfg, sender: sender}
+}
+
+// Redis keys. Codes and cooldowns are per flow; send counts, failed
+// attempts and lockouts are per phone, so switching flows does not reset them.
+func codeKey(purpose, phone string) string {
+	return fmt.Sprintf("otp:code:%s:%s", purpose, phone)
+}
+
+func cooldownKey(purpose, phone string) string {
+	return fmt.Sprintf("otp:cooldown:%s:%s", purpose, phone)
+}
+
+func sendsKey(phone string) string {
+	return "otp:sends:" + phone
+}
+
+func attemptsKey(phone string) string {
+	return "otp:attempts:" + phone
+}
+
+func lockKey(phone string) string {
+	return "otp:lock:" + phone
+}
+
+func (s *service) Send(ctx context.Context, purpose, phone string) (*Challenge, error) {
+	if err := s.checkLocked(ctx, phone); err != nil {
+		return nil, err
+	}
+
+	// The cooldown key doubles as the resend guard: only one request per
+	// cooldown gets to send
+	ok, err := cache.SetNX(ctx, cooldownKey(purpose, phone), "1", s.cfg.ResendCooldown)
+	if err != nil {
+		return nil, response.InternalServerError("Failed to send verification code", err)
+	}
+	if !ok {
+		wait, _ := cache.TTL(ctx, cooldownKey(purpose, phone))
+		return nil, response.TooManyRequests(fmt.Sprintf("Please wait %s before requesting another code", roundUp(wait)))
+	}
+
+	sends, err := cache.IncrementWithExpiry(ctx, sendsKey(phone), s.cfg.SendWindow)
+	if err != nil {
+		cache.Delete(ctx, cooldownKey(purpose, phone))
+		return nil, response.InternalServerError("Failed to send verification code", err)
+	}
+	if sends > int64(s.cfg.MaxSends) {
+		wait, _ := cache.TTL(ctx, sendsKey(phone))
+		return nil, response.TooManyRequests(fmt.Sprintf("Too many codes requested, try again in %s", roundUp(wait)))
+	}
+
+	code, err := generateCode(s.cfg.Length)
+	if err != nil {
+		cache.Delete(ctx, cooldownKey(purpose, phone))
+		return nil, response.InternalServerError("Failed to send verification code", err)
+	}
+
+	// A new code replaces any earlier one for the same flow
+	if err := cache.Set(ctx, codeKey(purpose, phone), s.hash(purpose, phone, code), s.cfg.TTL); err != nil {
+		cache.Delete(ctx, cooldownKey(purpose, phone))
+		return nil, response.InternalServerError("Failed to send verification code", err)
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %s.", code, roundUp(s.cfg.TTL))
+	if err := s.sender.Send(ctx, phone, message); err != nil {
+		cache.Delete(ctx, codeKey(purpose, phone))
+		cache.Delete(ctx, cooldownKey(purpose, phone))
+		return nil, response.InternalServerError("Failed to send verification code", err)
+	}
+
+	logger.Info("verification code sent", "purpose", purpose, "phone", phone)
+
+	now := time.Now()
+	return &Challenge{
+		ExpiresAt:   now.Add(s.cfg.TTL),
+		ResendAfter: now.Add(s.cfg.ResendCooldown),
+	}, nil
+}
+
+func (s *service) Verify(ctx context.Context, purpose, phone, code string) error {
+	if err := s.checkLocked(ctx, phone); err != nil {
+		return err
+	}
+
+	stored, err := cache.Get(ctx, codeKey(purpose, phone))
+	if errors.Is(err, redis.Nil) {
+		return response.BadRequest("Verification code expired or was not requested")
+	}
+	if err != nil {
+		return response.InternalServerError("Failed to verify code", err)
+	}
+
+	if !hmac.Equal([]byte(stored), []byte(s.hash(purpose, phone, code))) {
+		return s.recordFailure(ctx, purpose, phone)
+	}
+
+	// Consume the code; a concurrent verify of the same code loses here
+	consumed, err := cache.CompareAndDelete(ctx, codeKey(purpose, phone), stored)
+	if err != nil {
+		return response.InternalServerError("Failed to verify code", err)
+	}
+	if !consumed {
+		return response.BadRequest("Verification code expired or was not requested")
+	}
+
+	cache.Delete(ctx, attemptsKey(phone))
+	return nil
+}
+
+// recordFailure counts a wrong code and locks the phone out once it has
+// used up its attempts
+func (s *service) recordFailure(ctx context.Context, purpose, phone string) error {
+	attempts, err := cache.IncrementWithExpiry(ctx, attemptsKey(phone), s.cfg.LockoutDuration)
+	if err != nil {
+		return response.InternalServerError("Failed to verify code", err)
+	}
+
+	if attempts >= int64(s.cfg.MaxAttempts) {
+		if err := cache.Set(ctx, lockKey(phone), "1", s.cfg.LockoutDuration); err != nil {
+			return response.InternalServerError("Failed to verify code", err)
+		}
+		cache.Delete(ctx, codeKey(PurposeSignup, phone))
+		cache.Delete(ctx, codeKey(PurposeLogin, phone))
+		cache.Delete(ctx, attemptsKey(phone))
+
+		logger.Warn("phone locked out after failed verification attempts", "phone", phone, "purpose", purpose)
+		return response.TooManyRequests(fmt.Sprintf("Too many failed attempts, try again in %s", roundUp(s.cfg.LockoutDuration)))
+	}
+
+	return response.BadRequest(fmt.Sprintf("Invalid verification code, %d attempts left", int64(s.cfg.MaxAttempts)-attempts))
+}
+
+func (s *service) checkLocked(ctx context.Context, phone string) error {
+	wait, err := cache.TTL(ctx, lockKey(phone))
+	if err != nil {
+		return response.InternalServerError("Failed to check verification status", err)
+	}
+	if wait > 0 {
+		return response.TooManyRequests(fmt.Sprintf("Too many failed attempts, try again in %s", roundUp(wait)))
+	}
+	return nil
+}
+
+func (s *service) hash(purpose, phone, code string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(purpose + ":" + phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateCode returns a uniformly random string of digits
+func generateCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
+
+// roundUp formats a wait for users, in whole seconds or minutes
+func roundUp(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d seconds", int((d+time.Second-1)/time.Second))
+	}
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
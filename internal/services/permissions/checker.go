@@ -0,0 +1,84 @@
+package permissions
+
+import (
+	"context"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/cache"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+	"gorm.io/gorm"
+)
+
+// Checker resolves what an admin user may do. RequirePermission goes through
+// it on every admin request.
+type Checker interface {
+	// Permissions returns the union of the permissions of the user's roles
+	Permissions(ctx context.Context, userID string) ([]string, error)
+	Has(ctx context.Context, userID string, permission models.Permission) (bool, error)
+}
+
+type checker struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewChecker caches each user's permissions in Redis for ttl. Code that
+// changes roles or assignments calls Invalidate for the affected users.
+func NewChecker(db *gorm.DB, ttl time.Duration) Checker {
+	return &checker{db: db, ttl: ttl}
+}
+
+func permissionsKey(userID string) string {
+	return "auth:permissions:" + userID
+}
+
+// Invalidate drops the cached permissions of users; their next request
+// reloads them
+func Invalidate(ctx context.Context, userIDs ...string) {
+	for _, userID := range userIDs {
+		if err := cache.Delete(ctx, permissionsKey(userID)); err != nil {
+			logger.Error("failed to invalidate permissions", "error", err, "userId", userID)
+		}
+	}
+}
+
+func (c *checker) Has(ctx context.Context, userID string, permission models.Permission) (bool, error) {
+	granted, err := c.Permissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return models.HasPermission(granted, permission), nil
+}
+
+func (c *checker) Permissions(ctx context.Context, userID string) ([]string, error) {
+	var granted []string
+	if err := cache.GetJSON(ctx, permissionsKey(userID), &granted); err == nil {
+		return granted, nil
+	}
+
+	var roles []models.AdminRole
+	err := c.db.WithContext(ctx).
+		Joins("JOIN admin_role_assignments ara ON ara.role_id = admin_roles.id").
+		Where("ara.user_id = ?", userID).
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	granted = []string{}
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			if !seen[p] {
+				seen[p] = true
+				granted = append(granted, p)
+			}
+		}
+	}
+
+	if err := cache.SetJSON(ctx, permissionsKey(userID), granted, c.ttl); err != nil {
+		logger.Warn("failed to cache permissions", "error", err, "userId", userID)
+	}
+	return granted, nil
+}
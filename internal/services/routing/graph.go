@@ -0,0 +1,251 @@
+package routing
+
+import (
+	"container/heap"
+	"context"
+	"math"
+
+	"github.com/umar5678/go-backend/internal/utils/location"
+)
+
+// Grid cell size (degrees) of the spatial index used to snap points to nodes
+const gridCellDeg = 0.01
+
+// How often the search checks for cancellation (settled nodes)
+const cancelCheckInterval = 4096
+
+type edge struct {
+	to      int32
+	distKm  float64
+	timeSec float64
+}
+
+type gridCell struct {
+	lat, lon int
+}
+
+// graph is a directed road graph with travel time as edge weight
+type graph struct {
+	ids   map[int64]int32
+	lat   []float64
+	lon   []float64
+	edges [][]edge
+
+	grid        map[gridCell][]int32
+	maxSpeedKmh float64
+}
+
+func newGraph() *graph {
+	return &graph{ids: make(map[int64]int32)}
+}
+
+// node returns the index of an OSM node, adding it on first use
+func (g *graph) node(osmID int64, lat, lon float64) int32 {
+	if idx, ok := g.ids[osmID]; ok {
+		return idx
+	}
+	idx := int32(len(g.lat))
+	g.ids[osmID] = idx
+	g.lat = append(g.lat, lat)
+	g.lon = append(g.lon, lon)
+	g.edges = append(g.edges, nil)
+	return idx
+}
+
+func (g *graph) addEdge(a, b int32, speedKmh float64, forward, backward bool) {
+	dist := location.HaversineDistance(g.lat[a], g.lon[a], g.lat[b], g.lon[b])
+	seconds := dist / speedKmh * 3600
+
+	if forward {
+		g.edges[a] = append(g.edges[a], edge{to: b, distKm: dist, timeSec: seconds})
+	}
+	if backward {
+		g.edges[b] = append(g.edges[b], edge{to: a, distKm: dist, timeSec: seconds})
+	}
+	if speedKmh > g.maxSpeedKmh {
+		g.maxSpeedKmh = speedKmh
+	}
+}
+
+func (g *graph) edgeCount() int {
+	count := 0
+	for _, e := range g.edges {
+		count += len(e)
+	}
+	return count
+}
+
+// buildIndex drops the OSM ID map and builds the snapping grid
+func (g *graph) buildIndex() {
+	g.ids = nil
+	g.grid = make(map[gridCell][]int32)
+	for i := range g.lat {
+		cell := cellOf(g.lat[i], g.lon[i])
+		g.grid[cell] = append(g.grid[cell], int32(i))
+	}
+}
+
+func cellOf(lat, lon float64) gridCell {
+	return gridCell{
+		lat: int(math.Floor(lat / gridCellDeg)),
+		lon: int(math.Floor(lon / gridCellDeg)),
+	}
+}
+
+// nearest returns the closest node within maxKm of the point, or -1
+func (g *graph) nearest(p location.Point, maxKm float64) (int32, float64) {
+	// A grid cell is at most ~1.1km tall; widen the longitude search towards
+	// the poles where cells get narrower
+	latRings := int(math.Ceil(maxKm/(gridCellDeg*111.32))) + 1
+	lonRings := latRings
+	if cos := math.Cos(p.Latitude * math.Pi / 180); cos > 0.01 {
+		lonRings = int(math.Ceil(maxKm/(gridCellDeg*111.32*cos))) + 1
+	}
+
+	center := cellOf(p.Latitude, p.Longitude)
+	best, bestDist := int32(-1), maxKm
+
+	for dLat := -latRings; dLat <= latRings; dLat++ {
+		for dLon := -lonRings; dLon <= lonRings; dLon++ {
+			for _, idx := range g.grid[gridCell{lat: center.lat + dLat, lon: center.lon + dLon}] {
+				dist := location.HaversineDistance(p.Latitude, p.Longitude, g.lat[idx], g.lon[idx])
+				if dist <= bestDist {
+					best, bestDist = idx, dist
+				}
+			}
+		}
+	}
+
+	return best, bestDist
+}
+
+// heuristic is a lower bound on the travel time from a node to the target:
+// the straight line driven at the fastest speed in the graph
+func (g *graph) heuristic(from, to int32) float64 {
+	return location.HaversineDistance(g.lat[from], g.lon[from], g.lat[to], g.lon[to]) / g.maxSpeedKmh * 3600
+}
+
+// shortestPath runs A* on travel time and returns the node path
+func (g *graph) shortestPath(ctx context.Context, source, target int32) ([]int32, error) {
+	if source == target {
+		return []int32{source}, nil
+	}
+
+	cost := map[int32]float64{source: 0}
+	prev := make(map[int32]int32)
+	settled := make(map[int32]bool)
+
+	open := &searchQueue{{node: source, priority: g.heuristic(source, target)}}
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(searchItem)
+		if settled[current.node] {
+			continue
+		}
+		settled[current.node] = true
+
+		if current.node == target {
+			return reconstructPath(prev, source, target), nil
+		}
+
+		if len(settled)%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		for _, e := range g.edges[current.node] {
+			if settled[e.to] {
+				continue
+			}
+			next := cost[current.node] + e.timeSec
+			if known, ok := cost[e.to]; ok && known <= next {
+				continue
+			}
+			cost[e.to] = next
+			prev[e.to] = current.node
+			heap.Push(open, searchItem{node: e.to, priority: next + g.heuristic(e.to, target)})
+		}
+	}
+
+	return nil, ErrNoRoute
+}
+
+func reconstructPath(prev map[int32]int32, source, target int32) []int32 {
+	path := []int32{target}
+	for node := target; node != source; {
+		node = prev[node]
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type searchItem struct {
+	node     int32
+	priority float64
+}
+
+type searchQueue []searchItem
+
+func (q searchQueue) Len() int            { return len(q) }
+func (q searchQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q searchQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *searchQueue) Push(x interface{}) { *q = append(*q, x.(searchItem)) }
+func (q *searchQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// graphProvider routes on an OSM road graph
+type graphProvider struct {
+	graph     *graph
+	maxSnapKm float64
+}
+
+func (p *graphProvider) Route(ctx context.Context, from, to location.Point) (*Route, error) {
+	g := p.graph
+
+	source, sourceSnapKm := g.nearest(from, p.maxSnapKm)
+	target, targetSnapKm := g.nearest(to, p.maxSnapKm)
+	if source < 0 || target < 0 {
+		return nil, ErrNoRoute
+	}
+
+	path, err := g.shortestPath(ctx, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// The legs between the points and the road are counted as slow driving
+	distance := sourceSnapKm + targetSnapKm
+	seconds := (sourceSnapKm + targetSnapKm) / highwaySpeeds["service"] * 3600
+
+	points := make([]location.Point, 0, len(path)+2)
+	points = append(points, from)
+	for i, node := range path {
+		points = append(points, location.Point{Latitude: g.lat[node], Longitude: g.lon[node]})
+		if i == 0 {
+			continue
+		}
+		// Parallel ways between two nodes: the search took the fastest
+		best := edge{timeSec: math.Inf(1)}
+		for _, e := range g.edges[path[i-1]] {
+			if e.to == node && e.timeSec < best.timeSec {
+				best = e
+			}
+		}
+		distance += best.distKm
+		seconds += best.timeSec
+	}
+	points = append(points, to)
+
+	return &Route{
+		DistanceKm:  math.Round(distance*100) / 100,
+		DurationSec: int(math.Round(seconds)),
+		Points:      points,
+		Source:      SourceOSM,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package routing
+
+import (
+	"context"
+	"math"
+
+	"github.com/umar5678/go-backend/internal/utils/location"
+)
+
+// haversineProvider estimates road distance as the straight-line distance
+// times a detour factor, driven at a constant average speed
+type haversineProvider struct {
+	detourFactor float64
+	speedKmh     float64
+}
+
+func NewHaversineProvider(detourFactor, speedKmh float64) RoutingProvider {
+	if detourFactor < 1 {
+		detourFactor = 1
+	}
+	if speedKmh <= 0 {
+		speedKmh = 40
+	}
+	return &haversineProvider{
+		detourFactor: detourFactor,
+		speedKmh:     speedKmh,
+	}
+}
+
+func (p *haversineProvider) Route(ctx context.Context, from, to location.Point) (*Route, error) {
+	distance := location.CalculateDistance(from, to) * p.detourFactor
+
+	return &Route{
+		DistanceKm:  math.Round(distance*100) / 100,
+		DurationSec: location.CalculateETA(distance, p.speedKmh),
+		Source:      SourceEstimate,
+	}, nil
+}
@@ -0,0 +1,197 @@
+package routing
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+// Free-flow speed (km/h) per OSM highway class. Ways with any other highway
+// value (footway, cycleway, track, ...) are not drivable and are skipped.
+var highwaySpeeds = map[string]float64{
+	"motorway":       100,
+	"motorway_link":  60,
+	"trunk":          80,
+	"trunk_link":     50,
+	"primary":        60,
+	"primary_link":   40,
+	"secondary":      50,
+	"secondary_link": 40,
+	"tertiary":       40,
+	"tertiary_link":  30,
+	"unclassified":   30,
+	"residential":    30,
+	"living_street":  10,
+	"service":        15,
+}
+
+type osmNode struct {
+	ID  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type osmWay struct {
+	ID    int64 `xml:"id,attr"`
+	Nodes []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []struct {
+		Key   string `xml:"k,attr"`
+		Value string `xml:"v,attr"`
+	} `xml:"tag"`
+}
+
+// LoadOSMGraph builds a road graph from an OSM XML extract (.osm or .osm.gz)
+// and returns a provider that routes on it with A*
+func LoadOSMGraph(path string, maxSnapKm float64) (RoutingProvider, error) {
+	started := time.Now()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OSM extract: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzipped OSM extract: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	g, err := parseOSM(r)
+	if err != nil {
+		return nil, err
+	}
+	if g.edgeCount() == 0 {
+		return nil, fmt.Errorf("OSM extract has no drivable roads")
+	}
+
+	g.buildIndex()
+
+	logger.Info("OSM road graph loaded",
+		"file", path,
+		"nodes", len(g.lat),
+		"edges", g.edgeCount(),
+		"took", time.Since(started),
+	)
+
+	return &graphProvider{graph: g, maxSnapKm: maxSnapKm}, nil
+}
+
+// parseOSM streams the extract. Node coordinates are kept only until the
+// ways are read; the graph holds just the nodes that drivable ways use.
+func parseOSM(r io.Reader) (*graph, error) {
+	decoder := xml.NewDecoder(r)
+	coords := make(map[int64][2]float64)
+	g := newGraph()
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OSM extract: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "node":
+			var n osmNode
+			if err := decoder.DecodeElement(&n, &start); err != nil {
+				return nil, fmt.Errorf("failed to parse OSM node: %w", err)
+			}
+			coords[n.ID] = [2]float64{n.Lat, n.Lon}
+
+		case "way":
+			var w osmWay
+			if err := decoder.DecodeElement(&w, &start); err != nil {
+				return nil, fmt.Errorf("failed to parse OSM way: %w", err)
+			}
+			addWay(g, coords, &w)
+		}
+	}
+
+	return g, nil
+}
+
+func addWay(g *graph, coords map[int64][2]float64, w *osmWay) {
+	tags := make(map[string]string, len(w.Tags))
+	for _, t := range w.Tags {
+		tags[t.Key] = t.Value
+	}
+
+	highway := tags["highway"]
+	speed, drivable := highwaySpeeds[highway]
+	if !drivable || tags["access"] == "no" || tags["access"] == "private" {
+		return
+	}
+	if maxSpeed, ok := parseMaxSpeed(tags["maxspeed"]); ok {
+		speed = maxSpeed
+	}
+
+	forward, backward := true, true
+	switch tags["oneway"] {
+	case "yes", "true", "1":
+		backward = false
+	case "-1", "reverse":
+		forward = false
+	case "no", "false", "0":
+	default:
+		if highway == "motorway" || tags["junction"] == "roundabout" {
+			backward = false
+		}
+	}
+
+	prev := int32(-1)
+	for _, nd := range w.Nodes {
+		c, ok := coords[nd.Ref]
+		if !ok {
+			// Extracts clipped to a bounding box reference nodes outside it
+			prev = -1
+			continue
+		}
+		idx := g.node(nd.Ref, c[0], c[1])
+		if prev >= 0 && prev != idx {
+			g.addEdge(prev, idx, speed, forward, backward)
+		}
+		prev = idx
+	}
+}
+
+// parseMaxSpeed reads values like "50", "30 mph" or "50;70". Symbolic
+// values ("walk", "RU:urban") are ignored.
+func parseMaxSpeed(value string) (float64, bool) {
+	value = strings.TrimSpace(strings.Split(value, ";")[0])
+	if value == "" {
+		return 0, false
+	}
+
+	mph := strings.HasSuffix(value, "mph")
+	value = strings.TrimSpace(strings.TrimSuffix(value, "mph"))
+
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	if mph {
+		speed *= 1.609344
+	}
+	return speed, true
+}
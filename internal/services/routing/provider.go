@@ -0,0 +1,88 @@
+package routing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/utils/location"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+const (
+	SourceOSM      = "osm"
+	SourceEstimate = "estimate"
+)
+
+// ErrNoRoute is returned when a provider cannot route between two points,
+// e.g. they are off its road graph or in disconnected parts of it
+var ErrNoRoute = errors.New("no route found")
+
+// Route is a road distance and travel time between two points
+type Route struct {
+	DistanceKm  float64
+	DurationSec int
+	Points      []location.Point // road geometry; empty for straight-line estimates
+	Source      string
+}
+
+// RoutingProvider computes distance and travel time between two points.
+// Pricing, ETAs and tracking all go through it.
+type RoutingProvider interface {
+	Route(ctx context.Context, from, to location.Point) (*Route, error)
+}
+
+// NewProvider routes on the OSM extract from cfg when one is configured and
+// loads, falling back to the straight-line estimate for anything the graph
+// cannot answer. Without an extract only the estimate is used.
+func NewProvider(cfg config.RoutingConfig) RoutingProvider {
+	estimate := NewHaversineProvider(cfg.DetourFactor, cfg.FallbackSpeedKmh)
+
+	if cfg.OSMFile == "" {
+		logger.Info("no OSM extract configured, using straight-line route estimates")
+		return estimate
+	}
+
+	graph, err := LoadOSMGraph(cfg.OSMFile, cfg.MaxSnapKm)
+	if err != nil {
+		logger.Error("failed to load OSM extract, using straight-line route estimates",
+			"error", err,
+			"file", cfg.OSMFile,
+		)
+		return estimate
+	}
+
+	return NewFallbackProvider(graph, estimate)
+}
+
+type fallbackProvider struct {
+	primary  RoutingProvider
+	fallback RoutingProvider
+}
+
+// NewFallbackProvider answers from primary and uses fallback when primary fails
+func NewFallbackProvider(primary, fallback RoutingProvider) RoutingProvider {
+	return &fallbackProvider{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (p *fallbackProvider) Route(ctx context.Context, from, to location.Point) (*Route, error) {
+	route, err := p.primary.Route(ctx, from, to)
+	if err == nil {
+		return route, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	logger.Debug("routing fell back to estimate",
+		"error", err,
+		"fromLat", from.Latitude,
+		"fromLon", from.Longitude,
+		"toLat", to.Latitude,
+		"toLon", to.Longitude,
+	)
+	return p.fallback.Route(ctx, from, to)
+}
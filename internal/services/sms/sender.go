@@ -0,0 +1,118 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umar5678/go-backend/internal/config"
+	"github.com/umar5678/go-backend/internal/utils/logger"
+)
+
+const (
+	ProviderFile   = "file"
+	ProviderMemory = "memory"
+)
+
+// SMSSender delivers a text message to a phone number. Nothing in this
+// package talks to a carrier yet; both providers keep messages local so
+// phone flows can be exercised offline.
+type SMSSender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// NewSender returns the provider named in cfg, falling back to an in-memory
+// outbox for unknown names
+func NewSender(cfg config.SMSConfig) SMSSender {
+	switch cfg.Provider {
+	case ProviderFile:
+		return NewFileSender(cfg.OutboxFile)
+	case ProviderMemory:
+		return NewMemorySender()
+	default:
+		logger.Warn("unknown SMS provider, using in-memory outbox", "provider", cfg.Provider)
+		return NewMemorySender()
+	}
+}
+
+// Message is a text message as it was sent
+type Message struct {
+	To     string
+	Body   string
+	SentAt time.Time
+}
+
+// FileSender appends every message to a file, one line each
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(ctx context.Context, to, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create sms outbox directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open sms outbox: %w", err)
+	}
+	defer f.Close()
+
+	// One message per line, whatever the body contains
+	body := strings.ReplaceAll(message, "\n", " ")
+	if _, err := fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().UTC().Format(time.RFC3339), to, body); err != nil {
+		return fmt.Errorf("write sms outbox: %w", err)
+	}
+	return nil
+}
+
+// MemorySender keeps messages in memory; Messages and Last read them back
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(ctx context.Context, to, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, Message{To: to, Body: message, SentAt: time.Now()})
+	return nil
+}
+
+// Messages returns every message sent so far, oldest first
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+// Last returns the latest message sent to a number
+func (s *MemorySender) Last(to string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].To == to {
+			return s.messages[i], true
+		}
+	}
+	return Message{}, false
+}
@@ -7,28 +7,79 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token types; an access token cannot be used to refresh and a refresh
+// token cannot be used to call the API
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 type Claims struct {
-	UserID string `json:"userId"`
-	Role   string `json:"role"`
+	UserID    string `json:"userId"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // device session the token belongs to
+	Type      string `json:"typ"`
+	Version   int    `json:"ver"` // user's token version at issue; bumping it revokes every token
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a new JWT token (used for both access and refresh)
-func GenerateToken(userID, role, secret string, expiry time.Duration) (string, error) {
+// GenerateAccessToken creates an access token bound to a device session
+func GenerateAccessToken(userID, role, sessionID string, version int, secret string, expiry time.Duration) (string, error) {
+	return sign(Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		Type:      TokenTypeAccess,
+		Version:   version,
+	}, secret, expiry)
+}
+
+// GenerateRefreshToken creates a refresh token bound to a device session.
+// tokenID is stored as the jti, so the token can be matched against the one
+// the session last issued.
+func GenerateRefreshToken(userID, role, sessionID, tokenID string, version int, secret string, expiry time.Duration) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		Type:      TokenTypeRefresh,
+		Version:   version,
 	}
+	claims.ID = tokenID
+	return sign(claims, secret, expiry)
+}
+
+func sign(claims Claims, secret string, expiry time.Duration) (string, error) {
+	now := time.Now()
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(expiry))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
+// ValidateAccessToken validates a token and checks it is an access token
+func ValidateAccessToken(tokenString, secret string) (*Claims, error) {
+	return validateType(tokenString, secret, TokenTypeAccess)
+}
+
+// ValidateRefreshToken validates a token and checks it is a refresh token
+func ValidateRefreshToken(tokenString, secret string) (*Claims, error) {
+	return validateType(tokenString, secret, TokenTypeRefresh)
+}
+
+func validateType(tokenString, secret, tokenType string) (*Claims, error) {
+	claims, err := ValidateToken(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != tokenType {
+		return nil, errors.New("wrong token type")
+	}
+	return claims, nil
+}
+
 // ValidateToken validates and parses a JWT token
 func ValidateToken(tokenString, secret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
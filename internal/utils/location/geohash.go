@@ -18,6 +18,7 @@ func Encode(latitude, longitude float64, precision int) string {
 	var geohash strings.Builder
 	var bits uint
 	var bit uint
+	var bitCount int
 	var even = true
 
 	latMin, latMax := -90.0, 90.0
@@ -45,9 +46,11 @@ func Encode(latitude, longitude float64, precision int) string {
 		}
 
 		bits = (bits << 1) | bit
-		if (even && bits >= 16) || (!even && bits >= 16) {
+		bitCount++
+		if bitCount == 5 {
 			geohash.WriteByte(base32[bits])
 			bits = 0
+			bitCount = 0
 		}
 		even = !even
 	}
@@ -55,6 +58,40 @@ func Encode(latitude, longitude float64, precision int) string {
 	return geohash.String()
 }
 
+// DecodeBounds returns the bounding box of a geohash cell
+func DecodeBounds(geohash string) (minLat, maxLat, minLon, maxLon float64) {
+	minLat, maxLat = -90.0, 90.0
+	minLon, maxLon = -180.0, 180.0
+	even := true
+
+	for _, c := range geohash {
+		idx := strings.IndexRune(base32, c)
+		if idx < 0 {
+			break
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			if even {
+				mid := (minLon + maxLon) / 2
+				if idx&mask != 0 {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if idx&mask != 0 {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+
+	return minLat, maxLat, minLon, maxLon
+}
+
 // GetGeohashPrecision returns appropriate geohash precision for distance
 // Returns precision level (1-12) based on search radius
 func GetGeohashPrecision(radiusKm float64) int {
@@ -0,0 +1,96 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Columns carry the amount in major units as a decimal ("12.30") and no
+// currency; amounts scanned keep the currency the target was tagged with,
+// so tag them from the row's own currency column (see In). JSON is written
+// as a bare number and may be read back as {"amount": "12.30", "currency":
+// "USD"}; a currency that disagrees with the target's is rejected instead of
+// being silently dropped.
+
+// MarshalJSON writes the amount as a JSON number in major units (12.30), the
+// same shape the API returned when amounts were float64
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a number (12.3), a numeric string ("12.30") or an
+// object with the currency spelled out ({"amount": "12.30", "currency":
+// "EUR"}). A target already tagged with a currency only accepts that one.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	currency := a.currency
+	s := strings.TrimSpace(string(data))
+
+	if strings.HasPrefix(s, "{") {
+		var obj struct {
+			Amount   json.RawMessage `json:"amount"`
+			Currency Currency        `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		obj.Currency = Currency(strings.ToUpper(string(obj.Currency)))
+		if obj.Currency != "" {
+			if currency != "" && currency != obj.Currency {
+				return fmt.Errorf("money: amount is in %s, expected %s", obj.Currency, currency)
+			}
+			currency = obj.Currency
+		}
+		s = strings.TrimSpace(string(obj.Amount))
+	}
+
+	s = strings.Trim(s, `"`)
+	if s == "null" || s == "" {
+		*a = Amount{currency: currency}
+		return nil
+	}
+	parsed, err := Parse(s, Amount{currency: currency}.Currency())
+	if err != nil {
+		return err
+	}
+	*a = Amount{minor: parsed.minor, currency: currency}
+	return nil
+}
+
+// Value stores the amount in a DECIMAL column
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads a DECIMAL column
+func (a *Amount) Scan(src interface{}) error {
+	var parsed Amount
+	var err error
+
+	switch v := src.(type) {
+	case nil:
+		parsed = Amount{}
+	case []byte:
+		parsed, err = Parse(string(v), a.Currency())
+	case string:
+		parsed, err = Parse(v, a.Currency())
+	case float64:
+		parsed = FromFloat(v, a.Currency(), HalfUp)
+	case int64:
+		parsed = New(v*a.Currency().scale(), a.Currency())
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", src)
+	}
+	if err != nil {
+		return err
+	}
+
+	*a = Amount{minor: parsed.minor, currency: a.currency}
+	return nil
+}
+
+// GormDataType lets AutoMigrate create a DECIMAL column when no type tag is given
+func (Amount) GormDataType() string {
+	return "decimal(12,2)"
+}
@@ -0,0 +1,159 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{New(1230, USD), `12.30`},
+		{New(-5, USD), `-0.05`},
+		{New(1500, JPY), `1500`},
+		{Amount{}, `0.00`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", tt.in, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       Amount
+		in           string
+		wantMinor    int64
+		wantCurrency Currency
+		wantErr      bool
+	}{
+		{name: "number", in: `12.3`, wantMinor: 1230, wantCurrency: USD},
+		{name: "string", in: `"12.30"`, wantMinor: 1230, wantCurrency: USD},
+		{name: "null", in: `null`, wantMinor: 0, wantCurrency: USD},
+		{name: "object", in: `{"amount": "12.30", "currency": "eur"}`, wantMinor: 1230, wantCurrency: EUR},
+		{name: "object without currency", in: `{"amount": 7}`, wantMinor: 700, wantCurrency: USD},
+		{name: "tagged target keeps its currency", target: Zero(JPY), in: `1500`, wantMinor: 1500, wantCurrency: JPY},
+		{name: "tagged target accepts its own currency", target: Zero(EUR), in: `{"amount": "1.05", "currency": "EUR"}`, wantMinor: 105, wantCurrency: EUR},
+		{name: "tagged target rejects another currency", target: Zero(EUR), in: `{"amount": "1.05", "currency": "USD"}`, wantErr: true},
+		{name: "too many decimals", in: `12.345`, wantErr: true},
+		{name: "not a number", in: `"twelve"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.target
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %s, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.in, err)
+			}
+			if got.Minor() != tt.wantMinor || got.Currency() != tt.wantCurrency {
+				t.Errorf("Unmarshal(%s) = %d %s, want %d %s", tt.in, got.Minor(), got.Currency(), tt.wantMinor, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	in := struct {
+		Fare Amount  `json:"fare"`
+		Tip  *Amount `json:"tip,omitempty"`
+	}{Fare: New(1999, USD)}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"fare":19.99}` {
+		t.Fatalf("Marshal = %s", data)
+	}
+
+	out := in
+	out.Fare = Amount{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Fare.Equal(in.Fare) || out.Tip != nil {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestValue(t *testing.T) {
+	v, err := New(-1205, USD).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "-12.05" {
+		t.Errorf("Value() = %v, want -12.05", v)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       Amount
+		src          interface{}
+		wantMinor    int64
+		wantCurrency Currency
+		wantErr      bool
+	}{
+		{name: "bytes", src: []byte("12.30"), wantMinor: 1230, wantCurrency: USD},
+		{name: "string", src: "-0.50", wantMinor: -50, wantCurrency: USD},
+		{name: "float", src: 1.005, wantMinor: 101, wantCurrency: USD},
+		{name: "int", src: int64(3), wantMinor: 300, wantCurrency: USD},
+		{name: "null", target: New(500, EUR), src: nil, wantMinor: 0, wantCurrency: EUR},
+		{name: "tagged target keeps its currency", target: Zero(JPY), src: "1500", wantMinor: 1500, wantCurrency: JPY},
+		{name: "too many decimals for the currency", target: Zero(JPY), src: "15.5", wantErr: true},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.target
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Scan(%v) = %s, want an error", tt.src, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v): %v", tt.src, err)
+			}
+			if got.Minor() != tt.wantMinor || got.Currency() != tt.wantCurrency {
+				t.Errorf("Scan(%v) = %d %s, want %d %s", tt.src, got.Minor(), got.Currency(), tt.wantMinor, tt.wantCurrency)
+			}
+		})
+	}
+}
+
+func TestValueScanRoundTrip(t *testing.T) {
+	for _, minor := range []int64{0, 1, -1, 1999, 123456789} {
+		in := New(minor, USD)
+		v, err := in.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := Zero(USD)
+		if err := out.Scan(v); err != nil {
+			t.Fatal(err)
+		}
+		if !out.Equal(in) {
+			t.Errorf("round trip of %s = %s", in, out)
+		}
+	}
+}
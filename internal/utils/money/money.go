@@ -0,0 +1,315 @@
+// Package money represents monetary amounts as integer minor units (cents)
+// so totals, splits and ledgers add up exactly. Amounts convert from float64
+// only at the edges (rates, legacy inputs), always with an explicit rounding
+// mode.
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 code
+type Currency string
+
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	PKR Currency = "PKR"
+	AED Currency = "AED"
+	SAR Currency = "SAR"
+	JPY Currency = "JPY"
+)
+
+// DefaultCurrency is used when an amount is read from a column or payload
+// that does not say which currency it is in (wallets default to USD)
+const DefaultCurrency = USD
+
+// minor unit digits for currencies that do not use two
+var exponents = map[Currency]int{
+	JPY:   0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+// Exponent is the number of minor unit digits (2 for cents)
+func (c Currency) Exponent() int {
+	if e, ok := exponents[c]; ok {
+		return e
+	}
+	return 2
+}
+
+func (c Currency) scale() int64 {
+	s := int64(1)
+	for i := 0; i < c.Exponent(); i++ {
+		s *= 10
+	}
+	return s
+}
+
+// Amount is a quantity of money in minor units. The zero value is zero in
+// an unspecified currency, which combines with any currency.
+type Amount struct {
+	minor    int64
+	currency Currency
+}
+
+// New returns minor units (e.g. cents) of c
+func New(minor int64, c Currency) Amount {
+	return Amount{minor: minor, currency: c}
+}
+
+// Zero returns zero of c
+func Zero(c Currency) Amount {
+	return Amount{currency: c}
+}
+
+// FromFloat converts a major-unit float (12.345) to an Amount, rounding to
+// the minor unit with mode. The float's shortest decimal form is used, so
+// 1.005 is treated as exactly 1.005 rather than 1.00499999...
+func FromFloat(v float64, c Currency, mode RoundingMode) Amount {
+	r := floatRat(v)
+	r.Mul(r, new(big.Rat).SetInt64(c.scale()))
+	return Amount{minor: roundRat(r, mode), currency: c}
+}
+
+// Parse reads a decimal string in major units ("12.34", "-0.5"). It fails
+// when the string has more decimals than the currency allows.
+func Parse(s string, c Currency) (Amount, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Amount{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(c.scale()))
+	if !r.IsInt() {
+		return Amount{}, fmt.Errorf("money: %q has more than %d decimals", s, c.Exponent())
+	}
+	if !r.Num().IsInt64() {
+		return Amount{}, fmt.Errorf("money: %q is out of range", s)
+	}
+	return Amount{minor: r.Num().Int64(), currency: c}, nil
+}
+
+// Minor returns the amount in minor units
+func (a Amount) Minor() int64 {
+	return a.minor
+}
+
+// Currency returns the amount's currency, DefaultCurrency when unspecified
+func (a Amount) Currency() Currency {
+	if a.currency == "" {
+		return DefaultCurrency
+	}
+	return a.currency
+}
+
+// WithCurrency tags an amount read without a currency (e.g. from a column)
+func (a Amount) WithCurrency(c Currency) Amount {
+	a.currency = c
+	return a
+}
+
+// In returns the amount in c. An amount without a currency is read as c,
+// rescaled when c has a different number of minor digits; an amount already
+// in another currency is an error, not a conversion.
+func (a Amount) In(c Currency) (Amount, error) {
+	switch a.currency {
+	case c:
+		return a, nil
+	case "":
+		if c.Exponent() == DefaultCurrency.Exponent() {
+			return Amount{minor: a.minor, currency: c}, nil
+		}
+		return Parse(a.String(), c)
+	default:
+		return Amount{}, fmt.Errorf("money: amount is in %s, not %s", a.currency, c)
+	}
+}
+
+// Float64 returns the amount in major units, for APIs and columns that are
+// still float64. Do not do arithmetic on the result.
+func (a Amount) Float64() float64 {
+	f, _ := strconv.ParseFloat(a.String(), 64)
+	return f
+}
+
+// String formats the amount in major units without a currency ("12.30")
+func (a Amount) String() string {
+	exp := a.Currency().Exponent()
+	minor := a.minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absUint(minor), 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (a Amount) IsZero() bool     { return a.minor == 0 }
+func (a Amount) IsPositive() bool { return a.minor > 0 }
+func (a Amount) IsNegative() bool { return a.minor < 0 }
+
+// Neg returns -a
+func (a Amount) Neg() Amount {
+	a.minor = -a.minor
+	return a
+}
+
+// Abs returns |a|
+func (a Amount) Abs() Amount {
+	if a.minor < 0 {
+		a.minor = -a.minor
+	}
+	return a
+}
+
+// Add returns a + b. Mixing two different currencies panics: it is a bug,
+// not a runtime condition.
+func (a Amount) Add(b Amount) Amount {
+	return Amount{minor: a.minor + b.minor, currency: combine(a, b)}
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{minor: a.minor - b.minor, currency: combine(a, b)}
+}
+
+// Cmp returns -1, 0 or 1 as a is less than, equal to or greater than b
+func (a Amount) Cmp(b Amount) int {
+	combine(a, b)
+	switch {
+	case a.minor < b.minor:
+		return -1
+	case a.minor > b.minor:
+		return 1
+	}
+	return 0
+}
+
+func (a Amount) LessThan(b Amount) bool    { return a.Cmp(b) < 0 }
+func (a Amount) GreaterThan(b Amount) bool { return a.Cmp(b) > 0 }
+func (a Amount) Equal(b Amount) bool       { return a.Cmp(b) == 0 }
+
+// MulInt multiplies by a whole quantity; no rounding needed
+func (a Amount) MulInt(n int64) Amount {
+	a.minor *= n
+	return a
+}
+
+// Mul multiplies by a rate or quantity (surge multiplier, km, commission
+// rate) and rounds the result to the minor unit with mode
+func (a Amount) Mul(factor float64, mode RoundingMode) Amount {
+	r := new(big.Rat).SetInt64(a.minor)
+	r.Mul(r, floatRat(factor))
+	a.minor = roundRat(r, mode)
+	return a
+}
+
+// Allocate splits a in proportion to weights. The parts always add up to a
+// exactly; leftover minor units go one each to the first parts.
+func (a Amount) Allocate(weights ...int64) []Amount {
+	parts := make([]Amount, len(weights))
+	var total int64
+	for _, w := range weights {
+		if w < 0 {
+			panic("money: negative allocation weight")
+		}
+		total += w
+	}
+	if total == 0 {
+		for i := range parts {
+			parts[i] = Amount{currency: a.currency}
+		}
+		return parts
+	}
+
+	remaining := a.minor
+	for i, w := range weights {
+		share := new(big.Int).Mul(big.NewInt(a.minor), big.NewInt(w))
+		share.Quo(share, big.NewInt(total)) // truncates toward zero
+		parts[i] = Amount{minor: share.Int64(), currency: a.currency}
+		remaining -= share.Int64()
+	}
+
+	step := int64(1)
+	if remaining < 0 {
+		step = -1
+	}
+	for i := 0; remaining != 0; i = (i + 1) % len(parts) {
+		if weights[i] == 0 {
+			continue
+		}
+		parts[i].minor += step
+		remaining -= step
+	}
+	return parts
+}
+
+// Split divides a into n parts that differ by at most one minor unit
+func (a Amount) Split(n int) []Amount {
+	weights := make([]int64, n)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return a.Allocate(weights...)
+}
+
+// Sum adds amounts; the sum of nothing is zero in an unspecified currency
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total = total.Add(a)
+	}
+	return total
+}
+
+// Min returns the smaller of a and b
+func Min(a, b Amount) Amount {
+	if b.LessThan(a) {
+		return b
+	}
+	return a
+}
+
+// Max returns the larger of a and b
+func Max(a, b Amount) Amount {
+	if b.GreaterThan(a) {
+		return b
+	}
+	return a
+}
+
+func combine(a, b Amount) Currency {
+	switch {
+	case a.currency == "":
+		return b.currency
+	case b.currency == "" || a.currency == b.currency:
+		return a.currency
+	}
+	panic(fmt.Sprintf("money: currency mismatch %s and %s", a.currency, b.currency))
+}
+
+// floatRat is the exact value of v's shortest decimal form
+func floatRat(v float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'f', -1, 64))
+	return r
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
@@ -0,0 +1,68 @@
+package money
+
+import "math/big"
+
+// RoundingMode decides what happens to a fraction of a minor unit
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest minor unit, halves away from zero (1.005 -> 1.01)
+	HalfUp RoundingMode = iota
+	// HalfEven rounds to the nearest minor unit, halves to even (1.005 -> 1.00, 1.015 -> 1.02)
+	HalfEven
+	// Down truncates toward zero; use when the platform must not overpay
+	Down
+	// Up rounds away from zero; use when the platform must not undercharge
+	Up
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case HalfUp:
+		return "half_up"
+	case HalfEven:
+		return "half_even"
+	case Down:
+		return "down"
+	case Up:
+		return "up"
+	}
+	return "unknown"
+}
+
+// roundRat rounds r to a whole number with mode
+func roundRat(r *big.Rat, mode RoundingMode) int64 {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom() // always positive
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		// compare the remainder with half the denominator
+		half := new(big.Int).Mul(rem, big.NewInt(2)).Cmp(den)
+
+		switch mode {
+		case Up:
+			quo.Add(quo, big.NewInt(1))
+		case HalfUp:
+			if half >= 0 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		case HalfEven:
+			if half > 0 || (half == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, big.NewInt(1))
+			}
+		case Down:
+		}
+	}
+
+	if negative {
+		quo.Neg(quo)
+	}
+	if !quo.IsInt64() {
+		panic("money: amount out of range")
+	}
+	return quo.Int64()
+}
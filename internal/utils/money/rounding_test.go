@@ -0,0 +1,87 @@
+package money
+
+import "testing"
+
+func TestFromFloatRounding(t *testing.T) {
+	tests := []struct {
+		in   float64
+		mode RoundingMode
+		want int64
+	}{
+		{1.005, HalfUp, 101},
+		{1.004, HalfUp, 100},
+		{-1.005, HalfUp, -101},
+		{1.005, HalfEven, 100},
+		{1.015, HalfEven, 102},
+		{1.0051, HalfEven, 101},
+		{-1.015, HalfEven, -102},
+		{1.009, Down, 100},
+		{-1.009, Down, -100},
+		{1.001, Up, 101},
+		{-1.001, Up, -101},
+		{1.10, Up, 110},
+		{0.1 + 0.2, HalfUp, 30},
+	}
+
+	for _, tt := range tests {
+		got := FromFloat(tt.in, USD, tt.mode)
+		if got.Minor() != tt.want {
+			t.Errorf("FromFloat(%v, %s) = %d, want %d", tt.in, tt.mode, got.Minor(), tt.want)
+		}
+	}
+}
+
+func TestFromFloatCurrencyExponent(t *testing.T) {
+	tests := []struct {
+		in       float64
+		currency Currency
+		want     int64
+	}{
+		{12.5, JPY, 13},
+		{12.345, USD, 1235},
+		{1.2345, "KWD", 1235},
+	}
+
+	for _, tt := range tests {
+		got := FromFloat(tt.in, tt.currency, HalfUp)
+		if got.Minor() != tt.want {
+			t.Errorf("FromFloat(%v, %s) = %d, want %d", tt.in, tt.currency, got.Minor(), tt.want)
+		}
+	}
+}
+
+func TestMulRounding(t *testing.T) {
+	fare := New(1005, USD) // 10.05
+
+	tests := []struct {
+		factor float64
+		mode   RoundingMode
+		want   int64
+	}{
+		{0.5, HalfUp, 503},
+		{0.5, HalfEven, 502},
+		{0.5, Down, 502},
+		{0.5, Up, 503},
+		{1.5, HalfUp, 1508},
+	}
+
+	for _, tt := range tests {
+		got := fare.Mul(tt.factor, tt.mode)
+		if got.Minor() != tt.want {
+			t.Errorf("%s.Mul(%v, %s) = %d, want %d", fare, tt.factor, tt.mode, got.Minor(), tt.want)
+		}
+	}
+}
+
+func TestSplitKeepsEveryCent(t *testing.T) {
+	parts := New(100, USD).Split(3)
+	if len(parts) != 3 {
+		t.Fatalf("Split(3) returned %d parts", len(parts))
+	}
+	if sum := Sum(parts...); sum.Minor() != 100 {
+		t.Errorf("parts add up to %d, want 100", sum.Minor())
+	}
+	if parts[0].Minor() != 34 || parts[2].Minor() != 33 {
+		t.Errorf("Split(3) = %v, want the remainder on the first part", parts)
+	}
+}
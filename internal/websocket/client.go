@@ -156,6 +156,13 @@ func (c *Client) WritePump() {
 				return
 			}
 
+			// The user was logged out or suspended: close after telling them
+			if message.Type == TypeSessionEnded {
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session ended"))
+				return
+			}
+
 			// ✅ Track messages requiring acknowledgment
 			if message.RequireAck && message.MessageID != "" {
 				c.pendingAcks[message.MessageID] = message
@@ -92,7 +92,11 @@ func (h *Hub) Run(ctx context.Context) {
 			h.unregisterClient(client)
 
 		case message := <-h.broadcast:
-			h.broadcastMessage(message)
+			if message.Type == TypeSessionEnded {
+				h.endUserSession(message)
+			} else {
+				h.broadcastMessage(message)
+			}
 
 		case <-ctx.Done():
 			logger.Info("websocket hub shutting down")
@@ -493,6 +497,32 @@ func (h *Hub) broadcastMessage(message *Message) {
 	}
 }
 
+// endUserSession tells every local device of the target user why it is being
+// disconnected and closes the connection. WritePump closes it after writing
+// the message; a client whose buffer is full is closed straight away. Either
+// way ReadPump fails and unregisters the client.
+func (h *Hub) endUserSession(message *Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	clients := h.clients[message.TargetUserID]
+	for _, client := range clients {
+		select {
+		case client.send <- message:
+		default:
+			client.conn.Close()
+		}
+	}
+
+	if len(clients) > 0 {
+		logger.Info("websocket session ended",
+			"userID", message.TargetUserID,
+			"devices", len(clients),
+			"reason", message.Data["reason"],
+		)
+	}
+}
+
 // getOnlineUserIDsUnsafe returns slice of online user IDs (must be called within lock)
 func (h *Hub) getOnlineUserIDsUnsafe() []string {
 	userIDs := make([]string, 0, len(h.clients))
@@ -580,6 +610,20 @@ func (h *Hub) SendToUser(userID string, msg *Message) {
 	cache.PublishMessage(ctx, "websocket:broadcast", msg)
 }
 
+// DisconnectUser closes every connection of a user, on this server and the
+// others, after telling the devices why. Used when the user is logged out or
+// can no longer sign in; the handshake check keeps them from reconnecting.
+func (h *Hub) DisconnectUser(userID, reason string) {
+	msg := NewTargetedMessage(TypeSessionEnded, userID, map[string]interface{}{
+		"reason": reason,
+	})
+
+	// Other servers pick it up from Redis; ours too, which is harmless since
+	// the connections are already closing
+	h.broadcast <- msg
+	cache.PublishMessage(context.Background(), "websocket:broadcast", msg)
+}
+
 // ✅ NEW - Send to specific driver
 func (h *Hub) SendToDriver(driverID string, msg *Message) {
 	h.mu.RLock()
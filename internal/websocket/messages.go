@@ -26,16 +26,23 @@ const (
 	TypePresence    MessageType = "presence"
 
 	// ✅ RIDE-SPECIFIC EVENTS
-	TypeRideRequest          MessageType = "ride_request"           // New ride request to driver
-	TypeRideRequestAccepted  MessageType = "ride_request_accepted"  // Driver accepted
-	TypeRideRequestRejected  MessageType = "ride_request_rejected"  // Driver rejected
-	TypeRideStatusUpdate     MessageType = "ride_status_update"     // Status changed
-	TypeRideDriverArriving   MessageType = "ride_driver_arriving"   // Driver approaching
-	TypeRideDriverArrived    MessageType = "ride_driver_arrived"    // Driver at pickup
-	TypeRideStarted          MessageType = "ride_started"           // Ride in progress
-	TypeRideCompleted        MessageType = "ride_completed"         // Ride finished
-	TypeRideCancelled        MessageType = "ride_cancelled"         // Ride cancelled
-	TypeDriverLocationUpdate MessageType = "driver_location_update" // Driver location
+	TypeRideRequest                    MessageType = "ride_request"                      // New ride request to driver
+	TypeRideRequestAccepted            MessageType = "ride_request_accepted"             // Driver accepted
+	TypeRideRequestRejected            MessageType = "ride_request_rejected"             // Driver rejected
+	TypeRideStatusUpdate               MessageType = "ride_status_update"                // Status changed
+	TypeRideDriverArriving             MessageType = "ride_driver_arriving"              // Driver approaching
+	TypeRideDriverArrived              MessageType = "ride_driver_arrived"               // Driver at pickup
+	TypeRideStarted                    MessageType = "ride_started"                      // Ride in progress
+	TypeRideCompleted                  MessageType = "ride_completed"                    // Ride finished
+	TypeRideCancelled                  MessageType = "ride_cancelled"                    // Ride cancelled
+	TypeRideStopsUpdated               MessageType = "ride_stops_updated"                // Rider changed the stops
+	TypeRideStopArrived                MessageType = "ride_stop_arrived"                 // Driver reached a stop
+	TypeRideStopDeparted               MessageType = "ride_stop_departed"                // Driver left a stop
+	TypeRideDestinationChangeRequested MessageType = "ride_destination_change_requested" // Rider asked for a new dropoff
+	TypeRideDestinationChangeResponse  MessageType = "ride_destination_change_response"  // Driver accepted or declined it
+	TypeRideDestinationChanged         MessageType = "ride_destination_changed"          // New dropoff applied
+	TypeRideDestinationChangeRejected  MessageType = "ride_destination_change_rejected"  // Driver declined or the change failed
+	TypeDriverLocationUpdate           MessageType = "driver_location_update"            // Driver location
 
 	// System
 	TypeSystemMessage MessageType = "system"
@@ -44,6 +51,7 @@ const (
 	TypePong          MessageType = "pong"
 	TypeAck           MessageType = "ack"
 	TypeConnectionAck MessageType = "connection_ack" // ✅ NEW - Connection confirmation
+	TypeSessionEnded  MessageType = "session_ended"  // Sent right before the server closes a logged-out or suspended user's connections
 )
 
 // Message represents a WebSocket message
@@ -2,16 +2,19 @@
 package websocket
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/umar5678/go-backend/internal/services/authstate"
 	"github.com/umar5678/go-backend/internal/utils/jwt"
 	"github.com/umar5678/go-backend/internal/utils/logger"
 )
 
-// AuthMiddleware authenticates WebSocket connections
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// AuthMiddleware authenticates WebSocket connections with an access token
+// and the same account checks as HTTP requests
+func AuthMiddleware(jwtSecret string, accounts authstate.Checker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Try to get token from query parameter first (for WebSocket connections)
 		token := c.Query("token")
@@ -33,7 +36,7 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		}
 
 		// Validate token
-		claims, err := jwt.ValidateToken(token, jwtSecret)
+		claims, err := jwt.ValidateAccessToken(token, jwtSecret)
 		if err != nil {
 			logger.Warn("websocket authentication failed",
 				"error", err.Error(),
@@ -46,6 +49,23 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		if err := accounts.Check(c.Request.Context(), claims); err != nil {
+			logger.Warn("websocket authentication rejected",
+				"error", err.Error(),
+				"userID", claims.UserID,
+				"remote_addr", c.Request.RemoteAddr,
+			)
+			status := http.StatusUnauthorized
+			if errors.Is(err, authstate.ErrAccountInactive) {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{
+				"error": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
 		c.Set("userID", claims.UserID)
 		c.Set("role", claims.Role)
@@ -19,7 +19,7 @@ func AuthenticateWebSocket(ctx context.Context, token, jwtSecret string) (string
 	token = strings.TrimPrefix(token, "Bearer ")
 
 	// Verify JWT token
-	claims, err := jwt.ValidateToken(token, jwtSecret)
+	claims, err := jwt.ValidateAccessToken(token, jwtSecret)
 	if err != nil {
 		return "", errors.New("invalid or expired token")
 	}
@@ -41,7 +41,7 @@ func ValidateToken(token, jwtSecret string) (string, error) {
 
 	token = strings.TrimPrefix(token, "Bearer ")
 
-	claims, err := jwt.ValidateToken(token, jwtSecret)
+	claims, err := jwt.ValidateAccessToken(token, jwtSecret)
 	if err != nil {
 		return "", errors.New("invalid token")
 	}
@@ -5,41 +5,44 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/umar5678/go-backend/internal/config"
 	"github.com/umar5678/go-backend/internal/middleware"
+	"github.com/umar5678/go-backend/internal/models"
+	"github.com/umar5678/go-backend/internal/services/authstate"
+	"github.com/umar5678/go-backend/internal/services/permissions"
 )
 
 // RegisterRoutes sets up WebSocket routes
-func RegisterRoutes(router *gin.Engine, cfg *config.Config, server *Server) {
+func RegisterRoutes(router *gin.Engine, cfg *config.Config, server *Server, accounts authstate.Checker, checker permissions.Checker) {
 	ws := router.Group("/ws")
 	{
 		// WebSocket connection endpoint (uses WebSocket-specific auth)
-		ws.GET("/connect", AuthMiddleware(cfg.JWT.Secret), server.HandleConnection())
+		ws.GET("/connect", AuthMiddleware(cfg.JWT.Secret, accounts), server.HandleConnection())
 
 		// Health check (public)
 		ws.GET("/health", server.HandleHealthCheck())
 
 		// Stats endpoint (admin only)
 		ws.GET("/stats",
-			middleware.Auth(cfg),
-			middleware.RequireRole("admin"),
+			middleware.Auth(cfg, accounts),
+			middleware.RequirePermission(checker, models.PermissionRealtimeView),
 			server.HandleStats(),
 		)
 
 		// User presence check (requires auth)
 		ws.POST("/presence",
-			middleware.Auth(cfg),
+			middleware.Auth(cfg, accounts),
 			server.HandleUserPresence(),
 		)
 
 		// Send message to user (requires auth)
 		ws.POST("/send",
-			middleware.Auth(cfg),
+			middleware.Auth(cfg, accounts),
 			server.HandleSendToUser(),
 		)
 
 		// Broadcast message (admin only)
 		ws.POST("/broadcast",
-			middleware.Auth(cfg),
-			middleware.RequireRole("admin"),
+			middleware.Auth(cfg, accounts),
+			middleware.RequirePermission(checker, models.PermissionNotificationsBroadcast),
 			server.HandleBroadcast(),
 		)
 	}
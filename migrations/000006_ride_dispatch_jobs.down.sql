@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_ride_dispatch_jobs_updated_at ON ride_dispatch_jobs;
+DROP TABLE IF EXISTS ride_dispatch_jobs;
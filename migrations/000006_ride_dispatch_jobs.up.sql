@@ -0,0 +1,35 @@
+-- =====================================================
+-- RIDE DISPATCH JOBS
+-- Durable driver search state, claimed by dispatch workers
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS ride_dispatch_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ride_id UUID NOT NULL UNIQUE REFERENCES rides(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    current_radius_km DECIMAL(6,2) DEFAULT 0,
+    drivers_contacted INTEGER NOT NULL DEFAULT 0,
+    assigned_driver_id UUID,
+    last_error TEXT,
+
+    -- Worker lease
+    locked_by VARCHAR(100),
+    locked_until TIMESTAMP WITH TIME ZONE,
+
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ride_dispatch_jobs_status CHECK (status IN ('pending', 'running', 'completed', 'failed', 'cancelled'))
+);
+
+CREATE INDEX idx_ride_dispatch_jobs_status ON ride_dispatch_jobs(status);
+CREATE INDEX idx_ride_dispatch_jobs_next_run_at ON ride_dispatch_jobs(next_run_at) WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER update_ride_dispatch_jobs_updated_at BEFORE UPDATE ON ride_dispatch_jobs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_dispatch_ranking_weights_updated_at ON dispatch_ranking_weights;
+DROP TABLE IF EXISTS dispatch_ranking_weights;
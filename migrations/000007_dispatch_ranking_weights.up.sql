@@ -0,0 +1,30 @@
+-- =====================================================
+-- DISPATCH RANKING WEIGHTS
+-- Per city / vehicle type weights for ordering candidate drivers
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS dispatch_ranking_weights (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    city VARCHAR(100),
+    vehicle_type_id UUID REFERENCES vehicle_types(id) ON DELETE CASCADE,
+    eta_weight DECIMAL(5,2) NOT NULL DEFAULT 0.5,
+    rating_weight DECIMAL(5,2) NOT NULL DEFAULT 0.2,
+    acceptance_weight DECIMAL(5,2) NOT NULL DEFAULT 0.2,
+    idle_weight DECIMAL(5,2) NOT NULL DEFAULT 0.1,
+    cancellation_weight DECIMAL(5,2) NOT NULL DEFAULT 0,
+    max_eta_seconds INTEGER NOT NULL DEFAULT 900,
+    max_idle_minutes INTEGER NOT NULL DEFAULT 60,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- One row per (city, vehicle type) scope, NULLs meaning "any"
+CREATE UNIQUE INDEX idx_dispatch_ranking_weights_scope
+    ON dispatch_ranking_weights (COALESCE(city, ''), COALESCE(vehicle_type_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+CREATE TRIGGER update_dispatch_ranking_weights_updated_at BEFORE UPDATE ON dispatch_ranking_weights
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Global default
+INSERT INTO dispatch_ranking_weights (city, vehicle_type_id) VALUES (NULL, NULL);
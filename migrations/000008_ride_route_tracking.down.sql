@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_driver_locations_driver_timestamp;
+DROP INDEX IF EXISTS idx_rides_route_flagged;
+
+ALTER TABLE rides DROP COLUMN IF EXISTS route_flag_reason;
+ALTER TABLE rides DROP COLUMN IF EXISTS route_flagged;
+ALTER TABLE rides DROP COLUMN IF EXISTS route_polyline;
+ALTER TABLE rides DROP COLUMN IF EXISTS reported_duration;
+ALTER TABLE rides DROP COLUMN IF EXISTS reported_distance;
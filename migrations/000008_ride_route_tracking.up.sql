@@ -0,0 +1,16 @@
+-- =====================================================
+-- RIDE ROUTE TRACKING
+-- GPS-derived actuals: keep the driver's reported figures for audit,
+-- store the trip polyline and flag large discrepancies
+-- =====================================================
+
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS reported_distance DECIMAL(10,2);
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS reported_duration INTEGER;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS route_polyline TEXT;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS route_flagged BOOLEAN DEFAULT false;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS route_flag_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_rides_route_flagged ON rides(route_flagged) WHERE route_flagged = true;
+
+-- Trip distance is computed from location history between start and completion
+CREATE INDEX IF NOT EXISTS idx_driver_locations_driver_timestamp ON driver_locations(driver_id, timestamp);
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS service_orders DROP COLUMN IF EXISTS commission_rule_version;
+ALTER TABLE IF EXISTS service_orders DROP COLUMN IF EXISTS commission_rule_id;
+
+ALTER TABLE driver_profiles DROP COLUMN IF EXISTS tier;
+
+DROP TRIGGER IF EXISTS update_commission_rules_updated_at ON commission_rules;
+DROP TABLE IF EXISTS commission_rules;
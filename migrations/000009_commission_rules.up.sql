@@ -0,0 +1,70 @@
+-- =====================================================
+-- COMMISSION RULES
+-- Effective-dated platform commission per service line and scope
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS commission_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_line VARCHAR(50) NOT NULL,
+
+    -- Scope (NULL matches anything)
+    city VARCHAR(100),
+    vehicle_type_id UUID REFERENCES vehicle_types(id) ON DELETE CASCADE,
+    category VARCHAR(255),
+    tier VARCHAR(50),
+
+    -- Terms
+    rate DECIMAL(5,4) NOT NULL,
+    flat_fee DECIMAL(10,2) DEFAULT 0,
+    min_commission DECIMAL(10,2),
+    max_commission DECIMAL(10,2),
+
+    -- Versioning
+    version INTEGER NOT NULL DEFAULT 1,
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    effective_to TIMESTAMP WITH TIME ZONE,
+    is_active BOOLEAN DEFAULT true,
+
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_commission_rules_service_line CHECK (service_line IN ('ride', 'homeservice', 'laundry')),
+    CONSTRAINT chk_commission_rules_rate CHECK (rate >= 0 AND rate <= 1),
+    CONSTRAINT chk_commission_rules_effective CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX idx_commission_rules_service_line ON commission_rules(service_line);
+CREATE INDEX idx_commission_rules_effective_from ON commission_rules(effective_from);
+CREATE UNIQUE INDEX idx_commission_rules_scope_version ON commission_rules (
+    service_line,
+    COALESCE(city, ''),
+    COALESCE(vehicle_type_id, '00000000-0000-0000-0000-000000000000'::uuid),
+    COALESCE(category, ''),
+    COALESCE(tier, ''),
+    version
+);
+
+CREATE TRIGGER update_commission_rules_updated_at BEFORE UPDATE ON commission_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Global defaults matching the previously hardcoded rates
+INSERT INTO commission_rules (service_line, rate, notes) VALUES
+    ('ride', 0.20, 'Initial default (was hardcoded 80% driver share)'),
+    ('homeservice', 0.10, 'Initial default (was hardcoded 10% platform fee)'),
+    ('laundry', 0.10, 'Initial default');
+
+-- =====================================================
+-- DRIVER TIER
+-- =====================================================
+
+ALTER TABLE driver_profiles ADD COLUMN IF NOT EXISTS tier VARCHAR(50) DEFAULT 'standard';
+
+-- =====================================================
+-- COMMISSION ON HOME SERVICE ORDERS
+-- Which rule version priced the platform cut
+-- =====================================================
+
+ALTER TABLE IF EXISTS service_orders ADD COLUMN IF NOT EXISTS commission_rule_id UUID;
+ALTER TABLE IF EXISTS service_orders ADD COLUMN IF NOT EXISTS commission_rule_version INTEGER;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS update_surge_city_settings_updated_at ON surge_city_settings;
+DROP TABLE IF EXISTS surge_city_settings;
+
+DROP INDEX IF EXISTS idx_surge_pricing_zones_computed_geohash;
+DELETE FROM surge_pricing_zones WHERE source = 'computed';
+ALTER TABLE surge_pricing_zones DROP COLUMN IF EXISTS supply_count;
+ALTER TABLE surge_pricing_zones DROP COLUMN IF EXISTS demand_count;
+ALTER TABLE surge_pricing_zones DROP COLUMN IF EXISTS source;
@@ -0,0 +1,36 @@
+-- =====================================================
+-- SURGE ENGINE
+-- Computed demand/supply surge per geohash cell
+-- =====================================================
+
+ALTER TABLE surge_pricing_zones ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'manual';
+ALTER TABLE surge_pricing_zones ADD COLUMN IF NOT EXISTS demand_count INTEGER DEFAULT 0;
+ALTER TABLE surge_pricing_zones ADD COLUMN IF NOT EXISTS supply_count INTEGER DEFAULT 0;
+
+-- One live computed zone per cell
+CREATE UNIQUE INDEX IF NOT EXISTS idx_surge_pricing_zones_computed_geohash
+    ON surge_pricing_zones(area_geohash)
+    WHERE source = 'computed' AND deleted_at IS NULL;
+
+-- =====================================================
+-- SURGE CITY SETTINGS
+-- Per-city switch and cap for the surge engine
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS surge_city_settings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    city VARCHAR(100) NOT NULL UNIQUE,
+    center_lat DECIMAL(10,8) NOT NULL,
+    center_lon DECIMAL(11,8) NOT NULL,
+    radius_km DECIMAL(6,2) NOT NULL,
+    enabled BOOLEAN DEFAULT true,
+    max_multiplier DECIMAL(3,2),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_surge_city_settings_radius CHECK (radius_km > 0),
+    CONSTRAINT chk_surge_city_settings_max_multiplier CHECK (max_multiplier IS NULL OR max_multiplier >= 1)
+);
+
+CREATE TRIGGER update_surge_city_settings_updated_at BEFORE UPDATE ON surge_city_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_rides_quote_id;
+ALTER TABLE rides DROP COLUMN IF EXISTS fare_capped;
+ALTER TABLE rides DROP COLUMN IF EXISTS quote_id;
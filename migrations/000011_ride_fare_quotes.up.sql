@@ -0,0 +1,10 @@
+-- =====================================================
+-- UPFRONT FARE QUOTES
+-- Rides booked against a quote keep its id; the final
+-- fare is capped relative to the quoted fare
+-- =====================================================
+
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS quote_id VARCHAR(36);
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS fare_capped BOOLEAN DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_rides_quote_id ON rides(quote_id) WHERE quote_id IS NOT NULL;
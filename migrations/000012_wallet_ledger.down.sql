@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_journal_id;
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS journal_id;
+
+DROP TRIGGER IF EXISTS ledger_postings_balanced ON ledger_postings;
+DROP FUNCTION IF EXISTS check_ledger_journal_balanced();
+
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS ledger_journals;
+DROP TABLE IF EXISTS ledger_accounts;
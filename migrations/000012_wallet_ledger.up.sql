@@ -0,0 +1,94 @@
+-- =====================================================
+-- DOUBLE-ENTRY LEDGER
+-- Every wallet movement is a journal whose postings sum to zero
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(100) NOT NULL UNIQUE,   -- wallet:{wallet_id} or platform:*
+    account_type wallet_type NOT NULL,
+    wallet_id UUID UNIQUE REFERENCES wallets(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_journals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reference_type VARCHAR(50) NOT NULL,
+    reference_id VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_journals_reference ON ledger_journals(reference_type, reference_id);
+CREATE INDEX idx_ledger_journals_created_at ON ledger_journals(created_at);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    journal_id UUID NOT NULL REFERENCES ledger_journals(id) ON DELETE RESTRICT,
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id) ON DELETE RESTRICT,
+    amount DECIMAL(12,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ledger_postings_amount CHECK (amount <> 0)
+);
+
+CREATE INDEX idx_ledger_postings_journal_id ON ledger_postings(journal_id);
+CREATE INDEX idx_ledger_postings_account_id ON ledger_postings(account_id);
+
+-- A journal must balance by the time its transaction commits
+CREATE OR REPLACE FUNCTION check_ledger_journal_balanced()
+RETURNS TRIGGER AS $$
+DECLARE
+    total DECIMAL(14,2);
+BEGIN
+    SELECT COALESCE(SUM(amount), 0) INTO total
+    FROM ledger_postings
+    WHERE journal_id = NEW.journal_id;
+
+    IF total <> 0 THEN
+        RAISE EXCEPTION 'ledger journal % does not balance (off by %)', NEW.journal_id, total;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_ledger_journal_balanced();
+
+-- Wallet transactions point at the journal that moved the money
+ALTER TABLE wallet_transactions ADD COLUMN IF NOT EXISTS journal_id UUID REFERENCES ledger_journals(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_journal_id ON wallet_transactions(journal_id);
+
+-- =====================================================
+-- ACCOUNTS AND OPENING BALANCES
+-- =====================================================
+
+INSERT INTO ledger_accounts (code, account_type) VALUES
+    ('platform:external', 'platform'),
+    ('platform:clearing', 'platform'),
+    ('platform:revenue', 'platform');
+
+INSERT INTO ledger_accounts (code, account_type, wallet_id)
+SELECT 'wallet:' || id, wallet_type, id FROM wallets;
+
+-- Existing balances are brought in against the external account
+WITH opening AS (
+    INSERT INTO ledger_journals (reference_type, reference_id, description)
+    SELECT 'opening_balance', 'migration_000012', 'Wallet balances at ledger introduction'
+    WHERE EXISTS (SELECT 1 FROM wallets WHERE balance <> 0)
+    RETURNING id
+)
+INSERT INTO ledger_postings (journal_id, account_id, amount)
+SELECT opening.id, a.id, w.balance
+FROM opening
+JOIN wallets w ON w.balance <> 0
+JOIN ledger_accounts a ON a.wallet_id = w.id
+UNION ALL
+SELECT opening.id, (SELECT id FROM ledger_accounts WHERE code = 'platform:external'), -SUM(w.balance)
+FROM opening
+JOIN wallets w ON w.balance <> 0
+GROUP BY opening.id
+HAVING SUM(w.balance) <> 0;
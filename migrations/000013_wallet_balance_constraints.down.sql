@@ -0,0 +1,5 @@
+ALTER TABLE wallet_holds DROP CONSTRAINT IF EXISTS chk_wallet_holds_amount_positive;
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_held_within_balance;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_held_balance_non_negative;
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS chk_wallets_balance_non_negative;
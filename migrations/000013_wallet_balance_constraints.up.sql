@@ -0,0 +1,43 @@
+-- =====================================================
+-- WALLET BALANCE CONSTRAINTS
+-- Last line of defence behind the row locks in the wallet service:
+-- no write may leave a wallet overdrawn or holding more than it has
+-- =====================================================
+
+-- Existing rows that break the constraints would make ADD CONSTRAINT fail
+-- with a bare "check constraint is violated by some row". Name them instead.
+-- They have to be repaired by hand (reconcile the wallet against its ledger
+-- postings and holds, see GET /admin/wallet/reconciliation) before re-running.
+DO $$
+DECLARE
+    bad_wallets TEXT;
+    bad_holds TEXT;
+BEGIN
+    SELECT string_agg(format('%s (balance %s, held %s)', id, balance, held_balance), ', ' ORDER BY id)
+    INTO bad_wallets
+    FROM wallets
+    WHERE balance < 0 OR held_balance < 0 OR held_balance > balance;
+
+    IF bad_wallets IS NOT NULL THEN
+        RAISE EXCEPTION 'wallets overdrawn or holding more than their balance: %', bad_wallets
+            USING HINT = 'Correct balance/held_balance for these wallets, then run the migration again';
+    END IF;
+
+    SELECT string_agg(format('%s (amount %s)', id, amount), ', ' ORDER BY id)
+    INTO bad_holds
+    FROM wallet_holds
+    WHERE amount <= 0;
+
+    IF bad_holds IS NOT NULL THEN
+        RAISE EXCEPTION 'wallet holds with a non-positive amount: %', bad_holds
+            USING HINT = 'Release or correct these holds, then run the migration again';
+    END IF;
+END $$;
+
+ALTER TABLE wallets
+    ADD CONSTRAINT chk_wallets_balance_non_negative CHECK (balance >= 0),
+    ADD CONSTRAINT chk_wallets_held_balance_non_negative CHECK (held_balance >= 0),
+    ADD CONSTRAINT chk_wallets_held_within_balance CHECK (held_balance <= balance);
+
+ALTER TABLE wallet_holds
+    ADD CONSTRAINT chk_wallet_holds_amount_positive CHECK (amount > 0);
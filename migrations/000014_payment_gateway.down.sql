@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_gateway_reference;
+
+DELETE FROM ledger_accounts a
+WHERE a.code = 'platform:payouts_pending'
+  AND NOT EXISTS (SELECT 1 FROM ledger_postings p WHERE p.account_id = a.id);
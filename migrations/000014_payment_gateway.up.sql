@@ -0,0 +1,16 @@
+-- =====================================================
+-- PAYMENT GATEWAY
+-- Top-ups wait for the gateway's webhook before crediting a wallet;
+-- withdrawals and refunds leave the wallet at once and sit in
+-- platform:payouts_pending until the gateway settles them
+-- =====================================================
+
+INSERT INTO ledger_accounts (code, account_type) VALUES
+    ('platform:payouts_pending', 'platform')
+ON CONFLICT (code) DO NOTHING;
+
+-- Webhooks find their transaction by the gateway object they settle;
+-- one transaction per intent, refund or payout
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_transactions_gateway_reference
+    ON wallet_transactions(reference_type, reference_id)
+    WHERE reference_type IN ('topup', 'topup_refund', 'withdrawal');
@@ -0,0 +1,21 @@
+-- =====================================================
+-- JOB RUNS
+-- History of background scheduler runs (hold expiry, sweeps, cleanup)
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(100) NOT NULL,
+    instance_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    affected BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT,
+
+    CONSTRAINT chk_job_runs_status CHECK (status IN ('running', 'succeeded', 'failed'))
+);
+
+CREATE INDEX idx_job_runs_job_name_started_at ON job_runs(job_name, started_at DESC);
+CREATE INDEX idx_job_runs_started_at ON job_runs(started_at);
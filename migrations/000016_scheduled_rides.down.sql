@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rides_scheduled_at;
+ALTER TABLE rides DROP COLUMN IF EXISTS scheduled_at;
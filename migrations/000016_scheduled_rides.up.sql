@@ -0,0 +1,9 @@
+-- =====================================================
+-- SCHEDULED RIDES
+-- Rides booked ahead wait in 'scheduled' until shortly
+-- before scheduled_at, when the driver search starts
+-- =====================================================
+
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_rides_scheduled_at ON rides(scheduled_at) WHERE status = 'scheduled';
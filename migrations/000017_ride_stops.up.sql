@@ -0,0 +1,24 @@
+-- =====================================================
+-- RIDE STOPS
+-- Intermediate waypoints of multi-stop rides, visited in
+-- sequence order between pickup and dropoff
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS ride_stops (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ride_id UUID NOT NULL REFERENCES rides(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    lat DECIMAL(10,8) NOT NULL,
+    lon DECIMAL(11,8) NOT NULL,
+    address TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    arrived_at TIMESTAMP WITH TIME ZONE,
+    departed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_ride_stops_ride_sequence UNIQUE (ride_id, sequence),
+    CONSTRAINT chk_ride_stops_status CHECK (status IN ('pending', 'arrived', 'departed', 'skipped'))
+);
+
+CREATE INDEX idx_ride_stops_ride_id ON ride_stops(ride_id);
@@ -0,0 +1,25 @@
+-- =====================================================
+-- RIDE RATINGS
+-- Two-way ratings after a completed ride: the rider rates
+-- the driver and the driver rates the rider, once each
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS ride_ratings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ride_id UUID NOT NULL REFERENCES rides(id) ON DELETE CASCADE,
+    rater_role VARCHAR(20) NOT NULL,
+    rater_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ratee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_ride_ratings_ride_rater UNIQUE (ride_id, rater_role),
+    CONSTRAINT chk_ride_ratings_rater_role CHECK (rater_role IN ('rider', 'driver')),
+    CONSTRAINT chk_ride_ratings_score CHECK (score >= 1 AND score <= 5)
+);
+
+-- Averages and review queues look up ratings received by a user
+CREATE INDEX idx_ride_ratings_ratee ON ride_ratings(ratee_id, rater_role, created_at DESC);
+CREATE INDEX idx_ride_ratings_low_score ON ride_ratings(created_at DESC) WHERE score <= 2;
@@ -0,0 +1,4 @@
+ALTER TABLE rides DROP COLUMN IF EXISTS airport_fee;
+
+DROP TABLE IF EXISTS geofence_pickup_points;
+DROP TABLE IF EXISTS geofences;
@@ -0,0 +1,45 @@
+-- =====================================================
+-- GEOFENCES
+-- Admin-drawn polygons for operating cities, no-service
+-- zones and airports (designated pickup points, flat fee)
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS geofences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    city VARCHAR(100),
+    services TEXT[] NOT NULL DEFAULT '{}',
+    fee DECIMAL(10,2) NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    boundary GEOMETRY(Polygon, 4326) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_geofences_type CHECK (type IN ('operating_city', 'no_service', 'airport')),
+    CONSTRAINT chk_geofences_fee CHECK (fee >= 0),
+    CONSTRAINT chk_geofences_boundary CHECK (ST_IsValid(boundary))
+);
+
+-- Point-in-zone lookups on every estimate and booking
+CREATE INDEX idx_geofences_boundary ON geofences USING GIST(boundary);
+CREATE INDEX idx_geofences_type ON geofences(type) WHERE is_active = true;
+
+CREATE TABLE IF NOT EXISTS geofence_pickup_points (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    lat DECIMAL(10,8) NOT NULL,
+    lon DECIMAL(11,8) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_geofence_pickup_points_geofence_id ON geofence_pickup_points(geofence_id);
+
+-- =====================================================
+-- RIDES
+-- Airport fee charged on top of the metered fare
+-- =====================================================
+
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS airport_fee DECIMAL(10,2) NOT NULL DEFAULT 0;
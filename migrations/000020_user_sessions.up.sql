@@ -0,0 +1,27 @@
+-- =====================================================
+-- USER SESSIONS
+-- One row per logged-in device. Each session is a refresh
+-- token family: refreshing rotates refresh_token_id, and a
+-- replayed older token revokes the session
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_id VARCHAR(64) NOT NULL,
+    device_id VARCHAR(255),
+    device_name VARCHAR(255),
+    app_version VARCHAR(50),
+    user_agent VARCHAR(500),
+    ip_address VARCHAR(45),
+    last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Session lists and "log out everywhere" only touch live sessions
+CREATE INDEX idx_user_sessions_user_active ON user_sessions(user_id, last_used_at DESC) WHERE revoked_at IS NULL;
+CREATE INDEX idx_user_sessions_user_device ON user_sessions(user_id, device_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,7 @@
+-- =====================================================
+-- USER TOKEN VERSION
+-- Tokens carry the version they were issued at; bumping
+-- it revokes every token of the user at once
+-- =====================================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- =====================================================
+-- EMAIL VERIFICATION
+-- Set when the user opens the verification link sent to
+-- their current address; cleared when the address changes
+-- =====================================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS admin_role_assignments;
+DROP TRIGGER IF EXISTS update_admin_roles_updated_at ON admin_roles;
+DROP TABLE IF EXISTS admin_roles;
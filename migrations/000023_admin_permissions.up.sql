@@ -0,0 +1,58 @@
+-- =====================================================
+-- ADMIN PERMISSIONS
+-- Roles bundle named permissions ("orders.reassign",
+-- "wallet.adjust", "*" for all); admin users get roles
+-- through assignments
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS admin_roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_admin_roles_updated_at BEFORE UPDATE ON admin_roles
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS admin_role_assignments (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES admin_roles(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX idx_admin_role_assignments_role_id ON admin_role_assignments(role_id);
+
+-- Built-in roles
+INSERT INTO admin_roles (slug, name, description, permissions, is_system) VALUES
+    ('super_admin', 'Super Admin', 'Every permission, including managing roles',
+        ARRAY['*'], true),
+    ('ops_manager', 'Operations Manager', 'Runs day-to-day operations: providers, catalogue, orders, service areas and dispatch',
+        ARRAY['users.view', 'users.manage', 'providers.approve', 'dashboard.view',
+              'services.view', 'services.manage',
+              'orders.view', 'orders.update', 'orders.reassign', 'orders.bulk_update',
+              'analytics.view', 'realtime.view', 'notifications.broadcast',
+              'pricing.manage', 'geofences.manage', 'dispatch.view', 'ratings.review', 'jobs.view'], true),
+    ('finance', 'Finance', 'Revenue, reconciliation, wallet adjustments and commission rules',
+        ARRAY['users.view', 'dashboard.view', 'orders.view',
+              'analytics.view', 'revenue.view', 'wallet.view', 'wallet.adjust',
+              'commission.manage'], true),
+    ('support_agent', 'Support Agent', 'Looks up users, orders and rides and fixes order status',
+        ARRAY['users.view', 'dashboard.view', 'services.view', 'orders.view', 'orders.update',
+              'dispatch.view', 'ratings.review'], true)
+ON CONFLICT (slug) DO NOTHING;
+
+-- Existing admins keep full access
+INSERT INTO admin_role_assignments (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+CROSS JOIN admin_roles r
+WHERE u.role = 'admin' AND u.deleted_at IS NULL AND r.slug = 'super_admin'
+ON CONFLICT DO NOTHING;
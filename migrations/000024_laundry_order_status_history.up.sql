@@ -0,0 +1,20 @@
+-- =====================================================
+-- LAUNDRY ORDER STATUS HISTORY
+-- Audit trail of laundry order status changes; the
+-- home-service order_status_history table only references
+-- service_orders
+-- =====================================================
+
+CREATE TABLE IF NOT EXISTS laundry_order_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES laundry_orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    changed_by UUID REFERENCES users(id),
+    changed_by_role VARCHAR(50),
+    notes TEXT,
+    metadata JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_laundry_order_status_history_order_id ON laundry_order_status_history(order_id, created_at);